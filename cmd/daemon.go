@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/bangarangler/burnrate/internal/config"
+	"github.com/bangarangler/burnrate/internal/daemon"
+	"github.com/bangarangler/burnrate/internal/storage"
+	"github.com/bangarangler/burnrate/internal/tracker"
+	"github.com/spf13/cobra"
+)
+
+// How often the daemon publishes its state and an attached dashboard reads it
+const daemonStateInterval = 2 * time.Second
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Keep recording usage in the background without the dashboard",
+	Long: `Starts every tool watcher and records usage to the history database
+until interrupted, without opening the TUI. Pricing is refreshed periodically.
+
+Only one daemon can run at a time (see ~/.burnrate/daemon.pid). While it runs,
+'burnrate dashboard' shows the daemon's live state instead of starting its
+own watchers, so nothing is counted twice.
+
+Examples:
+  burnrate daemon
+  burnrate daemon --tag client-a
+  burnrate daemon --aider-log ~/.aider/usage.jsonl > ~/.burnrate/daemon.out &`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := daemon.Acquire(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		defer daemon.Release()
+
+		if err := storage.InitDB(); err != nil {
+			fmt.Printf("Error initializing DB: %v\n", err)
+			return
+		}
+		defer startBatchWriter()()
+
+		// Refresh pricing in the background (UpdatePricing rate-limits itself)
+		go func() {
+			for {
+				updatePricing()
+				time.Sleep(time.Hour)
+			}
+		}()
+
+		tracker.Global.SetTag(sessionTag)
+		startWatchers()
+		if config.Load().AutoReset == "daily" {
+			go autoResetDaily(false)
+		}
+		fmt.Printf("burnrate daemon running (pid %d)\n", os.Getpid())
+
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+
+		ticker := time.NewTicker(daemonStateInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if daemon.TakeResetRequest() {
+					tracker.Global.Reset()
+				}
+				tracker.Global.RefreshToolStatuses()
+				_ = daemon.WriteState(tracker.Global.Snapshot())
+			case <-sig:
+				return
+			}
+		}
+	},
+}
+
+// attachToDaemon mirrors a running daemon's state into tracker.Global so the
+// dashboard can display it without running duplicate watchers
+func attachToDaemon() {
+	for {
+		if snap, err := daemon.ReadState(); err == nil {
+			tracker.Global.LoadSnapshot(snap)
+		}
+		time.Sleep(daemonStateInterval)
+	}
+}
+
+// yieldWritesToDaemon keeps a standalone dashboard from recording while a
+// daemon is running. The daemon owns history writes; the dashboard's own
+// watchers keep feeding its session view.
+func yieldWritesToDaemon() {
+	for {
+		_, running := daemon.Running()
+		storage.SetReadOnly(running)
+		time.Sleep(daemonStateInterval)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+
+	daemonCmd.Flags().StringVar(&aiderLogPath, "aider-log", "", aiderLogFlagUsage)
+
+	daemonCmd.Flags().StringVar(&crushDBPath, "crush-db", "",
+		"Path to Crush SQLite database (default: .crush/crush.db)")
+
+	daemonCmd.Flags().StringVar(&openCodePath, "opencode-path", "",
+		"Path to OpenCode data directory (default: $XDG_DATA_HOME/opencode or ~/.local/share/opencode)")
+
+	daemonCmd.Flags().StringVar(&sessionTag, "tag", "",
+		"Label every recorded event, e.g. with a client or project")
+
+	daemonCmd.Flags().StringVar(&zedDBPath, "zed-db", "",
+		"Path to Zed's assistant threads database (default: ~/.local/share/zed/threads/threads.db)")
+
+	daemonCmd.Flags().StringVar(&liteLLMLogPath, "litellm-log", "",
+		"Path to a LiteLLM proxy's JSONL log (default: ~/.litellm/spend_logs.jsonl, or $BURNRATE_LITELLM_LOG)")
+
+	daemonCmd.Flags().StringVar(&toolsFlag, "tools", "", toolsFlagUsage)
+}
@@ -4,67 +4,131 @@ Copyright 2025 burnrate authors
 package cmd
 
 import (
+	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
 
-	"github.com/bangarangler/burnrate/internal/parser"
+	"github.com/bangarangler/burnrate/internal/config"
+	"github.com/bangarangler/burnrate/internal/daemon"
+	"github.com/bangarangler/burnrate/internal/log"
 	"github.com/bangarangler/burnrate/internal/pricing"
 	"github.com/bangarangler/burnrate/internal/storage"
 	"github.com/bangarangler/burnrate/internal/tracker"
 	"github.com/bangarangler/burnrate/internal/tui"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/term"
 	"github.com/spf13/cobra"
 )
 
 var aiderLogPath string
 var crushDBPath string
+var zedDBPath string
+var liteLLMLogPath string
+var openCodePath string
+var dashboardCompact bool
+var dashboardSnapshot bool
+var sessionTag string
+var toolsFlag string
+var dashboardBudget float64
+var sessionCap float64
+var eventTail int
+var onCap string
+var capActions string
+
+// aiderLogFlagUsage is the help for --aider-log, shared like toolsFlagUsage
+const aiderLogFlagUsage = "Comma-separated Aider analytics JSONL logs to watch (default: every log found in ~/.aider and the current directory, or ~/.aider/usage.jsonl)"
+
+// toolsFlagUsage is the help for --tools, shared by every command that watches
+const toolsFlagUsage = "Comma-separated tools to watch, e.g. opencode,aider (default: all, or $BURNRATE_TOOLS)"
 
 // dashboardCmd represents the dashboard command
 var dashboardCmd = &cobra.Command{
 	Use:   "dashboard",
 	Short: "Launch the live cost dashboard",
-	Long:  `Opens a terminal dashboard showing your current AI spend, burn rate, and tool status.`,
-	Run: func(cmd *cobra.Command, args []string) {
+	Long: `Opens a terminal dashboard showing your current AI spend, burn rate, and tool status.
+
+With --snapshot the dashboard is rendered once to stdout, without the full
+screen or key handling, and burnrate exits: a plain-text spend picture for
+cron jobs, emails, or logs. Colors are kept only on a terminal without
+NO_COLOR set.
+
+Examples:
+  burnrate dashboard
+  burnrate dashboard --snapshot >> ~/spend.log`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := config.Load()
+		if dashboardBudget < 0 {
+			return fmt.Errorf("--budget must be positive")
+		}
+		if sessionCap < 0 {
+			return fmt.Errorf("--session-cap must be positive")
+		}
+		if eventTail < 0 {
+			return fmt.Errorf("--tail must be positive")
+		}
+		if dashboardBudget > 0 {
+			cfg.DailyBudget = dashboardBudget
+		}
+		if sessionCap > 0 {
+			cfg.SessionCap = sessionCap
+		}
+		if eventTail > 0 {
+			cfg.EventTail = eventTail
+		}
+		if onCap != "" {
+			cfg.OnCap = onCap
+		}
+		if capActions != "" {
+			cfg.CapActions = config.ParseTools(capActions)
+		}
+
+		if dashboardSnapshot {
+			printDashboardSnapshot(cfg)
+			return nil
+		}
+
+		// The TUI owns the terminal, so logs go to a file from here on
+		if err := log.ToFile(log.DefaultFile()); err == nil {
+			defer log.Close()
+		}
+
 		// Initialize historical storage
 		if err := storage.InitDB(); err != nil {
-			// Fail gracefully - we can still run without history, but log it
-			// Ideally we'd show a warning in the TUI, but for now we proceed
+			// Fail gracefully - we can still run without history
+			log.Warnf("history disabled: %v", err)
 		}
+		defer startBatchWriter()()
 
 		// Initialize pricing (async fetch)
 		go func() {
 			// This will update the pricing map in the background
 			// If it fails, we just continue with hardcoded defaults
-			_ = pricing.UpdatePricing()
+			updatePricing()
 		}()
 
-		// Initialize tool watchers - they now report their own status to tracker
-
-		// OpenCode (Tier 1 - Full Tracking)
-		parser.StartOpenCodeWatcher()
-
-		// Aider (Tier 1 - Full Tracking)
-		parser.StartAiderWatcher(aiderLogPath)
-
-		// Codex (Tier 1 - Full Tracking, partial without OTEL)
-		parser.StartCodexWatcher()
-
-		// Crush (Tier 1 - Full Tracking)
-		parser.StartCrushWatcher(crushDBPath)
-
-		// Copilot (Tier 2 - Detection Only)
-		copilotStatus := parser.CheckCopilotStatus()
-		tracker.Global.SetToolStatus(tracker.ToolStatus{
-			Name:         "Copilot",
-			Tier:         tracker.TierDetectionOnly,
-			Status:       copilotStatus.StatusCode(),
-			Message:      copilotStatus.StatusMessage(),
-			DashboardURL: copilotStatus.DashboardURL,
-		})
+		if _, running := daemon.Running(); running {
+			// A daemon is already recording - mirror its state instead of
+			// starting duplicate watchers, and leave DB writes to it
+			storage.SetReadOnly(true)
+			go attachToDaemon()
+			if cfg.AutoReset == "daily" {
+				go autoResetDaily(true)
+			}
+		} else {
+			// Initialize tool watchers - they now report their own status to tracker
+			tracker.Global.SetTag(sessionTag)
+			startWatchers()
+			// If a daemon starts later, it takes over DB writes
+			go yieldWritesToDaemon()
+			if cfg.AutoReset == "daily" {
+				go autoResetDaily(false)
+			}
+		}
 
 		// Launch TUI
-		p := tea.NewProgram(tui.InitialModel(), tea.WithAltScreen())
+		p := tea.NewProgram(tui.InitialModel(cfg).WithCompact(dashboardCompact), tea.WithAltScreen())
 
 		// Handle graceful shutdown
 		sig := make(chan os.Signal, 1)
@@ -74,18 +138,95 @@ var dashboardCmd = &cobra.Command{
 			p.Send(tea.KeyMsg{Type: tea.KeyCtrlC})
 		}()
 
-		p.Run()
+		_, err := p.Run()
+		return err
 	},
 }
 
+// Snapshot size when stdout isn't a terminal to take it from
+const (
+	snapshotWidth  = 120
+	snapshotHeight = 40
+)
+
+// printDashboardSnapshot prints the dashboard rendered once (see --snapshot).
+// The session shown is a running daemon's; without one it's empty, and
+// history fills the rest.
+func printDashboardSnapshot(cfg *config.Config) {
+	if err := storage.InitDB(); err != nil {
+		log.Warnf("history disabled: %v", err)
+	}
+	pricing.LoadCache()
+	if _, running := daemon.Running(); running {
+		if snap, err := daemon.ReadState(); err == nil {
+			tracker.Global.LoadSnapshot(snap)
+		}
+	}
+
+	width, height := snapshotWidth, snapshotHeight
+	isTerminal := term.IsTerminal(os.Stdout.Fd())
+	if isTerminal {
+		if w, h, err := term.GetSize(os.Stdout.Fd()); err == nil {
+			width, height = w, h
+		}
+	}
+
+	view := tui.RenderOnce(cfg, dashboardCompact, width, height)
+	if !isTerminal || os.Getenv("NO_COLOR") != "" {
+		view = tui.PlainText(view)
+	}
+	fmt.Println(view)
+}
+
 func init() {
 	rootCmd.AddCommand(dashboardCmd)
 
 	// Aider analytics log path flag
-	dashboardCmd.Flags().StringVar(&aiderLogPath, "aider-log", "",
-		"Path to Aider analytics JSONL log file (default: ~/.aider/usage.jsonl)")
+	dashboardCmd.Flags().StringVar(&aiderLogPath, "aider-log", "", aiderLogFlagUsage)
 
 	// Crush database path flag
 	dashboardCmd.Flags().StringVar(&crushDBPath, "crush-db", "",
 		"Path to Crush SQLite database (default: .crush/crush.db)")
+
+	// Zed threads database path flag
+	dashboardCmd.Flags().StringVar(&zedDBPath, "zed-db", "",
+		"Path to Zed's assistant threads database (default: ~/.local/share/zed/threads/threads.db)")
+
+	dashboardCmd.Flags().StringVar(&liteLLMLogPath, "litellm-log", "",
+		"Path to a LiteLLM proxy's JSONL log (default: ~/.litellm/spend_logs.jsonl, or $BURNRATE_LITELLM_LOG)")
+
+	// OpenCode storage path flag
+	dashboardCmd.Flags().StringVar(&openCodePath, "opencode-path", "",
+		"Path to OpenCode data directory (default: $XDG_DATA_HOME/opencode or ~/.local/share/opencode)")
+
+	// Tools to watch flag
+	dashboardCmd.Flags().StringVar(&toolsFlag, "tools", "", toolsFlagUsage)
+
+	// Session tag flag
+	dashboardCmd.Flags().StringVar(&sessionTag, "tag", "",
+		"Label every recorded event, e.g. with a client or project (change with T)")
+
+	// Budget override flag
+	dashboardCmd.Flags().Float64Var(&dashboardBudget, "budget", 0,
+		"Daily budget in USD for this run only (default: $BURNRATE_DAILY_BUDGET or $5)")
+
+	// Session cap flags
+	dashboardCmd.Flags().Float64Var(&sessionCap, "session-cap", 0,
+		"Alert once this session's spend in USD passes this amount (default: $BURNRATE_SESSION_CAP, 0 = off)")
+	dashboardCmd.Flags().StringVar(&capActions, "cap-actions", "",
+		"Comma-separated alerts when the session cap is passed: banner, notify (default: banner, or $BURNRATE_CAP_ACTIONS)")
+	dashboardCmd.Flags().StringVar(&onCap, "on-cap", "",
+		`Shell command to run when the session cap is passed, e.g. "say stop spending" (or $BURNRATE_ON_CAP)`)
+
+	// Event list flag
+	dashboardCmd.Flags().IntVar(&eventTail, "tail", 0,
+		"Most events the events and top views list (default: $BURNRATE_EVENT_TAIL or 50, change with +/-)")
+
+	// Compact mode flag
+	dashboardCmd.Flags().BoolVar(&dashboardCompact, "compact", false,
+		"Start in a single-line view for small panes (toggle with c)")
+
+	// Snapshot flag
+	dashboardCmd.Flags().BoolVar(&dashboardSnapshot, "snapshot", false,
+		"Print the dashboard once as text and exit, e.g. for cron or email")
 }
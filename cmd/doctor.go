@@ -0,0 +1,242 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bangarangler/burnrate/internal/parser"
+	"github.com/bangarangler/burnrate/internal/pricing"
+	"github.com/bangarangler/burnrate/internal/storage"
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cobra"
+)
+
+// checkResult is the outcome of one doctor check. A "fail" is critical and
+// makes doctor exit non-zero; a "warn" is reported but doesn't.
+type checkResult struct {
+	name   string
+	status string // "pass", "warn", or "fail"
+	detail string
+	hint   string // How to fix it, shown for warn and fail
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check that burnrate can see your tools and record usage",
+	Long: `Runs a series of checks and prints pass/warn/fail for each, with a hint
+for anything that needs attention:
+
+  - the home directory resolves
+  - the history database opens and accepts writes
+  - the pricing API is reachable
+  - file watching (fsnotify) works
+  - each tool's log or data path exists and is readable
+
+Exits with status 1 if any critical check fails.
+
+Examples:
+  burnrate doctor
+  burnrate doctor --aider-log ~/work/.aider.analytics.jsonl
+  burnrate doctor --aider-log ~/.aider/usage.jsonl,.aider.analytics.jsonl`,
+	Run: func(cmd *cobra.Command, args []string) {
+		results := []checkResult{
+			checkHomeDir(),
+			checkDatabase(),
+			checkPricingAPI(),
+			checkFileWatching(),
+		}
+		for _, path := range parser.AiderLogPaths(aiderLogPath) {
+			results = append(results, checkPath("Aider log", path,
+				"Enable Aider analytics (--analytics-log) or pass --aider-log"))
+		}
+		results = append(results,
+			checkPath("OpenCode data", parser.OpenCodeMessageDir(openCodePath),
+				"Run OpenCode once, or pass --opencode-path"),
+			checkPath("Codex sessions", filepath.Join(parser.CodexDataDir(), "sessions"),
+				"Run Codex once, or set $CODEX_HOME"),
+			checkPath("Crush database", parser.CrushDBPath(crushDBPath),
+				"Run doctor from a project using Crush, or pass --crush-db"),
+			checkPath("Zed threads", parser.ZedDBPath(zedDBPath),
+				"Use Zed's assistant once, or pass --zed-db"),
+			checkPath("Gemini CLI chats", filepath.Join(parser.GeminiDataDir(), "tmp"),
+				"Run the Gemini CLI once"),
+			checkPath("LiteLLM log", parser.LiteLLMLogPath(liteLLMLogPath),
+				"Only needed behind a LiteLLM proxy: log calls as JSONL, or pass --litellm-log"),
+			checkCopilot(),
+			checkDetected("Amazon Q", parser.CheckAmazonQStatus(),
+				"Optional: install the Amazon Q CLI or IDE plugin to show its status"),
+			checkDetected("Tabnine", parser.CheckTabnineStatus(),
+				"Optional: install a Tabnine IDE plugin to show its status"),
+		)
+
+		failed := false
+		for _, r := range results {
+			printCheck(r)
+			if r.status == "fail" {
+				failed = true
+			}
+		}
+
+		fmt.Println()
+		if failed {
+			fmt.Println("Some critical checks failed.")
+			os.Exit(1)
+		}
+		fmt.Println("burnrate is ready to go.")
+	},
+}
+
+func printCheck(r checkResult) {
+	labels := map[string]string{"pass": "[PASS]", "warn": "[WARN]", "fail": "[FAIL]"}
+	fmt.Printf("%s %-18s %s\n", labels[r.status], r.name, r.detail)
+	if r.status != "pass" && r.hint != "" {
+		fmt.Printf("       %-18s -> %s\n", "", r.hint)
+	}
+}
+
+func checkHomeDir() checkResult {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return checkResult{"Home directory", "fail", err.Error(),
+			"Set $HOME so burnrate can find ~/.burnrate and your tools' logs"}
+	}
+	return checkResult{"Home directory", "pass", home, ""}
+}
+
+func checkDatabase() checkResult {
+	hint := "Check that ~/.burnrate exists and is writable by you"
+	if err := storage.InitDB(); err != nil {
+		return checkResult{"History database", "fail", err.Error(), hint}
+	}
+	if reason := storage.HistoryDisabled(); reason != "" {
+		return checkResult{"History database", "fail", "history disabled (" + reason + "), using a temporary database", hint}
+	}
+	if err := storage.CheckWritable(); err != nil {
+		return checkResult{"History database", "fail", "not writable: " + err.Error(), hint}
+	}
+	return checkResult{"History database", "pass", "opens and accepts writes", ""}
+}
+
+func checkPricingAPI() checkResult {
+	if pricing.Offline {
+		return checkResult{"Pricing API", "pass", "skipped (offline mode)", ""}
+	}
+	if err := pricing.Ping(); err != nil {
+		return checkResult{"Pricing API", "warn", err.Error(),
+			"Cached or built-in prices will be used; check your network or raise BURNRATE_PRICING_TIMEOUT"}
+	}
+	return checkResult{"Pricing API", "pass", pricing.PricingAPIURL, ""}
+}
+
+// checkFileWatching creates a temp file, watches it, and waits for a write event
+func checkFileWatching() checkResult {
+	hint := "Live updates won't work; on Linux, raise fs.inotify.max_user_watches and max_user_instances"
+
+	dir, err := os.MkdirTemp("", "burnrate-doctor")
+	if err != nil {
+		return checkResult{"File watching", "fail", err.Error(), "Check that the temp directory is writable"}
+	}
+	defer os.RemoveAll(dir)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return checkResult{"File watching", "fail", err.Error(), hint}
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(dir); err != nil {
+		return checkResult{"File watching", "fail", err.Error(), hint}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "probe"), []byte("probe"), 0644); err != nil {
+		return checkResult{"File watching", "fail", err.Error(), hint}
+	}
+
+	select {
+	case <-watcher.Events:
+		return checkResult{"File watching", "pass", "fsnotify delivered events", ""}
+	case err := <-watcher.Errors:
+		return checkResult{"File watching", "fail", err.Error(), hint}
+	case <-time.After(2 * time.Second):
+		return checkResult{"File watching", "fail", "no event within 2s", hint}
+	}
+}
+
+// checkPath reports whether a tool's file or directory exists and can be read.
+// A missing path only warns, since the tool may simply not be installed.
+func checkPath(name, path, hint string) checkResult {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return checkResult{name, "warn", "not found: " + path, hint}
+	}
+	if err == nil {
+		err = checkReadable(path, info.IsDir())
+	}
+	if err != nil {
+		return checkResult{name, "fail", err.Error(), "Fix the permissions on " + path}
+	}
+	return checkResult{name, "pass", path, ""}
+}
+
+func checkReadable(path string, isDir bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if isDir {
+		_, err = f.Readdirnames(1)
+	} else {
+		_, err = f.Read(make([]byte, 1))
+	}
+	if err == io.EOF {
+		return nil
+	}
+	return err
+}
+
+func checkCopilot() checkResult {
+	status := parser.CheckCopilotStatus()
+	if !status.IsInstalled() {
+		return checkResult{"Copilot", "warn", status.StatusMessage(),
+			"Optional: install the gh copilot extension to show Copilot's status"}
+	}
+	return checkResult{"Copilot", "pass", status.StatusMessage(), ""}
+}
+
+// detectedTool is a Tier 2 tool's detection status
+type detectedTool interface {
+	IsInstalled() bool
+	StatusCode() string
+	StatusMessage() string
+}
+
+// checkDetected reports a Tier 2 tool as found once it's installed or set up
+func checkDetected(name string, status detectedTool, hint string) checkResult {
+	if status.IsInstalled() || status.StatusCode() == "configured" {
+		return checkResult{name, "pass", status.StatusMessage(), ""}
+	}
+	return checkResult{name, "warn", status.StatusMessage(), hint}
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+
+	doctorCmd.Flags().StringVar(&aiderLogPath, "aider-log", "", aiderLogFlagUsage)
+
+	doctorCmd.Flags().StringVar(&crushDBPath, "crush-db", "",
+		"Path to Crush SQLite database (default: .crush/crush.db)")
+
+	doctorCmd.Flags().StringVar(&openCodePath, "opencode-path", "",
+		"Path to OpenCode data directory (default: $XDG_DATA_HOME/opencode or ~/.local/share/opencode)")
+
+	doctorCmd.Flags().StringVar(&zedDBPath, "zed-db", "",
+		"Path to Zed's assistant threads database (default: ~/.local/share/zed/threads/threads.db)")
+
+	doctorCmd.Flags().StringVar(&liteLLMLogPath, "litellm-log", "",
+		"Path to a LiteLLM proxy's JSONL log (default: ~/.litellm/spend_logs.jsonl, or $BURNRATE_LITELLM_LOG)")
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/bangarangler/burnrate/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var importFormat string
+
+var importCmd = &cobra.Command{
+	Use:   "import [file]",
+	Short: "Backfill history from a CSV or JSON file",
+	Long: `Imports usage events into the history database. Useful when switching
+machines or recovering a wiped history.db.
+
+Rows need timestamp (unix seconds or RFC3339), tool, and model, plus optional
+prompt_tokens, completion_tokens, and cost. Events already in the database
+are skipped, so importing the same file twice is safe. Malformed rows are
+skipped and listed, the rest still imported, and the exit status is 1.
+
+Examples:
+  burnrate import --format csv usage.csv
+  burnrate import --format json usage.jsonl`,
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := storage.InitDB(); err != nil {
+			return fmt.Errorf("initializing DB: %w", err)
+		}
+
+		file, err := os.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("opening file: %w", err)
+		}
+		defer file.Close()
+
+		imported, err := storage.ImportEvents(file, importFormat)
+
+		var importErr *storage.ImportError
+		if errors.As(err, &importErr) {
+			for _, s := range importErr.Skipped {
+				fmt.Printf("Skipped %s\n", s)
+			}
+			return fmt.Errorf("imported %d events, %w", imported, err)
+		} else if err != nil {
+			return fmt.Errorf("importing: %w", err)
+		}
+
+		fmt.Printf("Imported %d events\n", imported)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+
+	importCmd.Flags().StringVar(&importFormat, "format", "csv",
+		"Input format: csv or json")
+}
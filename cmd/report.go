@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/bangarangler/burnrate/internal/config"
+	"github.com/bangarangler/burnrate/internal/report"
+	"github.com/bangarangler/burnrate/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var reportWindow string
+var reportFormat string
+var reportWebhook string
+var reportMailTo string
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Summarize spend for sharing, or post it to a webhook",
+	Long: `Builds a spend summary for a window: the total, budget status, change from
+the prior period, and the most expensive models. It is printed as text,
+Markdown, or JSON, or posted as JSON to a webhook with --webhook. The
+payload's "text" field holds the digest, so Slack incoming webhooks show it
+as is.
+
+With --mail-to, the text digest is preceded by mail headers for sendmail -t.
+
+Examples:
+  burnrate report --window week
+  burnrate report --format markdown > spend.md
+  burnrate report --window week --webhook https://hooks.slack.com/services/...
+  burnrate report --window week --mail-to team@example.com | sendmail -t
+
+  # Every Friday at 17:00, from crontab
+  0 17 * * 5 burnrate report --window week --webhook https://hooks.slack.com/services/...`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if reportFormat != "text" && reportFormat != "markdown" && reportFormat != "json" {
+			fmt.Fprintf(os.Stderr, "Error: invalid format %q (use text, markdown, or json)\n", reportFormat)
+			os.Exit(1)
+		}
+
+		if err := storage.InitDB(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error initializing DB: %v\n", err)
+			os.Exit(1)
+		}
+
+		r, err := report.Build(reportWindow, config.Load(), time.Now())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if reportWebhook != "" {
+			if err := report.Send(reportWebhook, r); err != nil {
+				fmt.Fprintf(os.Stderr, "Error sending report: %v\n", err)
+				os.Exit(1)
+			}
+			fmt.Println("Report sent.")
+			return
+		}
+
+		switch reportFormat {
+		case "json":
+			out, _ := json.MarshalIndent(r, "", "  ")
+			fmt.Println(string(out))
+		case "markdown":
+			fmt.Print(r.Markdown())
+		default:
+			if reportMailTo != "" {
+				fmt.Printf("To: %s\nSubject: %s\nContent-Type: text/plain; charset=utf-8\n\n", reportMailTo, r.Title())
+			}
+			fmt.Print(r.Text())
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(reportCmd)
+
+	reportCmd.Flags().StringVar(&reportWindow, "window", "week",
+		"Time window: today, week, month, or all")
+	reportCmd.Flags().StringVar(&reportFormat, "format", "text",
+		"Output format: text, markdown, or json")
+	reportCmd.Flags().StringVar(&reportWebhook, "webhook", "",
+		"Post the report as JSON to this URL instead of printing it")
+	reportCmd.Flags().StringVar(&reportMailTo, "mail-to", "",
+		"Add To and Subject headers to the text report, for piping to sendmail -t")
+}
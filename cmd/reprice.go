@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/bangarangler/burnrate/internal/config"
+	"github.com/bangarangler/burnrate/internal/pricing"
+	"github.com/bangarangler/burnrate/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+var repriceDryRun bool
+var repriceAll bool
+var repriceTool string
+
+var repriceCmd = &cobra.Command{
+	Use:   "reprice",
+	Short: "Recompute the cost of recorded events with current pricing",
+	Long: `Recomputes the stored cost of recorded events from their tokens using
+current pricing, e.g. after a model was added to the pricing map. Free
+(:free) models are set to zero. Events for models that still have no price
+are left alone, since repricing them would repeat the same guess.
+
+Only events that were priced with the fallback for unknown models
+(BURNRATE_FALLBACK_MODEL's rates) are changed unless --all is given, so
+costs reported by the tools themselves are kept. Tokens are priced as the
+tracker prices them, reasoning at the model's reasoning rate.
+
+Examples:
+  burnrate reprice --dry-run
+  burnrate reprice --all
+  burnrate reprice --tool Aider`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := storage.InitDB(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error initializing DB: %v\n", err)
+			os.Exit(1)
+		}
+
+		// Reprice with the freshest prices available
+		_ = pricing.UpdatePricing()
+
+		changes, err := storage.RepriceEvents(storage.UsageFilter{Tool: repriceTool}, repriceEvent, repriceDryRun)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(changes) == 0 {
+			fmt.Println("All recorded costs match current pricing.")
+			return
+		}
+
+		var events int
+		var oldTotal, newTotal float64
+		fmt.Printf("%-35s | %8s | %12s | %12s\n", "Model", "Events", "Old cost", "New cost")
+		fmt.Println(strings.Repeat("-", 78))
+		for _, c := range changes {
+			fmt.Printf("%-35s | %8d | %12s | %12s\n", c.Model, c.Events, config.FormatMoney(c.OldCost), config.FormatMoney(c.NewCost))
+			events += c.Events
+			oldTotal += c.OldCost
+			newTotal += c.NewCost
+		}
+		fmt.Println(strings.Repeat("-", 78))
+		fmt.Printf("%-35s | %8d | %12s | %12s\n", "Total", events, config.FormatMoney(oldTotal), config.FormatMoney(newTotal))
+
+		fmt.Println()
+		if repriceDryRun {
+			fmt.Printf("Dry run: %d events would be updated. Run without --dry-run to apply.\n", events)
+		} else {
+			fmt.Printf("Updated %d events.\n", events)
+		}
+	},
+}
+
+// repriceEvent prices a stored event from its tokens with current pricing.
+// Without --all, only :free and fallback-priced events are repriced.
+func repriceEvent(e storage.UsageEvent) (cost, savings float64, ok bool) {
+	free := strings.Contains(e.Model, ":free")
+	if !pricing.IsKnown(e.Model) && !free {
+		return 0, 0, false
+	}
+	if !repriceAll && !free && !wasFallbackPriced(e) {
+		return 0, 0, false
+	}
+
+	cost = pricing.CostFromTokens(e.Model, e.PromptTokens, e.CacheReadTokens, e.CompletionTokens, e.ReasoningTokens)
+	savings = pricing.CalculateCacheSavings(e.Model, e.CacheReadTokens)
+	return cost, savings, true
+}
+
+// wasFallbackPriced reports whether an event's stored cost is exactly what
+// the fallback for unknown models charges for its tokens. An unknown model
+// has no cache pricing, so its cached tokens were billed as input.
+func wasFallbackPriced(e storage.UsageEvent) bool {
+	fallback := pricing.CalculateCost(pricing.FallbackModel, e.PromptTokens,
+		e.CompletionTokens-e.ReasoningTokens, e.ReasoningTokens)
+	return fallback > 0 && math.Abs(e.Cost-fallback) <= fallback*1e-6
+}
+
+func init() {
+	rootCmd.AddCommand(repriceCmd)
+
+	repriceCmd.Flags().BoolVar(&repriceDryRun, "dry-run", false,
+		"Report what would change without updating history")
+	repriceCmd.Flags().BoolVar(&repriceAll, "all", false,
+		"Also reprice costs the tools reported themselves, not just fallback-priced events")
+	repriceCmd.Flags().StringVar(&repriceTool, "tool", "",
+		"Only reprice events recorded from this tool")
+}
@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bangarangler/burnrate/internal/daemon"
+	"github.com/bangarangler/burnrate/internal/tracker"
+	"github.com/spf13/cobra"
+)
+
+var resetCmd = &cobra.Command{
+	Use:   "reset",
+	Short: "Start a new session without asking",
+	Long: `Clears the current session, the same as pressing r in the dashboard.
+
+If a daemon is running it starts a new session within a couple of seconds,
+and attached dashboards follow. Otherwise any session state left behind by
+a daemon is removed. History is never touched.
+
+Set BURNRATE_AUTO_RESET=daily to start a new session automatically when the
+day changes, so Session always means today's session.
+
+Examples:
+  burnrate reset`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if pid, running := daemon.Running(); running {
+			if err := daemon.RequestReset(); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				return
+			}
+			fmt.Printf("Asked the daemon (pid %d) to start a new session\n", pid)
+			return
+		}
+
+		if err := daemon.ClearState(); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		fmt.Println("Session reset")
+	},
+}
+
+// autoResetDaily starts a new session whenever the current one began on an
+// earlier day. An attached dashboard asks the daemon instead, since the
+// session it shows is the daemon's.
+func autoResetDaily(attached bool) {
+	for {
+		if tracker.Global.StartedBeforeToday() {
+			if attached {
+				_ = daemon.RequestReset()
+			} else {
+				tracker.Global.Reset()
+			}
+		}
+		time.Sleep(daemonStateInterval)
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(resetCmd)
+}
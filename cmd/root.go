@@ -4,11 +4,29 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"context"
+	"fmt"
 	"os"
+	"path/filepath"
+	"time"
 
+	"github.com/bangarangler/burnrate/internal/config"
+	"github.com/bangarangler/burnrate/internal/log"
+	"github.com/bangarangler/burnrate/internal/parser"
+	"github.com/bangarangler/burnrate/internal/pricing"
+	"github.com/bangarangler/burnrate/internal/storage"
+	"github.com/bangarangler/burnrate/internal/tracker"
 	"github.com/spf13/cobra"
 )
 
+var (
+	logLevelFlag  string
+	verboseFlag   bool
+	precisionFlag int
+	offlineFlag   bool
+	pricingURL    string
+)
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "burnrate",
@@ -17,6 +35,113 @@ var rootCmd = &cobra.Command{
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
 	// Run: func(cmd *cobra.Command, args []string) { },
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		// Apply settings shared by every subcommand
+		cfg, warnings, err := config.LoadChecked()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid configuration:\n%v\n", err)
+			os.Exit(1)
+		}
+		configureLogging(cfg)
+		for _, warning := range warnings {
+			log.Warnf("config: %s", warning)
+		}
+		pricing.FetchTimeout = cfg.PricingTimeout
+		pricing.Offline = cfg.Offline || offlineFlag
+		if pricingURL != "" {
+			cfg.PricingURL = pricingURL
+		}
+		if cfg.PricingURL != "" {
+			pricing.PricingAPIURL = cfg.PricingURL
+		}
+		pricing.PricingAPIToken = cfg.PricingToken
+		pricing.FallbackModel = cfg.FallbackModel
+		if liteLLMLogPath == "" {
+			liteLLMLogPath = cfg.LiteLLMLog
+		}
+		pricing.LocalProviders = cfg.LocalProviders
+		if cfg.ProviderMultipliers != nil {
+			pricing.ProviderMultipliers = cfg.ProviderMultipliers
+		}
+		if cfg.ModelFamilies != "" {
+			if families, err := pricing.ParseFamilies(cfg.ModelFamilies); err != nil {
+				log.Warnf("ignoring BURNRATE_MODEL_FAMILIES: %v", err)
+			} else {
+				pricing.ModelFamilies = append(families, pricing.ModelFamilies...)
+			}
+		}
+		tracker.Global.SetLocalCost(cfg.LocalCostPerHour, cfg.LocalCostPerRequest)
+		tracker.Global.SetIdleThreshold(cfg.IdleThreshold)
+		tracker.Global.SetToolActiveThreshold(cfg.ToolActiveThreshold)
+		tracker.Global.SetToolSort(cfg.ToolSort)
+		tracker.Global.SetLargeContextThreshold(cfg.LargeContextTokens)
+		tracker.Global.SetMaxEventCost(cfg.MaxEventCost)
+		config.SetCurrency(cfg.CurrencySymbol, cfg.CurrencyRate)
+		if cmd.Flags().Changed("precision") {
+			cfg.Precision = precisionFlag
+		}
+		config.SetPrecision(cfg.Precision)
+		parser.TrustToolCost = cfg.CostSource != "recompute"
+		parser.DedupMaxEntries = cfg.DedupMaxEntries
+		parser.DedupMaxAge = cfg.DedupMaxAge
+		parser.OpenCodeStartupScan = cfg.OpenCodeScan
+		if cfg.Timezone != "" {
+			if loc, err := time.LoadLocation(cfg.Timezone); err != nil {
+				log.Warnf("ignoring timezone %q: %v", cfg.Timezone, err)
+			} else {
+				storage.SetLocation(loc)
+			}
+		}
+		if home, err := os.UserHomeDir(); err == nil {
+			pricing.CacheFile = filepath.Join(home, ".burnrate", "pricing_cache.json")
+		}
+	},
+}
+
+// configureLogging applies --verbose, then --log-level, then the config/env level
+func configureLogging(cfg *config.Config) {
+	levelName := cfg.LogLevel
+	if logLevelFlag != "" {
+		levelName = logLevelFlag
+	}
+	if verboseFlag {
+		levelName = "debug"
+	}
+
+	level, err := log.ParseLevel(levelName)
+	if err != nil {
+		log.Warnf("%v", err)
+	}
+	log.SetLevel(level)
+}
+
+// updatePricing fetches prices (see pricing.UpdatePricing), then re-prices
+// the session's events that were estimated at fallback rates for models that
+// now have a price
+func updatePricing() {
+	if err := pricing.UpdatePricing(); err != nil {
+		return
+	}
+	if delta := tracker.Global.RepriceFallbacks(); delta != 0 {
+		log.Infof("pricing updated: session total adjusted by %s", config.FormatMoney(delta))
+	}
+}
+
+// startBatchWriter batches history writes when BURNRATE_BATCH_SIZE is above
+// one. Call the returned func before exiting to flush what's still buffered.
+func startBatchWriter() (stop func()) {
+	cfg := config.Load()
+	if cfg.BatchSize <= 1 {
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w := storage.StartBatchWriter(ctx, cfg.BatchSize, cfg.BatchInterval)
+	tracker.Global.SetBatchWriter(w)
+	return func() {
+		cancel()
+		w.Wait()
+	}
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -34,6 +159,16 @@ func init() {
 	// will be global for your application.
 
 	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.burnrate.yaml)")
+	rootCmd.PersistentFlags().StringVar(&logLevelFlag, "log-level", "",
+		"Log level: debug, info, warn, or error (default: warn, or $BURNRATE_LOG_LEVEL)")
+	rootCmd.PersistentFlags().BoolVarP(&verboseFlag, "verbose", "v", false,
+		"Enable debug logging (same as --log-level debug)")
+	rootCmd.PersistentFlags().IntVar(&precisionFlag, "precision", config.DefaultPrecision,
+		"Decimals shown for costs; single requests get two more (or set $BURNRATE_PRECISION)")
+	rootCmd.PersistentFlags().BoolVar(&offlineFlag, "offline", false,
+		"Never fetch pricing; use built-in defaults and the disk cache (or set $BURNRATE_OFFLINE=1)")
+	rootCmd.PersistentFlags().StringVar(&pricingURL, "pricing-url", "",
+		"OpenRouter-compatible pricing endpoint (default: OpenRouter, or $BURNRATE_PRICING_URL)")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/bangarangler/burnrate/internal/daemon"
+	"github.com/bangarangler/burnrate/internal/tracker"
+	"github.com/spf13/cobra"
+)
+
+var sessionCmd = &cobra.Command{
+	Use:   "session",
+	Short: "Print the current live session's summary",
+	Long: `Prints the session a running daemon is tracking: its cost, burn rate, call
+count, duration, and costliest models. No TUI is shown, so it suits shell
+prompts and scripts.
+
+The session is read from the state file the daemon saves every couple of
+seconds. If the daemon has stopped, the last session it saved is shown.
+
+Examples:
+  burnrate session`,
+	Run: func(cmd *cobra.Command, args []string) {
+		snap, err := daemon.ReadState()
+		if os.IsNotExist(err) {
+			fmt.Println("No live session: no daemon has saved one. Start one with `burnrate daemon`.")
+			return
+		}
+		if err != nil {
+			fmt.Printf("Error: reading session state: %v\n", err)
+			return
+		}
+
+		tracker.Global.LoadSnapshot(snap)
+		if _, running := daemon.Running(); !running {
+			fmt.Println("The daemon isn't running; showing the last session it saved.")
+			fmt.Println()
+		}
+		fmt.Print(tracker.Global.GetSummary())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(sessionCmd)
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/bangarangler/burnrate/internal/config"
+	"github.com/bangarangler/burnrate/internal/log"
+	"github.com/bangarangler/burnrate/internal/storage"
+	"github.com/bangarangler/burnrate/internal/tracker"
+	"github.com/bangarangler/burnrate/internal/tui"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+)
+
+var simulateSpeed string
+var simulateTUI bool
+var simulatePersist bool
+
+var simulateCmd = &cobra.Command{
+	Use:   "simulate <events.jsonl>",
+	Short: "Replay recorded usage events, for demos and testing",
+	Long: `Replays usage events from a JSONL file through the tracker as if they were
+happening now, keeping the gaps between their timestamps (scaled by --speed).
+Each line is one event:
+
+  {"tool": "Aider", "model": "gpt-4o", "prompt_tokens": 1200, "completion_tokens": 300,
+   "cost": 0.006, "timestamp": "2025-01-01T10:00:00Z"}
+
+Events without a cost are priced from their tokens, and those without a tool
+are shown as "Simulated". With --tui the dashboard animates as they arrive.
+
+Nothing is written to history unless --persist is given.
+
+Examples:
+  burnrate simulate demo.jsonl
+  burnrate simulate demo.jsonl --speed 10x --tui
+  burnrate simulate demo.jsonl --speed max`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		speed, err := parseSpeed(simulateSpeed)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+
+		file, err := os.Open(args[0])
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		events, err := tracker.ReadReplay(file)
+		file.Close()
+		if err != nil {
+			fmt.Printf("Error: invalid events in %s:\n%v\n", args[0], err)
+			return
+		}
+
+		// The TUI owns the terminal, so logs go to a file from here on
+		if simulateTUI {
+			if err := log.ToFile(log.DefaultFile()); err == nil {
+				defer log.Close()
+			}
+		}
+
+		if simulatePersist {
+			if err := storage.InitDB(); err != nil {
+				fmt.Printf("Error initializing DB: %v\n", err)
+				return
+			}
+			defer startBatchWriter()()
+		}
+		go updatePricing()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		if simulateTUI {
+			p := tea.NewProgram(tui.InitialModel(config.Load()), tea.WithAltScreen())
+			go tracker.Global.Replay(ctx, events, speed, nil)
+			p.Run()
+			return
+		}
+
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+		go func() {
+			<-sig
+			cancel()
+		}()
+
+		n := tracker.Global.Replay(ctx, events, speed, func(u tracker.Usage) {
+			fmt.Printf("%s  %-10s %-30s %8d in %8d out  %s\n", time.Now().Format("15:04:05"),
+				u.Tool, u.Model, u.PromptTokens, u.CompletionTokens, config.FormatMoneyEvent(u.Cost))
+		})
+		fmt.Printf("Replayed %d of %d events: %s\n", n, len(events), config.FormatMoney(tracker.Global.GetSessionCost()))
+	},
+}
+
+// parseSpeed reads a replay speed such as "10x", "0.5", or "max" (no waiting)
+func parseSpeed(s string) (float64, error) {
+	if strings.EqualFold(s, "max") {
+		return 0, nil
+	}
+	speed, err := strconv.ParseFloat(strings.TrimSuffix(strings.ToLower(s), "x"), 64)
+	if err != nil || speed <= 0 {
+		return 0, fmt.Errorf("--speed must be a positive multiplier such as 10x, or max, got %q", s)
+	}
+	return speed, nil
+}
+
+func init() {
+	rootCmd.AddCommand(simulateCmd)
+
+	simulateCmd.Flags().StringVar(&simulateSpeed, "speed", "1x",
+		"Replay speed, e.g. 10x, or max to replay without waiting")
+	simulateCmd.Flags().BoolVar(&simulateTUI, "tui", false,
+		"Show the dashboard while replaying")
+	simulateCmd.Flags().BoolVar(&simulatePersist, "persist", false,
+		"Record replayed events to history")
+}
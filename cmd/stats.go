@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bangarangler/burnrate/internal/config"
+	"github.com/bangarangler/burnrate/internal/storage"
+	"github.com/bangarangler/burnrate/internal/tracker"
+	"github.com/spf13/cobra"
+)
+
+var statsWindow string
+var statsTag string
+var statsGroupBy string
+var statsProvider string
+var statsProject string
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Print spend per model from history",
+	Long: `Prints recorded spend per model for a time window, or per model family with
+--group-by family (see BURNRATE_MODEL_FAMILIES), or per provider or project
+with --group-by provider or --group-by project. With --tag, only events
+recorded under that tag (see 'dashboard --tag') are counted, and with
+--provider or --project only that provider's or project's; otherwise a
+breakdown by tag follows the model table.
+
+Providers are recorded with each event, as the tool reported them or from
+the pricing table. Projects are the git remote (host/owner/repo) or
+directory the tool was working in, for tools that report one: Crush,
+OpenCode, and Codex. Events recorded by older versions have neither.
+
+Per model or family, the total is followed by its split into input, cache,
+output, and reasoning, each priced at the model's rate for it. Reasoning is
+only known for tools that report it separately (OpenCode, Codex, Gemini
+CLI); elsewhere it's counted as output.
+
+Examples:
+  burnrate stats
+  burnrate stats --window month
+  burnrate stats --tag client-a --window all
+  burnrate stats --group-by family
+  burnrate stats --provider anthropic --window week
+  burnrate stats --project github.com/bangarangler/burnrate
+  burnrate stats --group-by project --window month`,
+	Run: func(cmd *cobra.Command, args []string) {
+		switch statsGroupBy {
+		case "model", "family", "provider", "project":
+		default:
+			fmt.Printf("Error: --group-by must be model, family, provider, or project, got %q\n", statsGroupBy)
+			return
+		}
+		filtered := statsTag != "" || statsProvider != "" || statsProject != ""
+		if statsGroupBy == "provider" && filtered {
+			fmt.Println("Error: --group-by provider can't be combined with --tag, --provider, or --project")
+			return
+		}
+		if err := storage.InitDB(); err != nil {
+			fmt.Printf("Error initializing DB: %v\n", err)
+			return
+		}
+
+		filter := storage.UsageFilter{Tag: statsTag, Provider: strings.ToLower(statsProvider), Project: statsProject}
+		usages, total, err := tracker.Global.GetFilteredHistoricalUsage(statsWindow, filter)
+		switch statsGroupBy {
+		case "provider":
+			usages, total, err = tracker.Global.GetUsageByProvider(statsWindow)
+		case "project":
+			usages, total, err = tracker.Global.GetUsageByProject(statsWindow, filter)
+		}
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		if len(usages) == 0 {
+			fmt.Println("No usage recorded for this window.")
+			return
+		}
+
+		// Per-model rows carry the token counts the split is priced from
+		var split *tracker.CostBreakdown
+		if statsGroupBy == "model" || statsGroupBy == "family" {
+			b := tracker.BreakdownCost(usages)
+			split = &b
+		}
+
+		label := "Model"
+		switch statsGroupBy {
+		case "family":
+			usages = tracker.GroupByFamily(usages)
+			label = "Family"
+		case "provider", "project":
+			for i := range usages {
+				if usages[i].Model == "" {
+					usages[i].Model = "(unknown)"
+				}
+			}
+			label = strings.ToUpper(statsGroupBy[:1]) + statsGroupBy[1:]
+		}
+
+		fmt.Printf("%-35s | %10s | %10s | %s\n", label, "Input", "Output", "Cost")
+		fmt.Println(strings.Repeat("-", 72))
+		for _, u := range usages {
+			fmt.Printf("%-35s | %10d | %10d | %s\n", u.Model, u.PromptTokens, u.CompletionTokens, config.FormatMoney(u.Cost))
+		}
+		fmt.Println(strings.Repeat("-", 72))
+		if !filtered {
+			prompt, completion, _ := tracker.Global.GetTokenTotals(statsWindow)
+			fmt.Printf("%-35s | %10d | %10d | %s\n", "Total", prompt, completion, config.FormatMoney(total))
+		} else {
+			fmt.Printf("%-35s | %10s | %10s | %s\n", "Total", "", "", config.FormatMoney(total))
+		}
+		if split != nil {
+			fmt.Printf("By token type: input %s · cache %s · output %s · reasoning %s",
+				config.FormatMoney(split.Input), config.FormatMoney(split.CacheRead),
+				config.FormatMoney(split.Output), config.FormatMoney(split.Reasoning))
+			if split.Other > 0 {
+				fmt.Printf(" · other %s", config.FormatMoney(split.Other))
+			}
+			fmt.Println()
+		}
+
+		if filtered {
+			return
+		}
+
+		byTag, err := tracker.Global.GetSpendByTag(statsWindow)
+		if err != nil || len(byTag) < 2 {
+			// Nothing to break down when everything shares one tag
+			return
+		}
+
+		tags := make([]string, 0, len(byTag))
+		for tag := range byTag {
+			tags = append(tags, tag)
+		}
+		sort.Slice(tags, func(i, j int) bool { return byTag[tags[i]] > byTag[tags[j]] })
+
+		fmt.Println()
+		fmt.Println("By tag:")
+		for _, tag := range tags {
+			label := tag
+			if label == "" {
+				label = "(untagged)"
+			}
+			fmt.Printf("  %-33s   %s\n", label, config.FormatMoney(byTag[tag]))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+
+	statsCmd.Flags().StringVar(&statsWindow, "window", "today",
+		"Time window: today, week, month, or all")
+	statsCmd.Flags().StringVar(&statsTag, "tag", "",
+		"Only count events recorded with this tag")
+	statsCmd.Flags().StringVar(&statsGroupBy, "group-by", "model",
+		"Rows per model, per model family such as Claude Sonnet, per provider, or per project (model, family, provider, or project)")
+	statsCmd.Flags().StringVar(&statsProvider, "provider", "",
+		"Only count events served by this provider, e.g. anthropic")
+	statsCmd.Flags().StringVar(&statsProject, "project", "",
+		"Only count events from this project, e.g. github.com/owner/repo")
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/bangarangler/burnrate/internal/config"
+	"github.com/bangarangler/burnrate/internal/parser"
+	"github.com/bangarangler/burnrate/internal/pricing"
+	"github.com/bangarangler/burnrate/internal/storage"
+	"github.com/bangarangler/burnrate/internal/tracker"
+	"github.com/spf13/cobra"
+)
+
+var syncOnce bool
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Backfill history from every tool's existing logs",
+	Long: `Parses the existing logs and databases of every supported tool and writes
+their usage to the history database, without opening the dashboard.
+
+Events are recorded under their own timestamps, so anything the dashboard
+already recorded is skipped rather than counted twice.
+
+With --once the command exits after the backfill, which makes it suitable
+for cron. Without it, burnrate keeps watching for new usage until interrupted.
+
+Examples:
+  burnrate sync --once
+  burnrate sync --once --aider-log ~/.aider/usage.jsonl`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := storage.InitDB(); err != nil {
+			fmt.Printf("Error initializing DB: %v\n", err)
+			return
+		}
+
+		// Pricing is only needed for tools that don't report their own cost
+		_ = pricing.UpdatePricing()
+
+		backfillHistory()
+
+		usages := tracker.Global.GetUsages()
+		fmt.Printf("Processed %d events (%s)\n", len(usages), config.FormatMoney(tracker.Global.GetSessionCost()))
+
+		if syncOnce {
+			return
+		}
+
+		// Keep recording until signaled
+		startWatchers()
+
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+		<-sig
+	},
+}
+
+// backfillHistory runs every parser's one-shot scan
+func backfillHistory() {
+	tools := enabledTools()
+
+	if config.ToolEnabled(tools, "OpenCode") {
+		parser.ParseOpenCodeOnce(openCodePath)
+	}
+	if config.ToolEnabled(tools, "Aider") {
+		parser.ParseAiderLogOnce(aiderLogPath)
+	}
+	if config.ToolEnabled(tools, "Codex") {
+		parser.ParseCodexSessionsOnce()
+	}
+	if config.ToolEnabled(tools, "Zed") {
+		parser.ParseZedDBOnce(zedDBPath)
+	}
+	if config.ToolEnabled(tools, "Gemini CLI") {
+		parser.ParseGeminiSessionsOnce()
+	}
+	if config.ToolEnabled(tools, "LiteLLM") {
+		parser.ParseLiteLLMLogOnce(liteLLMLogPath)
+	}
+	for _, cfg := range genericParsers(tools) {
+		parser.ParseGenericOnce(cfg)
+	}
+
+	if !config.ToolEnabled(tools, "Crush") {
+		return
+	}
+	if crushDBPath != "" {
+		parser.ParseCrushDBOnce(crushDBPath)
+	} else {
+		parser.ParseAllCrushDBs()
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+
+	syncCmd.Flags().BoolVar(&syncOnce, "once", false,
+		"Exit after backfilling instead of continuing to watch")
+
+	syncCmd.Flags().StringVar(&aiderLogPath, "aider-log", "", aiderLogFlagUsage)
+
+	syncCmd.Flags().StringVar(&crushDBPath, "crush-db", "",
+		"Path to Crush SQLite database (default: search common project directories)")
+
+	syncCmd.Flags().StringVar(&openCodePath, "opencode-path", "",
+		"Path to OpenCode data directory (default: $XDG_DATA_HOME/opencode or ~/.local/share/opencode)")
+
+	syncCmd.Flags().StringVar(&zedDBPath, "zed-db", "",
+		"Path to Zed's assistant threads database (default: ~/.local/share/zed/threads/threads.db)")
+
+	syncCmd.Flags().StringVar(&liteLLMLogPath, "litellm-log", "",
+		"Path to a LiteLLM proxy's JSONL log (default: ~/.litellm/spend_logs.jsonl, or $BURNRATE_LITELLM_LOG)")
+
+	syncCmd.Flags().StringVar(&toolsFlag, "tools", "", toolsFlagUsage)
+}
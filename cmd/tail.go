@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/bangarangler/burnrate/internal/daemon"
+	"github.com/bangarangler/burnrate/internal/storage"
+	"github.com/bangarangler/burnrate/internal/tracker"
+	"github.com/spf13/cobra"
+)
+
+var tailAll bool
+
+// tailEvent is one line of `burnrate tail` output
+type tailEvent struct {
+	Timestamp        string  `json:"timestamp"`
+	Tool             string  `json:"tool"`
+	Model            string  `json:"model"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	Cost             float64 `json:"cost"`
+}
+
+var tailCmd = &cobra.Command{
+	Use:   "tail",
+	Short: "Stream usage events as JSON Lines",
+	Long: `Watches every supported tool and prints one JSON object per usage event to
+stdout as it arrives, for piping into jq or other tools. No TUI is shown.
+
+Events already in the tools' logs when tail starts are skipped unless --all
+is given. Logs go to stderr, so stdout only ever carries events.
+
+Examples:
+  burnrate tail | jq .cost
+  burnrate tail --all > events.jsonl`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := storage.InitDB(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error initializing DB: %v\n", err)
+		}
+		defer startBatchWriter()()
+
+		go func() {
+			updatePricing()
+		}()
+
+		// Subscribe before the watchers replay existing logs
+		events, unsubscribe := tracker.Global.Subscribe()
+		defer unsubscribe()
+		start := tracker.Global.StartTime
+
+		// Leave history writes to a running daemon, as the dashboard does
+		if _, running := daemon.Running(); running {
+			storage.SetReadOnly(true)
+		} else {
+			go yieldWritesToDaemon()
+		}
+		startWatchers()
+
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+
+		// os.Stdout is unbuffered, so each event reaches the pipe as soon as
+		// it's encoded
+		enc := json.NewEncoder(os.Stdout)
+		for {
+			select {
+			case u := <-events:
+				if !tailAll && u.Timestamp.Before(start) {
+					continue
+				}
+				err := enc.Encode(tailEvent{
+					Timestamp:        u.Timestamp.Format(time.RFC3339),
+					Tool:             u.Tool,
+					Model:            u.Model,
+					PromptTokens:     u.PromptTokens,
+					CompletionTokens: u.CompletionTokens,
+					Cost:             u.Cost,
+				})
+				if err != nil {
+					// Downstream closed the pipe
+					return
+				}
+			case <-sig:
+				return
+			}
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tailCmd)
+
+	tailCmd.Flags().BoolVar(&tailAll, "all", false,
+		"Also emit events already in the logs when tail starts")
+
+	tailCmd.Flags().StringVar(&aiderLogPath, "aider-log", "", aiderLogFlagUsage)
+
+	tailCmd.Flags().StringVar(&crushDBPath, "crush-db", "",
+		"Path to Crush SQLite database (default: .crush/crush.db)")
+
+	tailCmd.Flags().StringVar(&openCodePath, "opencode-path", "",
+		"Path to OpenCode data directory (default: $XDG_DATA_HOME/opencode or ~/.local/share/opencode)")
+
+	tailCmd.Flags().StringVar(&zedDBPath, "zed-db", "",
+		"Path to Zed's assistant threads database (default: ~/.local/share/zed/threads/threads.db)")
+
+	tailCmd.Flags().StringVar(&toolsFlag, "tools", "", toolsFlagUsage)
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/bangarangler/burnrate/internal/pricing"
+	"github.com/spf13/cobra"
+)
+
+// Build metadata, set at link time by mage build:
+// -X github.com/bangarangler/burnrate/cmd.Version=...
+var (
+	Version = "dev"
+	Commit  = ""
+)
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print version and build information",
+	Long: `Prints the binary's version and commit, the Go version it was built with,
+and the date the built-in pricing defaults were last updated. Include this
+when reporting an issue, especially about stale prices.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Printf("burnrate %s\n", Version)
+		if commit := buildCommit(); commit != "" {
+			fmt.Printf("Commit:     %s\n", commit)
+		}
+		fmt.Printf("Go version: %s\n", runtime.Version())
+		fmt.Printf("Pricing:    defaults as of %s\n", pricing.DefaultsAsOf)
+	},
+}
+
+// buildCommit returns the commit set at link time, falling back to the one
+// go build stamps when building from a git checkout
+func buildCommit() string {
+	if Commit != "" {
+		return Commit
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	var revision, modified string
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			revision = setting.Value
+		case "vcs.modified":
+			modified = setting.Value
+		}
+	}
+	if len(revision) > 12 {
+		revision = revision[:12]
+	}
+	if revision != "" && modified == "true" {
+		revision += "-dirty"
+	}
+	return revision
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+}
@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/bangarangler/burnrate/internal/config"
+	"github.com/bangarangler/burnrate/internal/log"
+	"github.com/bangarangler/burnrate/internal/parser"
+	"github.com/bangarangler/burnrate/internal/tracker"
+	"github.com/spf13/cobra"
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch <tool>",
+	Short: "Run one tool's watcher and print every event it parses",
+	Long: `Starts only the named tool's watcher, without the TUI, and prints each event
+it matches: the raw fields read from the tool, whether it was skipped as a
+duplicate, and the usage recorded with its computed cost. Debug logs go to
+stderr. Runs until interrupted.
+
+Nothing is written to history, so watching can't double count anything.
+
+Examples:
+  burnrate watch aider
+  burnrate watch opencode --opencode-path ~/opencode-data`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		tool, err := watchableTool(args[0])
+		if err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+
+		if !cmd.Flags().Changed("log-level") {
+			log.SetLevel(log.LevelDebug)
+		}
+
+		go func() {
+			updatePricing()
+		}()
+
+		// Trace and usage events arrive from different goroutines
+		var mu sync.Mutex
+		parser.Trace = func(e parser.TraceEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			printTraceEvent(e)
+		}
+		defer func() { parser.Trace = nil }()
+
+		events, unsubscribe := tracker.Global.Subscribe()
+		defer unsubscribe()
+
+		toolsFlag = tool
+		startWatchers()
+		for _, s := range tracker.Global.GetToolStatuses() {
+			if strings.EqualFold(s.Name, tool) {
+				fmt.Printf("%s: %s (%s)\n", s.Name, s.Status, s.Message)
+			}
+		}
+
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+		for {
+			select {
+			case u := <-events:
+				mu.Lock()
+				fmt.Printf("  recorded %s %s: %d in, %d out, cost %s\n",
+					u.Timestamp.Format(time.RFC3339), u.Model, u.PromptTokens, u.CompletionTokens,
+					config.FormatMoneyEvent(u.Cost))
+				mu.Unlock()
+			case <-sig:
+				return
+			}
+		}
+	},
+}
+
+// watchableTool returns the tool named by name, which must be one that
+// records usage: a built-in full-tracking tool or a custom parser
+func watchableTool(name string) (string, error) {
+	if config.ToolEnabled(detectionOnlyTools, name) {
+		return "", fmt.Errorf("%s is detection-only, so there are no events to watch", strings.ToLower(name))
+	}
+
+	var known []string
+	for _, tool := range builtinTools {
+		if !config.ToolEnabled(detectionOnlyTools, tool) {
+			known = append(known, tool)
+		}
+	}
+	for _, cfg := range genericParsers(nil) {
+		known = append(known, cfg.Name)
+	}
+	for _, tool := range known {
+		if strings.EqualFold(tool, name) {
+			return tool, nil
+		}
+	}
+	return "", fmt.Errorf("unknown tool %q (known: %s)", name, strings.Join(known, ", "))
+}
+
+// printTraceEvent prints a matched event's raw fields, sorted by name
+func printTraceEvent(e parser.TraceEvent) {
+	state := "new"
+	if e.Deduped {
+		state = "duplicate, skipped"
+	}
+	fmt.Printf("[%s] %s (%s)\n", e.Tool, e.Key, state)
+
+	names := make([]string, 0, len(e.Fields))
+	for name := range e.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("  %-20s %v\n", name+":", e.Fields[name])
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+
+	watchCmd.Flags().StringVar(&aiderLogPath, "aider-log", "", aiderLogFlagUsage)
+
+	watchCmd.Flags().StringVar(&crushDBPath, "crush-db", "",
+		"Path to Crush SQLite database (default: .crush/crush.db)")
+
+	watchCmd.Flags().StringVar(&openCodePath, "opencode-path", "",
+		"Path to OpenCode data directory (default: $XDG_DATA_HOME/opencode or ~/.local/share/opencode)")
+
+	watchCmd.Flags().StringVar(&zedDBPath, "zed-db", "",
+		"Path to Zed's assistant threads database (default: ~/.local/share/zed/threads/threads.db)")
+
+	watchCmd.Flags().StringVar(&liteLLMLogPath, "litellm-log", "",
+		"Path to a LiteLLM proxy's JSONL log (default: ~/.litellm/spend_logs.jsonl, or $BURNRATE_LITELLM_LOG)")
+}
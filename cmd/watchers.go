@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/bangarangler/burnrate/internal/config"
+	"github.com/bangarangler/burnrate/internal/log"
+	"github.com/bangarangler/burnrate/internal/parser"
+	"github.com/bangarangler/burnrate/internal/tracker"
+)
+
+// builtinTools are the tools burnrate knows how to watch, as named in
+// --tools and BURNRATE_TOOLS
+var builtinTools = []string{"OpenCode", "Aider", "Codex", "Crush", "Zed", "Gemini CLI", "LiteLLM", "Copilot", "Amazon Q", "Tabnine"}
+
+// detectionOnlyTools are the builtin tools burnrate can only detect: their
+// usage is shown on their own dashboards
+var detectionOnlyTools = []string{"Copilot", "Amazon Q", "Tabnine"}
+
+// startWatchers starts the watcher of every enabled tool. Each one reports
+// its own status to tracker.Global; tools that aren't enabled never appear.
+func startWatchers() {
+	tools := enabledTools()
+
+	// OpenCode (Tier 1 - Full Tracking)
+	if config.ToolEnabled(tools, "OpenCode") {
+		parser.StartOpenCodeWatcher(openCodePath)
+	}
+
+	// Aider (Tier 1 - Full Tracking)
+	if config.ToolEnabled(tools, "Aider") {
+		parser.StartAiderWatcher(aiderLogPath)
+	}
+
+	// Codex (Tier 1 - Full Tracking, partial without OTEL)
+	if config.ToolEnabled(tools, "Codex") {
+		parser.StartCodexWatcher()
+	}
+
+	// Crush (Tier 1 - Full Tracking)
+	if config.ToolEnabled(tools, "Crush") {
+		parser.StartCrushWatcher(crushDBPath)
+	}
+
+	// Zed (Tier 1 - Full Tracking)
+	if config.ToolEnabled(tools, "Zed") {
+		parser.StartZedWatcher(zedDBPath)
+	}
+
+	// Gemini CLI (Tier 1 - Full Tracking)
+	if config.ToolEnabled(tools, "Gemini CLI") {
+		parser.StartGeminiWatcher()
+	}
+
+	// LiteLLM proxy (Tier 1 - Full Tracking, for every tool routed through it)
+	if config.ToolEnabled(tools, "LiteLLM") {
+		parser.StartLiteLLMWatcher(liteLLMLogPath)
+	}
+
+	// User-defined JSONL logs (Tier 1 - Full Tracking)
+	for _, cfg := range genericParsers(tools) {
+		parser.StartGenericWatcher(cfg)
+	}
+
+	// Copilot (Tier 2 - Detection Only)
+	if config.ToolEnabled(tools, "Copilot") {
+		copilotStatus := parser.CheckCopilotStatus()
+		tracker.Global.SetToolStatus(tracker.ToolStatus{
+			Name:         "Copilot",
+			Tier:         tracker.TierDetectionOnly,
+			Status:       copilotStatus.StatusCode(),
+			Message:      copilotStatus.StatusMessage(),
+			DashboardURL: copilotStatus.DashboardURL,
+		})
+	}
+
+	// Amazon Q Developer (Tier 2 - Detection Only)
+	if config.ToolEnabled(tools, "Amazon Q") {
+		amazonQStatus := parser.CheckAmazonQStatus()
+		tracker.Global.SetToolStatus(tracker.ToolStatus{
+			Name:         "Amazon Q",
+			Tier:         tracker.TierDetectionOnly,
+			Status:       amazonQStatus.StatusCode(),
+			Message:      amazonQStatus.StatusMessage(),
+			DashboardURL: amazonQStatus.DashboardURL,
+		})
+	}
+
+	// Tabnine (Tier 2 - Detection Only)
+	if config.ToolEnabled(tools, "Tabnine") {
+		tabnineStatus := parser.CheckTabnineStatus()
+		tracker.Global.SetToolStatus(tracker.ToolStatus{
+			Name:         "Tabnine",
+			Tier:         tracker.TierDetectionOnly,
+			Status:       tabnineStatus.StatusCode(),
+			Message:      tabnineStatus.StatusMessage(),
+			DashboardURL: tabnineStatus.DashboardURL,
+		})
+	}
+}
+
+// enabledTools is --tools if set, otherwise BURNRATE_TOOLS. Names that match
+// no tool are warned about, since they're most likely typos.
+func enabledTools() []string {
+	tools := config.Load().EnabledTools
+	if toolsFlag != "" {
+		tools = config.ParseTools(toolsFlag)
+	}
+	if len(tools) == 0 {
+		return nil
+	}
+
+	known := append([]string{}, builtinTools...)
+	for _, cfg := range genericParsers(nil) {
+		known = append(known, cfg.Name)
+	}
+	for _, tool := range tools {
+		if !config.ToolEnabled(known, tool) {
+			log.Warnf("unknown tool %q in tools list (known: %s)", tool, strings.Join(known, ", "))
+		}
+	}
+	return tools
+}
+
+// genericParsers loads the enabled user-defined parsers in
+// ~/.burnrate/parsers.toml. A broken file disables them all rather than
+// half-applying it.
+func genericParsers(tools []string) []parser.GenericConfig {
+	configs, err := parser.LoadGenericConfigs(parser.DefaultGenericConfigPath())
+	if err != nil {
+		log.Warnf("custom parsers disabled: %v", err)
+		return nil
+	}
+
+	var enabled []parser.GenericConfig
+	for _, cfg := range configs {
+		if config.ToolEnabled(tools, cfg.Name) {
+			enabled = append(enabled, cfg)
+		}
+	}
+	return enabled
+}
@@ -2,26 +2,61 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"sort"
 	"strings"
+	"time"
 
+	"github.com/bangarangler/burnrate/internal/config"
 	"github.com/bangarangler/burnrate/internal/pricing"
 	"github.com/bangarangler/burnrate/internal/storage"
 	"github.com/bangarangler/burnrate/internal/tracker"
 	"github.com/spf13/cobra"
 )
 
+var (
+	whatIfFromFile  string
+	whatIfRecommend bool
+	whatIfMinTier   string
+	whatIfFamilies  []string
+	whatIfDays      int
+)
+
+// recommendRunnersUp is how many cheaper alternatives --recommend lists
+// after its pick
+const recommendRunnersUp = 4
+
 var whatIfCmd = &cobra.Command{
 	Use:   "whatif [model]",
 	Short: "Compare current session cost with another model",
 	Long: `Calculates what your current session would have cost if you had used
 a different model for all requests.
 
+With --from-file, prices a hypothetical workload instead: a CSV of
+prompt,completion token pairs, one request per line. History isn't read,
+so this works for planning before running anything.
+
+With --recommend, suggests the cheapest model that's plausibly as capable
+as the one you spend most on, pricing each of your recent requests on it,
+and projects the monthly savings. --min-tier and --family narrow the
+candidates. Capability tiers are coarse, so treat this as a starting point.
+
 Examples:
   burnrate whatif gpt-4
   burnrate whatif claude-3-opus
-  burnrate whatif (shows comparison with top models)`,
+  burnrate whatif (shows comparison with top models)
+  burnrate whatif --from-file workload.csv
+  burnrate whatif --recommend --min-tier standard`,
 	Run: func(cmd *cobra.Command, args []string) {
+		if whatIfRecommend {
+			runWhatIfRecommend()
+			return
+		}
+		if whatIfFromFile != "" {
+			runWhatIfWorkload(whatIfFromFile, args)
+			return
+		}
+
 		// Initialize DB first!
 		if err := storage.InitDB(); err != nil {
 			fmt.Printf("Error initializing DB: %v\n", err)
@@ -62,57 +97,200 @@ Examples:
 			printComparison(currentCost, hypotheticalCost, targetModel)
 		} else {
 			// Show comparison table with common models
-			commonModels := pricing.CommonModels
-
-			fmt.Printf("Current Session Cost: $%.4f\n", currentCost)
-			fmt.Println(strings.Repeat("-", 50))
-			fmt.Printf("%-30s | %-10s | %s\n", "Model", "Cost", "Diff")
-			fmt.Println(strings.Repeat("-", 50))
-
-			// Sort models by cost for better readability? No, stick to list order or sort by cost diff.
-			// Let's compute all first.
-			type result struct {
-				model string
-				cost  float64
-			}
-			var results []result
+			fmt.Printf("Current Session Cost: %s\n", config.FormatMoney(currentCost))
+			printWhatIfTable(whatIfCosts(totalPrompt, totalCompletion), currentCost)
+		}
+	},
+}
 
-			for _, m := range commonModels {
-				cost, err := pricing.CalculateHypotheticalCost(m, totalPrompt, totalCompletion)
-				if err == nil {
-					results = append(results, result{m, cost})
-				}
-			}
+// runWhatIfWorkload prices a workload file on one model, or on the common
+// models compared with the cheapest of them
+func runWhatIfWorkload(path string, args []string) {
+	file, err := os.Open(path)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	defer file.Close()
 
-			// Sort by cost ascending
-			sort.Slice(results, func(i, j int) bool {
-				return results[i].cost < results[j].cost
-			})
-
-			for _, res := range results {
-				diff := res.cost - currentCost
-				diffStr := fmt.Sprintf("+$%.4f", diff)
-				if diff < 0 {
-					diffStr = fmt.Sprintf("-$%.4f", -diff)
-				} else if diff == 0 {
-					diffStr = "="
-				}
-
-				fmt.Printf("%-30s | $%.4f  | %s\n", res.model, res.cost, diffStr)
-			}
+	workload, err := pricing.ReadWorkload(file)
+	if err != nil {
+		fmt.Printf("Error: invalid workload %s:\n%v\n", path, err)
+		return
+	}
+
+	pricing.UpdatePricing()
+
+	fmt.Printf("Workload: %d requests, %d prompt + %d completion tokens\n",
+		workload.Requests, workload.PromptTokens, workload.CompletionTokens)
+
+	if len(args) > 0 {
+		cost, err := pricing.CalculateHypotheticalCost(args[0], workload.PromptTokens, workload.CompletionTokens)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
 		}
-	},
+		fmt.Printf("Cost:     %s (%s)\n", config.FormatMoney(cost), args[0])
+		return
+	}
+
+	results := whatIfCosts(workload.PromptTokens, workload.CompletionTokens)
+	if len(results) == 0 {
+		return
+	}
+	fmt.Println("Diff is from the cheapest model")
+	printWhatIfTable(results, results[0].cost)
+}
+
+// runWhatIfRecommend prices the last whatIfDays of requests, one by one, on
+// every candidate model and recommends the cheapest
+func runWhatIfRecommend() {
+	minTier := pricing.TierUnknown
+	if whatIfMinTier != "" {
+		tier, err := pricing.ParseTier(whatIfMinTier)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			return
+		}
+		minTier = tier
+	}
+	if whatIfDays <= 0 {
+		fmt.Println("Error: --days must be positive")
+		return
+	}
+
+	if err := storage.InitDB(); err != nil {
+		fmt.Printf("Error initializing DB: %v\n", err)
+		return
+	}
+	pricing.UpdatePricing()
+
+	now := time.Now()
+	events, err := storage.GetEvents(now.AddDate(0, 0, -whatIfDays).Unix())
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+	if len(events) == 0 {
+		fmt.Printf("No usage in the last %d days to base a recommendation on.\n", whatIfDays)
+		return
+	}
+
+	var current float64
+	spend := make(map[string]float64)
+	requests := make([]pricing.Request, 0, len(events))
+	for _, e := range events {
+		current += e.Cost
+		spend[e.Model] += e.Cost
+		requests = append(requests, pricing.Request{
+			Prompt:     e.PromptTokens - e.CacheReadTokens,
+			CacheRead:  e.CacheReadTokens,
+			Completion: e.CompletionTokens - e.ReasoningTokens,
+			Reasoning:  e.ReasoningTokens,
+		})
+	}
+
+	// Without --min-tier, stay in the tier of the model most is spent on
+	top := ""
+	for model, cost := range spend {
+		if top == "" || cost > spend[top] || cost == spend[top] && model < top {
+			top = model
+		}
+	}
+	if minTier == pricing.TierUnknown {
+		minTier = pricing.TierOf(top)
+	}
+
+	// Scale to a month by the span the history covers, at least a day
+	span := now.Sub(time.Unix(events[0].Timestamp, 0))
+	span = min(max(span, 24*time.Hour), time.Duration(whatIfDays)*24*time.Hour)
+	perMonth := float64(30*24*time.Hour) / float64(span)
+
+	fmt.Printf("%d requests in the last %d days cost %s (most on %s, %s tier)\n",
+		len(events), whatIfDays, config.FormatMoney(current), top, pricing.TierOf(top))
+
+	candidates := pricing.RankCandidates(requests, minTier, whatIfFamilies)
+	if len(candidates) == 0 {
+		fmt.Println("No priced models match the tier and family filters.")
+		return
+	}
+
+	best := candidates[0]
+	fmt.Printf("\nRecommended:       %s (%s, %s tier)\n", best.Model, best.Family, best.Tier)
+	fmt.Printf("Would have cost:   %s\n", config.FormatMoney(best.Cost))
+	if savings := current - best.Cost; savings > 0 {
+		fmt.Printf("Savings:           %s (%.0f%%)\n", config.FormatMoney(savings), savings/current*100)
+		fmt.Printf("Projected monthly: %s saved\n", config.FormatMoney(savings*perMonth))
+	} else {
+		fmt.Println("Savings:           None; you're already on the cheapest match")
+	}
+
+	var cheaper []whatIfResult
+	for _, c := range candidates[1:min(len(candidates), 1+recommendRunnersUp)] {
+		if c.Cost < current {
+			cheaper = append(cheaper, whatIfResult{c.Model, c.Cost})
+		}
+	}
+	if len(cheaper) > 0 {
+		fmt.Println("\nAlso cheaper than now:")
+		printWhatIfTable(cheaper, current)
+	}
+
+	fmt.Println("\nPrices only: cheaper models may be less capable at your tasks, so try one")
+	fmt.Println("on real work before switching.")
+}
+
+// whatIfResult is what the tokens would cost on one model
+type whatIfResult struct {
+	model string
+	cost  float64
+}
+
+// whatIfCosts prices the tokens on each of the common models, cheapest first
+func whatIfCosts(prompt, completion int) []whatIfResult {
+	var results []whatIfResult
+	for _, m := range pricing.CommonModels {
+		cost, err := pricing.CalculateHypotheticalCost(m, prompt, completion)
+		if err == nil {
+			results = append(results, whatIfResult{m, cost})
+		}
+	}
+
+	// Sort by cost ascending
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].cost < results[j].cost
+	})
+	return results
+}
+
+// printWhatIfTable prints each model's cost and its difference from baseline
+func printWhatIfTable(results []whatIfResult, baseline float64) {
+	fmt.Println(strings.Repeat("-", 50))
+	fmt.Printf("%-30s | %-10s | %s\n", "Model", "Cost", "Diff")
+	fmt.Println(strings.Repeat("-", 50))
+
+	for _, res := range results {
+		diff := res.cost - baseline
+		diffStr := "+" + config.FormatMoney(diff)
+		if diff < 0 {
+			diffStr = config.FormatMoney(diff)
+		} else if diff == 0 {
+			diffStr = "="
+		}
+
+		fmt.Printf("%-30s | %-10s | %s\n", res.model, config.FormatMoney(res.cost), diffStr)
+	}
 }
 
 func printComparison(current, hypothetical float64, model string) {
-	fmt.Printf("Current Cost:       $%.4f\n", current)
-	fmt.Printf("Hypothetical Cost:  $%.4f (%s)\n", hypothetical, model)
+	fmt.Printf("Current Cost:       %s\n", config.FormatMoney(current))
+	fmt.Printf("Hypothetical Cost:  %s (%s)\n", config.FormatMoney(hypothetical), model)
 
 	diff := hypothetical - current
 	if diff > 0 {
-		fmt.Printf("Difference:         +$%.4f (%.1fx more expensive)\n", diff, hypothetical/current)
+		fmt.Printf("Difference:         +%s (%.1fx more expensive)\n", config.FormatMoney(diff), hypothetical/current)
 	} else if diff < 0 {
-		fmt.Printf("Savings:            $%.4f (%.1fx cheaper)\n", -diff, current/hypothetical)
+		fmt.Printf("Savings:            %s (%.1fx cheaper)\n", config.FormatMoney(-diff), current/hypothetical)
 	} else {
 		fmt.Println("Difference:         None")
 	}
@@ -120,4 +298,15 @@ func printComparison(current, hypothetical float64, model string) {
 
 func init() {
 	rootCmd.AddCommand(whatIfCmd)
+
+	whatIfCmd.Flags().StringVar(&whatIfFromFile, "from-file", "",
+		"Price a hypothetical workload: a CSV of prompt,completion token pairs")
+	whatIfCmd.Flags().BoolVar(&whatIfRecommend, "recommend", false,
+		"Recommend the cheapest plausibly capable model for your recent requests")
+	whatIfCmd.Flags().StringVar(&whatIfMinTier, "min-tier", "",
+		"With --recommend, the least capable tier to suggest: budget, standard, or frontier (default: that of your top model)")
+	whatIfCmd.Flags().StringSliceVar(&whatIfFamilies, "family", nil,
+		"With --recommend, only suggest models in these families, e.g. \"Claude Sonnet,GPT-5\"")
+	whatIfCmd.Flags().IntVar(&whatIfDays, "days", 30,
+		"With --recommend, how many days of history to analyze")
 }
@@ -1,25 +1,264 @@
 package config
 
 import (
-	"os"
+	"errors"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
-	DailyBudget float64
+	DailyBudget    float64       // In USD, like every cost (see CurrencyRate)
+	WeeklyBudget   float64       // In USD (0 = 7 daily budgets)
+	MonthlyBudget  float64       // In USD (0 = a daily budget per day of the month)
+	BudgetBasis    string        // Budget the dashboard's bar is against: day, week, month ("" = the active tab's)
+	DailyHardLimit float64       // Spend that triggers the dashboard's warning banner (0 = off)
+	PricingTimeout time.Duration // Per-request timeout for pricing fetches
+	PricingURL     string        // OpenRouter-compatible pricing endpoint ("" = OpenRouter)
+	PricingToken   string        // Bearer token for PricingURL ("" = none)
+	Timezone       string        // IANA zone for day boundaries (default: local)
+	LogLevel       string        // debug, info, warn, or error (default: warn)
+	IdleThreshold  time.Duration // Gap without events after which the session counts as idle (0 = off)
+	CurrencySymbol string        // Shown before amounts (default: $)
+	CurrencyRate   float64       // Multiplier from USD to the display currency (default: 1)
+	Precision      int           // Decimals shown for costs; single requests get two more (default: 4)
+
+	AutoReset string // "daily" starts a new session when the day changes ("" = off)
+
+	MaxEventCost float64 // In USD; a single event costing more is skipped as an outlier (0 = off)
+
+	LargeContextTokens int  // Warn when one request's input exceeds this many tokens (0 = off)
+	ShowInFlight       bool // Show requests still streaming below the session table
+
+	// Session cap: once the live session's cost crosses SessionCap, each of
+	// CapActions fires once ("banner", "notify"), and OnCap is run if set
+	SessionCap float64  // In USD (0 = off)
+	CapActions []string // Lowercased (default: banner)
+	OnCap      string   // Shell command to run, e.g. "say stop spending"
+
+	// The session's burn rate is highlighted once it's VelocityFactor times
+	// the usual (see storage.GetTypicalBurnRate)
+	VelocityFactor float64 // 0 = off
+
+	EnabledTools []string // Tools to watch, lowercased (empty = all)
+	LiteLLMLog   string   // A LiteLLM proxy's JSONL log ("" = ~/.litellm/spend_logs.jsonl)
+	CostSource   string   // "tool" uses a tool's own reported cost, "recompute" prices every event
+	OpenCodeScan bool     // Read OpenCode's existing messages at startup, not just new ones
+
+	Offline bool // Never fetch pricing; use built-in defaults and the disk cache
+
+	// Staleness: how old fetched prices can be before the pricing dot turns
+	// stale, and how long an active tool can go without an event before
+	// it's shown as idle
+	PricingFreshness    time.Duration
+	ToolActiveThreshold time.Duration // 0 = tools stay active
+
+	ToolSort string // Tools panel order: name, activity, or tier (default)
+
+	// Activity separated by more than SessionGap is counted as separate
+	// sessions in history (see storage.SplitSessions)
+	SessionGap time.Duration
+
+	FallbackModel string // Prices models without a known price ("" leaves them unpriced)
+
+	// Model families group releases of a model for display, e.g. every
+	// Claude Sonnet as one row
+	ModelFamilies string // Extra name=pattern pairs, tried before the built-in ones
+	GroupByFamily bool   // Start the dashboard grouped by family (toggle with g)
+
+	EventTail int // Most rows the dashboard's events and top views list (change with + and -)
+
+	FreeModels string // Free models' rows: show (default), group into one, or hide (cycle with F)
+
+	// Local models (e.g. Ollama) are billed for running time, not tokens
+	LocalProviders      []string // Providers that serve models locally, lowercased
+	LocalCostPerHour    float64  // In USD, for time spent on local models (0 = off)
+	LocalCostPerRequest float64  // In USD, flat cost of each local request (0 = off)
+
+	// Multipliers on a provider's list prices, e.g. "anthropic" -> 0.8 for a
+	// 20% discount. Keys are lowercase; unlisted providers pay list price.
+	ProviderMultipliers map[string]float64
+
+	// History writes are batched into one transaction per BatchSize events
+	// or BatchInterval, whichever comes first
+	BatchSize     int           // Events per batch (0 or 1 = write each event)
+	BatchInterval time.Duration // Longest an event waits to be written
+
+	// Bounds on the keys each parser keeps to skip events it already recorded
+	DedupMaxEntries int           // Keys per parser (0 = unbounded)
+	DedupMaxAge     time.Duration // Forget keys recorded longer ago than this (0 = off)
 }
 
-// Load loads the configuration from environment variables or defaults
+// Load loads the configuration from the config file (see FilePath) and
+// environment variables, which take precedence, over the defaults. Invalid
+// settings keep their defaults; LoadChecked reports them.
 func Load() *Config {
+	cfg, _, _ := LoadChecked()
+	return cfg
+}
+
+// LoadChecked is Load, also returning a warning for each config file key
+// that isn't a setting, and an error naming every invalid setting by its
+// variable or its file key and line. Flags, applied by the caller, take
+// precedence over both.
+func LoadChecked() (*Config, []string, error) {
 	cfg := &Config{
-		DailyBudget: 5.0, // Default $5.00/day
+		DailyBudget:    5.0, // Default $5.00/day
+		PricingTimeout: 10 * time.Second,
+		LogLevel:       "warn",
+		IdleThreshold:  5 * time.Minute,
+		CurrencySymbol: "$",
+		CurrencyRate:   1,
+		Precision:      DefaultPrecision,
+
+		LargeContextTokens: 150_000,
+		MaxEventCost:       50,
+		CapActions:         []string{"banner"},
+		CostSource:         "tool",
+		OpenCodeScan:       true,
+		FallbackModel:      "gpt-4o-mini",
+		LocalProviders:     []string{"ollama", "ollama_chat"},
+		DedupMaxEntries:    100_000,
+		BatchInterval:      2 * time.Second,
+		VelocityFactor:     2,
+
+		PricingFreshness:    time.Hour,
+		ToolSort:            "tier",
+		FreeModels:          "show",
+		EventTail:           50,
+		ToolActiveThreshold: 15 * time.Minute,
+		SessionGap:          30 * time.Minute,
+	}
+
+	path := FilePath()
+	l := &loader{fileName: filepath.Base(path), used: make(map[string]bool)}
+	if file, err := readFile(path); err != nil {
+		l.errs = append(l.errs, err)
+	} else {
+		l.file = file
 	}
 
-	if val := os.Getenv("BURNRATE_DAILY_BUDGET"); val != "" {
-		if f, err := strconv.ParseFloat(val, 64); err == nil {
-			cfg.DailyBudget = f
+	l.float("BURNRATE_DAILY_BUDGET", &cfg.DailyBudget, nonNegative)
+	l.float("BURNRATE_WEEKLY_BUDGET", &cfg.WeeklyBudget, nonNegative)
+	l.float("BURNRATE_MONTHLY_BUDGET", &cfg.MonthlyBudget, nonNegative)
+	l.oneOf("BURNRATE_BUDGET_BASIS", &cfg.BudgetBasis, "day", "week", "month")
+	l.float("BURNRATE_DAILY_HARD_LIMIT", &cfg.DailyHardLimit, nonNegative)
+	l.duration("BURNRATE_PRICING_TIMEOUT", &cfg.PricingTimeout, positive)
+	l.str("BURNRATE_PRICING_URL", &cfg.PricingURL)
+	l.str("BURNRATE_PRICING_TOKEN", &cfg.PricingToken)
+	l.duration("BURNRATE_IDLE_THRESHOLD", &cfg.IdleThreshold, nonNegative)
+	l.str("BURNRATE_CURRENCY_SYMBOL", &cfg.CurrencySymbol)
+	l.float("BURNRATE_CURRENCY_RATE", &cfg.CurrencyRate, positive)
+	l.int("BURNRATE_PRECISION", &cfg.Precision, between(0, maxMoneyDecimals))
+	l.float("BURNRATE_MAX_EVENT_COST", &cfg.MaxEventCost, nonNegative)
+	l.int("BURNRATE_LARGE_CONTEXT_TOKENS", &cfg.LargeContextTokens, nonNegative)
+	l.oneOf("BURNRATE_AUTO_RESET", &cfg.AutoReset, "daily")
+	l.bool("BURNRATE_SHOW_IN_FLIGHT", &cfg.ShowInFlight)
+	l.float("BURNRATE_SESSION_CAP", &cfg.SessionCap, nonNegative)
+	l.list("BURNRATE_CAP_ACTIONS", &cfg.CapActions)
+	l.str("BURNRATE_ON_CAP", &cfg.OnCap)
+	l.float("BURNRATE_VELOCITY_FACTOR", &cfg.VelocityFactor, nonNegative)
+	l.list("BURNRATE_TOOLS", &cfg.EnabledTools)
+	l.str("BURNRATE_LITELLM_LOG", &cfg.LiteLLMLog)
+	l.oneOf("BURNRATE_COST_SOURCE", &cfg.CostSource, "tool", "recompute")
+	l.bool("BURNRATE_OFFLINE", &cfg.Offline)
+	l.bool("BURNRATE_OPENCODE_SCAN", &cfg.OpenCodeScan)
+	l.duration("BURNRATE_PRICING_FRESHNESS", &cfg.PricingFreshness, positive)
+	l.duration("BURNRATE_SESSION_GAP", &cfg.SessionGap, positive)
+	l.duration("BURNRATE_TOOL_ACTIVE_THRESHOLD", &cfg.ToolActiveThreshold, nonNegative)
+	l.oneOf("BURNRATE_TOOL_SORT", &cfg.ToolSort, "name", "activity", "tier")
+	l.int("BURNRATE_EVENT_TAIL", &cfg.EventTail, positive)
+
+	// "none" shows unknown models' cost as unknown instead of estimating it
+	l.str("BURNRATE_FALLBACK_MODEL", &cfg.FallbackModel)
+	if cfg.FallbackModel == "none" {
+		cfg.FallbackModel = ""
+	}
+
+	l.str("BURNRATE_MODEL_FAMILIES", &cfg.ModelFamilies)
+	l.bool("BURNRATE_GROUP_BY_FAMILY", &cfg.GroupByFamily)
+	l.oneOf("BURNRATE_FREE_MODELS", &cfg.FreeModels, "show", "group", "hide")
+	l.list("BURNRATE_LOCAL_PROVIDERS", &cfg.LocalProviders)
+	l.float("BURNRATE_LOCAL_COST_PER_HOUR", &cfg.LocalCostPerHour, nonNegative)
+	l.float("BURNRATE_LOCAL_COST_PER_REQUEST", &cfg.LocalCostPerRequest, nonNegative)
+	l.multipliers("BURNRATE_PROVIDER_MULTIPLIERS", &cfg.ProviderMultipliers)
+	l.int("BURNRATE_DEDUP_MAX_ENTRIES", &cfg.DedupMaxEntries, nonNegative)
+	l.duration("BURNRATE_DEDUP_MAX_AGE", &cfg.DedupMaxAge, nonNegative)
+	l.int("BURNRATE_BATCH_SIZE", &cfg.BatchSize, nonNegative)
+	l.duration("BURNRATE_BATCH_INTERVAL", &cfg.BatchInterval, positive)
+	l.timezone("BURNRATE_TZ", &cfg.Timezone)
+	l.str("BURNRATE_LOG_LEVEL", &cfg.LogLevel)
+
+	// BURNRATE_DEBUG=1 is shorthand for BURNRATE_LOG_LEVEL=debug
+	if val, _, ok := l.lookup("BURNRATE_DEBUG"); ok {
+		if on, err := strconv.ParseBool(val); err != nil || on {
+			cfg.LogLevel = "debug"
 		}
 	}
 
-	return cfg
+	return cfg, l.unknownKeys(), errors.Join(l.errs...)
+}
+
+// WindowBudget is the budget for a "week" or "month": WeeklyBudget or
+// MonthlyBudget if set, or else the daily budget scaled to 7 days or the
+// days in now's month. Any other window gets the daily budget.
+func (c *Config) WindowBudget(window string, now time.Time) float64 {
+	switch window {
+	case "week":
+		if c.WeeklyBudget > 0 {
+			return c.WeeklyBudget
+		}
+		return c.DailyBudget * 7
+	case "month":
+		if c.MonthlyBudget > 0 {
+			return c.MonthlyBudget
+		}
+		daysInMonth := time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, now.Location()).Day()
+		return c.DailyBudget * float64(daysInMonth)
+	}
+	return c.DailyBudget
+}
+
+// ParseTools splits a comma-separated tool list such as "opencode,aider"
+func ParseTools(list string) []string {
+	var tools []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+			tools = append(tools, name)
+		}
+	}
+	return tools
+}
+
+// ParseMultipliers parses a comma-separated list of provider=multiplier
+// pairs such as "anthropic=0.8,openai=1.15". Entries that aren't a name and a
+// positive number are skipped.
+func ParseMultipliers(list string) map[string]float64 {
+	multipliers := make(map[string]float64)
+	for _, entry := range strings.Split(list, ",") {
+		name, value, ok := strings.Cut(entry, "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !ok || name == "" {
+			continue
+		}
+		if f, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil && f > 0 {
+			multipliers[name] = f
+		}
+	}
+	return multipliers
+}
+
+// ToolEnabled reports whether a tool is in the list, ignoring case. An empty
+// list enables every tool.
+func ToolEnabled(tools []string, name string) bool {
+	if len(tools) == 0 {
+		return true
+	}
+	for _, tool := range tools {
+		if strings.EqualFold(tool, name) {
+			return true
+		}
+	}
+	return false
 }
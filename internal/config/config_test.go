@@ -0,0 +1,145 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseToolsAndToolEnabled(t *testing.T) {
+	// 1. Names are trimmed and lowercased; empty entries are dropped
+	tools := ParseTools(" OpenCode, aider,,")
+	if !reflect.DeepEqual(tools, []string{"opencode", "aider"}) {
+		t.Errorf("Expected [opencode aider], got %v", tools)
+	}
+
+	// 2. Matching ignores case
+	if !ToolEnabled(tools, "Aider") || ToolEnabled(tools, "Crush") {
+		t.Errorf("Expected Aider enabled and Crush disabled with %v", tools)
+	}
+
+	// 3. An empty list enables everything
+	if !ToolEnabled(nil, "Crush") {
+		t.Error("Expected every tool enabled with an empty list")
+	}
+}
+
+func TestLoadReadsEnabledTools(t *testing.T) {
+	t.Setenv("BURNRATE_TOOLS", "zed,codex")
+	if cfg := Load(); !reflect.DeepEqual(cfg.EnabledTools, []string{"zed", "codex"}) {
+		t.Errorf("Expected [zed codex], got %v", cfg.EnabledTools)
+	}
+}
+
+func TestParseMultipliers(t *testing.T) {
+	got := ParseMultipliers(" Anthropic=0.8, openai = 1.15,bad,google=-1,xai=abc")
+	want := map[string]float64{"anthropic": 0.8, "openai": 1.15}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+// writeConfigFile points Load at a config file with contents
+func writeConfigFile(t *testing.T, contents string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	t.Setenv("BURNRATE_CONFIG", path)
+}
+
+func TestLoadMergesFileUnderEnv(t *testing.T) {
+	writeConfigFile(t, `# Budgets
+daily_budget = 12.5
+weekly_budget = 60
+tools = ["Aider", "zed"]   # inline comment
+idle_threshold = "10m"
+large_context_tokens = 200_000
+on_cap = "say 'stop # now'"
+
+[provider_multipliers]
+anthropic = 0.8
+`)
+	t.Setenv("BURNRATE_WEEKLY_BUDGET", "70")
+
+	cfg, warnings, err := LoadChecked()
+	if err != nil || len(warnings) != 0 {
+		t.Fatalf("Expected a clean load, got %v, %v", warnings, err)
+	}
+
+	// 1. File values replace the defaults
+	if cfg.DailyBudget != 12.5 || cfg.IdleThreshold != 10*time.Minute || cfg.LargeContextTokens != 200_000 {
+		t.Errorf("Expected the file's budget, idle threshold, and threshold, got %+v", cfg)
+	}
+	if !reflect.DeepEqual(cfg.EnabledTools, []string{"aider", "zed"}) || cfg.OnCap != "say 'stop # now'" {
+		t.Errorf("Expected the file's tools and command, got %v and %q", cfg.EnabledTools, cfg.OnCap)
+	}
+	if !reflect.DeepEqual(cfg.ProviderMultipliers, map[string]float64{"anthropic": 0.8}) {
+		t.Errorf("Expected the multipliers table, got %v", cfg.ProviderMultipliers)
+	}
+
+	// 2. The environment overrides the file
+	if cfg.WeeklyBudget != 70 {
+		t.Errorf("Expected BURNRATE_WEEKLY_BUDGET to win, got %v", cfg.WeeklyBudget)
+	}
+}
+
+func TestLoadReportsInvalidSettings(t *testing.T) {
+	writeConfigFile(t, `daily_budget = -5
+precision = 12
+dayly_budget = 3
+
+[theme]
+color = "red"
+`)
+	t.Setenv("BURNRATE_BUDGET_BASIS", "fortnight")
+	t.Setenv("BURNRATE_TZ", "Mars/Olympus_Mons")
+
+	cfg, warnings, err := LoadChecked()
+
+	// 1. Each invalid value is an error naming its key and line, or variable
+	if err == nil {
+		t.Fatal("Expected errors for the invalid settings")
+	}
+	for _, want := range []string{
+		`config.toml:1: daily_budget: must be a number zero or more, got "-5"`,
+		`config.toml:2: precision: must be a whole number from 0 to 8, got "12"`,
+		`BURNRATE_BUDGET_BASIS: must be one of day, week, month, got "fortnight"`,
+		`BURNRATE_TZ: must be a time zone such as Europe/Berlin, got "Mars/Olympus_Mons"`,
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected the error to contain %q, got:\n%v", want, err)
+		}
+	}
+
+	// 2. Invalid settings keep their defaults
+	if cfg.DailyBudget != 5 || cfg.Precision != DefaultPrecision || cfg.BudgetBasis != "" || cfg.Timezone != "" {
+		t.Errorf("Expected defaults for the invalid settings, got %+v", cfg)
+	}
+
+	// 3. Keys that aren't settings are warnings, in file order
+	want := []string{"config.toml:3: unknown key dayly_budget", "config.toml:6: unknown key theme.color"}
+	if !reflect.DeepEqual(warnings, want) {
+		t.Errorf("Expected %v, got %v", want, warnings)
+	}
+}
+
+func TestParseFileSyntaxErrors(t *testing.T) {
+	tests := map[string]string{
+		"daily_budget 5":                  `config.toml:1: expected key = value, got "daily_budget 5"`,
+		"currency_symbol = €":             "config.toml:1: currency_symbol: invalid value € (strings need quotes)",
+		"tools = [\"aider\"":              `config.toml:1: tools: unterminated array ["aider"`,
+		"[provider_multipliers]\nx = -1":  "config.toml:2: provider_multipliers.x: must be a positive number, got -1",
+		"offline = true\noffline = false": "config.toml:2: offline is set twice",
+	}
+	for contents, want := range tests {
+		_, err := parseFile(strings.NewReader(contents), "config.toml")
+		if err == nil || err.Error() != want {
+			t.Errorf("Expected %q for %q, got %v", want, contents, err)
+		}
+	}
+}
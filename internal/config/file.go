@@ -0,0 +1,100 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// FilePath is the config file Load reads: BURNRATE_CONFIG if set, or else
+// ~/.burnrate/config.toml
+func FilePath() string {
+	if path := os.Getenv("BURNRATE_CONFIG"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".burnrate", "config.toml")
+}
+
+// fileValue is one setting from the config file, as the string its
+// environment variable would hold
+type fileValue struct {
+	value string
+	line  int
+}
+
+// multipliersTable is the file's table of provider multipliers, the
+// counterpart of BURNRATE_PROVIDER_MULTIPLIERS
+const multipliersTable = "provider_multipliers"
+
+// readFile parses a config file: the subset of TOML its settings need.
+// Each line is a key = value pair, where the key is a BURNRATE_ variable's
+// name without the prefix, in lowercase (daily_budget for
+// BURNRATE_DAILY_BUDGET), and the value is a string, number, boolean, or
+// array of strings. Provider multipliers may also be given as a
+// [provider_multipliers] table of name = multiplier pairs. A missing file
+// reads as empty.
+func readFile(path string) (map[string]fileValue, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return parseFile(file, filepath.Base(path))
+}
+
+// parseFile parses config file contents (see readFile). name prefixes
+// errors, as in "config.toml:3: ...".
+func parseFile(r io.Reader, name string) (map[string]fileValue, error) {
+	entries, err := ReadTOML(r, name)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]fileValue)
+	var multipliers []string
+	multipliersLine := 0
+	for _, e := range entries {
+		if e.Key == "" {
+			if e.Table == multipliersTable {
+				multipliersLine = e.Line
+			}
+			continue
+		}
+
+		key := e.Key
+		if e.Table == multipliersTable {
+			if f, err := strconv.ParseFloat(e.Value, 64); err != nil || f <= 0 {
+				return nil, fmt.Errorf("%s:%d: %s.%s: must be a positive number, got %s", name, e.Line, e.Table, key, e.Raw)
+			}
+			multipliers = append(multipliers, key+"="+e.Value)
+			continue
+		}
+		// Other tables' keys are kept as table.key, to be warned about as
+		// unknown
+		if e.Table != "" {
+			key = e.Table + "." + key
+		}
+		if _, dup := values[key]; dup {
+			return nil, fmt.Errorf("%s:%d: %s is set twice", name, e.Line, key)
+		}
+		values[key] = fileValue{value: e.Value, line: e.Line}
+	}
+
+	if multipliers != nil {
+		if _, dup := values[multipliersTable]; dup {
+			return nil, fmt.Errorf("%s:%d: %s is set twice", name, multipliersLine, multipliersTable)
+		}
+		values[multipliersTable] = fileValue{value: strings.Join(multipliers, ","), line: multipliersLine}
+	}
+	return values, nil
+}
@@ -0,0 +1,189 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// loader reads settings from the environment or, for those not set there,
+// the config file, collecting an error for each invalid value
+type loader struct {
+	file     map[string]fileValue // By key, see readFile
+	fileName string
+	used     map[string]bool // File keys that name a setting
+	errs     []error
+}
+
+// lookup returns a setting's value and where it came from: its
+// environment variable, or the config file's key and line. ok is false when
+// neither sets it.
+func (l *loader) lookup(env string) (val, origin string, ok bool) {
+	key := strings.ToLower(strings.TrimPrefix(env, "BURNRATE_"))
+	l.used[key] = true
+	if val := os.Getenv(env); val != "" {
+		return val, env, true
+	}
+	if v, ok := l.file[key]; ok {
+		return v.value, fmt.Sprintf("%s:%d: %s", l.fileName, v.line, key), true
+	}
+	return "", "", false
+}
+
+// invalid records that a setting's value was rejected
+func (l *loader) invalid(origin, want, val string) {
+	l.errs = append(l.errs, fmt.Errorf("%s: must be %s, got %q", origin, want, val))
+}
+
+// check is a constraint on a numeric setting
+type check struct {
+	ok   func(float64) bool
+	want string // What a valid value is, e.g. "zero or more"
+}
+
+var nonNegative = check{func(f float64) bool { return f >= 0 }, "zero or more"}
+var positive = check{func(f float64) bool { return f > 0 }, "more than zero"}
+
+// between allows whole numbers from lo to hi
+func between(lo, hi int) check {
+	return check{
+		func(f float64) bool { return f >= float64(lo) && f <= float64(hi) },
+		fmt.Sprintf("from %d to %d", lo, hi),
+	}
+}
+
+func (l *loader) str(env string, dst *string) {
+	if val, _, ok := l.lookup(env); ok {
+		*dst = val
+	}
+}
+
+func (l *loader) float(env string, dst *float64, c check) {
+	val, origin, ok := l.lookup(env)
+	if !ok {
+		return
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+	if err != nil || !c.ok(f) {
+		l.invalid(origin, "a number "+c.want, val)
+		return
+	}
+	*dst = f
+}
+
+func (l *loader) int(env string, dst *int, c check) {
+	val, origin, ok := l.lookup(env)
+	if !ok {
+		return
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(val))
+	if err != nil || !c.ok(float64(n)) {
+		l.invalid(origin, "a whole number "+c.want, val)
+		return
+	}
+	*dst = n
+}
+
+func (l *loader) duration(env string, dst *time.Duration, c check) {
+	val, origin, ok := l.lookup(env)
+	if !ok {
+		return
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(val))
+	if err != nil || !c.ok(float64(d)) {
+		l.invalid(origin, "a duration such as 30s or 5m, "+c.want, val)
+		return
+	}
+	*dst = d
+}
+
+func (l *loader) bool(env string, dst *bool) {
+	val, origin, ok := l.lookup(env)
+	if !ok {
+		return
+	}
+	on, err := strconv.ParseBool(strings.TrimSpace(val))
+	if err != nil {
+		l.invalid(origin, "true or false", val)
+		return
+	}
+	*dst = on
+}
+
+// oneOf reads a setting that must be one of options, ignoring case
+func (l *loader) oneOf(env string, dst *string, options ...string) {
+	val, origin, ok := l.lookup(env)
+	if !ok {
+		return
+	}
+	lower := strings.ToLower(strings.TrimSpace(val))
+	for _, option := range options {
+		if lower == option {
+			*dst = option
+			return
+		}
+	}
+	l.invalid(origin, "one of "+strings.Join(options, ", "), val)
+}
+
+// list reads a comma-separated list (see ParseTools)
+func (l *loader) list(env string, dst *[]string) {
+	if val, _, ok := l.lookup(env); ok {
+		*dst = ParseTools(val)
+	}
+}
+
+// multipliers reads provider=multiplier pairs (see ParseMultipliers),
+// rejecting the setting if any pair is malformed
+func (l *loader) multipliers(env string, dst *map[string]float64) {
+	val, origin, ok := l.lookup(env)
+	if !ok {
+		return
+	}
+	for _, entry := range strings.Split(val, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		name, value, found := strings.Cut(entry, "=")
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if !found || strings.TrimSpace(name) == "" || err != nil || f <= 0 {
+			l.invalid(origin, "provider=multiplier pairs with multipliers more than zero", entry)
+			return
+		}
+	}
+	*dst = ParseMultipliers(val)
+}
+
+// timezone reads an IANA time zone name such as Europe/Berlin
+func (l *loader) timezone(env string, dst *string) {
+	val, origin, ok := l.lookup(env)
+	if !ok {
+		return
+	}
+	if _, err := time.LoadLocation(val); err != nil {
+		l.invalid(origin, "a time zone such as Europe/Berlin", val)
+		return
+	}
+	*dst = val
+}
+
+// unknownKeys warns of config file keys that aren't settings, such as
+// misspellings, in file order
+func (l *loader) unknownKeys() []string {
+	var keys []string
+	for key := range l.file {
+		if !l.used[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return l.file[keys[i]].line < l.file[keys[j]].line })
+
+	var warnings []string
+	for _, key := range keys {
+		warnings = append(warnings, fmt.Sprintf("%s:%d: unknown key %s", l.fileName, l.file[key].line, key))
+	}
+	return warnings
+}
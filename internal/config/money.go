@@ -0,0 +1,101 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+// Display currency and precision. Costs are tracked and priced in USD
+// throughout; only FormatMoney converts, using the symbol and rate set at
+// startup.
+var (
+	currencyMu     sync.RWMutex
+	currencySymbol = "$"
+	currencyRate   = 1.0
+	moneyDecimals  = DefaultPrecision
+)
+
+// DefaultPrecision is how many decimals FormatMoney shows by default
+const DefaultPrecision = 4
+
+// maxMoneyDecimals caps the precision, including the extra decimals shown
+// so a small cost doesn't round to zero
+const maxMoneyDecimals = 8
+
+// eventExtraDecimals is how much finer a single request's cost is shown than
+// totals, since one request often costs a fraction of a cent
+const eventExtraDecimals = 2
+
+// SetCurrency sets the symbol and USD multiplier used by FormatMoney.
+// An empty symbol or non-positive rate keeps the USD default.
+func SetCurrency(symbol string, rate float64) {
+	if symbol == "" {
+		symbol = "$"
+	}
+	if rate <= 0 {
+		rate = 1
+	}
+	currencyMu.Lock()
+	defer currencyMu.Unlock()
+	currencySymbol = symbol
+	currencyRate = rate
+}
+
+// SetPrecision sets how many decimals FormatMoney shows. Values outside
+// 0-maxMoneyDecimals keep the default.
+func SetPrecision(decimals int) {
+	if decimals < 0 || decimals > maxMoneyDecimals {
+		decimals = DefaultPrecision
+	}
+	currencyMu.Lock()
+	defer currencyMu.Unlock()
+	moneyDecimals = decimals
+}
+
+// FormatMoney formats a USD amount in the display currency to the configured
+// precision (four decimals by default), e.g. "€0.0123" or "-$1.5000"
+func FormatMoney(usd float64) string {
+	currencyMu.RLock()
+	decimals := moneyDecimals
+	currencyMu.RUnlock()
+	return formatMoney(usd, decimals)
+}
+
+// FormatMoneyEvent is FormatMoney for a single request's cost, two decimals
+// finer than totals
+func FormatMoneyEvent(usd float64) string {
+	currencyMu.RLock()
+	decimals := moneyDecimals + eventExtraDecimals
+	currencyMu.RUnlock()
+	return formatMoney(usd, min(decimals, maxMoneyDecimals))
+}
+
+// FormatMoneyCents is FormatMoney rounded to two decimals, for budgets,
+// rates, and chart labels
+func FormatMoneyCents(usd float64) string {
+	return formatMoney(usd, 2)
+}
+
+func formatMoney(usd float64, decimals int) string {
+	currencyMu.RLock()
+	symbol, rate := currencySymbol, currencyRate
+	currencyMu.RUnlock()
+
+	amount := usd * rate
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	return fmt.Sprintf("%s%s%.*f", sign, symbol, visibleDecimals(amount, decimals), amount)
+}
+
+// visibleDecimals adds decimals until a non-zero amount no longer rounds to
+// zero, so a column of tiny costs doesn't all read $0.0000
+func visibleDecimals(amount float64, decimals int) int {
+	for amount > 0 && decimals < maxMoneyDecimals && amount < 0.5*math.Pow10(-decimals) {
+		decimals++
+	}
+	return decimals
+}
@@ -0,0 +1,67 @@
+package config
+
+import "testing"
+
+func TestFormatMoneyConvertsForDisplay(t *testing.T) {
+	defer SetCurrency("", 0)
+
+	// 1. USD by default
+	if got := FormatMoney(1.5); got != "$1.5000" {
+		t.Errorf("Expected $1.5000, got %s", got)
+	}
+
+	// 2. Symbol and rate apply to display only
+	SetCurrency("€", 0.9)
+	if got := FormatMoney(2); got != "€1.8000" {
+		t.Errorf("Expected €1.8000, got %s", got)
+	}
+	if got := FormatMoneyCents(10); got != "€9.00" {
+		t.Errorf("Expected €9.00, got %s", got)
+	}
+
+	// 3. Negative amounts put the sign before the symbol
+	if got := FormatMoney(-1); got != "-€0.9000" {
+		t.Errorf("Expected -€0.9000, got %s", got)
+	}
+
+	// 4. Invalid settings fall back to USD
+	SetCurrency("", -1)
+	if got := FormatMoneyCents(3); got != "$3.00" {
+		t.Errorf("Expected $3.00, got %s", got)
+	}
+}
+
+func TestFormatMoneyPrecision(t *testing.T) {
+	defer SetPrecision(DefaultPrecision)
+
+	// 1. Totals use the configured precision, single requests two more
+	SetPrecision(2)
+	if got := FormatMoney(1.23456); got != "$1.23" {
+		t.Errorf("Expected $1.23, got %s", got)
+	}
+	if got := FormatMoneyEvent(1.23456); got != "$1.2346" {
+		t.Errorf("Expected $1.2346, got %s", got)
+	}
+
+	// 2. Tiny costs gain decimals rather than reading as zero
+	SetPrecision(DefaultPrecision)
+	if got := FormatMoney(0.0000123); got != "$0.00001" {
+		t.Errorf("Expected $0.00001, got %s", got)
+	}
+	if got := FormatMoneyEvent(0.00000042); got != "$0.0000004" {
+		t.Errorf("Expected $0.0000004, got %s", got)
+	}
+	if got := FormatMoney(0); got != "$0.0000" {
+		t.Errorf("Expected $0.0000 for zero, got %s", got)
+	}
+
+	// 3. Per-event precision is capped, and out-of-range settings keep the default
+	SetPrecision(8)
+	if got := FormatMoneyEvent(0.123456789); got != "$0.12345679" {
+		t.Errorf("Expected $0.12345679, got %s", got)
+	}
+	SetPrecision(-1)
+	if got := FormatMoney(1); got != "$1.0000" {
+		t.Errorf("Expected $1.0000, got %s", got)
+	}
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// TOMLEntry is one line of a TOML file that sets a key or starts a table
+type TOMLEntry struct {
+	Line       int
+	Table      string // The table a key is in ("" at the top level), or a header starts
+	ArrayTable bool   // Table is an entry in an [[array of tables]]
+	Key        string // Empty for a table header
+	Raw        string // The value as written
+	Value      string // The value as an environment variable would hold it (see parseValue)
+	Quoted     bool   // The value is a string, rather than a number, boolean, or array
+}
+
+// ReadTOML parses the subset of TOML burnrate's files need: key = value
+// pairs, where the value is a string, number, boolean, or array of strings,
+// under optional [table] or [[table]] headers, with # comments. name
+// prefixes errors, as in "config.toml:3: ...".
+func ReadTOML(r io.Reader, name string) ([]TOMLEntry, error) {
+	var entries []TOMLEntry
+	var table string
+	var arrayTable bool
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(stripComment(scanner.Text()))
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			header, closing := line[1:], "]"
+			arrayTable = strings.HasPrefix(header, "[")
+			if arrayTable {
+				header, closing = header[1:], "]]"
+			}
+			if !strings.HasSuffix(header, closing) {
+				return nil, fmt.Errorf("%s:%d: unterminated table header %q", name, n, line)
+			}
+			table = strings.TrimSpace(strings.TrimSuffix(header, closing))
+			entries = append(entries, TOMLEntry{Line: n, Table: table, ArrayTable: arrayTable})
+			continue
+		}
+
+		key, raw, ok := strings.Cut(line, "=")
+		key, raw = strings.TrimSpace(key), strings.TrimSpace(raw)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%s:%d: expected key = value, got %q", name, n, line)
+		}
+		value, err := parseValue(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %s: %v", name, n, key, err)
+		}
+		entries = append(entries, TOMLEntry{
+			Line:       n,
+			Table:      table,
+			ArrayTable: arrayTable,
+			Key:        key,
+			Raw:        raw,
+			Value:      value,
+			Quoted:     strings.HasPrefix(raw, `"`) || strings.HasPrefix(raw, "'"),
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return entries, nil
+}
+
+// parseValue reads a TOML value as an environment variable would hold it:
+// a string's contents, a number or boolean as written, or an array's
+// strings joined with commas
+func parseValue(raw string) (string, error) {
+	switch {
+	case raw == "":
+		return "", fmt.Errorf("missing value")
+	case strings.HasPrefix(raw, `"`):
+		s, err := strconv.Unquote(raw)
+		if err != nil {
+			return "", fmt.Errorf("invalid string %s", raw)
+		}
+		return s, nil
+	case strings.HasPrefix(raw, "'"):
+		if len(raw) < 2 || !strings.HasSuffix(raw, "'") {
+			return "", fmt.Errorf("invalid string %s", raw)
+		}
+		return raw[1 : len(raw)-1], nil
+	case strings.HasPrefix(raw, "["):
+		if !strings.HasSuffix(raw, "]") {
+			return "", fmt.Errorf("unterminated array %s", raw)
+		}
+		var items []string
+		for _, item := range splitArray(raw[1 : len(raw)-1]) {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			s, err := parseValue(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, s)
+		}
+		return strings.Join(items, ","), nil
+	case raw == "true" || raw == "false":
+		return raw, nil
+	}
+
+	// A number, which TOML lets group digits with underscores
+	number := strings.ReplaceAll(raw, "_", "")
+	if _, err := strconv.ParseFloat(number, 64); err != nil {
+		return "", fmt.Errorf("invalid value %s (strings need quotes)", raw)
+	}
+	return number, nil
+}
+
+// splitArray splits an array's contents at the commas outside strings
+func splitArray(s string) []string {
+	var items []string
+	var quote rune
+	escaped := false
+	start := 0
+	for i, c := range s {
+		switch {
+		case escaped:
+			escaped = false
+		case quote == '"' && c == '\\':
+			escaped = true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ',':
+			items = append(items, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(items, s[start:])
+}
+
+// stripComment removes a # comment, unless the # is inside a string
+func stripComment(line string) string {
+	var quote rune
+	escaped := false
+	for i, c := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case quote == '"' && c == '\\':
+			escaped = true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
@@ -0,0 +1,195 @@
+// internal/daemon/daemon.go
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/bangarangler/burnrate/internal/tracker"
+)
+
+// ErrAlreadyRunning is returned by Acquire when another daemon holds the lock
+var ErrAlreadyRunning = errors.New("daemon already running")
+
+// Dir returns the burnrate data directory (~/.burnrate)
+func Dir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(home, ".burnrate"), nil
+}
+
+func pidPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "daemon.pid"), nil
+}
+
+func statePath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "daemon.json"), nil
+}
+
+func resetPath() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "reset"), nil
+}
+
+// Acquire takes the daemon lock by writing our PID to ~/.burnrate/daemon.pid.
+// A PID file left behind by a dead process is replaced.
+func Acquire() error {
+	path, err := pidPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			defer file.Close()
+			_, err = fmt.Fprintf(file, "%d\n", os.Getpid())
+			return err
+		}
+		if !os.IsExist(err) {
+			return fmt.Errorf("failed to create pid file: %w", err)
+		}
+
+		// Lock exists - only keep it if the owner is still alive
+		if pid, ok := Running(); ok {
+			return fmt.Errorf("%w (pid %d)", ErrAlreadyRunning, pid)
+		}
+		os.Remove(path)
+	}
+
+	return fmt.Errorf("failed to acquire daemon lock")
+}
+
+// Release removes the PID file and state snapshot
+func Release() {
+	if path, err := pidPath(); err == nil {
+		os.Remove(path)
+	}
+	if path, err := statePath(); err == nil {
+		os.Remove(path)
+	}
+}
+
+// Running reports whether a daemon is alive, and its PID
+func Running() (int, bool) {
+	path, err := pidPath()
+	if err != nil {
+		return 0, false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, false
+	}
+	if pid == os.Getpid() {
+		return pid, true
+	}
+
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return 0, false
+	}
+	// Signal 0 checks for existence without affecting the process
+	if err := process.Signal(syscall.Signal(0)); err != nil {
+		return 0, false
+	}
+	return pid, true
+}
+
+// WriteState saves the tracker's current state for attached dashboards to read
+func WriteState(snap tracker.Snapshot) error {
+	path, err := statePath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+
+	// Write then rename so readers never see a partial file
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// ReadState loads the most recent state written by the daemon
+func ReadState() (tracker.Snapshot, error) {
+	var snap tracker.Snapshot
+
+	path, err := statePath()
+	if err != nil {
+		return snap, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return snap, err
+	}
+
+	err = json.Unmarshal(data, &snap)
+	return snap, err
+}
+
+// ClearState removes a saved state snapshot, such as one left behind by a
+// daemon that didn't exit cleanly
+func ClearState() error {
+	path, err := statePath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// RequestReset asks the running daemon to start a new session. The daemon
+// picks the request up the next time it publishes its state.
+func RequestReset() error {
+	path, err := resetPath()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, nil, 0644)
+}
+
+// TakeResetRequest reports whether a reset was requested, clearing the
+// request so it's acted on once
+func TakeResetRequest() bool {
+	path, err := resetPath()
+	if err != nil {
+		return false
+	}
+	return os.Remove(path) == nil
+}
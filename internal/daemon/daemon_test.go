@@ -0,0 +1,133 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bangarangler/burnrate/internal/tracker"
+)
+
+// writePID fakes a daemon.pid left by another process
+func writePID(t *testing.T, pid int) {
+	t.Helper()
+	path, err := pidPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(fmt.Sprintf("%d\n", pid)), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAcquireTakesTheLockOnce(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	// 1. With no daemon the lock is free
+	if _, ok := Running(); ok {
+		t.Fatal("Expected no daemon running")
+	}
+	if err := Acquire(); err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	if pid, ok := Running(); !ok || pid != os.Getpid() {
+		t.Errorf("Expected this process as the daemon, got %d (running=%v)", pid, ok)
+	}
+
+	// 2. A second Acquire fails while the holder is alive
+	if err := Acquire(); !errors.Is(err, ErrAlreadyRunning) {
+		t.Errorf("Expected ErrAlreadyRunning, got %v", err)
+	}
+
+	// 3. Release frees it
+	Release()
+	if _, ok := Running(); ok {
+		t.Error("Expected no daemon after Release")
+	}
+}
+
+func TestAcquireRespectsAnotherLiveDaemon(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	// The test's parent process is alive and isn't this one
+	writePID(t, os.Getppid())
+	if err := Acquire(); !errors.Is(err, ErrAlreadyRunning) {
+		t.Errorf("Expected ErrAlreadyRunning for a live pid, got %v", err)
+	}
+}
+
+func TestAcquireTakesOverAStalePID(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	// 1. A PID file left by a process that has exited
+	cmd := exec.Command("true")
+	if err := cmd.Run(); err != nil {
+		t.Skipf("cannot run a short-lived process: %v", err)
+	}
+	writePID(t, cmd.Process.Pid)
+	if _, ok := Running(); ok {
+		t.Fatal("Expected a dead pid not to count as running")
+	}
+
+	// 2. Acquire replaces it with ours
+	if err := Acquire(); err != nil {
+		t.Fatalf("Expected to take over the stale lock, got %v", err)
+	}
+	defer Release()
+	if pid, ok := Running(); !ok || pid != os.Getpid() {
+		t.Errorf("Expected this process to hold the lock, got %d (running=%v)", pid, ok)
+	}
+
+	// 3. A garbled PID file is stale too
+	Release()
+	path, _ := pidPath()
+	os.WriteFile(path, []byte("not a pid"), 0644)
+	if err := Acquire(); err != nil {
+		t.Errorf("Expected to take over a garbled lock, got %v", err)
+	}
+}
+
+func TestStateRoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := os.MkdirAll(filepath.Join(os.Getenv("HOME"), ".burnrate"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// 1. Nothing to read before the daemon writes
+	if _, err := ReadState(); !os.IsNotExist(err) {
+		t.Errorf("Expected a not-exist error, got %v", err)
+	}
+
+	// 2. What's written is read back
+	start := time.Unix(1735000000, 0)
+	want := tracker.Snapshot{
+		StartTime:   start,
+		SessionCost: 1.25,
+		Usages:      []tracker.Usage{{Model: "gpt-4o", PromptTokens: 100, Cost: 1.25, Timestamp: start}},
+		Tag:         "client-a",
+	}
+	if err := WriteState(want); err != nil {
+		t.Fatalf("WriteState failed: %v", err)
+	}
+	got, err := ReadState()
+	if err != nil {
+		t.Fatalf("ReadState failed: %v", err)
+	}
+	if !got.StartTime.Equal(start) || got.SessionCost != 1.25 || got.Tag != "client-a" ||
+		len(got.Usages) != 1 || got.Usages[0].Model != "gpt-4o" {
+		t.Errorf("Expected the written state back, got %+v", got)
+	}
+
+	// 3. Release removes it with the lock
+	Release()
+	if _, err := ReadState(); !os.IsNotExist(err) {
+		t.Errorf("Expected the state removed on Release, got %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+// Package log is a small leveled logger. It writes to stderr by default;
+// while the TUI owns the terminal, output must be redirected (see ToFile) so
+// log lines never corrupt the alt-screen.
+package log
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level is a log severity. Messages below the current level are dropped.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = map[Level]string{
+	LevelDebug: "DEBUG",
+	LevelInfo:  "INFO",
+	LevelWarn:  "WARN",
+	LevelError: "ERROR",
+}
+
+func (l Level) String() string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("LEVEL(%d)", int(l))
+}
+
+// ParseLevel converts "debug", "info", "warn"/"warning", or "error" to a Level
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	}
+	return LevelWarn, fmt.Errorf("unknown log level %q (use debug, info, warn, or error)", s)
+}
+
+var (
+	mu     sync.Mutex
+	level            = LevelWarn
+	out    io.Writer = os.Stderr
+	closer io.Closer // Set when output is a file we opened
+)
+
+// SetLevel sets the minimum level that is written
+func SetLevel(l Level) {
+	mu.Lock()
+	defer mu.Unlock()
+	level = l
+}
+
+// GetLevel returns the current minimum level
+func GetLevel() Level {
+	mu.Lock()
+	defer mu.Unlock()
+	return level
+}
+
+// SetOutput redirects log output. Pass io.Discard to silence logging.
+func SetOutput(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	closeLocked()
+	out = w
+}
+
+// ToFile appends log output to path, creating its directory if needed.
+// If the file can't be opened, output is discarded rather than falling back
+// to stderr, since callers use this when the terminal isn't available.
+func ToFile(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		SetOutput(io.Discard)
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		SetOutput(io.Discard)
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	closeLocked()
+	out = f
+	closer = f
+	return nil
+}
+
+// DefaultFile returns ~/.burnrate/burnrate.log
+func DefaultFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".burnrate", "burnrate.log")
+}
+
+// Close closes the log file opened by ToFile and restores stderr
+func Close() {
+	mu.Lock()
+	defer mu.Unlock()
+	closeLocked()
+	out = os.Stderr
+}
+
+func closeLocked() {
+	if closer != nil {
+		closer.Close()
+		closer = nil
+	}
+}
+
+func logf(l Level, format string, args ...any) {
+	mu.Lock()
+	defer mu.Unlock()
+	if l < level {
+		return
+	}
+	fmt.Fprintf(out, "%s %-5s %s\n", time.Now().Format("2006-01-02 15:04:05"), l, fmt.Sprintf(format, args...))
+}
+
+// Debugf logs detail useful when diagnosing a problem
+func Debugf(format string, args ...any) { logf(LevelDebug, format, args...) }
+
+// Infof logs routine events
+func Infof(format string, args ...any) { logf(LevelInfo, format, args...) }
+
+// Warnf logs problems that were recovered from but may affect results
+func Warnf(format string, args ...any) { logf(LevelWarn, format, args...) }
+
+// Errorf logs failures
+func Errorf(format string, args ...any) { logf(LevelError, format, args...) }
@@ -0,0 +1,41 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestLevelFiltering(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	SetLevel(LevelInfo)
+	defer func() {
+		Close()
+		SetLevel(LevelWarn)
+	}()
+
+	Debugf("hidden %d", 1)
+	Infof("shown %d", 2)
+	Errorf("shown %d", 3)
+
+	out := buf.String()
+	if strings.Contains(out, "hidden") {
+		t.Errorf("Expected debug line to be filtered, got %q", out)
+	}
+	if !strings.Contains(out, "INFO  shown 2") || !strings.Contains(out, "ERROR shown 3") {
+		t.Errorf("Expected info and error lines, got %q", out)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for input, want := range map[string]Level{"debug": LevelDebug, "INFO": LevelInfo, "warning": LevelWarn, "error": LevelError} {
+		got, err := ParseLevel(input)
+		if err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %v, %v; expected %v", input, got, err, want)
+		}
+	}
+	if _, err := ParseLevel("loud"); err == nil {
+		t.Error("Expected an error for an unknown level")
+	}
+}
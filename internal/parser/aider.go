@@ -9,8 +9,10 @@ import (
 	"os/user"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/bangarangler/burnrate/internal/log"
 	"github.com/bangarangler/burnrate/internal/tracker"
 	"github.com/fsnotify/fsnotify"
 )
@@ -24,12 +26,17 @@ type AiderAnalyticsEvent struct {
 	Time       int64                `json:"time"` // Unix timestamp (seconds)
 }
 
-// AiderEventProperties contains the relevant fields from message_send events
+// AiderEventProperties contains the relevant fields from message_send events.
+// Tokens and cost are the request's only, with no split per model, so they
+// belong to MainModel, the model that served it. In architect mode the
+// editor's requests are events of their own, with the editor model as
+// MainModel; the weak model's requests (commit messages, chat summaries)
+// aren't logged at all, so their cost can't be tracked.
 type AiderEventProperties struct {
 	MainModel        string  `json:"main_model"`
-	WeakModel        string  `json:"weak_model"`
-	EditorModel      string  `json:"editor_model"`
-	EditFormat       string  `json:"edit_format"`
+	WeakModel        string  `json:"weak_model"`   // Configured, not used by this request
+	EditorModel      string  `json:"editor_model"` // Configured, not used by this request
+	EditFormat       string  `json:"edit_format"`  // e.g. "diff", "architect", "editor-diff"
 	PromptTokens     int     `json:"prompt_tokens"`
 	CompletionTokens int     `json:"completion_tokens"`
 	TotalTokens      int     `json:"total_tokens"`
@@ -38,7 +45,8 @@ type AiderEventProperties struct {
 }
 
 // Track processed events to avoid duplicates
-var processedAiderEvents = make(map[string]bool)
+var processedAiderEvents = newDedupSet[struct{}]()
+var processedAiderMu sync.Mutex // Protect the set
 
 // Default analytics log paths to check
 var defaultAiderLogPaths = []string{
@@ -47,8 +55,9 @@ var defaultAiderLogPaths = []string{
 	".aider.analytics.jsonl", // Current directory
 }
 
-// StartAiderWatcher watches for updates to Aider analytics log files
-func StartAiderWatcher(logPath string) error {
+// AiderLogPath resolves the analytics log to watch. An empty logPath finds
+// an existing log, falling back to ~/.aider/usage.jsonl.
+func AiderLogPath(logPath string) string {
 	usr, _ := user.Current()
 
 	// Expand ~ in path
@@ -64,12 +73,53 @@ func StartAiderWatcher(logPath string) error {
 			logPath = filepath.Join(usr.HomeDir, ".aider", "usage.jsonl")
 		}
 	}
+	return logPath
+}
+
+// AiderLogPaths resolves the analytics logs to watch. Each of logPaths may
+// be a comma-separated list, as --aider-log takes. With none given, every
+// existing default log is watched, falling back to ~/.aider/usage.jsonl.
+func AiderLogPaths(logPaths ...string) []string {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, list := range logPaths {
+		for _, path := range strings.Split(list, ",") {
+			path = strings.TrimSpace(path)
+			if path == "" {
+				continue
+			}
+			path = AiderLogPath(path)
+			if !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+	if len(paths) > 0 {
+		return paths
+	}
 
-	// Check if log file or directory exists
-	logExists := false
-	if _, err := os.Stat(logPath); err == nil {
-		logExists = true
+	if paths = findAiderLogFiles(); len(paths) > 0 {
+		return paths
 	}
+	return []string{AiderLogPath("")}
+}
+
+// StartAiderWatcher watches for updates to Aider analytics log files (see
+// AiderLogPaths). Events logged to more than one of them count once.
+func StartAiderWatcher(logPaths ...string) error {
+	paths := AiderLogPaths(logPaths...)
+
+	// Check if any log file exists
+	watched := make(map[string]bool, len(paths))
+	var missing []string
+	for _, path := range paths {
+		watched[filepath.Clean(path)] = true
+		if _, err := os.Stat(path); err != nil {
+			missing = append(missing, path)
+		}
+	}
+	logExists := len(missing) < len(paths)
 
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
@@ -79,11 +129,19 @@ func StartAiderWatcher(logPath string) error {
 			Status:  "error",
 			Message: "Failed to create watcher",
 		})
+		log.Errorf("aider: failed to create watcher: %v", err)
 		return err
 	}
 
 	// Process existing events first
-	processAiderLogFile(logPath)
+	for _, path := range paths {
+		processAiderLogFile(path)
+	}
+
+	message := "Watching analytics log"
+	if len(paths) > 1 {
+		message = fmt.Sprintf("Watching %d analytics logs", len(paths))
+	}
 
 	// Set initial status based on whether we found a log
 	if logExists {
@@ -91,7 +149,7 @@ func StartAiderWatcher(logPath string) error {
 			Name:    "Aider",
 			Tier:    tracker.TierFullTracking,
 			Status:  "active",
-			Message: "Watching analytics log",
+			Message: message,
 		})
 	} else {
 		tracker.Global.SetToolStatus(tracker.ToolStatus{
@@ -99,6 +157,8 @@ func StartAiderWatcher(logPath string) error {
 			Tier:    tracker.TierFullTracking,
 			Status:  "waiting",
 			Message: "Waiting for log file",
+			Path:    strings.Join(paths, ", "),
+			Hint:    "Run aider with --analytics-log " + paths[0] + ", or pass --aider-log",
 		})
 	}
 
@@ -109,7 +169,8 @@ func StartAiderWatcher(logPath string) error {
 				if !ok {
 					return
 				}
-				if event.Op&fsnotify.Write == fsnotify.Write {
+				// Only the active logs grow; rotated copies are read by backfill
+				if event.Op&fsnotify.Write == fsnotify.Write && watched[filepath.Clean(event.Name)] {
 					processAiderLogFile(event.Name)
 				}
 				// Update status to active when we see file activity
@@ -118,30 +179,41 @@ func StartAiderWatcher(logPath string) error {
 						Name:    "Aider",
 						Tier:    tracker.TierFullTracking,
 						Status:  "active",
-						Message: "Watching analytics log",
+						Message: message,
 					})
 				}
-			case _, ok := <-watcher.Errors:
+			case err, ok := <-watcher.Errors:
 				if !ok {
 					return
 				}
+				log.Warnf("aider: watcher error: %v", err)
 			}
 		}
 	}()
 
-	// Watch the log file directory (fsnotify can't watch non-existent files)
-	dir := filepath.Dir(logPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return err
-	}
-
-	if err := watcher.Add(dir); err != nil {
-		return err
+	// Watch each log's directory (fsnotify can't watch non-existent files)
+	dirs := make(map[string]bool)
+	for _, path := range paths {
+		dir := filepath.Dir(path)
+		if dirs[dir] {
+			continue
+		}
+		dirs[dir] = true
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			log.Warnf("aider: failed to create log directory %s: %v", dir, err)
+			return err
+		}
+		if err := watcher.Add(dir); err != nil {
+			log.Warnf("aider: failed to watch %s: %v", dir, err)
+			return err
+		}
 	}
 
-	// Also watch the file itself if it exists
-	if _, err := os.Stat(logPath); err == nil {
-		watcher.Add(logPath)
+	// Also watch the files themselves if they exist
+	for _, path := range paths {
+		if _, err := os.Stat(path); err == nil {
+			watcher.Add(path)
+		}
 	}
 
 	return nil
@@ -149,24 +221,35 @@ func StartAiderWatcher(logPath string) error {
 
 // findAiderLogFile looks for an existing Aider analytics log file
 func findAiderLogFile() string {
+	if paths := findAiderLogFiles(); len(paths) > 0 {
+		return paths[0]
+	}
+	return ""
+}
+
+// findAiderLogFiles returns every default Aider analytics log that exists
+func findAiderLogFiles() []string {
 	usr, _ := user.Current()
 
+	var found []string
 	for _, path := range defaultAiderLogPaths {
 		expanded := path
 		if strings.HasPrefix(path, "~") {
 			expanded = filepath.Join(usr.HomeDir, path[1:])
 		}
 		if _, err := os.Stat(expanded); err == nil {
-			return expanded
+			found = append(found, expanded)
 		}
 	}
-	return ""
+	return found
 }
 
-// processAiderLogFile reads and processes new events from an Aider analytics log
+// processAiderLogFile reads and processes new events from an Aider analytics
+// log, which may be a gzipped rotated copy
 func processAiderLogFile(filename string) {
-	file, err := os.Open(filename)
+	file, err := openLogFile(filename)
 	if err != nil {
+		log.Debugf("aider: cannot open %s: %v", filename, err)
 		return
 	}
 	defer file.Close()
@@ -176,7 +259,13 @@ func processAiderLogFile(filename string) {
 	buf := make([]byte, 0, 64*1024)
 	scanner.Buffer(buf, 1024*1024)
 
+	// How many times each event has appeared so far in this file, so
+	// identical requests in one log count separately
+	occurrences := make(map[string]int)
+
+	lineNum := 0
 	for scanner.Scan() {
+		lineNum++
 		line := scanner.Text()
 		if line == "" {
 			continue
@@ -184,6 +273,7 @@ func processAiderLogFile(filename string) {
 
 		var event AiderAnalyticsEvent
 		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			log.Debugf("aider: skipping malformed line %d in %s: %v", lineNum, filename, err)
 			continue
 		}
 
@@ -192,19 +282,38 @@ func processAiderLogFile(filename string) {
 			continue
 		}
 
-		// Skip if already processed (using timestamp + model as unique key)
+		ts := time.Now()
+		if event.Time > 0 {
+			ts = time.Unix(event.Time, 0)
+		}
+
+		// Skip if already processed, in this log or another
 		eventKey := makeAiderEventKey(event)
-		if processedAiderEvents[eventKey] {
+		occurrences[eventKey]++
+		eventKey = fmt.Sprintf("%s#%d", eventKey, occurrences[eventKey])
+		isNew := markAiderEventProcessed(eventKey, ts)
+		trace("Aider", eventKey, !isNew, map[string]any{
+			"time":              event.Time,
+			"main_model":        event.Properties.MainModel,
+			"weak_model":        event.Properties.WeakModel,
+			"editor_model":      event.Properties.EditorModel,
+			"edit_format":       event.Properties.EditFormat,
+			"prompt_tokens":     event.Properties.PromptTokens,
+			"completion_tokens": event.Properties.CompletionTokens,
+			"total_tokens":      event.Properties.TotalTokens,
+			"cost":              event.Properties.Cost,
+		})
+		if !isNew {
 			continue
 		}
-		processedAiderEvents[eventKey] = true
 
 		// Skip events with no token usage
 		if event.Properties.TotalTokens == 0 {
 			continue
 		}
 
-		// Use the main model for display
+		// The request's tokens and cost are all the main model's (see
+		// AiderEventProperties)
 		model := event.Properties.MainModel
 		if model == "" {
 			model = "aider-unknown"
@@ -213,44 +322,63 @@ func processAiderLogFile(filename string) {
 		// Use the pre-calculated cost from Aider if available
 		cost := event.Properties.Cost
 
-		tracker.Global.AddUsageWithTool(
-			"Aider",
-			model,
-			event.Properties.PromptTokens,
-			event.Properties.CompletionTokens,
-			cost,
-		)
-		tracker.Global.IncrementToolEvents("Aider")
+		accepted := tracker.Global.AddToolUsage("Aider", tracker.Usage{
+			Model:            model,
+			PromptTokens:     event.Properties.PromptTokens,
+			CompletionTokens: event.Properties.CompletionTokens,
+			TotalTokens:      event.Properties.PromptTokens + event.Properties.CompletionTokens,
+			Cost:             cost,
+			Timestamp:        ts,
+			EventKey:         eventKey,
+		})
+		if accepted {
+			tracker.Global.IncrementToolEvents("Aider")
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		log.Warnf("aider: stopped reading %s: %v", filename, err)
 	}
 }
 
-// makeAiderEventKey creates a unique key for deduplication
+// markAiderEventProcessed records an event key, returning false if it was already seen
+func markAiderEventProcessed(key string, at time.Time) bool {
+	processedAiderMu.Lock()
+	defer processedAiderMu.Unlock()
+
+	if processedAiderEvents.seen(key, at) {
+		return false
+	}
+	processedAiderEvents.put(key, at, struct{}{})
+	return true
+}
+
+// makeAiderEventKey creates a key for deduplication from the event's
+// contents, so the same request logged to two files counts once. Callers
+// number repeats within a file, so distinct requests in the same second
+// (same model, same token totals) count separately, and re-reads of a file
+// produce the same keys.
 func makeAiderEventKey(event AiderAnalyticsEvent) string {
-	return fmt.Sprintf("%s:%s:%s:%d",
+	return fmt.Sprintf("%s:%s:%s:%d:%d:%d:%.8f",
 		event.UserID,
 		event.Properties.MainModel,
 		time.Unix(event.Time, 0).Format(time.RFC3339),
-		event.Properties.TotalTokens)
+		event.Properties.PromptTokens,
+		event.Properties.CompletionTokens,
+		event.Properties.TotalTokens,
+		event.Properties.Cost)
 }
 
-// ParseAiderLogOnce does a one-time parse of an Aider analytics log file
-// Useful for the dashboard to load historical data
-func ParseAiderLogOnce(logPath string) error {
-	usr, _ := user.Current()
-
-	// Expand ~ in path
-	if strings.HasPrefix(logPath, "~") {
-		logPath = filepath.Join(usr.HomeDir, logPath[1:])
-	}
-
-	if logPath == "" {
-		logPath = findAiderLogFile()
-	}
-
-	if logPath == "" {
-		return nil // No log file found, not an error
+// ParseAiderLogOnce does a one-time parse of Aider analytics logs (see
+// AiderLogPaths) and their rotated copies. Useful for the dashboard to load
+// historical data
+func ParseAiderLogOnce(logPaths ...string) error {
+	for _, logPath := range AiderLogPaths(logPaths...) {
+		// Rotated copies hold the older history, so read them first
+		for _, rotated := range rotatedLogs(logPath) {
+			processAiderLogFile(rotated)
+		}
+		processAiderLogFile(logPath)
 	}
-
-	processAiderLogFile(logPath)
 	return nil
 }
@@ -0,0 +1,213 @@
+package parser
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bangarangler/burnrate/internal/storage"
+	"github.com/bangarangler/burnrate/internal/tracker"
+)
+
+func TestAiderSameSecondEventsBothCounted(t *testing.T) {
+	// Two distinct requests in the same second, same model, same token totals
+	line := `{"event": "message_send", "user_id": "u1", "time": 1735000000, "properties": {"main_model": "gpt-4o", "prompt_tokens": 100, "completion_tokens": 50, "total_tokens": 150, "cost": 0.001}}`
+
+	logPath := filepath.Join(t.TempDir(), "usage.jsonl")
+	if err := os.WriteFile(logPath, []byte(line+"\n"+line+"\n"), 0644); err != nil {
+		t.Fatalf("failed to write log: %v", err)
+	}
+
+	tracker.Global.Reset()
+	defer tracker.Global.Reset()
+	t.Setenv("HOME", t.TempDir())
+	if err := storage.InitDB(); err != nil {
+		t.Fatalf("InitDB failed: %v", err)
+	}
+	defer func() {
+		storage.DB.Close()
+		storage.DB = nil
+	}()
+	rows := func() int {
+		_, events, _, _ := storage.GetLifetimeStats()
+		return events
+	}
+
+	// 1. Both events must be counted, in the session and in history
+	processAiderLogFile(logPath)
+	if got := len(tracker.Global.GetUsages()); got != 2 {
+		t.Fatalf("Expected 2 usages, got %d", got)
+	}
+	if got := rows(); got != 2 {
+		t.Errorf("Expected 2 history rows, got %d", got)
+	}
+
+	// 2. Re-reading the same file must not double count
+	processAiderLogFile(logPath)
+	if got := len(tracker.Global.GetUsages()); got != 2 {
+		t.Errorf("Expected 2 usages after re-read, got %d", got)
+	}
+	if got := rows(); got != 2 {
+		t.Errorf("Expected 2 history rows after re-read, got %d", got)
+	}
+}
+
+func TestAiderArchitectModeAttributesEachRequestToItsModel(t *testing.T) {
+	// Architect mode: the architect's request, then the editor's, each naming
+	// the other models it was configured with
+	lines := []string{
+		`{"event": "message_send", "user_id": "u1", "time": 1735000000, "properties": {"main_model": "o1", "editor_model": "claude-sonnet-4", "weak_model": "gpt-4o-mini", "edit_format": "architect", "prompt_tokens": 1000, "completion_tokens": 500, "total_tokens": 1500, "cost": 0.045}}`,
+		`{"event": "message_send", "user_id": "u1", "time": 1735000010, "properties": {"main_model": "claude-sonnet-4", "editor_model": "claude-sonnet-4", "weak_model": "gpt-4o-mini", "edit_format": "editor-diff", "prompt_tokens": 2000, "completion_tokens": 300, "total_tokens": 2300, "cost": 0.0105}}`,
+	}
+	logPath := filepath.Join(t.TempDir(), "usage.jsonl")
+	if err := os.WriteFile(logPath, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatalf("failed to write log: %v", err)
+	}
+
+	tracker.Global.Reset()
+	defer tracker.Global.Reset()
+	processAiderLogFile(logPath)
+
+	// Each request's cost goes to the model that served it, never to the
+	// editor or weak model it merely names
+	costs := map[string]float64{}
+	for _, u := range tracker.Global.GetUsages() {
+		costs[u.Model] += u.Cost
+	}
+	if len(costs) != 2 || costs["o1"] != 0.045 || costs["claude-sonnet-4"] != 0.0105 {
+		t.Errorf("Expected $0.045 on o1 and $0.0105 on claude-sonnet-4, got %v", costs)
+	}
+}
+
+func TestAiderConcurrentProcessing(t *testing.T) {
+	// Run with -race: watcher goroutines can process the same file at once
+	var lines []string
+	for i := 0; i < 50; i++ {
+		lines = append(lines, fmt.Sprintf(`{"event": "message_send", "user_id": "u1", "time": %d, "properties": {"main_model": "gpt-4o", "prompt_tokens": 10, "completion_tokens": 5, "total_tokens": 15, "cost": 0.0001}}`, 1735000000+i))
+	}
+
+	logPath := filepath.Join(t.TempDir(), "usage.jsonl")
+	if err := os.WriteFile(logPath, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatalf("failed to write log: %v", err)
+	}
+
+	tracker.Global.Reset()
+	defer tracker.Global.Reset()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			processAiderLogFile(logPath)
+		}()
+	}
+	wg.Wait()
+
+	if got := len(tracker.Global.GetUsages()); got != len(lines) {
+		t.Errorf("Expected %d usages, got %d", len(lines), got)
+	}
+}
+
+func TestAiderBackfillReadsRotatedLogs(t *testing.T) {
+	event := func(ts int64, model string) string {
+		return fmt.Sprintf(`{"event": "message_send", "user_id": "u1", "time": %d, "properties": {"main_model": %q, "prompt_tokens": 100, "completion_tokens": 50, "total_tokens": 150, "cost": 0.001}}`+"\n", ts, model)
+	}
+
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "usage.jsonl")
+
+	// Oldest history is gzipped in usage.jsonl.2.gz, then usage.jsonl.1
+	var gzipped bytes.Buffer
+	gz := gzip.NewWriter(&gzipped)
+	gz.Write([]byte(event(1735000000, "oldest")))
+	gz.Close()
+	files := map[string][]byte{
+		logPath + ".2.gz": gzipped.Bytes(),
+		logPath + ".1":    []byte(event(1735000100, "older")),
+		logPath:           []byte(event(1735000200, "current")),
+	}
+	for path, data := range files {
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+
+	// 1. Rotated copies are found oldest first
+	rotated := rotatedLogs(logPath)
+	if len(rotated) != 2 || rotated[0] != logPath+".2.gz" || rotated[1] != logPath+".1" {
+		t.Fatalf("Expected [%s.2.gz %s.1], got %v", logPath, logPath, rotated)
+	}
+
+	tracker.Global.Reset()
+	defer tracker.Global.Reset()
+
+	// 2. Backfill reads every copy, decompressing the gzipped one, in order
+	if err := ParseAiderLogOnce(logPath); err != nil {
+		t.Fatalf("ParseAiderLogOnce failed: %v", err)
+	}
+	usages := tracker.Global.GetUsages()
+	if len(usages) != 3 {
+		t.Fatalf("Expected 3 usages, got %d", len(usages))
+	}
+	for i, want := range []string{"oldest", "older", "current"} {
+		if usages[i].Model != want {
+			t.Errorf("Expected usage %d to be %s, got %s", i+1, want, usages[i].Model)
+		}
+	}
+}
+
+func TestAiderWatchesSeveralLogs(t *testing.T) {
+	event := func(ts int64, cost float64) string {
+		return fmt.Sprintf(`{"event": "message_send", "user_id": "u1", "time": %d, "properties": {"main_model": "gpt-4o", "prompt_tokens": 100, "completion_tokens": 50, "total_tokens": 150, "cost": %g}}`+"\n", ts, cost)
+	}
+
+	// The global log and a project's log, both holding the 1735000100 request
+	dir := t.TempDir()
+	global := filepath.Join(dir, "usage.jsonl")
+	project := filepath.Join(dir, "project", ".aider.analytics.jsonl")
+	if err := os.MkdirAll(filepath.Dir(project), 0755); err != nil {
+		t.Fatalf("failed to create project dir: %v", err)
+	}
+	if err := os.WriteFile(global, []byte(event(1735000000, 0.01)+event(1735000100, 0.02)), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", global, err)
+	}
+	if err := os.WriteFile(project, []byte(event(1735000100, 0.02)+event(1735000200, 0.04)), 0644); err != nil {
+		t.Fatalf("failed to write %s: %v", project, err)
+	}
+
+	tracker.Global.Reset()
+	defer tracker.Global.Reset()
+	processedAiderEvents = newDedupSet[struct{}]()
+	total := func() float64 { return math.Round(tracker.Global.GetSessionCost()*100) / 100 }
+
+	// 1. Both logs are read, as a comma-separated --aider-log gives them,
+	// and the request in both counts once
+	if err := StartAiderWatcher(global + ", " + project); err != nil {
+		t.Fatalf("StartAiderWatcher failed: %v", err)
+	}
+	if got := len(tracker.Global.GetUsages()); got != 3 {
+		t.Errorf("Expected 3 usages, got %d", got)
+	}
+	if got := total(); got != 0.07 {
+		t.Errorf("Expected $0.07, got $%.2f", got)
+	}
+	if got := tracker.Global.GetToolStatus("Aider").Message; got != "Watching 2 analytics logs" {
+		t.Errorf("Expected both logs watched, got %q", got)
+	}
+
+	// 2. New events in either log are picked up
+	f, err := os.OpenFile(project, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("failed to open %s: %v", project, err)
+	}
+	f.WriteString(event(1735000300, 0.08))
+	f.Close()
+	waitFor(t, "the new event to be recorded", func() bool { return total() == 0.15 })
+}
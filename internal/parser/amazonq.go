@@ -0,0 +1,93 @@
+package parser
+
+import (
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+)
+
+// AmazonQStatus represents the detection status of Amazon Q Developer
+// (formerly CodeWhisperer), in its CLI or an IDE plugin
+type AmazonQStatus struct {
+	CLIInstalled bool   // 'q' CLI found
+	Configured   bool   // Signed in: CLI data or IDE plugin settings present
+	CLIPath      string // Path to CLI if found
+	ConfigPath   string // Path to the data or settings directory if found
+	DashboardURL string // URL to view usage (always set)
+}
+
+// amazonQDashboardURL is where Amazon Q Developer subscriptions and usage
+// are shown
+const amazonQDashboardURL = "https://console.aws.amazon.com/amazonq/developer"
+
+// CheckAmazonQStatus performs a one-time detection of Amazon Q Developer.
+// This is a Tier 2 tool - we can only detect installation, not parse usage logs
+func CheckAmazonQStatus() AmazonQStatus {
+	usr, err := user.Current()
+	if err != nil {
+		return detectAmazonQ("", exec.LookPath)
+	}
+	return detectAmazonQ(usr.HomeDir, exec.LookPath)
+}
+
+// detectAmazonQ looks for the CLI with lookPath and for its data and the
+// IDE plugins' settings under home
+func detectAmazonQ(home string, lookPath func(string) (string, error)) AmazonQStatus {
+	status := AmazonQStatus{DashboardURL: amazonQDashboardURL}
+
+	if path, err := lookPath("q"); err == nil {
+		status.CLIInstalled = true
+		status.CLIPath = path
+	}
+	if home == "" {
+		return status
+	}
+
+	// The CLI keeps its sign-in in a SQLite database; the IDE plugins keep
+	// settings under ~/.aws, in a directory named for the product at the time
+	candidates := []string{
+		filepath.Join(home, ".local", "share", "amazon-q", "data.sqlite3"),
+		filepath.Join(home, "Library", "Application Support", "amazon-q", "data.sqlite3"),
+		filepath.Join(home, ".aws", "amazonq"),
+		filepath.Join(home, ".aws", "codewhisperer"),
+	}
+	for _, path := range candidates {
+		if hasContent(path) {
+			status.Configured = true
+			status.ConfigPath = filepath.Dir(path)
+			if info, err := os.Stat(path); err == nil && info.IsDir() {
+				status.ConfigPath = path
+			}
+			break
+		}
+	}
+	return status
+}
+
+// IsInstalled returns true if the Amazon Q CLI is installed
+func (s AmazonQStatus) IsInstalled() bool {
+	return s.CLIInstalled
+}
+
+// StatusMessage returns a human-readable status message
+func (s AmazonQStatus) StatusMessage() string {
+	if s.Configured {
+		return "Detected, view usage at console.aws.amazon.com/amazonq"
+	}
+	if s.IsInstalled() {
+		return "CLI found but not signed in"
+	}
+	return "Not installed"
+}
+
+// StatusCode returns the status code for display
+func (s AmazonQStatus) StatusCode() string {
+	if s.Configured {
+		return "configured"
+	}
+	if s.IsInstalled() {
+		return "installed"
+	}
+	return "not_found"
+}
@@ -0,0 +1,58 @@
+package parser
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectAmazonQ(t *testing.T) {
+	noCLI := func(string) (string, error) { return "", errors.New("not found") }
+	withCLI := func(name string) (string, error) { return "/usr/local/bin/" + name, nil }
+
+	// 1. Nothing installed
+	home := t.TempDir()
+	s := detectAmazonQ(home, noCLI)
+	if s.StatusCode() != "not_found" || s.DashboardURL == "" {
+		t.Errorf("Expected not_found with a dashboard URL, got %s (%q)", s.StatusCode(), s.DashboardURL)
+	}
+
+	// 2. The CLI alone is installed but not signed in
+	s = detectAmazonQ(home, withCLI)
+	if s.StatusCode() != "installed" || s.CLIPath != "/usr/local/bin/q" {
+		t.Errorf("Expected installed at /usr/local/bin/q, got %s at %q", s.StatusCode(), s.CLIPath)
+	}
+
+	// 3. An empty settings directory doesn't count
+	pluginDir := filepath.Join(home, ".aws", "amazonq")
+	if err := os.MkdirAll(pluginDir, 0755); err != nil {
+		t.Fatalf("failed to create %s: %v", pluginDir, err)
+	}
+	if s := detectAmazonQ(home, noCLI); s.Configured {
+		t.Errorf("Expected an empty %s not to count", pluginDir)
+	}
+
+	// 4. IDE plugin settings are detected without the CLI
+	if err := os.WriteFile(filepath.Join(pluginDir, "settings.json"), []byte(`{"region": "us-east-1"}`), 0644); err != nil {
+		t.Fatalf("failed to write settings: %v", err)
+	}
+	s = detectAmazonQ(home, noCLI)
+	if s.StatusCode() != "configured" || s.ConfigPath != pluginDir {
+		t.Errorf("Expected configured from %s, got %s from %q", pluginDir, s.StatusCode(), s.ConfigPath)
+	}
+
+	// 5. So is the CLI's sign-in database
+	home = t.TempDir()
+	dataDir := filepath.Join(home, ".local", "share", "amazon-q")
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		t.Fatalf("failed to create %s: %v", dataDir, err)
+	}
+	if err := os.WriteFile(filepath.Join(dataDir, "data.sqlite3"), []byte("SQLite format 3"), 0644); err != nil {
+		t.Fatalf("failed to write database: %v", err)
+	}
+	s = detectAmazonQ(home, withCLI)
+	if s.StatusCode() != "configured" || s.ConfigPath != dataDir {
+		t.Errorf("Expected configured from %s, got %s from %q", dataDir, s.StatusCode(), s.ConfigPath)
+	}
+}
@@ -8,8 +8,10 @@ import (
 	"os/user"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/bangarangler/burnrate/internal/log"
 	"github.com/bangarangler/burnrate/internal/pricing"
 	"github.com/bangarangler/burnrate/internal/tracker"
 	"github.com/fsnotify/fsnotify"
@@ -62,11 +64,14 @@ type CodexUsageEvent struct {
 	ReasoningTokenCount int    `json:"reasoning_token_count,omitempty"`
 	ToolTokenCount      int    `json:"tool_token_count,omitempty"`
 	Model               string `json:"model,omitempty"`
+	ConversationID      string `json:"conversation_id,omitempty"` // The rollout's session ID
 }
 
 // Track processed entries to avoid duplicates
-var processedCodexSessions = make(map[string]bool)
+var processedCodexSessions = newDedupSet[struct{}]()
 var processedCodexRollouts = make(map[string]int64) // filename -> last processed offset
+var codexSessionProjects = make(map[string]string)  // session ID -> project, from its cwd
+var processedCodexMu sync.Mutex                     // Protect all three
 
 // CodexDataDir returns the Codex data directory
 func CodexDataDir() string {
@@ -91,12 +96,20 @@ func StartCodexWatcher() error {
 			Tier:    tracker.TierFullTracking,
 			Status:  "not_found",
 			Message: "~/.codex directory not found",
+			Path:    baseDir,
+			Hint:    "Run Codex once, or set $CODEX_HOME",
 		})
+		log.Debugf("codex: %s not found", baseDir)
+		startWhenFound("codex", func() bool {
+			_, err := os.Stat(CodexDataDir())
+			return err == nil
+		}, StartCodexWatcher)
 		return err
 	}
 
 	// Ensure sessions directory exists
 	if err := os.MkdirAll(sessionsDir, 0755); err != nil {
+		log.Warnf("codex: failed to create %s: %v", sessionsDir, err)
 		return err
 	}
 
@@ -108,6 +121,7 @@ func StartCodexWatcher() error {
 			Status:  "error",
 			Message: "Failed to create watcher",
 		})
+		log.Errorf("codex: failed to create watcher: %v", err)
 		return err
 	}
 
@@ -126,6 +140,8 @@ func StartCodexWatcher() error {
 			Tier:    tracker.TierFullTracking,
 			Status:  "partial",
 			Message: otelStatus,
+			Path:    filepath.Join(baseDir, "config.toml"),
+			Hint:    `Token counts need OTEL: add [otel] with exporter = "otlp-http" to config.toml`,
 		})
 	}
 
@@ -150,10 +166,11 @@ func StartCodexWatcher() error {
 						watcher.Add(event.Name)
 					}
 				}
-			case _, ok := <-watcher.Errors:
+			case err, ok := <-watcher.Errors:
 				if !ok {
 					return
 				}
+				log.Warnf("codex: watcher error: %v", err)
 			}
 		}
 	}()
@@ -186,6 +203,7 @@ func processExistingCodexSessions(sessionsDir string) {
 func processCodexRolloutFile(filename string) {
 	file, err := os.Open(filename)
 	if err != nil {
+		log.Debugf("codex: cannot open %s: %v", filename, err)
 		return
 	}
 	defer file.Close()
@@ -193,11 +211,12 @@ func processCodexRolloutFile(filename string) {
 	// Get file info for tracking
 	stat, err := file.Stat()
 	if err != nil {
+		log.Debugf("codex: cannot stat %s: %v", filename, err)
 		return
 	}
 
 	// Skip if we've already processed this file at this size
-	lastOffset, exists := processedCodexRollouts[filename]
+	lastOffset, exists := codexRolloutOffset(filename)
 	if exists && lastOffset >= stat.Size() {
 		return
 	}
@@ -222,47 +241,128 @@ func processCodexRolloutFile(filename string) {
 
 		var entry CodexRolloutEntry
 		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			log.Debugf("codex: skipping malformed line in %s: %v", filename, err)
 			continue
 		}
 
-		// Try to extract session metadata for model info
-		var sessionMeta CodexSessionMeta
-		if err := json.Unmarshal(entry.Item, &sessionMeta); err == nil {
-			if sessionMeta.SessionMeta.Meta.ID != "" {
+		switch codexItemKind(entry.Item) {
+		case "SessionMeta":
+			// Session metadata carries the provider and working directory
+			var sessionMeta CodexSessionMeta
+			if err := json.Unmarshal(entry.Item, &sessionMeta); err == nil && sessionMeta.SessionMeta.Meta.ID != "" {
 				currentProvider = sessionMeta.SessionMeta.Meta.ModelProvider
-				// Skip if already processed
-				if processedCodexSessions[sessionMeta.SessionMeta.Meta.ID] {
-					continue
-				}
-				processedCodexSessions[sessionMeta.SessionMeta.Meta.ID] = true
+				started, _ := time.Parse(time.RFC3339, entry.Timestamp)
+				id := sessionMeta.SessionMeta.Meta.ID
+				isNew := markCodexSessionProcessed(id, started)
+				setCodexSessionProject(id, ProjectKey(sessionMeta.SessionMeta.Meta.Cwd))
+				trace("Codex", id, !isNew, map[string]any{
+					"timestamp":      entry.Timestamp,
+					"model_provider": currentProvider,
+					"cwd":            sessionMeta.SessionMeta.Meta.Cwd,
+				})
 			}
-			continue
-		}
-
-		// Try to extract message with model info
-		var message CodexMessage
-		if err := json.Unmarshal(entry.Item, &message); err == nil {
-			if message.Message.Model != "" {
+		case "Message":
+			// Messages carry the model. Rollout files don't contain token
+			// counts; token usage is only available via OTEL (if enabled).
+			var message CodexMessage
+			if err := json.Unmarshal(entry.Item, &message); err == nil && message.Message.Model != "" {
 				currentModel = message.Message.Model
 			}
-			// Note: Rollout files don't contain token counts
-			// Token usage only available via OTEL (if enabled)
-			continue
 		}
 	}
+	if err := scanner.Err(); err != nil {
+		log.Warnf("codex: stopped reading %s: %v", filename, err)
+	}
 
 	// Update processed offset
 	newOffset, _ := file.Seek(0, 1) // Get current position
 	if newOffset == 0 {
 		newOffset = stat.Size()
 	}
-	processedCodexRollouts[filename] = newOffset
+	setCodexRolloutOffset(filename, newOffset)
 
 	// Store model info for potential future OTEL integration
 	_ = currentModel
 	_ = currentProvider
 }
 
+// codexItemKind returns a rollout item's type: its top-level key, such as
+// "SessionMeta" or "Message". Decoding into each struct in turn can't tell
+// them apart, since unknown keys are ignored and every decode succeeds.
+func codexItemKind(item json.RawMessage) string {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(item, &fields); err != nil {
+		return ""
+	}
+	for _, kind := range []string{"SessionMeta", "Message"} {
+		if _, ok := fields[kind]; ok {
+			return kind
+		}
+	}
+	return ""
+}
+
+// markCodexSessionProcessed records a session ID, returning false if it was already seen
+func markCodexSessionProcessed(id string, at time.Time) bool {
+	processedCodexMu.Lock()
+	defer processedCodexMu.Unlock()
+
+	if processedCodexSessions.seen(id, at) {
+		return false
+	}
+	processedCodexSessions.put(id, at, struct{}{})
+	return true
+}
+
+// setCodexSessionProject records the project a session was started in, for
+// its OTEL usage events to be attributed to
+func setCodexSessionProject(id, project string) {
+	processedCodexMu.Lock()
+	defer processedCodexMu.Unlock()
+
+	if project != "" {
+		codexSessionProjects[id] = project
+	}
+}
+
+// codexSessionProject returns the project a session was started in, or "" if
+// its rollout hasn't been read
+func codexSessionProject(id string) string {
+	processedCodexMu.Lock()
+	defer processedCodexMu.Unlock()
+
+	return codexSessionProjects[id]
+}
+
+// codexRolloutOffset returns the last processed offset for a rollout file
+func codexRolloutOffset(filename string) (int64, bool) {
+	processedCodexMu.Lock()
+	defer processedCodexMu.Unlock()
+
+	offset, ok := processedCodexRollouts[filename]
+	return offset, ok
+}
+
+// setCodexRolloutOffset records the last processed offset for a rollout file
+func setCodexRolloutOffset(filename string, offset int64) {
+	processedCodexMu.Lock()
+	defer processedCodexMu.Unlock()
+
+	processedCodexRollouts[filename] = offset
+}
+
+// ParseCodexSessionsOnce does a one-time walk of existing Codex rollout files
+// Useful for backfilling history without starting a watcher
+func ParseCodexSessionsOnce() error {
+	sessionsDir := filepath.Join(CodexDataDir(), "sessions")
+	if _, err := os.Stat(sessionsDir); os.IsNotExist(err) {
+		return nil // No sessions, not an error
+	}
+
+	processExistingCodexSessions(sessionsDir)
+	return nil
+}
+
 // ParseCodexOTELEvent processes an OpenTelemetry event from Codex
 // This is useful if the user has OTEL export enabled
 func ParseCodexOTELEvent(eventData []byte) error {
@@ -285,9 +385,20 @@ func ParseCodexOTELEvent(eventData []byte) error {
 	// Calculate total output (including reasoning and tool tokens)
 	output := event.OutputTokenCount + event.ReasoningTokenCount + event.ToolTokenCount
 
-	cost := pricing.CalculateCost(model, input, output)
-
-	tracker.Global.AddUsageWithTool("Codex", model, input, output, cost)
+	cost := pricing.CalculateCostWithCache(model, event.InputTokenCount, event.CachedTokenCount,
+		event.OutputTokenCount+event.ToolTokenCount, event.ReasoningTokenCount)
+
+	tracker.Global.AddToolUsage("Codex", tracker.Usage{
+		Model:            model,
+		PromptTokens:     input,
+		CompletionTokens: output,
+		TotalTokens:      input + output,
+		CacheReadTokens:  event.CachedTokenCount,
+		ReasoningTokens:  event.ReasoningTokenCount,
+		Cost:             cost,
+		CacheSavings:     pricing.CalculateCacheSavings(model, event.CachedTokenCount),
+		Project:          codexSessionProject(event.ConversationID),
+	})
 	return nil
 }
 
@@ -327,73 +438,74 @@ func ParseCodexHistoryOnce() ([]CodexHistoryEntry, error) {
 	return entries, scanner.Err()
 }
 
-// GetCodexSessions returns a list of recent Codex sessions from rollout files
-// Returns session IDs and their start times
-func GetCodexSessions() ([]struct {
+// CodexSession is a Codex session as described by its rollout file
+type CodexSession struct {
 	ID        string
 	StartTime time.Time
-	Model     string
+	Model     string // From the first message that names one
 	Provider  string
-}, error) {
-	sessionsDir := filepath.Join(CodexDataDir(), "sessions")
+}
 
-	var sessions []struct {
-		ID        string
-		StartTime time.Time
-		Model     string
-		Provider  string
-	}
+// GetCodexSessions returns the Codex sessions found in rollout files.
+// It reads each file independently of the watcher, so it never touches the
+// live offsets or processed-session set.
+func GetCodexSessions() ([]CodexSession, error) {
+	sessionsDir := filepath.Join(CodexDataDir(), "sessions")
 
-	filepath.Walk(sessionsDir, func(path string, info os.FileInfo, _ error) error {
-		if info == nil || info.IsDir() {
+	var sessions []CodexSession
+	// Unreadable entries, including a missing sessions directory, are skipped
+	err := filepath.Walk(sessionsDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
 			return nil
 		}
 		if !strings.HasPrefix(filepath.Base(path), "rollout-") || !strings.HasSuffix(path, ".jsonl") {
 			return nil
 		}
-
-		file, err := os.Open(path)
-		if err != nil {
-			return nil
+		if session, ok := readCodexSession(path); ok {
+			sessions = append(sessions, session)
 		}
-		defer file.Close()
+		return nil
+	})
+	return sessions, err
+}
 
-		scanner := bufio.NewScanner(file)
-		for scanner.Scan() {
-			line := scanner.Text()
-			if line == "" {
-				continue
-			}
+// readCodexSession reads a rollout file's session metadata and the model of
+// its first message that names one, stopping as soon as it has both
+func readCodexSession(path string) (CodexSession, bool) {
+	file, err := os.Open(path)
+	if err != nil {
+		log.Debugf("codex: cannot open %s: %v", path, err)
+		return CodexSession{}, false
+	}
+	defer file.Close()
 
-			var entry CodexRolloutEntry
-			if err := json.Unmarshal([]byte(line), &entry); err != nil {
-				continue
-			}
+	var session CodexSession
+	scanner := bufio.NewScanner(file)
+	buf := make([]byte, 0, 64*1024)
+	scanner.Buffer(buf, 1024*1024)
 
+	for scanner.Scan() && (session.ID == "" || session.Model == "") {
+		var entry CodexRolloutEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+
+		switch codexItemKind(entry.Item) {
+		case "SessionMeta":
 			var sessionMeta CodexSessionMeta
-			if err := json.Unmarshal(entry.Item, &sessionMeta); err != nil {
-				continue
+			if err := json.Unmarshal(entry.Item, &sessionMeta); err == nil && session.ID == "" {
+				session.ID = sessionMeta.SessionMeta.Meta.ID
+				session.Provider = sessionMeta.SessionMeta.Meta.ModelProvider
+				session.StartTime, _ = time.Parse(time.RFC3339, entry.Timestamp)
 			}
-
-			if sessionMeta.SessionMeta.Meta.ID != "" {
-				ts, _ := time.Parse(time.RFC3339, entry.Timestamp)
-				sessions = append(sessions, struct {
-					ID        string
-					StartTime time.Time
-					Model     string
-					Provider  string
-				}{
-					ID:        sessionMeta.SessionMeta.Meta.ID,
-					StartTime: ts,
-					Provider:  sessionMeta.SessionMeta.Meta.ModelProvider,
-				})
-				break // Only need first entry per file
+		case "Message":
+			var message CodexMessage
+			if err := json.Unmarshal(entry.Item, &message); err == nil && session.Model == "" {
+				session.Model = message.Message.Model
 			}
 		}
-		return nil
-	})
-
-	return sessions, nil
+	}
+	return session, session.ID != ""
 }
 
 // CheckCodexOTELEnabled checks if OTEL export is enabled in Codex config
@@ -0,0 +1,60 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestGetCodexSessionsReadsModelAndProvider(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("CODEX_HOME", home)
+
+	dir := filepath.Join(home, "sessions", "2025", "06", "01")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("failed to create sessions dir: %v", err)
+	}
+
+	// Metadata first, then a user message without a model, then the reply
+	rollout := `{"timestamp": "2025-06-01T10:00:00Z", "item": {"SessionMeta": {"meta": {"id": "sess-1", "model_provider": "openai"}}}}
+{"timestamp": "2025-06-01T10:00:05Z", "item": {"Message": {"role": "user", "content": "hi"}}}
+{"timestamp": "2025-06-01T10:00:09Z", "item": {"Message": {"role": "assistant", "content": "hello", "model": "gpt-5-codex"}}}
+{"timestamp": "2025-06-01T10:01:00Z", "item": {"Message": {"role": "assistant", "content": "later", "model": "gpt-5"}}}
+`
+	if err := os.WriteFile(filepath.Join(dir, "rollout-2025-06-01T10-00-00-sess-1.jsonl"), []byte(rollout), 0644); err != nil {
+		t.Fatalf("failed to write rollout: %v", err)
+	}
+
+	sessions, err := GetCodexSessions()
+	if err != nil {
+		t.Fatalf("GetCodexSessions failed: %v", err)
+	}
+	if len(sessions) != 1 {
+		t.Fatalf("Expected 1 session, got %+v", sessions)
+	}
+
+	s := sessions[0]
+	if s.ID != "sess-1" || s.Provider != "openai" {
+		t.Errorf("Expected sess-1 from openai, got %+v", s)
+	}
+	if s.Model != "gpt-5-codex" {
+		t.Errorf("Expected the first message's model gpt-5-codex, got %q", s.Model)
+	}
+	if !s.StartTime.Equal(time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the session to start at 10:00, got %v", s.StartTime)
+	}
+
+	// Reading sessions leaves the watcher's offsets alone
+	if _, tracked := codexRolloutOffset(filepath.Join(dir, "rollout-2025-06-01T10-00-00-sess-1.jsonl")); tracked {
+		t.Error("Expected GetCodexSessions not to record a rollout offset")
+	}
+}
+
+func TestGetCodexSessionsWithoutSessionsDir(t *testing.T) {
+	t.Setenv("CODEX_HOME", t.TempDir())
+	sessions, err := GetCodexSessions()
+	if err != nil || len(sessions) != 0 {
+		t.Errorf("Expected no sessions and no error, got %+v, %v", sessions, err)
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"os/user"
 	"path/filepath"
 	"strings"
+
+	"github.com/bangarangler/burnrate/internal/log"
 )
 
 // CopilotStatus represents the detection status of GitHub Copilot CLI
@@ -56,6 +58,7 @@ func checkGHCopilotExtension() bool {
 	cmd.Stderr = nil
 
 	if err := cmd.Run(); err != nil {
+		log.Debugf("copilot: gh extension list failed: %v", err)
 		return false
 	}
 
@@ -0,0 +1,47 @@
+// internal/parser/cost.go
+package parser
+
+import (
+	"github.com/bangarangler/burnrate/internal/log"
+	"github.com/bangarangler/burnrate/internal/pricing"
+)
+
+// TrustToolCost uses a tool's own reported cost when it has one. When false,
+// events are priced from their tokens like tools that report no cost.
+var TrustToolCost = true
+
+// costDivergenceFactor is how far a tool's reported cost may be from our
+// price, in either direction, before the event is flagged as a mismatch
+const costDivergenceFactor = 2.0
+
+// costsDiverge reports whether two positive costs differ by more than
+// costDivergenceFactor
+func costsDiverge(reported, computed float64) bool {
+	if reported <= 0 || computed <= 0 {
+		return false
+	}
+	return reported > computed*costDivergenceFactor || computed > reported*costDivergenceFactor
+}
+
+// chooseCost picks between a tool's reported cost and our computed price,
+// and reports whether they disagree. Reported costs outside (0, 100) are
+// treated as missing. A model we have no price for can't be checked, and
+// keeps its reported cost even when recomputing, since our price would only
+// be a guess.
+func chooseCost(tool, model string, reported, computed float64) (cost float64, mismatch bool) {
+	if reported <= 0 || reported >= 100 { // Sanity check
+		return computed, false
+	}
+	if !pricing.IsKnown(model) {
+		return reported, false
+	}
+
+	mismatch = costsDiverge(reported, computed)
+	if mismatch {
+		log.Debugf("%s: %s reported $%.4f but prices at $%.4f", tool, model, reported, computed)
+	}
+	if TrustToolCost {
+		return reported, mismatch
+	}
+	return computed, mismatch
+}
@@ -0,0 +1,53 @@
+package parser
+
+import "testing"
+
+func TestCostsDiverge(t *testing.T) {
+	cases := []struct {
+		reported, computed float64
+		want               bool
+	}{
+		{1.00, 1.00, false},
+		{1.00, 1.90, false}, // Within 2x
+		{1.00, 2.50, true},  // Tool reported far less
+		{3.00, 1.00, true},  // Tool reported far more
+		{1.00, 0, false},    // Nothing to compare against
+	}
+	for _, c := range cases {
+		if got := costsDiverge(c.reported, c.computed); got != c.want {
+			t.Errorf("costsDiverge(%v, %v): expected %v, got %v", c.reported, c.computed, c.want, got)
+		}
+	}
+}
+
+func TestChooseCost(t *testing.T) {
+	defer func(trust bool) { TrustToolCost = trust }(TrustToolCost)
+
+	// 1. Trusting the tool keeps its cost but flags the disagreement
+	TrustToolCost = true
+	cost, mismatch := chooseCost("test", "gpt-4o", 0.50, 0.10)
+	if cost != 0.50 || !mismatch {
+		t.Errorf("Expected $0.50 flagged, got $%.2f (mismatch=%v)", cost, mismatch)
+	}
+
+	// 2. Recomputing uses our price, still flagged
+	TrustToolCost = false
+	cost, mismatch = chooseCost("test", "gpt-4o", 0.50, 0.10)
+	if cost != 0.10 || !mismatch {
+		t.Errorf("Expected $0.10 flagged, got $%.2f (mismatch=%v)", cost, mismatch)
+	}
+
+	// 3. An unknown model can't be checked and keeps the tool's cost
+	cost, mismatch = chooseCost("test", "no-such-model", 0.50, 0.10)
+	if cost != 0.50 || mismatch {
+		t.Errorf("Expected $0.50 unflagged, got $%.2f (mismatch=%v)", cost, mismatch)
+	}
+
+	// 4. A missing or absurd reported cost falls back to our price
+	if cost, _ := chooseCost("test", "gpt-4o", 0, 0.10); cost != 0.10 {
+		t.Errorf("Expected $0.10 without a reported cost, got $%.2f", cost)
+	}
+	if cost, _ := chooseCost("test", "gpt-4o", 250, 0.10); cost != 0.10 {
+		t.Errorf("Expected $0.10 for an absurd reported cost, got $%.2f", cost)
+	}
+}
@@ -3,13 +3,17 @@ package parser
 
 import (
 	"database/sql"
+	"fmt"
 	"os"
 	"os/user"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/bangarangler/burnrate/internal/log"
 	"github.com/bangarangler/burnrate/internal/pricing"
+	"github.com/bangarangler/burnrate/internal/storage"
 	"github.com/bangarangler/burnrate/internal/tracker"
 	"github.com/fsnotify/fsnotify"
 	_ "github.com/mattn/go-sqlite3"
@@ -24,8 +28,8 @@ type CrushSession struct {
 	PromptTokens     int
 	CompletionTokens int
 	Cost             float64
-	CreatedAt        int64 // Unix timestamp in milliseconds
-	UpdatedAt        int64 // Unix timestamp in milliseconds
+	CreatedAt        int64 // Unix timestamp in seconds
+	UpdatedAt        int64 // Unix timestamp in seconds
 }
 
 // CrushMessage represents a message from Crush's SQLite database
@@ -35,12 +39,24 @@ type CrushMessage struct {
 	Role       string
 	Model      sql.NullString
 	Provider   sql.NullString
-	CreatedAt  int64 // Unix timestamp in milliseconds
+	CreatedAt  int64 // Unix timestamp in seconds
 	FinishedAt sql.NullInt64
 }
 
-// Track processed sessions to avoid duplicates
-var processedCrushSessions = make(map[string]int64) // sessionID -> last updated_at
+// Track processed sessions across every database to avoid duplicates
+var processedCrushSessions = newCrushSessionSet() // dbPath:sessionID -> totals last seen
+var processedCrushMu sync.Mutex                   // Protect the set
+
+// crushActiveWindow is how long after its last update a session is kept
+// through eviction. Sessions hold running totals, so forgetting one that
+// is still in use would count all of it again on its next update.
+const crushActiveWindow = time.Hour
+
+func newCrushSessionSet() *dedupSet[crushTotals] {
+	set := newDedupSet[crushTotals]()
+	set.keepFor = crushActiveWindow
+	return set
+}
 
 // Default database paths to check (project-relative first, then common locations)
 var defaultCrushDBPaths = []string{
@@ -50,8 +66,9 @@ var defaultCrushDBPaths = []string{
 	"~/Library/Application Support/crush/crush.db", // macOS standard (lowercase)
 }
 
-// StartCrushWatcher watches for updates to Crush SQLite databases
-func StartCrushWatcher(dbPath string) error {
+// CrushDBPath resolves the database to watch. An empty dbPath finds an
+// existing database, falling back to .crush/crush.db in the current directory.
+func CrushDBPath(dbPath string) string {
 	usr, _ := user.Current()
 
 	// Expand ~ in path
@@ -67,6 +84,12 @@ func StartCrushWatcher(dbPath string) error {
 			dbPath = ".crush/crush.db"
 		}
 	}
+	return dbPath
+}
+
+// StartCrushWatcher watches for updates to Crush SQLite databases
+func StartCrushWatcher(dbPath string) error {
+	dbPath = CrushDBPath(dbPath)
 
 	// Check if database exists
 	dbExists := false
@@ -82,6 +105,7 @@ func StartCrushWatcher(dbPath string) error {
 			Status:  "error",
 			Message: "Failed to create watcher",
 		})
+		log.Errorf("crush: failed to create watcher: %v", err)
 		return err
 	}
 
@@ -102,6 +126,8 @@ func StartCrushWatcher(dbPath string) error {
 			Tier:    tracker.TierFullTracking,
 			Status:  "not_found",
 			Message: ".crush/crush.db not found",
+			Path:    dbPath,
+			Hint:    "Start burnrate from a project using Crush, or pass --crush-db",
 		})
 	}
 
@@ -124,10 +150,11 @@ func StartCrushWatcher(dbPath string) error {
 						Message: "Watching database",
 					})
 				}
-			case _, ok := <-watcher.Errors:
+			case err, ok := <-watcher.Errors:
 				if !ok {
 					return
 				}
+				log.Warnf("crush: watcher error: %v", err)
 			}
 		}
 	}()
@@ -135,10 +162,12 @@ func StartCrushWatcher(dbPath string) error {
 	// Watch the database file's directory (fsnotify can't watch non-existent files)
 	dir := filepath.Dir(dbPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Warnf("crush: failed to create %s: %v", dir, err)
 		return err
 	}
 
 	if err := watcher.Add(dir); err != nil {
+		log.Warnf("crush: failed to watch %s: %v", dir, err)
 		return err
 	}
 
@@ -168,14 +197,15 @@ func findCrushDB() string {
 
 // processCrushDB reads and processes new/updated sessions from a Crush database
 func processCrushDB(dbPath string) {
-	db, err := sql.Open("sqlite3", dbPath+"?mode=ro")
+	db, err := openToolDB(dbPath)
 	if err != nil {
+		log.Warnf("crush: cannot open %s: %v", dbPath, err)
 		return
 	}
 	defer db.Close()
 
 	// Query sessions with token usage
-	rows, err := db.Query(`
+	rows, err := queryWithRetry(db, `
 		SELECT 
 			id, 
 			parent_session_id, 
@@ -190,7 +220,12 @@ func processCrushDB(dbPath string) {
 		WHERE prompt_tokens > 0 OR completion_tokens > 0
 		ORDER BY created_at ASC
 	`)
+	if isLocked(err) {
+		log.Warnf("crush: skipped reading %s: database stayed locked: %v", dbPath, err)
+		return
+	}
 	if err != nil {
+		log.Debugf("crush: cannot query sessions in %s: %v", dbPath, err)
 		return
 	}
 	defer rows.Close()
@@ -209,12 +244,22 @@ func processCrushDB(dbPath string) {
 			&session.UpdatedAt,
 		)
 		if err != nil {
+			log.Debugf("crush: skipping unreadable session row in %s: %v", dbPath, err)
 			continue
 		}
 
 		// Skip if already processed and not updated
-		lastUpdated, exists := processedCrushSessions[session.ID]
-		if exists && lastUpdated >= session.UpdatedAt {
+		key := crushSessionKey(dbPath, session.ID)
+		last, isNew := claimCrushSessionUpdate(key, session)
+		trace("Crush", key, !isNew, map[string]any{
+			"title":             session.Title,
+			"message_count":     session.MessageCount,
+			"prompt_tokens":     session.PromptTokens,
+			"completion_tokens": session.CompletionTokens,
+			"cost":              session.Cost,
+			"updated_at":        session.UpdatedAt,
+		})
+		if !isNew {
 			continue
 		}
 
@@ -224,37 +269,94 @@ func processCrushDB(dbPath string) {
 			model = "crush-unknown"
 		}
 
-		// Calculate incremental usage if we've seen this session before
-		var promptDelta, completionDelta int
-		var costDelta float64
-
-		if exists {
-			// This is an update - we need to get the difference
-			// For simplicity, we'll just add the total if it's the first time
-			// In a production system, we'd track previous values
-			promptDelta = session.PromptTokens
-			completionDelta = session.CompletionTokens
-			costDelta = session.Cost
-		} else {
-			promptDelta = session.PromptTokens
-			completionDelta = session.CompletionTokens
-			costDelta = session.Cost
+		// Sessions keep running totals, so only the growth since they were
+		// last seen is new usage. Totals that shrank (a rewritten session)
+		// record nothing.
+		promptDelta := session.PromptTokens - last.promptTokens
+		completionDelta := session.CompletionTokens - last.completionTokens
+		costDelta := pricing.AddUSD(session.Cost, -last.cost)
+		if promptDelta < 0 || completionDelta < 0 || promptDelta == 0 && completionDelta == 0 {
+			continue
 		}
 
 		// Use pre-calculated cost if available, otherwise calculate
 		if strings.Contains(model, ":free") {
 			costDelta = 0.0
-		} else if costDelta <= 0 && (promptDelta > 0 || completionDelta > 0) {
-			costDelta = pricing.CalculateCost(model, promptDelta, completionDelta)
+		} else if costDelta <= 0 {
+			costDelta = pricing.CalculateCost(model, promptDelta, completionDelta, 0)
 		}
 
-		if promptDelta > 0 || completionDelta > 0 {
-			tracker.Global.AddUsageWithTool("Crush", model, promptDelta, completionDelta, costDelta)
+		accepted := tracker.Global.AddToolUsage("Crush", tracker.Usage{
+			Model:            model,
+			PromptTokens:     promptDelta,
+			CompletionTokens: completionDelta,
+			TotalTokens:      promptDelta + completionDelta,
+			Cost:             costDelta,
+			Timestamp:        time.Unix(session.UpdatedAt, 0),
+			Project:          crushProject(dbPath),
+			EventKey:         fmt.Sprintf("%s@%d", key, session.UpdatedAt),
+		})
+		if accepted {
 			tracker.Global.IncrementToolEvents("Crush")
 		}
+	}
+}
+
+// crushSessionKey identifies a session across databases. Session IDs are only
+// unique within one database, and the same database can be reached by
+// different paths (relative from the watcher, absolute from a scan).
+func crushSessionKey(dbPath, sessionID string) string {
+	if abs, err := filepath.Abs(dbPath); err == nil {
+		dbPath = abs
+	}
+	return dbPath + ":" + sessionID
+}
 
-		processedCrushSessions[session.ID] = session.UpdatedAt
+// crushProject is the project a Crush database belongs to: Crush keeps it in
+// .crush inside the project directory
+func crushProject(dbPath string) string {
+	dir, err := filepath.Abs(filepath.Dir(dbPath))
+	if err != nil {
+		return ""
+	}
+	if filepath.Base(dir) == ".crush" {
+		dir = filepath.Dir(dir)
 	}
+	return ProjectKey(dir)
+}
+
+// crushTotals are a session's running totals as last processed
+type crushTotals struct {
+	updatedAt        int64
+	promptTokens     int
+	completionTokens int
+	cost             float64
+}
+
+// claimCrushSessionUpdate records that a session (see crushSessionKey) has
+// been processed up to its updated_at, returning the totals it had when last
+// processed (zero for a new session). isNew is false if that update was
+// already handled.
+func claimCrushSessionUpdate(key string, session CrushSession) (last crushTotals, isNew bool) {
+	processedCrushMu.Lock()
+	defer processedCrushMu.Unlock()
+
+	at := time.Unix(session.UpdatedAt, 0)
+	last, existed := processedCrushSessions.get(key)
+	if existed && last.updatedAt >= session.UpdatedAt {
+		return last, false
+	}
+	// A forgotten session that hasn't changed since was already handled
+	if !existed && processedCrushSessions.expired(at) {
+		return last, false
+	}
+	processedCrushSessions.put(key, at, crushTotals{
+		updatedAt:        session.UpdatedAt,
+		promptTokens:     session.PromptTokens,
+		completionTokens: session.CompletionTokens,
+		cost:             session.Cost,
+	})
+	return last, true
 }
 
 // getSessionPrimaryModel finds the most-used model in a session
@@ -270,6 +372,9 @@ func getSessionPrimaryModel(db *sql.DB, sessionID string) string {
 
 	var model, provider sql.NullString
 	if err := row.Scan(&model, &provider); err != nil {
+		if err != sql.ErrNoRows {
+			log.Debugf("crush: cannot read model for session %s: %v", sessionID, err)
+		}
 		return ""
 	}
 
@@ -304,6 +409,15 @@ func ParseCrushDBOnce(dbPath string) error {
 
 // ParseAllCrushDBs finds and parses all Crush databases on the system
 func ParseAllCrushDBs() error {
+	for _, dbPath := range FindCrushDBs() {
+		processCrushDB(dbPath)
+	}
+	return nil
+}
+
+// FindCrushDBs returns every project's Crush database under the current
+// directory and the usual code directories in home
+func FindCrushDBs() []string {
 	usr, _ := user.Current()
 
 	// Find all .crush directories with crush.db files
@@ -327,6 +441,7 @@ func ParseAllCrushDBs() error {
 	}
 
 	foundDBs := make(map[string]bool)
+	var dbPaths []string
 
 	for _, basePath := range searchPaths {
 		if _, err := os.Stat(basePath); err != nil {
@@ -335,7 +450,8 @@ func ParseAllCrushDBs() error {
 
 		filepath.Walk(basePath, func(path string, info os.FileInfo, err error) error {
 			if err != nil {
-				return nil // Skip errors
+				log.Debugf("crush: skipping %s: %v", path, err)
+				return nil
 			}
 
 			// Don't recurse too deep
@@ -353,7 +469,7 @@ func ParseAllCrushDBs() error {
 			if info.Name() == "crush.db" && strings.Contains(path, ".crush") {
 				if !foundDBs[path] {
 					foundDBs[path] = true
-					processCrushDB(path)
+					dbPaths = append(dbPaths, path)
 				}
 			}
 
@@ -361,7 +477,7 @@ func ParseAllCrushDBs() error {
 		})
 	}
 
-	return nil
+	return dbPaths
 }
 
 // GetCrushSessions returns all sessions from a Crush database
@@ -381,7 +497,7 @@ func GetCrushSessions(dbPath string) ([]CrushSession, error) {
 		return nil, nil
 	}
 
-	db, err := sql.Open("sqlite3", dbPath+"?mode=ro")
+	db, err := openToolDB(dbPath)
 	if err != nil {
 		return nil, err
 	}
@@ -421,6 +537,7 @@ func GetCrushSessions(dbPath string) ([]CrushSession, error) {
 			&session.UpdatedAt,
 		)
 		if err != nil {
+			log.Debugf("crush: skipping unreadable session row in %s: %v", dbPath, err)
 			continue
 		}
 		sessions = append(sessions, session)
@@ -429,102 +546,123 @@ func GetCrushSessions(dbPath string) ([]CrushSession, error) {
 	return sessions, nil
 }
 
-// GetCrushUsageByDate returns token usage aggregated by date
-func GetCrushUsageByDate(dbPath string, since time.Time) (map[string]struct {
+// CrushDailyUsage is one day's usage across Crush sessions
+type CrushDailyUsage struct {
 	PromptTokens     int
 	CompletionTokens int
 	Cost             float64
-}, error) {
-	usr, _ := user.Current()
+}
 
-	if strings.HasPrefix(dbPath, "~") {
-		dbPath = filepath.Join(usr.HomeDir, dbPath[1:])
+// CrushModelUsage is one model's usage across Crush sessions
+type CrushModelUsage struct {
+	PromptTokens     int
+	CompletionTokens int
+	Cost             float64
+	SessionCount     int
+}
+
+// crushDBPaths resolves the databases to aggregate. No paths means the
+// default database, found as GetCrushSessions finds it.
+func crushDBPaths(dbPaths []string) []string {
+	if len(dbPaths) == 0 {
+		dbPaths = []string{""}
 	}
 
-	if dbPath == "" {
-		dbPath = findCrushDB()
+	usr, _ := user.Current()
+	var resolved []string
+	for _, dbPath := range dbPaths {
+		if strings.HasPrefix(dbPath, "~") {
+			dbPath = filepath.Join(usr.HomeDir, dbPath[1:])
+		}
+		if dbPath == "" {
+			dbPath = findCrushDB()
+		}
+		if dbPath != "" {
+			resolved = append(resolved, dbPath)
+		}
 	}
+	return resolved
+}
 
-	if dbPath == "" {
+// GetCrushUsageByDate returns token usage since a time aggregated by date,
+// summed across the given databases
+func GetCrushUsageByDate(dbPaths []string, since time.Time) (map[string]CrushDailyUsage, error) {
+	resolved := crushDBPaths(dbPaths)
+	if len(resolved) == 0 {
 		return nil, nil
 	}
 
-	db, err := sql.Open("sqlite3", dbPath+"?mode=ro")
+	result := make(map[string]CrushDailyUsage)
+	for _, dbPath := range resolved {
+		if err := addCrushUsageByDate(result, dbPath, since); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// addCrushUsageByDate adds one database's usage by date to result
+func addCrushUsageByDate(result map[string]CrushDailyUsage, dbPath string, since time.Time) error {
+	db, err := openToolDB(dbPath)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer db.Close()
 
-	sinceMs := since.UnixMilli()
 	rows, err := db.Query(`
-		SELECT 
-			date(created_at/1000, 'unixepoch') as day,
-			SUM(prompt_tokens) as prompt_tokens,
-			SUM(completion_tokens) as completion_tokens,
-			SUM(cost) as cost
+		SELECT created_at, prompt_tokens, completion_tokens, cost
 		FROM sessions
 		WHERE created_at >= ?
-		GROUP BY day
-		ORDER BY day ASC
-	`, sinceMs)
+		ORDER BY created_at ASC
+	`, since.Unix())
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
-	result := make(map[string]struct {
-		PromptTokens     int
-		CompletionTokens int
-		Cost             float64
-	})
-
 	for rows.Next() {
-		var day string
+		var createdAt int64
 		var promptTokens, completionTokens int
 		var cost float64
 
-		if err := rows.Scan(&day, &promptTokens, &completionTokens, &cost); err != nil {
+		if err := rows.Scan(&createdAt, &promptTokens, &completionTokens, &cost); err != nil {
 			continue
 		}
 
-		result[day] = struct {
-			PromptTokens     int
-			CompletionTokens int
-			Cost             float64
-		}{
-			PromptTokens:     promptTokens,
-			CompletionTokens: completionTokens,
-			Cost:             cost,
-		}
+		// Bucket by day in the same timezone as burnrate's own history
+		day := storage.DayKey(time.Unix(createdAt, 0))
+		entry := result[day]
+		entry.PromptTokens += promptTokens
+		entry.CompletionTokens += completionTokens
+		entry.Cost += cost
+		result[day] = entry
 	}
 
-	return result, nil
+	return nil
 }
 
-// GetCrushUsageByModel returns token usage aggregated by model
-func GetCrushUsageByModel(dbPath string) (map[string]struct {
-	PromptTokens     int
-	CompletionTokens int
-	Cost             float64
-	SessionCount     int
-}, error) {
-	usr, _ := user.Current()
-
-	if strings.HasPrefix(dbPath, "~") {
-		dbPath = filepath.Join(usr.HomeDir, dbPath[1:])
-	}
-
-	if dbPath == "" {
-		dbPath = findCrushDB()
+// GetCrushUsageByModel returns token usage aggregated by model, summed across
+// the given databases
+func GetCrushUsageByModel(dbPaths []string) (map[string]CrushModelUsage, error) {
+	resolved := crushDBPaths(dbPaths)
+	if len(resolved) == 0 {
+		return nil, nil
 	}
 
-	if dbPath == "" {
-		return nil, nil
+	result := make(map[string]CrushModelUsage)
+	for _, dbPath := range resolved {
+		if err := addCrushUsageByModel(result, dbPath); err != nil {
+			return nil, err
+		}
 	}
+	return result, nil
+}
 
-	db, err := sql.Open("sqlite3", dbPath+"?mode=ro")
+// addCrushUsageByModel adds one database's usage by model to result
+func addCrushUsageByModel(result map[string]CrushModelUsage, dbPath string) error {
+	db, err := openToolDB(dbPath)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer db.Close()
 
@@ -548,17 +686,10 @@ func GetCrushUsageByModel(dbPath string) (map[string]struct {
 		ORDER BY cost DESC
 	`)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer rows.Close()
 
-	result := make(map[string]struct {
-		PromptTokens     int
-		CompletionTokens int
-		Cost             float64
-		SessionCount     int
-	})
-
 	for rows.Next() {
 		var model, provider string
 		var promptTokens, completionTokens, sessionCount int
@@ -573,18 +704,13 @@ func GetCrushUsageByModel(dbPath string) (map[string]struct {
 			key = model + " (" + provider + ")"
 		}
 
-		result[key] = struct {
-			PromptTokens     int
-			CompletionTokens int
-			Cost             float64
-			SessionCount     int
-		}{
-			PromptTokens:     promptTokens,
-			CompletionTokens: completionTokens,
-			Cost:             cost,
-			SessionCount:     sessionCount,
-		}
+		entry := result[key]
+		entry.PromptTokens += promptTokens
+		entry.CompletionTokens += completionTokens
+		entry.Cost += cost
+		entry.SessionCount += sessionCount
+		result[key] = entry
 	}
 
-	return result, nil
+	return nil
 }
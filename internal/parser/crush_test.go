@@ -0,0 +1,344 @@
+package parser
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bangarangler/burnrate/internal/storage"
+	"github.com/bangarangler/burnrate/internal/tracker"
+)
+
+// writeCrushFixture creates a Crush database with one session
+func writeCrushFixture(t *testing.T, dbPath string) {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("failed to open fixture: %v", err)
+	}
+	defer db.Close()
+
+	for _, stmt := range []string{
+		`CREATE TABLE sessions (id TEXT PRIMARY KEY, parent_session_id TEXT, title TEXT,
+			message_count INTEGER, prompt_tokens INTEGER, completion_tokens INTEGER,
+			cost REAL, created_at INTEGER, updated_at INTEGER)`,
+		`CREATE TABLE messages (id TEXT PRIMARY KEY, session_id TEXT, model TEXT, provider TEXT)`,
+		`INSERT INTO sessions VALUES ('s1', NULL, 'fix tests', 2, 1000, 200, 0.05, 1748772000, 1748772060)`,
+		`INSERT INTO messages VALUES ('m1', 's1', 'gpt-4o', 'openai')`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("failed to build fixture: %v", err)
+		}
+	}
+}
+
+// lockDB takes an exclusive lock on the database, like a writer mid-transaction.
+// The returned func releases it.
+func lockDB(t *testing.T, dbPath string) func() {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("failed to open database to lock: %v", err)
+	}
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Fatalf("failed to get connection: %v", err)
+	}
+	if _, err := conn.ExecContext(context.Background(), "BEGIN EXCLUSIVE"); err != nil {
+		t.Fatalf("failed to lock database: %v", err)
+	}
+	return func() {
+		conn.ExecContext(context.Background(), "ROLLBACK")
+		conn.Close()
+		db.Close()
+	}
+}
+
+func TestCrushRetriesWhileDatabaseIsLocked(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "crush.db")
+	writeCrushFixture(t, dbPath)
+
+	// Keep the test fast: barely wait inside SQLite, retry quickly
+	defer func(timeout, delay time.Duration, retries int) {
+		sqliteBusyTimeout, sqliteRetryDelay, sqliteRetries = timeout, delay, retries
+	}(sqliteBusyTimeout, sqliteRetryDelay, sqliteRetries)
+	sqliteBusyTimeout, sqliteRetryDelay = 10*time.Millisecond, 20*time.Millisecond
+
+	tracker.Global.Reset()
+	defer tracker.Global.Reset()
+	processedCrushSessions = newCrushSessionSet()
+
+	// 1. A lock that outlasts every retry skips the read without recording
+	sqliteRetries = 1
+	unlock := lockDB(t, dbPath)
+	processCrushDB(dbPath)
+	unlock()
+	if usages := tracker.Global.GetUsages(); len(usages) != 0 {
+		t.Fatalf("Expected no usages while locked, got %+v", usages)
+	}
+
+	// 2. A lock released between retries is waited out
+	sqliteRetries = 5
+	unlock = lockDB(t, dbPath)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		unlock()
+	}()
+	processCrushDB(dbPath)
+	usages := tracker.Global.GetUsages()
+	if len(usages) != 1 {
+		t.Fatalf("Expected 1 usage after the lock cleared, got %d", len(usages))
+	}
+	if usages[0].Model != "gpt-4o (openai)" || usages[0].PromptTokens != 1000 {
+		t.Errorf("Expected the gpt-4o session, got %+v", usages[0])
+	}
+	// Crush stores times in Unix seconds
+	if got := usages[0].Timestamp; !got.Equal(time.Unix(1748772060, 0)) {
+		t.Errorf("Expected the session's updated_at as its time, got %v", got)
+	}
+}
+
+func TestIsLocked(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "crush.db")
+	writeCrushFixture(t, dbPath)
+
+	defer func(timeout time.Duration) { sqliteBusyTimeout = timeout }(sqliteBusyTimeout)
+	sqliteBusyTimeout = 10 * time.Millisecond
+
+	unlock := lockDB(t, dbPath)
+	defer unlock()
+
+	db, err := openToolDB(dbPath)
+	if err != nil {
+		t.Fatalf("failed to open: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Query(`SELECT id FROM sessions`)
+	if !isLocked(err) {
+		t.Errorf("Expected a locked error, got %v", err)
+	}
+	if isLocked(sql.ErrNoRows) {
+		t.Error("Expected sql.ErrNoRows not to count as locked")
+	}
+}
+
+func TestCrushMergesDatabasesWithCollidingSessionIDs(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "a", "crush.db")
+	second := filepath.Join(dir, "b", "crush.db")
+	for _, dbPath := range []string{first, second} {
+		if err := os.MkdirAll(filepath.Dir(dbPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		// Both databases hold a session with the ID s1
+		writeCrushFixture(t, dbPath)
+	}
+
+	tracker.Global.Reset()
+	defer tracker.Global.Reset()
+	processedCrushSessions = newCrushSessionSet()
+
+	// 1. Each database's session is recorded once
+	processCrushDB(first)
+	processCrushDB(second)
+	processCrushDB(first)
+	if usages := tracker.Global.GetUsages(); len(usages) != 2 {
+		t.Fatalf("Expected both s1 sessions recorded once, got %+v", usages)
+	}
+
+	// 2. Usage by model sums across databases
+	byModel, err := GetCrushUsageByModel([]string{first, second})
+	if err != nil {
+		t.Fatalf("GetCrushUsageByModel failed: %v", err)
+	}
+	if got := byModel["gpt-4o (openai)"]; got.SessionCount != 2 || got.PromptTokens != 2000 {
+		t.Errorf("Expected 2 sessions and 2000 prompt tokens, got %+v", got)
+	}
+
+	// 3. So does usage by date
+	byDate, err := GetCrushUsageByDate([]string{first, second}, time.Unix(0, 0))
+	if err != nil {
+		t.Fatalf("GetCrushUsageByDate failed: %v", err)
+	}
+	if _, ok := byDate[storage.DayKey(time.Unix(1748772000, 0))]; !ok || len(byDate) != 1 {
+		t.Errorf("Expected one day, the sessions' created_at, got %+v", byDate)
+	}
+	if later, _ := GetCrushUsageByDate([]string{first}, time.Unix(1748772001, 0)); len(later) != 0 {
+		t.Errorf("Expected no usage since after the sessions, got %+v", later)
+	}
+	var cost float64
+	for _, day := range byDate {
+		cost += day.Cost
+	}
+	if diff := cost - 0.10; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("Expected $0.10 across both databases, got %f", cost)
+	}
+}
+
+func TestCrushCountsNewSessionsAfterDedupEviction(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "crush.db")
+	writeCrushFixture(t, dbPath)
+	addSession := func(id string, updatedAt int64) {
+		t.Helper()
+		db, err := sql.Open("sqlite3", dbPath)
+		if err != nil {
+			t.Fatalf("failed to open fixture: %v", err)
+		}
+		defer db.Close()
+		if _, err := db.Exec(`INSERT INTO sessions VALUES (?, NULL, 'more', 2, 500, 100, 0.02, ?, ?)`,
+			id, updatedAt-60, updatedAt); err != nil {
+			t.Fatalf("failed to add session: %v", err)
+		}
+		if _, err := db.Exec(`INSERT INTO messages VALUES (?, ?, 'gpt-4o', 'openai')`, "m-"+id, id); err != nil {
+			t.Fatalf("failed to add message: %v", err)
+		}
+	}
+
+	defer func(max int) { DedupMaxEntries = max }(DedupMaxEntries)
+	DedupMaxEntries = 1
+	tracker.Global.Reset()
+	defer tracker.Global.Reset()
+	processedCrushSessions = newCrushSessionSet()
+	processedCrushSessions.keepFor = 0 // Every session is idle
+
+	// 1. A second session pushes the set over the cap, evicting s1
+	processCrushDB(dbPath)
+	addSession("s2", 1748775600)
+	processCrushDB(dbPath)
+	if usages := tracker.Global.GetUsages(); len(usages) != 2 {
+		t.Fatalf("Expected 2 sessions recorded, got %d", len(usages))
+	}
+	if want := time.Unix(1748775600, 0); !processedCrushSessions.horizon.Equal(want) {
+		t.Errorf("Expected the horizon at the evicted session's time %v, got %v", want, processedCrushSessions.horizon)
+	}
+
+	// 2. Rereading doesn't count the forgotten sessions again
+	processCrushDB(dbPath)
+	if usages := tracker.Global.GetUsages(); len(usages) != 2 {
+		t.Errorf("Expected evicted sessions not to be recounted, got %d usages", len(usages))
+	}
+
+	// 3. A newer session is still counted
+	addSession("s3", 1748779200)
+	processCrushDB(dbPath)
+	if usages := tracker.Global.GetUsages(); len(usages) != 3 {
+		t.Errorf("Expected the newer session counted, got %d usages", len(usages))
+	}
+}
+
+func TestCrushKeepsActiveSessionsThroughEviction(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "crush.db")
+	writeCrushFixture(t, dbPath)
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("failed to open fixture: %v", err)
+	}
+	defer db.Close()
+
+	defer func(max int) { DedupMaxEntries = max }(DedupMaxEntries)
+	DedupMaxEntries = 1
+	tracker.Global.Reset()
+	defer tracker.Global.Reset()
+	processedCrushSessions = newCrushSessionSet()
+
+	// 1. A second session goes over the cap, but both were just updated
+	processCrushDB(dbPath)
+	if _, err := db.Exec(`INSERT INTO sessions VALUES ('s2', NULL, 'more', 2, 500, 100, 0.02, 1748775540, 1748775600)`); err != nil {
+		t.Fatalf("failed to add session: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO messages VALUES ('m-s2', 's2', 'gpt-4o', 'openai')`); err != nil {
+		t.Fatalf("failed to add message: %v", err)
+	}
+	processCrushDB(dbPath)
+	if n := processedCrushSessions.len(); n != 2 {
+		t.Fatalf("Expected both active sessions kept, got %d", n)
+	}
+
+	// 2. The first session's next update adds only its growth
+	if _, err := db.Exec(`UPDATE sessions SET prompt_tokens = 1200, completion_tokens = 250, cost = 0.06, updated_at = 1748779200 WHERE id = 's1'`); err != nil {
+		t.Fatalf("failed to update session: %v", err)
+	}
+	processCrushDB(dbPath)
+	usages := tracker.Global.GetUsages()
+	if len(usages) != 3 || usages[2].PromptTokens != 200 {
+		t.Errorf("Expected a third usage adding 200 prompt tokens, got %+v", usages)
+	}
+}
+
+func TestCrushRecordsOnlySessionGrowth(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "crush.db")
+	writeCrushFixture(t, dbPath)
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("failed to open fixture: %v", err)
+	}
+	defer db.Close()
+	update := func(prompt, completion int, cost float64, updatedAt int64) {
+		t.Helper()
+		if _, err := db.Exec(`UPDATE sessions SET prompt_tokens = ?, completion_tokens = ?, cost = ?, updated_at = ? WHERE id = 's1'`,
+			prompt, completion, cost, updatedAt); err != nil {
+			t.Fatalf("failed to update session: %v", err)
+		}
+	}
+
+	tracker.Global.Reset()
+	defer tracker.Global.Reset()
+	processedCrushSessions = newCrushSessionSet()
+
+	// 1. The session is first recorded in full
+	processCrushDB(dbPath)
+
+	// 2. Two updates record only what each added
+	update(1500, 300, 0.08, 1748772120)
+	processCrushDB(dbPath)
+	update(1800, 350, 0.09, 1748772180)
+	processCrushDB(dbPath)
+
+	// 3. An update without new tokens records nothing
+	update(1800, 350, 0.09, 1748772240)
+	processCrushDB(dbPath)
+
+	usages := tracker.Global.GetUsages()
+	if len(usages) != 3 {
+		t.Fatalf("Expected 3 usages, got %d: %+v", len(usages), usages)
+	}
+	for i, want := range []int{1000, 500, 300} {
+		if usages[i].PromptTokens != want {
+			t.Errorf("Expected usage %d to add %d prompt tokens, got %d", i, want, usages[i].PromptTokens)
+		}
+	}
+	if got := usages[2].CompletionTokens; got != 50 {
+		t.Errorf("Expected the last update to add 50 completion tokens, got %d", got)
+	}
+	if got := tracker.Global.GetSessionCost(); got < 0.0899 || got > 0.0901 {
+		t.Errorf("Expected the total cost to match the session's 0.09, got %v", got)
+	}
+}
+
+func TestCrushRejectedSessionIsNotCountedAsEvent(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "crush.db")
+	writeCrushFixture(t, dbPath)
+
+	tracker.Global.Reset()
+	defer tracker.Global.Reset()
+	defer tracker.Global.SetMaxEventCost(0)
+	processedCrushSessions = newCrushSessionSet()
+	tracker.Global.SetToolStatus(tracker.ToolStatus{Name: "Crush", Tier: tracker.TierFullTracking, Status: "active"})
+
+	// The fixture's $0.05 session is over the ceiling
+	tracker.Global.SetMaxEventCost(0.01)
+	processCrushDB(dbPath)
+
+	status := tracker.Global.GetToolStatus("Crush")
+	if status.RejectedEvents != 1 || status.EventCount != 0 {
+		t.Errorf("Expected 1 rejected and no counted events, got %d and %d", status.RejectedEvents, status.EventCount)
+	}
+}
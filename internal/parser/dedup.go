@@ -0,0 +1,118 @@
+// internal/parser/dedup.go
+package parser
+
+import (
+	"sort"
+	"time"
+)
+
+// DedupMaxEntries caps how many keys each parser remembers for deduplication
+// (0 = unbounded). Past the cap, keys for the oldest events are forgotten.
+var DedupMaxEntries = 100000
+
+// DedupMaxAge forgets keys recorded longer ago than this (0 = only the cap
+// applies)
+var DedupMaxAge time.Duration
+
+// dedupSweepInterval is how often keys are checked against DedupMaxAge
+const dedupSweepInterval = time.Minute
+
+// dedupSet remembers which events a parser has recorded, bounded by
+// DedupMaxEntries and DedupMaxAge so a long-running daemon doesn't grow
+// without limit. Parsers reread whole files and databases, so a forgotten
+// key's event would otherwise be counted again: the set keeps a horizon, the
+// newest time among forgotten events, and treats anything at or before it as
+// already seen. That only drops an event that first appears after newer
+// ones were forgotten, such as an old log discovered days into a run.
+// Callers hold their own lock.
+type dedupSet[V any] struct {
+	entries   map[string]dedupEntry[V]
+	horizon   time.Time
+	lastSweep time.Time
+
+	// keepFor spares keys recorded this recently from eviction, for values
+	// that are still being updated (0 = any key can be evicted). The set
+	// can go over DedupMaxEntries while that many are in use.
+	keepFor time.Duration
+}
+
+type dedupEntry[V any] struct {
+	at    time.Time // When the event happened
+	added time.Time // When it was recorded
+	value V
+}
+
+func newDedupSet[V any]() *dedupSet[V] {
+	return &dedupSet[V]{entries: make(map[string]dedupEntry[V])}
+}
+
+// get returns the value stored for key, if it's still remembered
+func (s *dedupSet[V]) get(key string) (V, bool) {
+	entry, ok := s.entries[key]
+	return entry.value, ok
+}
+
+// seen reports whether an event has been recorded, or happened early enough
+// that it would have been forgotten
+func (s *dedupSet[V]) seen(key string, at time.Time) bool {
+	if _, ok := s.entries[key]; ok {
+		return true
+	}
+	return s.expired(at)
+}
+
+// expired reports whether at is at or before the horizon
+func (s *dedupSet[V]) expired(at time.Time) bool {
+	return !s.horizon.IsZero() && !at.IsZero() && !at.After(s.horizon)
+}
+
+// put remembers key for an event that happened at, then evicts if needed
+func (s *dedupSet[V]) put(key string, at time.Time, value V) {
+	now := time.Now()
+	s.entries[key] = dedupEntry[V]{at: at, added: now, value: value}
+
+	if DedupMaxAge > 0 && now.Sub(s.lastSweep) >= dedupSweepInterval {
+		s.lastSweep = now
+		cutoff := now.Add(-DedupMaxAge)
+		for k, entry := range s.entries {
+			if entry.added.Before(cutoff) && s.idle(entry, now) {
+				s.forget(k, entry)
+			}
+		}
+	}
+
+	if DedupMaxEntries > 0 && len(s.entries) > DedupMaxEntries {
+		// Trim to 90% of the cap so the sort isn't repeated on every insert
+		keys := make([]string, 0, len(s.entries))
+		for k, entry := range s.entries {
+			if s.idle(entry, now) {
+				keys = append(keys, k)
+			}
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return s.entries[keys[i]].at.Before(s.entries[keys[j]].at)
+		})
+		excess := min(len(s.entries)-DedupMaxEntries*9/10, len(keys))
+		for _, k := range keys[:excess] {
+			s.forget(k, s.entries[k])
+		}
+	}
+}
+
+// idle reports whether an entry was recorded long enough ago to evict
+func (s *dedupSet[V]) idle(entry dedupEntry[V], now time.Time) bool {
+	return now.Sub(entry.added) >= s.keepFor
+}
+
+// forget drops a key, moving the horizon up to its event
+func (s *dedupSet[V]) forget(key string, entry dedupEntry[V]) {
+	delete(s.entries, key)
+	if entry.at.After(s.horizon) {
+		s.horizon = entry.at
+	}
+}
+
+// len returns how many keys are remembered
+func (s *dedupSet[V]) len() int {
+	return len(s.entries)
+}
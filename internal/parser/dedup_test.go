@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestDedupSetStaysUnderTheCap(t *testing.T) {
+	defer func(max int) { DedupMaxEntries = max }(DedupMaxEntries)
+	DedupMaxEntries = 100
+
+	set := newDedupSet[struct{}]()
+	start := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	at := func(i int) time.Time { return start.Add(time.Duration(i) * time.Second) }
+
+	// 1. Many more keys than the cap keeps the set under it
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("event-%d", i)
+		if set.seen(key, at(i)) {
+			t.Fatalf("Expected %s to be new", key)
+		}
+		set.put(key, at(i), struct{}{})
+		if set.len() > DedupMaxEntries {
+			t.Fatalf("Expected at most %d keys, got %d after %s", DedupMaxEntries, set.len(), key)
+		}
+	}
+
+	// 2. Recent events are still deduped
+	if !set.seen("event-999", at(999)) {
+		t.Error("Expected the most recent event to be remembered")
+	}
+
+	// 3. Forgotten events are too old to be counted again
+	if !set.seen("event-0", at(0)) {
+		t.Error("Expected an evicted event to still count as seen")
+	}
+
+	// 4. A new event after the evicted ones is recorded
+	if set.seen("event-1000", at(1000)) {
+		t.Error("Expected a newer event to be new")
+	}
+}
+
+func TestDedupSetForgetsOldKeys(t *testing.T) {
+	defer func(max int, age time.Duration) {
+		DedupMaxEntries, DedupMaxAge = max, age
+	}(DedupMaxEntries, DedupMaxAge)
+	DedupMaxEntries, DedupMaxAge = 0, time.Hour
+
+	set := newDedupSet[int64]()
+	old := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	set.put("old", old, 1)
+	set.entries["old"] = dedupEntry[int64]{at: old, added: time.Now().Add(-2 * time.Hour), value: 1}
+	set.lastSweep = time.Time{}
+
+	// Recording the next key sweeps out the one recorded two hours ago
+	set.put("new", old.Add(time.Minute), 2)
+	if _, ok := set.get("old"); ok {
+		t.Error("Expected the old key to be forgotten")
+	}
+	if value, ok := set.get("new"); !ok || value != 2 {
+		t.Errorf("Expected the new key with value 2, got %d, %v", value, ok)
+	}
+	if !set.seen("old", old) {
+		t.Error("Expected the forgotten event to still count as seen")
+	}
+}
@@ -0,0 +1,254 @@
+// internal/parser/gemini.go
+package parser
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bangarangler/burnrate/internal/log"
+	"github.com/bangarangler/burnrate/internal/pricing"
+	"github.com/bangarangler/burnrate/internal/tracker"
+	"github.com/fsnotify/fsnotify"
+)
+
+// GeminiSession is a Gemini CLI chat recording. The CLI rewrites one file per
+// session as it goes:
+// ~/.gemini/tmp/<project hash>/chats/session-<time>-<id>.json
+type GeminiSession struct {
+	SessionID string          `json:"sessionId"`
+	Messages  []GeminiMessage `json:"messages"`
+}
+
+// GeminiMessage is one message of a session. Only the model's replies
+// ("gemini") carry a model and token usage.
+type GeminiMessage struct {
+	ID        string        `json:"id"`
+	Timestamp string        `json:"timestamp"` // ISO 8601
+	Type      string        `json:"type"`      // "user", "gemini", "info", ...
+	Model     string        `json:"model"`
+	Tokens    *GeminiTokens `json:"tokens"`
+}
+
+// GeminiTokens is a reply's usage as the Gemini API reports it
+type GeminiTokens struct {
+	Input    int `json:"input"`    // Prompt tokens, cached ones included
+	Output   int `json:"output"`   // Candidate tokens
+	Cached   int `json:"cached"`   // Portion of Input served from cache
+	Thoughts int `json:"thoughts"` // Thinking tokens
+	Tool     int `json:"tool"`     // Tool-use prompt tokens
+	Total    int `json:"total"`
+}
+
+// Track processed messages to avoid duplicates, since whole sessions are
+// rewritten on every message
+var processedGeminiMessages = newDedupSet[struct{}]() // sessionID/messageID
+var processedGeminiMu sync.Mutex                      // Held while a session is read
+
+// GeminiDataDir returns the Gemini CLI's data directory
+func GeminiDataDir() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".gemini")
+}
+
+// isGeminiSessionFile reports whether path is a chat recording
+func isGeminiSessionFile(path string) bool {
+	base := filepath.Base(path)
+	return strings.HasPrefix(base, "session-") && strings.HasSuffix(base, ".json") &&
+		filepath.Base(filepath.Dir(path)) == "chats"
+}
+
+// StartGeminiWatcher watches the Gemini CLI's chat recordings for new usage
+func StartGeminiWatcher() error {
+	baseDir := GeminiDataDir()
+	tmpDir := filepath.Join(baseDir, "tmp")
+
+	if _, err := os.Stat(baseDir); os.IsNotExist(err) {
+		tracker.Global.SetToolStatus(tracker.ToolStatus{
+			Name:    "Gemini CLI",
+			Tier:    tracker.TierFullTracking,
+			Status:  "not_found",
+			Message: "~/.gemini directory not found",
+			Path:    baseDir,
+			Hint:    "Run the Gemini CLI once",
+		})
+		log.Debugf("gemini: %s not found", baseDir)
+		startWhenFound("gemini", func() bool {
+			_, err := os.Stat(GeminiDataDir())
+			return err == nil
+		}, StartGeminiWatcher)
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		tracker.Global.SetToolStatus(tracker.ToolStatus{
+			Name:    "Gemini CLI",
+			Tier:    tracker.TierFullTracking,
+			Status:  "error",
+			Message: "Failed to create watcher",
+		})
+		log.Errorf("gemini: failed to create watcher: %v", err)
+		return err
+	}
+
+	// Process existing sessions first
+	processExistingGeminiSessions(tmpDir)
+
+	tracker.Global.SetToolStatus(tracker.ToolStatus{
+		Name:    "Gemini CLI",
+		Tier:    tracker.TierFullTracking,
+		Status:  "active",
+		Message: "Watching chat recordings",
+	})
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+					continue
+				}
+				if isGeminiSessionFile(event.Name) {
+					processGeminiSessionFile(event.Name)
+					continue
+				}
+				// New project and chats directories appear as they're used
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					watcher.Add(event.Name)
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Warnf("gemini: watcher error: %v", err)
+			}
+		}
+	}()
+
+	// Create tmp so projects started later are seen (the CLI would create it)
+	if err := os.MkdirAll(tmpDir, 0755); err != nil {
+		log.Warnf("gemini: failed to create %s: %v", tmpDir, err)
+		return err
+	}
+	filepath.Walk(tmpDir, func(path string, info os.FileInfo, _ error) error {
+		if info != nil && info.IsDir() {
+			watcher.Add(path)
+		}
+		return nil
+	})
+
+	return nil
+}
+
+// processExistingGeminiSessions reads every chat recording under tmpDir
+func processExistingGeminiSessions(tmpDir string) {
+	filepath.Walk(tmpDir, func(path string, info os.FileInfo, _ error) error {
+		if info != nil && !info.IsDir() && isGeminiSessionFile(path) {
+			processGeminiSessionFile(path)
+		}
+		return nil
+	})
+}
+
+// processGeminiSessionFile records the replies in a session that haven't
+// been recorded yet
+func processGeminiSessionFile(filename string) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		log.Debugf("gemini: cannot read %s: %v", filename, err)
+		return
+	}
+
+	var session GeminiSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		// Most likely caught mid-write; the next write event rereads it
+		log.Debugf("gemini: cannot parse %s: %v", filename, err)
+		return
+	}
+	if session.SessionID == "" {
+		session.SessionID = filename
+	}
+
+	processedGeminiMu.Lock()
+	defer processedGeminiMu.Unlock()
+
+	for _, msg := range session.Messages {
+		if msg.Type != "gemini" || msg.Tokens == nil {
+			continue
+		}
+		key := session.SessionID + "/" + msg.ID
+		ts, err := time.Parse(time.RFC3339, msg.Timestamp)
+		if err != nil {
+			ts = time.Now()
+		}
+		deduped := processedGeminiMessages.seen(key, ts)
+		trace("Gemini CLI", key, deduped, map[string]any{
+			"timestamp":       msg.Timestamp,
+			"model":           msg.Model,
+			"tokens.input":    msg.Tokens.Input,
+			"tokens.output":   msg.Tokens.Output,
+			"tokens.cached":   msg.Tokens.Cached,
+			"tokens.thoughts": msg.Tokens.Thoughts,
+			"tokens.tool":     msg.Tokens.Tool,
+		})
+		if deduped {
+			continue
+		}
+		processedGeminiMessages.put(key, ts, struct{}{})
+		recordGeminiMessage(key, msg, ts)
+	}
+}
+
+// recordGeminiMessage records one reply's usage under its session/message key
+func recordGeminiMessage(key string, msg GeminiMessage, ts time.Time) {
+	tokens := msg.Tokens
+	if tokens.Input == 0 && tokens.Output == 0 && tokens.Thoughts == 0 {
+		return
+	}
+
+	model := msg.Model
+	if model == "" {
+		model = "gemini-unknown"
+	}
+
+	// Cached tokens are part of Input; tool-use prompts are billed as input
+	// and thinking as reasoning
+	uncached := tokens.Input - tokens.Cached + tokens.Tool
+	prompt := tokens.Input + tokens.Tool
+	completion := tokens.Output + tokens.Thoughts
+
+	accepted := tracker.Global.AddToolUsage("Gemini CLI", tracker.Usage{
+		Model:            model,
+		PromptTokens:     prompt,
+		CompletionTokens: completion,
+		TotalTokens:      prompt + completion,
+		CacheReadTokens:  tokens.Cached,
+		ReasoningTokens:  tokens.Thoughts,
+		Cost:             pricing.CalculateCostWithCache(model, uncached, tokens.Cached, tokens.Output, tokens.Thoughts),
+		CacheSavings:     pricing.CalculateCacheSavings(model, tokens.Cached),
+		Timestamp:        ts,
+		EventKey:         key,
+	})
+	if accepted {
+		tracker.Global.IncrementToolEvents("Gemini CLI")
+	}
+}
+
+// ParseGeminiSessionsOnce does a one-time read of the Gemini CLI's chat
+// recordings. Useful for backfilling history without starting a watcher
+func ParseGeminiSessionsOnce() error {
+	tmpDir := filepath.Join(GeminiDataDir(), "tmp")
+	if _, err := os.Stat(tmpDir); os.IsNotExist(err) {
+		return nil // No sessions, not an error
+	}
+
+	processExistingGeminiSessions(tmpDir)
+	return nil
+}
@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bangarangler/burnrate/internal/pricing"
+	"github.com/bangarangler/burnrate/internal/tracker"
+)
+
+func TestGeminiSessionRecordsReplies(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	dir := filepath.Join(home, ".gemini", "tmp", "3f2a9c", "chats")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("failed to create chats dir: %v", err)
+	}
+
+	// A user prompt, then a reply that used the cache, thinking and a tool
+	session := `{
+  "sessionId": "b1c2",
+  "projectHash": "3f2a9c",
+  "messages": [
+    {"id": "m1", "timestamp": "2025-07-01T09:00:00.000Z", "type": "user", "content": "fix the build"},
+    {"id": "m2", "timestamp": "2025-07-01T09:00:07.000Z", "type": "gemini", "content": "done", "model": "gemini-2.5-pro",
+     "tokens": {"input": 12000, "output": 800, "cached": 8000, "thoughts": 300, "tool": 50, "total": 13150}}
+  ]
+}`
+	path := filepath.Join(dir, "session-2025-07-01T09-00-b1c2.json")
+	if err := os.WriteFile(path, []byte(session), 0644); err != nil {
+		t.Fatalf("failed to write session: %v", err)
+	}
+
+	tracker.Global.Reset()
+	defer tracker.Global.Reset()
+	processedGeminiMessages = newDedupSet[struct{}]()
+
+	// 1. Only the reply is recorded, with cached tokens split out
+	ParseGeminiSessionsOnce()
+	usages := tracker.Global.GetUsages()
+	if len(usages) != 1 {
+		t.Fatalf("Expected 1 usage, got %d", len(usages))
+	}
+	u := usages[0]
+	if u.Model != "gemini-2.5-pro" || u.PromptTokens != 12050 || u.CompletionTokens != 1100 || u.CacheReadTokens != 8000 {
+		t.Errorf("Unexpected usage: %+v", u)
+	}
+	if want := pricing.CalculateCostWithCache("gemini-2.5-pro", 4050, 8000, 800, 300); math.Abs(u.Cost-want) > 1e-9 {
+		t.Errorf("Expected cost %f, got %f", want, u.Cost)
+	}
+	if !u.Timestamp.Equal(time.Date(2025, 7, 1, 9, 0, 7, 0, time.UTC)) {
+		t.Errorf("Expected the reply's timestamp, got %v", u.Timestamp)
+	}
+
+	// 2. The CLI rewrites the whole session; only the new reply is added
+	session = session[:len(session)-4] + `,
+    {"id": "m3", "timestamp": "2025-07-01T09:01:00.000Z", "type": "gemini", "model": "gemini-2.5-flash",
+     "tokens": {"input": 1000, "output": 100, "cached": 0, "thoughts": 0, "tool": 0, "total": 1100}}
+  ]
+}`
+	if err := os.WriteFile(path, []byte(session), 0644); err != nil {
+		t.Fatalf("failed to rewrite session: %v", err)
+	}
+	processGeminiSessionFile(path)
+	if got := len(tracker.Global.GetUsages()); got != 2 {
+		t.Errorf("Expected 2 usages after the rewrite, got %d", got)
+	}
+}
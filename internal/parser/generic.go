@@ -0,0 +1,308 @@
+// internal/parser/generic.go
+package parser
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bangarangler/burnrate/internal/log"
+	"github.com/bangarangler/burnrate/internal/pricing"
+	"github.com/bangarangler/burnrate/internal/tracker"
+	"github.com/fsnotify/fsnotify"
+)
+
+// GenericConfig describes a user-defined JSONL log: where it is, and the
+// dotted JSON path (e.g. "usage.input_tokens") to each field of an event.
+// Array elements are addressed by index ("choices.0.model").
+type GenericConfig struct {
+	Name             string // Tool name shown in the dashboard
+	Glob             string // Log files, e.g. "~/.mytool/logs/*.jsonl"
+	Model            string
+	PromptTokens     string
+	CompletionTokens string
+	Cost             string // Optional; events are priced from tokens when absent
+	Timestamp        string // Optional; Unix seconds or milliseconds, or RFC3339
+}
+
+// Validate checks that the config names a tool, a glob, and enough fields
+// to record usage
+func (c GenericConfig) Validate() error {
+	switch {
+	case c.Name == "":
+		return fmt.Errorf("parser is missing name")
+	case c.Glob == "":
+		return fmt.Errorf("parser %q is missing glob", c.Name)
+	case c.Model == "":
+		return fmt.Errorf("parser %q is missing model", c.Name)
+	case c.PromptTokens == "" && c.CompletionTokens == "" && c.Cost == "":
+		return fmt.Errorf("parser %q needs prompt_tokens, completion_tokens, or cost", c.Name)
+	}
+	if _, err := filepath.Match(c.Glob, ""); err != nil {
+		return fmt.Errorf("parser %q has an invalid glob: %w", c.Name, err)
+	}
+	return nil
+}
+
+// Track how far each file has been read to avoid duplicates
+var processedGenericOffsets = make(map[string]int64) // filename -> offset after the last complete line
+var processedGenericMu sync.Mutex                    // Held while a file is read
+
+// expandGlob expands a leading ~ in a glob pattern
+func expandGlob(pattern string) string {
+	if strings.HasPrefix(pattern, "~") {
+		usr, _ := user.Current()
+		return filepath.Join(usr.HomeDir, pattern[1:])
+	}
+	return pattern
+}
+
+// StartGenericWatcher watches the files matching a user-defined parser's glob
+func StartGenericWatcher(cfg GenericConfig) error {
+	if err := cfg.Validate(); err != nil {
+		log.Warnf("generic: %v", err)
+		return err
+	}
+	pattern := expandGlob(cfg.Glob)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		tracker.Global.SetToolStatus(tracker.ToolStatus{
+			Name:    cfg.Name,
+			Tier:    tracker.TierFullTracking,
+			Status:  "error",
+			Message: "Failed to create watcher",
+		})
+		log.Errorf("generic: %s: failed to create watcher: %v", cfg.Name, err)
+		return err
+	}
+
+	// Process existing files first
+	matches, _ := filepath.Glob(pattern)
+	for _, path := range matches {
+		processGenericFile(cfg, path)
+	}
+
+	// Set initial status
+	if len(matches) > 0 {
+		tracker.Global.SetToolStatus(tracker.ToolStatus{
+			Name:    cfg.Name,
+			Tier:    tracker.TierFullTracking,
+			Status:  "active",
+			Message: fmt.Sprintf("Watching %d files", len(matches)),
+		})
+	} else {
+		tracker.Global.SetToolStatus(tracker.ToolStatus{
+			Name:    cfg.Name,
+			Tier:    tracker.TierFullTracking,
+			Status:  "not_found",
+			Message: "No files match " + cfg.Glob,
+			Path:    cfg.Glob,
+			Hint:    "Check its glob in " + DefaultGenericConfigPath(),
+		})
+	}
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+					continue
+				}
+				if matched, _ := filepath.Match(pattern, event.Name); matched {
+					processGenericFile(cfg, event.Name)
+					tracker.Global.SetToolStatus(tracker.ToolStatus{
+						Name:    cfg.Name,
+						Tier:    tracker.TierFullTracking,
+						Status:  "active",
+						Message: "Watching " + cfg.Glob,
+					})
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Warnf("generic: %s: watcher error: %v", cfg.Name, err)
+			}
+		}
+	}()
+
+	// Watch the directories that can hold matching files. A wildcard in the
+	// directory part is resolved now; directories created later aren't seen.
+	dirs, _ := filepath.Glob(filepath.Dir(pattern))
+	for _, dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			log.Warnf("generic: %s: failed to watch %s: %v", cfg.Name, dir, err)
+		}
+	}
+
+	return nil
+}
+
+// processGenericFile records events from lines appended since the last read.
+// A trailing line without a newline is left for the next write to finish.
+func processGenericFile(cfg GenericConfig, filename string) {
+	processedGenericMu.Lock()
+	defer processedGenericMu.Unlock()
+
+	file, err := os.Open(filename)
+	if err != nil {
+		log.Debugf("generic: %s: cannot open %s: %v", cfg.Name, filename, err)
+		return
+	}
+	defer file.Close()
+
+	offset := processedGenericOffsets[filename]
+	if stat, err := file.Stat(); err == nil && stat.Size() < offset {
+		// Truncated or replaced: start over
+		offset = 0
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		log.Debugf("generic: %s: cannot seek %s: %v", cfg.Name, filename, err)
+		return
+	}
+
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			// EOF, possibly mid-line: keep the offset at the last full line
+			if err != io.EOF {
+				log.Warnf("generic: %s: stopped reading %s: %v", cfg.Name, filename, err)
+			}
+			break
+		}
+		offset += int64(len(line))
+
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+		usage, ok := parseGenericLine(cfg, line)
+		if !ok {
+			continue
+		}
+		if tracker.Global.AddToolUsage(cfg.Name, usage) {
+			tracker.Global.IncrementToolEvents(cfg.Name)
+		}
+	}
+
+	processedGenericOffsets[filename] = offset
+}
+
+// parseGenericLine extracts a usage from one JSON line. Lines without a
+// model or any usage are skipped, so logs can mix in other event types.
+func parseGenericLine(cfg GenericConfig, line []byte) (tracker.Usage, bool) {
+	var event any
+	if err := json.Unmarshal(line, &event); err != nil {
+		log.Debugf("generic: %s: skipping malformed line: %v", cfg.Name, err)
+		return tracker.Usage{}, false
+	}
+
+	model, _ := lookupJSONPath(event, cfg.Model).(string)
+	if model == "" {
+		return tracker.Usage{}, false
+	}
+
+	prompt, _ := jsonNumber(lookupJSONPath(event, cfg.PromptTokens))
+	completion, _ := jsonNumber(lookupJSONPath(event, cfg.CompletionTokens))
+	cost, hasCost := jsonNumber(lookupJSONPath(event, cfg.Cost))
+	if prompt == 0 && completion == 0 && cost == 0 {
+		return tracker.Usage{}, false
+	}
+
+	// Use the logged cost if present, otherwise calculate
+	if !hasCost {
+		cost = pricing.CalculateCost(model, int(prompt), int(completion), 0)
+	}
+
+	// Without a timestamp field, events are stamped as they're read
+	ts, ok := jsonTime(lookupJSONPath(event, cfg.Timestamp))
+	if !ok {
+		ts = time.Now()
+	}
+
+	return tracker.Usage{
+		Model:            model,
+		PromptTokens:     int(prompt),
+		CompletionTokens: int(completion),
+		TotalTokens:      int(prompt + completion),
+		Cost:             cost,
+		Timestamp:        ts,
+	}, true
+}
+
+// lookupJSONPath follows a dotted path through decoded JSON, indexing arrays
+// by number. Returns nil if the path is empty or doesn't exist.
+func lookupJSONPath(value any, path string) any {
+	if path == "" {
+		return nil
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]any:
+			value = v[key]
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil
+			}
+			value = v[i]
+		default:
+			return nil
+		}
+	}
+	return value
+}
+
+// jsonNumber converts a JSON number, or a string holding one, to float64
+func jsonNumber(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// jsonTime converts Unix seconds, Unix milliseconds, or an RFC3339 string
+func jsonTime(value any) (time.Time, bool) {
+	if s, ok := value.(string); ok {
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			return t, true
+		}
+	}
+	n, ok := jsonNumber(value)
+	if !ok || n <= 0 {
+		return time.Time{}, false
+	}
+	if n >= 1e12 {
+		return time.UnixMilli(int64(n)), true
+	}
+	return time.Unix(int64(n), 0), true
+}
+
+// ParseGenericOnce does a one-time read of every file matching a parser's glob
+// Useful for backfilling history without starting a watcher
+func ParseGenericOnce(cfg GenericConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	matches, _ := filepath.Glob(expandGlob(cfg.Glob))
+	for _, path := range matches {
+		processGenericFile(cfg, path)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/bangarangler/burnrate/internal/config"
+)
+
+// DefaultGenericConfigPath returns ~/.burnrate/parsers.toml
+func DefaultGenericConfigPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".burnrate", "parsers.toml")
+}
+
+// LoadGenericConfigs reads user-defined parsers from a TOML file with one
+// [[parser]] table per log:
+//
+//	[[parser]]
+//	name = "MyTool"
+//	glob = "~/.mytool/logs/*.jsonl"
+//	model = "request.model"
+//	prompt_tokens = "usage.input"
+//	completion_tokens = "usage.output"
+//	cost = "usage.cost_usd"   # optional
+//	timestamp = "ts"          # optional
+//
+// A missing file means no generic parsers and is not an error.
+func LoadGenericConfigs(path string) ([]GenericConfig, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return parseGenericTOML(f, path)
+}
+
+// parseGenericTOML parses parsers.toml: [[parser]] tables of string keys.
+// name prefixes errors, as in "parsers.toml:3: ...".
+func parseGenericTOML(r io.Reader, name string) ([]GenericConfig, error) {
+	entries, err := config.ReadTOML(r, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var configs []GenericConfig
+	var current *GenericConfig
+	for _, e := range entries {
+		if e.Key == "" {
+			if e.Table != "parser" || !e.ArrayTable {
+				return nil, fmt.Errorf("%s:%d: unknown table %s (expected [[parser]])", name, e.Line, e.Table)
+			}
+			configs = append(configs, GenericConfig{})
+			current = &configs[len(configs)-1]
+			continue
+		}
+
+		if current == nil {
+			return nil, fmt.Errorf("%s:%d: %s is outside a [[parser]] table", name, e.Line, e.Key)
+		}
+		if !e.Quoted {
+			return nil, fmt.Errorf("%s:%d: %s: expected a quoted string, got %s", name, e.Line, e.Key, e.Raw)
+		}
+
+		switch e.Key {
+		case "name":
+			current.Name = e.Value
+		case "glob":
+			current.Glob = e.Value
+		case "model":
+			current.Model = e.Value
+		case "prompt_tokens":
+			current.PromptTokens = e.Value
+		case "completion_tokens":
+			current.CompletionTokens = e.Value
+		case "cost":
+			current.Cost = e.Value
+		case "timestamp":
+			current.Timestamp = e.Value
+		default:
+			return nil, fmt.Errorf("%s:%d: unknown key %q", name, e.Line, e.Key)
+		}
+	}
+
+	for _, cfg := range configs {
+		if err := cfg.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return configs, nil
+}
@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bangarangler/burnrate/internal/tracker"
+)
+
+func TestGenericParserReadsCustomShape(t *testing.T) {
+	config := `
+# A tool that nests usage under "resp"
+[[parser]]
+name = "MyTool"
+glob = "` + filepath.Join(t.TempDir(), "*.jsonl") + `"
+model = "req.model"
+prompt_tokens = "resp.usage.in"
+completion_tokens = "resp.usage.out"
+cost = "resp.billing.0.usd"  # first line item
+timestamp = "ts"
+`
+	configs, err := parseGenericTOML(strings.NewReader(config), "parsers.toml")
+	if err != nil {
+		t.Fatalf("parseGenericTOML failed: %v", err)
+	}
+	if len(configs) != 1 || configs[0].Name != "MyTool" || configs[0].Cost != "resp.billing.0.usd" {
+		t.Fatalf("Expected the MyTool parser, got %+v", configs)
+	}
+	cfg := configs[0]
+
+	lines := []string{
+		`{"ts": 1735000000, "req": {"model": "gpt-4o"}, "resp": {"usage": {"in": 1000, "out": 500}, "billing": [{"usd": 0.25}]}}`,
+		`{"ts": "2025-01-01T00:00:00Z", "req": {"model": "gpt-4o"}, "resp": {"usage": {"in": 1000000, "out": 0}}}`,
+		`{"ts": 1735000100, "event": "heartbeat"}`,
+		`not json`,
+	}
+	logPath := filepath.Join(filepath.Dir(cfg.Glob), "a.jsonl")
+	if err := os.WriteFile(logPath, []byte(strings.Join(lines, "\n")+"\n"), 0644); err != nil {
+		t.Fatalf("failed to write log: %v", err)
+	}
+
+	tracker.Global.Reset()
+	defer tracker.Global.Reset()
+
+	// 1. Usage lines are recorded; other events and bad lines are skipped
+	if err := ParseGenericOnce(cfg); err != nil {
+		t.Fatalf("ParseGenericOnce failed: %v", err)
+	}
+	usages := tracker.Global.GetUsages()
+	if len(usages) != 2 {
+		t.Fatalf("Expected 2 usages, got %d: %+v", len(usages), usages)
+	}
+
+	// 2. Fields come from their dotted paths, including array indexes
+	first := usages[0]
+	if first.Tool != "MyTool" || first.PromptTokens != 1000 || first.CompletionTokens != 500 || first.Cost != 0.25 {
+		t.Errorf("Expected MyTool 1000/500 at $0.25, got %+v", first)
+	}
+	if !first.Timestamp.Equal(time.Unix(1735000000, 0)) {
+		t.Errorf("Expected Unix timestamp 1735000000, got %v", first.Timestamp)
+	}
+
+	// 3. Without a cost field the event is priced from tokens
+	if second := usages[1]; second.Cost != 2.50 || second.Timestamp.Year() != 2025 {
+		t.Errorf("Expected gpt-4o input priced at $2.50 in 2025, got %+v", second)
+	}
+
+	// 4. Only appended lines are read next time
+	f, _ := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString(`{"ts": 1735000200, "req": {"model": "gpt-4o"}, "resp": {"usage": {"in": 10, "out": 5}}}` + "\n")
+	f.Close()
+	processGenericFile(cfg, logPath)
+	if got := len(tracker.Global.GetUsages()); got != 3 {
+		t.Errorf("Expected 3 usages after an append, got %d", got)
+	}
+}
+
+func TestGenericConfigRejectsIncompleteParsers(t *testing.T) {
+	bad := []string{
+		"name = \"x\"", // Outside a table
+		"[[parser]]\nname = \"x\"\nglob = \"*.log\"", // No model or usage fields
+		"[[parser]]\ncolour = \"red\"",               // Unknown key
+		"[parsers]",                                  // Unknown table
+		"[[parser]]\nname = 5",                       // Not a string
+	}
+	for _, config := range bad {
+		if _, err := parseGenericTOML(strings.NewReader(config), "parsers.toml"); err == nil {
+			t.Errorf("Expected an error for %q", config)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+// internal/parser/litellm.go
+package parser
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"math"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bangarangler/burnrate/internal/log"
+	"github.com/bangarangler/burnrate/internal/pricing"
+	"github.com/bangarangler/burnrate/internal/tracker"
+	"github.com/fsnotify/fsnotify"
+)
+
+// LiteLLMLogEntry is one call in a LiteLLM proxy's JSONL log: LiteLLM's
+// standard logging payload, as a logging callback writes it. The proxy sees
+// every call routed through it, so this covers tools burnrate can't parse
+// natively.
+type LiteLLMLogEntry struct {
+	ID               string  `json:"id"` // LiteLLM's call ID
+	Model            string  `json:"model"`
+	Provider         string  `json:"custom_llm_provider"` // e.g. "openai", "anthropic"
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	ResponseCost     float64 `json:"response_cost"` // LiteLLM's own price, in USD
+	StartTime        float64 `json:"startTime"`     // Unix seconds
+	Status           string  `json:"status"`        // "success" or "failure"
+}
+
+// Track how far the log has been read, and the calls already recorded in
+// case it's rewritten
+var processedLiteLLMOffsets = make(map[string]int64) // filename -> offset after the last complete line
+var processedLiteLLMCalls = newDedupSet[struct{}]()  // call ID
+var processedLiteLLMMu sync.Mutex                    // Held while the log is read
+
+// LiteLLMLogPath resolves the proxy log to watch, defaulting to
+// ~/.litellm/spend_logs.jsonl
+func LiteLLMLogPath(logPath string) string {
+	usr, _ := user.Current()
+	if logPath == "" {
+		return filepath.Join(usr.HomeDir, ".litellm", "spend_logs.jsonl")
+	}
+	if strings.HasPrefix(logPath, "~") {
+		logPath = filepath.Join(usr.HomeDir, logPath[1:])
+	}
+	return logPath
+}
+
+// StartLiteLLMWatcher watches a LiteLLM proxy's JSONL log for new calls
+func StartLiteLLMWatcher(logPath string) error {
+	logPath = LiteLLMLogPath(logPath)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		tracker.Global.SetToolStatus(tracker.ToolStatus{
+			Name:    "LiteLLM",
+			Tier:    tracker.TierFullTracking,
+			Status:  "error",
+			Message: "Failed to create watcher",
+		})
+		log.Errorf("litellm: failed to create watcher: %v", err)
+		return err
+	}
+
+	// Process existing calls first
+	processLiteLLMLogFile(logPath)
+
+	if _, err := os.Stat(logPath); err == nil {
+		tracker.Global.SetToolStatus(tracker.ToolStatus{
+			Name:    "LiteLLM",
+			Tier:    tracker.TierFullTracking,
+			Status:  "active",
+			Message: "Watching proxy log",
+		})
+	} else {
+		tracker.Global.SetToolStatus(tracker.ToolStatus{
+			Name:    "LiteLLM",
+			Tier:    tracker.TierFullTracking,
+			Status:  "waiting",
+			Message: "Waiting for log file",
+			Path:    logPath,
+			Hint:    "Have the proxy log its standard logging payload as JSONL to " + logPath + ", or pass --litellm-log",
+		})
+	}
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Op&(fsnotify.Write|fsnotify.Create) == 0 || filepath.Clean(event.Name) != filepath.Clean(logPath) {
+					continue
+				}
+				processLiteLLMLogFile(event.Name)
+				tracker.Global.SetToolStatus(tracker.ToolStatus{
+					Name:    "LiteLLM",
+					Tier:    tracker.TierFullTracking,
+					Status:  "active",
+					Message: "Watching proxy log",
+				})
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Warnf("litellm: watcher error: %v", err)
+			}
+		}
+	}()
+
+	// Watch the log's directory, since the file may not exist yet
+	dir := filepath.Dir(logPath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		log.Warnf("litellm: failed to create log directory %s: %v", dir, err)
+		return err
+	}
+	if err := watcher.Add(dir); err != nil {
+		log.Warnf("litellm: failed to watch %s: %v", dir, err)
+		return err
+	}
+
+	return nil
+}
+
+// processLiteLLMLogFile records calls from lines appended since the last
+// read. A trailing line without a newline is left for the next write to
+// finish.
+func processLiteLLMLogFile(filename string) {
+	processedLiteLLMMu.Lock()
+	defer processedLiteLLMMu.Unlock()
+
+	file, err := os.Open(filename)
+	if err != nil {
+		log.Debugf("litellm: cannot open %s: %v", filename, err)
+		return
+	}
+	defer file.Close()
+
+	offset := processedLiteLLMOffsets[filename]
+	if stat, err := file.Stat(); err == nil && stat.Size() < offset {
+		// Truncated or rotated: start over, and let call IDs skip repeats
+		offset = 0
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		log.Debugf("litellm: cannot seek %s: %v", filename, err)
+		return
+	}
+
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			// EOF, possibly mid-line: keep the offset at the last full line
+			if err != io.EOF {
+				log.Warnf("litellm: stopped reading %s: %v", filename, err)
+			}
+			break
+		}
+		offset += int64(len(line))
+
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+		var entry LiteLLMLogEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			log.Debugf("litellm: skipping malformed line in %s: %v", filename, err)
+			continue
+		}
+		processLiteLLMEntry(entry)
+	}
+
+	processedLiteLLMOffsets[filename] = offset
+}
+
+// processLiteLLMEntry records one call unless it failed or was already
+// recorded
+func processLiteLLMEntry(entry LiteLLMLogEntry) {
+	if entry.ID == "" || entry.Status == "failure" {
+		return
+	}
+
+	ts := time.Now()
+	if entry.StartTime > 0 {
+		sec, frac := math.Modf(entry.StartTime)
+		ts = time.Unix(int64(sec), int64(frac*1e9))
+	}
+
+	deduped := processedLiteLLMCalls.seen(entry.ID, ts)
+	trace("LiteLLM", entry.ID, deduped, map[string]any{
+		"startTime":           entry.StartTime,
+		"model":               entry.Model,
+		"custom_llm_provider": entry.Provider,
+		"prompt_tokens":       entry.PromptTokens,
+		"completion_tokens":   entry.CompletionTokens,
+		"response_cost":       entry.ResponseCost,
+	})
+	if deduped {
+		return
+	}
+	processedLiteLLMCalls.put(entry.ID, ts, struct{}{})
+
+	if entry.PromptTokens == 0 && entry.CompletionTokens == 0 {
+		return
+	}
+
+	model := entry.Model
+	if model == "" {
+		model = "litellm-unknown"
+	}
+	display := model
+	if entry.Provider != "" {
+		display = model + " (" + entry.Provider + ")"
+	}
+
+	computed := pricing.CalculateCost(model, entry.PromptTokens, entry.CompletionTokens, 0)
+	cost, mismatch := chooseCost("litellm", model, entry.ResponseCost, computed)
+
+	accepted := tracker.Global.AddToolUsage("LiteLLM", tracker.Usage{
+		Model:            display,
+		PromptTokens:     entry.PromptTokens,
+		CompletionTokens: entry.CompletionTokens,
+		TotalTokens:      entry.PromptTokens + entry.CompletionTokens,
+		Cost:             cost,
+		Timestamp:        ts,
+		EventKey:         entry.ID,
+	})
+	if accepted {
+		tracker.Global.IncrementToolEvents("LiteLLM")
+	}
+	if mismatch {
+		tracker.Global.RecordCostMismatch("LiteLLM")
+	}
+}
+
+// ParseLiteLLMLogOnce does a one-time read of a LiteLLM proxy log. Useful
+// for backfilling history without starting a watcher
+func ParseLiteLLMLogOnce(logPath string) error {
+	logPath = LiteLLMLogPath(logPath)
+	if _, err := os.Stat(logPath); os.IsNotExist(err) {
+		return nil // No log, not an error
+	}
+
+	processLiteLLMLogFile(logPath)
+	return nil
+}
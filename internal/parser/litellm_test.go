@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bangarangler/burnrate/internal/tracker"
+)
+
+// liteLLMFixture is a proxy log with calls to two providers, a failed call,
+// and a call logged twice
+var liteLLMFixture = []string{
+	`{"id": "call-1", "model": "gpt-4o", "custom_llm_provider": "openai", "prompt_tokens": 1000, "completion_tokens": 200, "response_cost": 0.0045, "startTime": 1735000000.25, "status": "success"}`,
+	`{"id": "call-2", "model": "claude-sonnet-4", "custom_llm_provider": "anthropic", "prompt_tokens": 2000, "completion_tokens": 100, "response_cost": 0.0075, "startTime": 1735000010.5, "status": "success"}`,
+	`{"id": "call-3", "model": "gpt-4o", "custom_llm_provider": "openai", "prompt_tokens": 500, "completion_tokens": 0, "response_cost": 0, "startTime": 1735000020, "status": "failure"}`,
+	`{"id": "call-1", "model": "gpt-4o", "custom_llm_provider": "openai", "prompt_tokens": 1000, "completion_tokens": 200, "response_cost": 0.0045, "startTime": 1735000000.25, "status": "success"}`,
+}
+
+func TestLiteLLMRecordsEachCallOnce(t *testing.T) {
+	tracker.Global.Reset()
+	defer tracker.Global.Reset()
+	processedLiteLLMCalls = newDedupSet[struct{}]()
+	processedLiteLLMOffsets = make(map[string]int64)
+
+	logPath := filepath.Join(t.TempDir(), "spend_logs.jsonl")
+	if err := os.WriteFile(logPath, []byte(strings.Join(liteLLMFixture, "\n")+"\n"), 0644); err != nil {
+		t.Fatalf("failed to write log: %v", err)
+	}
+
+	// 1. Successful calls are recorded once each, with their provider and cost
+	processLiteLLMLogFile(logPath)
+	usages := tracker.Global.GetUsages()
+	if len(usages) != 2 {
+		t.Fatalf("Expected 2 usages, got %d: %+v", len(usages), usages)
+	}
+	if usages[0].Model != "gpt-4o (openai)" || usages[0].Cost != 0.0045 || usages[0].Timestamp.Unix() != 1735000000 {
+		t.Errorf("Expected the gpt-4o call at its start time with LiteLLM's cost, got %+v", usages[0])
+	}
+	if usages[1].Model != "claude-sonnet-4 (anthropic)" {
+		t.Errorf("Expected the Anthropic call, got %s", usages[1].Model)
+	}
+
+	// 2. A partial line waits for the rest of it
+	file, _ := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0644)
+	defer file.Close()
+	file.WriteString(`{"id": "call-4", "model": "gpt-4o", "custom_llm_provider": "openai", `)
+	processLiteLLMLogFile(logPath)
+	if n := len(tracker.Global.GetUsages()); n != 2 {
+		t.Fatalf("Expected the partial line to be skipped, got %d usages", n)
+	}
+	file.WriteString(`"prompt_tokens": 10, "completion_tokens": 5, "response_cost": 0.0001, "status": "success"}` + "\n")
+	processLiteLLMLogFile(logPath)
+	if n := len(tracker.Global.GetUsages()); n != 3 {
+		t.Errorf("Expected the finished line to be recorded, got %d usages", n)
+	}
+
+	// 3. A rewritten log doesn't record calls twice
+	processedLiteLLMOffsets = make(map[string]int64)
+	processLiteLLMLogFile(logPath)
+	if n := len(tracker.Global.GetUsages()); n != 3 {
+		t.Errorf("Expected no duplicates after a re-read, got %d usages", n)
+	}
+}
@@ -0,0 +1,68 @@
+// internal/parser/logfile.go
+package parser
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// openLogFile opens a log for reading, decompressing it if it's gzipped
+// (detected by a .gz extension, as rotation tools name them)
+func openLogFile(filename string) (io.ReadCloser, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(filename, ".gz") {
+		return file, nil
+	}
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return gzipFile{gz, file}, nil
+}
+
+// gzipFile reads through the decompressor and closes both it and the file
+type gzipFile struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (g gzipFile) Close() error {
+	g.Reader.Close()
+	return g.file.Close()
+}
+
+// rotatedLogs returns the rotated copies of a log (e.g. usage.jsonl.1 and
+// usage.jsonl.2.gz), oldest first. Numbered copies count up with age, so
+// the highest number comes first; other suffixes, such as dates, sort by
+// name ahead of them.
+func rotatedLogs(path string) []string {
+	matches, _ := filepath.Glob(path + ".*")
+
+	number := func(rotated string) (int, bool) {
+		suffix := strings.TrimPrefix(rotated, path+".")
+		n, err := strconv.Atoi(strings.TrimSuffix(suffix, ".gz"))
+		return n, err == nil
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		ni, numberedI := number(matches[i])
+		nj, numberedJ := number(matches[j])
+		switch {
+		case numberedI && numberedJ:
+			return ni > nj
+		case numberedI != numberedJ:
+			return numberedJ
+		}
+		return matches[i] < matches[j]
+	})
+	return matches
+}
@@ -2,6 +2,7 @@
 package parser
 
 import (
+	"cmp"
 	"encoding/json"
 	"io/fs"
 	"os"
@@ -9,7 +10,9 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/bangarangler/burnrate/internal/log"
 	"github.com/bangarangler/burnrate/internal/pricing"
 	"github.com/bangarangler/burnrate/internal/tracker"
 	"github.com/fsnotify/fsnotify"
@@ -32,17 +35,74 @@ type Message struct {
 			Write int `json:"write"`
 		} `json:"cache"`
 	} `json:"tokens"`
-	Timestamp int64 `json:"time.created"` // Unix milli
+	Time struct {
+		Created int64 `json:"created"` // Unix milli
+	} `json:"time"`
+	Path struct {
+		Cwd  string `json:"cwd"`
+		Root string `json:"root"` // The project's root, e.g. the git repository
+	} `json:"path"`
 }
 
 var watchedPaths = make(map[string]bool)
-var processedMessageIDs = make(map[string]bool) // Track processed messages to avoid duplicates
-var processedMu sync.Mutex                      // Protect the map
+var watchedMu sync.Mutex                          // Protect watchedPaths
+var processedMessageIDs = newDedupSet[struct{}]() // Track processed messages to avoid duplicates
+var processedMu sync.Mutex                        // Protect the set
 
-// StartOpenCodeWatcher watches for new/updated message files
-func StartOpenCodeWatcher() error {
+// OpenCodeStartupScan makes StartOpenCodeWatcher read the message files
+// already on disk before watching for new ones, so the session includes
+// work done before burnrate started. Turn it off if rescanning a large
+// storage tree makes startup slow.
+var OpenCodeStartupScan = true
+
+// OpenCodeMessageDir returns the directory OpenCode stores message files in.
+// dataPath overrides the search (see resolveOpenCodeMessageDir).
+func OpenCodeMessageDir(dataPath string) string {
 	usr, _ := user.Current()
-	basePath := filepath.Join(usr.HomeDir, ".local", "share", "opencode", "storage", "message")
+	return resolveOpenCodeMessageDir(dataPath, usr.HomeDir, os.Getenv("XDG_DATA_HOME"))
+}
+
+// resolveOpenCodeMessageDir picks OpenCode's message directory. An override
+// may point at the OpenCode data directory or the message directory itself.
+// Otherwise the first existing candidate wins, in order: $XDG_DATA_HOME/opencode,
+// ~/.local/share/opencode, ~/Library/Application Support/opencode (macOS).
+// If none exist the XDG location is returned so status reports a sensible path.
+func resolveOpenCodeMessageDir(override, home, xdgDataHome string) string {
+	messageDir := func(dataDir string) string {
+		return filepath.Join(dataDir, "storage", "message")
+	}
+
+	if override != "" {
+		if strings.HasPrefix(override, "~") {
+			override = filepath.Join(home, override[1:])
+		}
+		if info, err := os.Stat(messageDir(override)); err == nil && info.IsDir() {
+			return messageDir(override)
+		}
+		return override
+	}
+
+	var candidates []string
+	if xdgDataHome != "" {
+		candidates = append(candidates, filepath.Join(xdgDataHome, "opencode"))
+	}
+	candidates = append(candidates,
+		filepath.Join(home, ".local", "share", "opencode"),
+		filepath.Join(home, "Library", "Application Support", "opencode"),
+	)
+
+	for _, dataDir := range candidates {
+		if info, err := os.Stat(messageDir(dataDir)); err == nil && info.IsDir() {
+			return messageDir(dataDir)
+		}
+	}
+	return messageDir(candidates[0])
+}
+
+// StartOpenCodeWatcher watches for new/updated message files.
+// dataPath overrides where OpenCode's storage is looked up; empty searches the defaults.
+func StartOpenCodeWatcher(dataPath string) error {
+	basePath := OpenCodeMessageDir(dataPath)
 
 	// Check if the storage directory exists
 	if _, err := os.Stat(basePath); os.IsNotExist(err) {
@@ -51,7 +111,14 @@ func StartOpenCodeWatcher() error {
 			Tier:    tracker.TierFullTracking,
 			Status:  "not_found",
 			Message: "Storage directory not found",
+			Path:    basePath,
+			Hint:    "Run OpenCode once, or pass --opencode-path",
 		})
+		log.Debugf("opencode: %s not found", basePath)
+		startWhenFound("opencode", func() bool {
+			_, err := os.Stat(OpenCodeMessageDir(dataPath))
+			return err == nil
+		}, func() error { return StartOpenCodeWatcher(dataPath) })
 		return err
 	}
 
@@ -63,17 +130,10 @@ func StartOpenCodeWatcher() error {
 			Status:  "error",
 			Message: "Failed to create watcher",
 		})
+		log.Errorf("opencode: failed to create watcher: %v", err)
 		return err
 	}
 
-	// Report active status
-	tracker.Global.SetToolStatus(tracker.ToolStatus{
-		Name:    "OpenCode",
-		Tier:    tracker.TierFullTracking,
-		Status:  "active",
-		Message: "Watching storage",
-	})
-
 	go func() {
 		for {
 			select {
@@ -85,23 +145,21 @@ func StartOpenCodeWatcher() error {
 				if event.Op&fsnotify.Create == fsnotify.Create {
 					if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
 						if strings.HasPrefix(filepath.Base(event.Name), "ses_") {
-							if !watchedPaths[event.Name] {
-								watcher.Add(event.Name)
-								watchedPaths[event.Name] = true
-							}
+							watchOnce(watcher, event.Name)
 						}
 					}
 				}
 				// Handle both Create and Write events - deduplication handles duplicates
 				if event.Op&fsnotify.Write == fsnotify.Write || event.Op&fsnotify.Create == fsnotify.Create {
-					if strings.HasPrefix(filepath.Base(event.Name), "msg_") && strings.HasSuffix(event.Name, ".json") {
+					if isOpenCodeMessageFile(event.Name) {
 						parseMessageFile(event.Name)
 					}
 				}
-			case _, ok := <-watcher.Errors:
+			case err, ok := <-watcher.Errors:
 				if !ok {
 					return
 				}
+				log.Warnf("opencode: watcher error: %v", err)
 			}
 		}
 	}()
@@ -109,10 +167,7 @@ func StartOpenCodeWatcher() error {
 	// Add existing session directories
 	filepath.Walk(basePath, func(path string, info fs.FileInfo, _ error) error {
 		if info.IsDir() && path != basePath {
-			if !watchedPaths[path] {
-				watcher.Add(path)
-				watchedPaths[path] = true
-			}
+			watchOnce(watcher, path)
 		}
 		return nil
 	})
@@ -120,35 +175,92 @@ func StartOpenCodeWatcher() error {
 	// Watch base for new sessions
 	watcher.Add(basePath)
 
+	// Read what's already on disk. Watching starts first so nothing written
+	// during the scan is missed; dedup skips messages seen by both.
+	if OpenCodeStartupScan {
+		started := time.Now()
+		n := scanOpenCodeMessages(basePath)
+		log.Debugf("opencode: scanned %d message files in %s", n, time.Since(started).Round(time.Millisecond))
+	}
+
+	// Report active status once everything is watched
+	tracker.Global.SetToolStatus(tracker.ToolStatus{
+		Name:    "OpenCode",
+		Tier:    tracker.TierFullTracking,
+		Status:  "active",
+		Message: "Watching storage",
+	})
+
 	return nil
 }
 
+// watchOnce adds a directory to the watcher unless it's already watched
+func watchOnce(watcher *fsnotify.Watcher, path string) {
+	watchedMu.Lock()
+	defer watchedMu.Unlock()
+
+	if watchedPaths[path] {
+		return
+	}
+	if err := watcher.Add(path); err != nil {
+		log.Warnf("opencode: failed to watch %s: %v", path, err)
+		return
+	}
+	watchedPaths[path] = true
+}
+
 // parseMessageFile processes a single message file
 func parseMessageFile(filename string) {
 	data, err := os.ReadFile(filename)
 	if err != nil {
+		log.Debugf("opencode: cannot read %s: %v", filename, err)
 		return
 	}
 
 	var msg Message
 	if err := json.Unmarshal(data, &msg); err != nil {
+		// Often a partially written file; the next write event retries it
+		log.Debugf("opencode: cannot parse %s: %v", filename, err)
 		return
 	}
 
 	// Skip messages without real token usage (user messages, incomplete writes)
 	// Don't mark as processed yet - we may get a WRITE event with actual data later
 	if msg.Tokens.Input == 0 && msg.Tokens.Output == 0 {
+		markOpenCodeInFlight(filename, msg)
 		return
 	}
 
+	// Fall back to the file's mtime so re-reads map to the same history row
+	ts := time.UnixMilli(msg.Time.Created)
+	if msg.Time.Created == 0 {
+		ts = time.Now()
+		if info, err := os.Stat(filename); err == nil {
+			ts = info.ModTime()
+		}
+	}
+
 	// Skip if already processed (deduplication with mutex for thread safety)
 	processedMu.Lock()
-	if processedMessageIDs[msg.ID] {
-		processedMu.Unlock()
-		return
+	deduped := processedMessageIDs.seen(msg.ID, ts)
+	if !deduped {
+		processedMessageIDs.put(msg.ID, ts, struct{}{})
 	}
-	processedMessageIDs[msg.ID] = true
 	processedMu.Unlock()
+	trace("OpenCode", msg.ID, deduped, map[string]any{
+		"time.created":       msg.Time.Created,
+		"modelID":            msg.ModelID,
+		"providerID":         msg.ProviderID,
+		"cost":               msg.Cost,
+		"tokens.input":       msg.Tokens.Input,
+		"tokens.output":      msg.Tokens.Output,
+		"tokens.reasoning":   msg.Tokens.Reasoning,
+		"tokens.cache.read":  msg.Tokens.Cache.Read,
+		"tokens.cache.write": msg.Tokens.Cache.Write,
+	})
+	if deduped {
+		return
+	}
 
 	model := msg.ModelID
 	if msg.ProviderID != "" {
@@ -158,14 +270,92 @@ func parseMessageFile(filename string) {
 	input := msg.Tokens.Input + msg.Tokens.Cache.Read
 	output := msg.Tokens.Output + msg.Tokens.Reasoning + msg.Tokens.Cache.Write
 
-	// Prefer pre-calculated cost if available and reasonable
-	var cost float64
-	if msg.Cost > 0 && msg.Cost < 100 { // Sanity check
-		cost = msg.Cost
-	} else {
-		cost = pricing.CalculateCost(msg.ModelID, input, output)
+	// Prefer the pre-calculated cost if available and reasonable, but price
+	// the tokens too so a disagreement can be flagged. Reasoning is priced
+	// separately for models that bill it differently.
+	computed := pricing.CalculateCostWithCache(msg.ModelID, msg.Tokens.Input, msg.Tokens.Cache.Read,
+		msg.Tokens.Output+msg.Tokens.Cache.Write, msg.Tokens.Reasoning)
+	cost, mismatch := chooseCost("opencode", msg.ModelID, msg.Cost, computed)
+	savings := pricing.CalculateCacheSavings(msg.ModelID, msg.Tokens.Cache.Read)
+
+	accepted := tracker.Global.AddToolUsage("OpenCode", tracker.Usage{
+		Model:            model,
+		PromptTokens:     input,
+		CompletionTokens: output,
+		TotalTokens:      input + output,
+		CacheReadTokens:  msg.Tokens.Cache.Read,
+		ReasoningTokens:  msg.Tokens.Reasoning,
+		Cost:             cost,
+		CacheSavings:     savings,
+		Timestamp:        ts,
+		Project:          ProjectKey(cmp.Or(msg.Path.Cwd, msg.Path.Root)),
+		EventKey:         msg.ID,
+	})
+	if accepted {
+		tracker.Global.IncrementToolEvents("OpenCode")
+	}
+	tracker.Global.ClearProvisional("OpenCode", msg.ID)
+	if mismatch {
+		tracker.Global.RecordCostMismatch("OpenCode")
 	}
+}
+
+// markOpenCodeInFlight shows an assistant message OpenCode has started but not
+// yet written tokens for as in flight. Files written longer ago than
+// tracker.ProvisionalTTL are requests that never finished, not live ones.
+func markOpenCodeInFlight(filename string, msg Message) {
+	if msg.Role != "assistant" || msg.ID == "" {
+		return
+	}
+	info, err := os.Stat(filename)
+	if err != nil || time.Since(info.ModTime()) > tracker.ProvisionalTTL {
+		return
+	}
+
+	model := msg.ModelID
+	if msg.ProviderID != "" {
+		model = model + " (" + msg.ProviderID + ")"
+	}
+	started := info.ModTime()
+	if msg.Time.Created != 0 {
+		started = time.UnixMilli(msg.Time.Created)
+	}
+	tracker.Global.SetProvisional("OpenCode", msg.ID, tracker.Usage{
+		Model:     model,
+		Timestamp: started,
+	})
+}
+
+// ParseOpenCodeOnce does a one-time scan of all existing OpenCode message files
+// Useful for backfilling history without starting a watcher
+func ParseOpenCodeOnce(dataPath string) error {
+	basePath := OpenCodeMessageDir(dataPath)
+	if _, err := os.Stat(basePath); os.IsNotExist(err) {
+		return nil // No storage directory, not an error
+	}
+	scanOpenCodeMessages(basePath)
+	return nil
+}
+
+// scanOpenCodeMessages parses every message file under basePath and returns
+// how many there were. Messages already processed are skipped by dedup.
+func scanOpenCodeMessages(basePath string) int {
+	n := 0
+	filepath.Walk(basePath, func(path string, info fs.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+		if isOpenCodeMessageFile(path) {
+			parseMessageFile(path)
+			n++
+		}
+		return nil
+	})
+	return n
+}
 
-	tracker.Global.AddUsageWithTool("OpenCode", model, input, output, cost)
-	tracker.Global.IncrementToolEvents("OpenCode")
+// isOpenCodeMessageFile reports whether path is one of OpenCode's msg_*.json
+// message files
+func isOpenCodeMessageFile(path string) bool {
+	return strings.HasPrefix(filepath.Base(path), "msg_") && strings.HasSuffix(path, ".json")
 }
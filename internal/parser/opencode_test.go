@@ -0,0 +1,211 @@
+package parser
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bangarangler/burnrate/internal/tracker"
+)
+
+func TestResolveOpenCodeMessageDir(t *testing.T) {
+	home := t.TempDir()
+	xdg := filepath.Join(t.TempDir(), "data")
+
+	mkdir := func(path string) string {
+		t.Helper()
+		if err := os.MkdirAll(path, 0755); err != nil {
+			t.Fatalf("failed to create %s: %v", path, err)
+		}
+		return path
+	}
+
+	linuxDir := filepath.Join(home, ".local", "share", "opencode", "storage", "message")
+	macDir := filepath.Join(home, "Library", "Application Support", "opencode", "storage", "message")
+	xdgDir := filepath.Join(xdg, "opencode", "storage", "message")
+
+	// 1. Nothing exists: report the XDG location
+	if got := resolveOpenCodeMessageDir("", home, xdg); got != xdgDir {
+		t.Errorf("Expected %s with no storage, got %s", xdgDir, got)
+	}
+
+	// 2. Only the macOS location exists
+	mkdir(macDir)
+	if got := resolveOpenCodeMessageDir("", home, xdg); got != macDir {
+		t.Errorf("Expected macOS dir %s, got %s", macDir, got)
+	}
+
+	// 3. ~/.local/share takes precedence over macOS when XDG_DATA_HOME is unset
+	mkdir(linuxDir)
+	if got := resolveOpenCodeMessageDir("", home, ""); got != linuxDir {
+		t.Errorf("Expected %s, got %s", linuxDir, got)
+	}
+
+	// 4. XDG_DATA_HOME wins once it has storage
+	mkdir(xdgDir)
+	if got := resolveOpenCodeMessageDir("", home, xdg); got != xdgDir {
+		t.Errorf("Expected XDG dir %s, got %s", xdgDir, got)
+	}
+
+	// 5. An override may name the data dir or the message dir directly
+	custom := filepath.Join(t.TempDir(), "opencode")
+	customMessages := mkdir(filepath.Join(custom, "storage", "message"))
+	if got := resolveOpenCodeMessageDir(custom, home, xdg); got != customMessages {
+		t.Errorf("Expected %s from data dir override, got %s", customMessages, got)
+	}
+	if got := resolveOpenCodeMessageDir(customMessages, home, xdg); got != customMessages {
+		t.Errorf("Expected %s from message dir override, got %s", customMessages, got)
+	}
+}
+
+// waitFor polls cond until it holds or a second passes
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// resetOpenCodeDedup forgets processed messages. Watchers started by earlier
+// tests may still be handling events, so it takes the lock they do.
+func resetOpenCodeDedup() {
+	processedMu.Lock()
+	processedMessageIDs = newDedupSet[struct{}]()
+	processedMu.Unlock()
+}
+
+func TestOpenCodeWatcherStartsWhenStorageAppears(t *testing.T) {
+	defer func(interval time.Duration) { RescanInterval = interval }(RescanInterval)
+	RescanInterval = 10 * time.Millisecond
+
+	tracker.Global.Reset()
+	defer tracker.Global.Reset()
+	resetOpenCodeDedup()
+	status := func() string { return tracker.Global.GetToolStatus("OpenCode").Status }
+
+	// 1. Missing storage is reported as not found
+	dataDir := filepath.Join(t.TempDir(), "opencode")
+	StartOpenCodeWatcher(dataDir)
+	if got := status(); got != "not_found" {
+		t.Fatalf("Expected not_found before OpenCode runs, got %s", got)
+	}
+
+	// 2. Once OpenCode creates it, the watcher attaches
+	sessionDir := filepath.Join(dataDir, "storage", "message", "ses_1")
+	if err := os.MkdirAll(sessionDir, 0755); err != nil {
+		t.Fatalf("failed to create storage: %v", err)
+	}
+	waitFor(t, "OpenCode to become active", func() bool { return status() == "active" })
+
+	// 3. And new messages are tracked
+	msg := `{"id": "msg_rescan", "role": "assistant", "modelID": "gpt-4o", "providerID": "openai",
+		"tokens": {"input": 1000, "output": 100}}`
+	if err := os.WriteFile(filepath.Join(sessionDir, "msg_rescan.json"), []byte(msg), 0644); err != nil {
+		t.Fatalf("failed to write message: %v", err)
+	}
+	waitFor(t, "the message to be recorded", func() bool { return len(tracker.Global.GetUsages()) == 1 })
+}
+
+func TestOpenCodeWatcherScansExistingMessages(t *testing.T) {
+	defer func(scan bool) { OpenCodeStartupScan = scan }(OpenCodeStartupScan)
+
+	tracker.Global.Reset()
+	defer tracker.Global.Reset()
+	resetOpenCodeDedup()
+
+	// A storage tree from before burnrate started: two sessions' replies,
+	// a user message without tokens, and a file that isn't a message
+	dataDir := filepath.Join(t.TempDir(), "opencode")
+	files := map[string]string{
+		"ses_a/msg_a1.json": `{"id": "msg_a1", "role": "assistant", "modelID": "gpt-4o", "providerID": "openai",
+			"tokens": {"input": 1000, "output": 100}}`,
+		"ses_a/msg_a2.json": `{"id": "msg_a2", "role": "user"}`,
+		"ses_b/msg_b1.json": `{"id": "msg_b1", "role": "assistant", "modelID": "gpt-4o", "providerID": "openai",
+			"tokens": {"input": 2000, "output": 200}}`,
+		"ses_b/notes.json": `{"id": "notes", "tokens": {"input": 5, "output": 5}}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dataDir, "storage", "message", name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create storage: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+
+	// 1. With the scan off, only new messages would be tracked
+	OpenCodeStartupScan = false
+	if err := StartOpenCodeWatcher(dataDir); err != nil {
+		t.Fatalf("failed to start watcher: %v", err)
+	}
+	if got := len(tracker.Global.GetUsages()); got != 0 {
+		t.Errorf("Expected no usages without the startup scan, got %d", got)
+	}
+
+	// 2. With it on, the existing replies are read before watching
+	OpenCodeStartupScan = true
+	if err := StartOpenCodeWatcher(dataDir); err != nil {
+		t.Fatalf("failed to start watcher: %v", err)
+	}
+	if got := len(tracker.Global.GetUsages()); got != 2 {
+		t.Fatalf("Expected the 2 existing replies, got %d", got)
+	}
+
+	// 3. Scanning again doesn't count them twice
+	if err := ParseOpenCodeOnce(dataDir); err != nil {
+		t.Fatalf("failed to rescan: %v", err)
+	}
+	if got := len(tracker.Global.GetUsages()); got != 2 {
+		t.Errorf("Expected rescanning to be deduplicated, got %d usages", got)
+	}
+}
+
+func TestOpenCodeMessageTimestamp(t *testing.T) {
+	tracker.Global.Reset()
+	defer tracker.Global.Reset()
+	resetOpenCodeDedup()
+
+	// As OpenCode writes it, with the times nested under "time"
+	const raw = `{
+		"id": "msg_01JZ8K3T2V",
+		"sessionID": "ses_01JZ8K1A9Q",
+		"role": "assistant",
+		"time": {"created": 1767261600000, "completed": 1767261612345},
+		"modelID": "claude-sonnet-4-20250514",
+		"providerID": "anthropic",
+		"path": {"cwd": "/home/dev/project", "root": "/home/dev/project"},
+		"cost": 0.0123,
+		"tokens": {"input": 1200, "output": 340, "reasoning": 0, "cache": {"read": 0, "write": 0}}
+	}`
+	want := time.UnixMilli(1767261600000)
+
+	// 1. The struct decodes the nested creation time
+	var msg Message
+	if err := json.Unmarshal([]byte(raw), &msg); err != nil {
+		t.Fatalf("failed to unmarshal message: %v", err)
+	}
+	if msg.Time.Created == 0 {
+		t.Fatalf("Expected a creation time, got 0")
+	}
+
+	// 2. And the usage is recorded at it, not at the file's mtime
+	path := filepath.Join(t.TempDir(), "msg_01JZ8K3T2V.json")
+	if err := os.WriteFile(path, []byte(raw), 0644); err != nil {
+		t.Fatalf("failed to write message: %v", err)
+	}
+	parseMessageFile(path)
+	usages := tracker.Global.GetUsages()
+	if len(usages) != 1 {
+		t.Fatalf("Expected 1 usage, got %d", len(usages))
+	}
+	if !usages[0].Timestamp.Equal(want) {
+		t.Errorf("Expected timestamp %s, got %s", want, usages[0].Timestamp)
+	}
+}
@@ -0,0 +1,154 @@
+// internal/parser/project.go
+package parser
+
+import (
+	"bufio"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Project keys already derived, by working directory. A directory's remote
+// rarely changes while burnrate runs, and parsers ask for every event.
+var projectKeys = make(map[string]string)
+var projectKeysMu sync.Mutex
+
+// ProjectKey identifies the project a tool was working in, from its working
+// directory. Inside a git repository with an origin remote it's the remote
+// as host/owner/repo, so clones in different places count as one project;
+// otherwise it's the repository's (or the directory's) name. Empty for an
+// empty cwd.
+func ProjectKey(cwd string) string {
+	if cwd == "" {
+		return ""
+	}
+	cwd = filepath.Clean(cwd)
+
+	projectKeysMu.Lock()
+	defer projectKeysMu.Unlock()
+	if key, ok := projectKeys[cwd]; ok {
+		return key
+	}
+
+	key := filepath.Base(cwd)
+	if root, gitDir := findGitRoot(cwd); root != "" {
+		key = filepath.Base(root)
+		if remote := normalizeRemote(originURL(gitDir)); remote != "" {
+			key = remote
+		}
+	}
+	projectKeys[cwd] = key
+	return key
+}
+
+// findGitRoot walks up from dir to the enclosing repository, returning its
+// working tree and the directory holding its config. Both are empty outside
+// a repository.
+func findGitRoot(dir string) (root, gitDir string) {
+	for {
+		dotGit := filepath.Join(dir, ".git")
+		if info, err := os.Stat(dotGit); err == nil {
+			if info.IsDir() {
+				return dir, dotGit
+			}
+			// Worktrees and submodules have a .git file pointing elsewhere
+			return dir, linkedGitDir(dir, dotGit)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ""
+		}
+		dir = parent
+	}
+}
+
+// linkedGitDir follows a .git file's "gitdir:" line. A worktree's gitdir
+// shares its config with the main repository, named by its commondir file.
+func linkedGitDir(root, dotGit string) string {
+	data, err := os.ReadFile(dotGit)
+	if err != nil {
+		return ""
+	}
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return ""
+	}
+	gitDir = strings.TrimSpace(gitDir)
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(root, gitDir)
+	}
+	if common, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir := strings.TrimSpace(string(common))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+		return commonDir
+	}
+	return gitDir
+}
+
+// originURL reads the origin remote's url from a repository's config, or ""
+func originURL(gitDir string) string {
+	if gitDir == "" {
+		return ""
+	}
+	file, err := os.Open(filepath.Join(gitDir, "config"))
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	inOrigin := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inOrigin = line == `[remote "origin"]`
+			continue
+		}
+		if !inOrigin {
+			continue
+		}
+		if name, value, ok := strings.Cut(line, "="); ok && strings.TrimSpace(name) == "url" {
+			return strings.TrimSpace(value)
+		}
+	}
+	return ""
+}
+
+// normalizeRemote turns a remote url into host/owner/repo, dropping the
+// scheme, user, port, and .git suffix, so that SSH and HTTPS clones of a
+// repository match. A local path remote gives just the repository name.
+func normalizeRemote(remote string) string {
+	if remote == "" {
+		return ""
+	}
+
+	var host, path string
+	if strings.Contains(remote, "://") {
+		u, err := url.Parse(remote)
+		if err != nil {
+			return ""
+		}
+		host, path = u.Hostname(), u.Path
+	} else if before, after, ok := strings.Cut(remote, ":"); ok && !strings.Contains(before, "/") {
+		// scp-like syntax: [user@]host:owner/repo
+		host, path = before, after
+		if i := strings.LastIndex(host, "@"); i >= 0 {
+			host = host[i+1:]
+		}
+	} else {
+		path = remote
+	}
+
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if path == "" {
+		return ""
+	}
+	if host == "" {
+		return filepath.Base(path)
+	}
+	return strings.ToLower(host) + "/" + path
+}
@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeGitRepo creates a repository skeleton at dir whose origin is remote
+// (no remote when empty)
+func writeGitRepo(t *testing.T, dir, remote string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Join(dir, ".git"), 0755); err != nil {
+		t.Fatalf("failed to create repo: %v", err)
+	}
+	config := "[core]\n\tbare = false\n"
+	if remote != "" {
+		config += "[remote \"upstream\"]\n\turl = https://example.com/other/fork.git\n" +
+			"[remote \"origin\"]\n\turl = " + remote + "\n\tfetch = +refs/heads/*:refs/remotes/origin/*\n"
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".git", "config"), []byte(config), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+}
+
+func TestProjectKeyFromCwd(t *testing.T) {
+	base := t.TempDir()
+
+	// 1. Clones over SSH and HTTPS, from any subdirectory, are the same project
+	sshClone := filepath.Join(base, "ssh", "burnrate")
+	writeGitRepo(t, sshClone, "git@github.com:bangarangler/burnrate.git")
+	httpsClone := filepath.Join(base, "https", "br")
+	writeGitRepo(t, httpsClone, "https://user@GitHub.com:443/bangarangler/burnrate")
+
+	sub := filepath.Join(sshClone, "internal", "parser")
+	os.MkdirAll(sub, 0755)
+
+	for _, cwd := range []string{sshClone, sub, httpsClone} {
+		if key := ProjectKey(cwd); key != "github.com/bangarangler/burnrate" {
+			t.Errorf("Expected %s to be github.com/bangarangler/burnrate, got %q", cwd, key)
+		}
+	}
+
+	// 2. A worktree uses its main repository's remote
+	worktree := filepath.Join(base, "feature")
+	gitDir := filepath.Join(sshClone, ".git", "worktrees", "feature")
+	os.MkdirAll(gitDir, 0755)
+	os.MkdirAll(worktree, 0755)
+	os.WriteFile(filepath.Join(gitDir, "commondir"), []byte("../..\n"), 0644)
+	os.WriteFile(filepath.Join(worktree, ".git"), []byte("gitdir: "+gitDir+"\n"), 0644)
+	if key := ProjectKey(worktree); key != "github.com/bangarangler/burnrate" {
+		t.Errorf("Expected the worktree to be github.com/bangarangler/burnrate, got %q", key)
+	}
+
+	// 3. Without an origin, the repository's name
+	local := filepath.Join(base, "scratch")
+	writeGitRepo(t, local, "")
+	os.MkdirAll(filepath.Join(local, "src"), 0755)
+	if key := ProjectKey(filepath.Join(local, "src")); key != "scratch" {
+		t.Errorf("Expected scratch, got %q", key)
+	}
+
+	// 4. Outside any repository, the directory's name
+	plain := filepath.Join(base, "notes")
+	os.MkdirAll(plain, 0755)
+	if key := ProjectKey(plain); key != "notes" {
+		t.Errorf("Expected notes, got %q", key)
+	}
+
+	// 5. No cwd, no project
+	if key := ProjectKey(""); key != "" {
+		t.Errorf("Expected no project for an empty cwd, got %q", key)
+	}
+}
+
+func TestNormalizeRemote(t *testing.T) {
+	tests := map[string]string{
+		"git@github.com:owner/repo.git":         "github.com/owner/repo",
+		"ssh://git@gitlab.com:2222/group/sub/r": "gitlab.com/group/sub/r",
+		"https://github.com/owner/repo.git/":    "github.com/owner/repo",
+		"/srv/git/repo.git":                     "repo",
+		"file:///srv/git/repo.git":              "repo",
+		"":                                      "",
+	}
+	for remote, want := range tests {
+		if got := normalizeRemote(remote); got != want {
+			t.Errorf("Expected %q to normalize to %q, got %q", remote, want, got)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package parser
+
+import (
+	"time"
+
+	"github.com/bangarangler/burnrate/internal/log"
+)
+
+// RescanInterval is how often a tool whose data directory was missing at
+// startup is looked for again
+var RescanInterval = 30 * time.Second
+
+// startWhenFound polls until found reports the tool's data exists, then calls
+// start. A watcher can't watch a directory that doesn't exist yet, or rely on
+// its parent being watched, so tracking begins once the tool is first used.
+func startWhenFound(tool string, found func() bool, start func() error) {
+	go func() {
+		for {
+			time.Sleep(RescanInterval)
+			if found() {
+				log.Infof("%s: data directory appeared, starting watcher", tool)
+				start()
+				return
+			}
+		}
+	}()
+}
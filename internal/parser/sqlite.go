@@ -0,0 +1,51 @@
+// internal/parser/sqlite.go
+package parser
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// Tools like Crush keep writing to their databases while we read them, so a
+// read can hit "database is locked". SQLite waits up to sqliteBusyTimeout for
+// the lock itself; if that still fails, the query is retried a few times.
+// immutable=1 would skip locking entirely, but it's only safe for files that
+// never change, which these don't.
+var (
+	sqliteBusyTimeout = 5 * time.Second
+	sqliteRetries     = 3                      // Attempts after the first
+	sqliteRetryDelay  = 250 * time.Millisecond // Doubles after each attempt
+)
+
+// openToolDB opens another tool's SQLite database read-only with a busy timeout
+func openToolDB(dbPath string) (*sql.DB, error) {
+	dsn := fmt.Sprintf("%s?mode=ro&_busy_timeout=%d", dbPath, sqliteBusyTimeout.Milliseconds())
+	return sql.Open("sqlite3", dsn)
+}
+
+// queryWithRetry runs a query, retrying while the database is locked
+func queryWithRetry(db *sql.DB, query string, args ...any) (*sql.Rows, error) {
+	delay := sqliteRetryDelay
+	for attempt := 0; ; attempt++ {
+		rows, err := db.Query(query, args...)
+		if err == nil || !isLocked(err) || attempt >= sqliteRetries {
+			return rows, err
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// isLocked reports whether err is SQLite's "database is locked" (SQLITE_BUSY)
+// or "database table is locked" (SQLITE_LOCKED)
+func isLocked(err error) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package parser
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+)
+
+// TabnineStatus represents the detection status of Tabnine, which runs as
+// a local engine its IDE plugins download
+type TabnineStatus struct {
+	Installed    bool   // Engine binaries found
+	Configured   bool   // Config file present
+	InstallPath  string // Path to the engine directory if found
+	ConfigPath   string // Path to the config directory if found
+	DashboardURL string // URL to view usage (always set)
+}
+
+// tabnineDashboardURL is where Tabnine accounts and usage are shown
+const tabnineDashboardURL = "https://app.tabnine.com"
+
+// CheckTabnineStatus performs a one-time detection of Tabnine.
+// This is a Tier 2 tool - we can only detect installation, not parse usage logs
+func CheckTabnineStatus() TabnineStatus {
+	usr, err := user.Current()
+	if err != nil {
+		return TabnineStatus{DashboardURL: tabnineDashboardURL}
+	}
+	return detectTabnine(usr.HomeDir)
+}
+
+// detectTabnine looks for Tabnine's engine and config under home
+func detectTabnine(home string) TabnineStatus {
+	status := TabnineStatus{DashboardURL: tabnineDashboardURL}
+
+	installDirs := []string{
+		filepath.Join(home, ".tabnine"),
+		filepath.Join(home, ".local", "share", "TabNine"),
+		filepath.Join(home, "Library", "Application Support", "TabNine"),
+	}
+	for _, dir := range installDirs {
+		if hasContent(dir) {
+			status.Installed = true
+			status.InstallPath = dir
+			break
+		}
+	}
+
+	configDirs := []string{
+		filepath.Join(home, ".config", "TabNine"),
+		filepath.Join(home, "Library", "Preferences", "TabNine"),
+	}
+	for _, dir := range configDirs {
+		if hasContent(filepath.Join(dir, "tabnine_config.json")) {
+			status.Configured = true
+			status.ConfigPath = dir
+			break
+		}
+	}
+	return status
+}
+
+// IsInstalled returns true if Tabnine's engine is installed
+func (s TabnineStatus) IsInstalled() bool {
+	return s.Installed
+}
+
+// StatusMessage returns a human-readable status message
+func (s TabnineStatus) StatusMessage() string {
+	if s.Configured {
+		return "Detected, view usage at app.tabnine.com"
+	}
+	if s.IsInstalled() {
+		return "Installed but not configured"
+	}
+	return "Not installed"
+}
+
+// StatusCode returns the status code for display
+func (s TabnineStatus) StatusCode() string {
+	if s.Configured {
+		return "configured"
+	}
+	if s.IsInstalled() {
+		return "installed"
+	}
+	return "not_found"
+}
+
+// hasContent reports whether path is a non-empty directory, or a file with
+// more than an empty "{}" or "[]" in it
+func hasContent(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	if !info.IsDir() {
+		return info.Size() > 2
+	}
+	entries, err := os.ReadDir(path)
+	return err == nil && len(entries) > 0
+}
@@ -0,0 +1,48 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectTabnine(t *testing.T) {
+	home := t.TempDir()
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+
+	// 1. Nothing installed
+	s := detectTabnine(home)
+	if s.StatusCode() != "not_found" || s.DashboardURL == "" {
+		t.Errorf("Expected not_found with a dashboard URL, got %s (%q)", s.StatusCode(), s.DashboardURL)
+	}
+
+	// 2. The engine a plugin downloaded, before it's configured
+	engineDir := filepath.Join(home, ".tabnine")
+	write(filepath.Join(engineDir, "5.0.0", "TabNine"), "binary")
+	s = detectTabnine(home)
+	if s.StatusCode() != "installed" || s.InstallPath != engineDir {
+		t.Errorf("Expected installed at %s, got %s at %q", engineDir, s.StatusCode(), s.InstallPath)
+	}
+
+	// 3. An empty config file doesn't count
+	configDir := filepath.Join(home, ".config", "TabNine")
+	write(filepath.Join(configDir, "tabnine_config.json"), "{}")
+	if s := detectTabnine(home); s.Configured {
+		t.Errorf("Expected an empty config not to count")
+	}
+
+	// 4. A real config is detected
+	write(filepath.Join(configDir, "tabnine_config.json"), `{"version": "5.0.0", "hide_promotional_message": true}`)
+	s = detectTabnine(home)
+	if s.StatusCode() != "configured" || s.ConfigPath != configDir {
+		t.Errorf("Expected configured from %s, got %s from %q", configDir, s.StatusCode(), s.ConfigPath)
+	}
+}
@@ -0,0 +1,22 @@
+package parser
+
+// TraceEvent is one event a parser matched in a tool's data, before it is
+// recorded, for debugging field mappings with `burnrate watch`
+type TraceEvent struct {
+	Tool    string
+	Key     string         // Dedup key the event is tracked under
+	Fields  map[string]any // Fields as read from the tool, before pricing
+	Deduped bool           // Already recorded, so skipped
+}
+
+// Trace is called with every event a parser matches, duplicates included,
+// when set. Events that are recorded also reach tracker subscribers with
+// their computed cost.
+var Trace func(TraceEvent)
+
+// trace reports a matched event to Trace, if set
+func trace(tool, key string, deduped bool, fields map[string]any) {
+	if Trace != nil {
+		Trace(TraceEvent{Tool: tool, Key: key, Fields: fields, Deduped: deduped})
+	}
+}
@@ -0,0 +1,353 @@
+// internal/parser/zed.go
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bangarangler/burnrate/internal/log"
+	"github.com/bangarangler/burnrate/internal/pricing"
+	"github.com/bangarangler/burnrate/internal/tracker"
+	"github.com/fsnotify/fsnotify"
+	"github.com/klauspost/compress/zstd"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// ZedThread is the part of a serialized Zed assistant thread that carries
+// usage. Zed stores one JSON document per thread in its threads database.
+type ZedThread struct {
+	Model                *ZedThreadModel `json:"model"`
+	CumulativeTokenUsage ZedTokenUsage   `json:"cumulative_token_usage"`
+	RequestTokenUsage    []ZedTokenUsage `json:"request_token_usage"` // One entry per message; zero for user messages
+}
+
+// ZedThreadModel is the model a thread was last run with
+type ZedThreadModel struct {
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+}
+
+// ZedTokenUsage is the token usage of one request (or a whole thread)
+type ZedTokenUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+func (u ZedTokenUsage) isZero() bool {
+	return u.InputTokens == 0 && u.OutputTokens == 0 &&
+		u.CacheCreationInputTokens == 0 && u.CacheReadInputTokens == 0
+}
+
+// since returns the usage added after an earlier running total, and false
+// if any count went down, as when a thread is rewritten
+func (u ZedTokenUsage) since(earlier ZedTokenUsage) (ZedTokenUsage, bool) {
+	growth := ZedTokenUsage{
+		InputTokens:              u.InputTokens - earlier.InputTokens,
+		OutputTokens:             u.OutputTokens - earlier.OutputTokens,
+		CacheCreationInputTokens: u.CacheCreationInputTokens - earlier.CacheCreationInputTokens,
+		CacheReadInputTokens:     u.CacheReadInputTokens - earlier.CacheReadInputTokens,
+	}
+	ok := growth.InputTokens >= 0 && growth.OutputTokens >= 0 &&
+		growth.CacheCreationInputTokens >= 0 && growth.CacheReadInputTokens >= 0
+	return growth, ok
+}
+
+// Track processed threads to avoid duplicates
+var processedZedThreads = make(map[string]zedThreadProgress) // threadID -> progress
+var processedZedMu sync.Mutex                                // Held for a whole scan
+
+// zedThreadProgress is how much of a thread has been recorded
+type zedThreadProgress struct {
+	updatedAt  string        // Thread's updated_at when last processed
+	requests   int           // Number of request_token_usage entries already recorded
+	cumulative ZedTokenUsage // Running total already recorded, for threads without per-request usage
+}
+
+// zstdDecoder decompresses threads; one decoder is reused for every thread
+var zstdDecoder = sync.OnceValues(func() (*zstd.Decoder, error) {
+	return zstd.NewReader(nil, zstd.WithDecoderConcurrency(1))
+})
+
+// Default database paths to check
+var defaultZedDBPaths = []string{
+	"~/.local/share/zed/threads/threads.db",                // Linux
+	"~/Library/Application Support/Zed/threads/threads.db", // macOS
+}
+
+// ZedDBPath resolves the threads database to watch. An empty dbPath finds an
+// existing database under $XDG_DATA_HOME or the defaults, falling back to
+// the Linux location.
+func ZedDBPath(dbPath string) string {
+	usr, _ := user.Current()
+
+	// Expand ~ in path
+	if strings.HasPrefix(dbPath, "~") {
+		dbPath = filepath.Join(usr.HomeDir, dbPath[1:])
+	}
+	if dbPath != "" {
+		return dbPath
+	}
+
+	candidates := []string{}
+	if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" {
+		candidates = append(candidates, filepath.Join(xdg, "zed", "threads", "threads.db"))
+	}
+	for _, path := range defaultZedDBPaths {
+		candidates = append(candidates, filepath.Join(usr.HomeDir, path[1:]))
+	}
+
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return candidates[0]
+}
+
+// StartZedWatcher watches Zed's assistant threads database for new usage
+func StartZedWatcher(dbPath string) error {
+	dbPath = ZedDBPath(dbPath)
+
+	// Check if database exists
+	dbExists := false
+	if _, err := os.Stat(dbPath); err == nil {
+		dbExists = true
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		tracker.Global.SetToolStatus(tracker.ToolStatus{
+			Name:    "Zed",
+			Tier:    tracker.TierFullTracking,
+			Status:  "error",
+			Message: "Failed to create watcher",
+		})
+		log.Errorf("zed: failed to create watcher: %v", err)
+		return err
+	}
+
+	// Process existing data first
+	processZedDB(dbPath)
+
+	// Set initial status
+	if dbExists {
+		tracker.Global.SetToolStatus(tracker.ToolStatus{
+			Name:    "Zed",
+			Tier:    tracker.TierFullTracking,
+			Status:  "active",
+			Message: "Watching threads database",
+		})
+	} else {
+		tracker.Global.SetToolStatus(tracker.ToolStatus{
+			Name:    "Zed",
+			Tier:    tracker.TierFullTracking,
+			Status:  "not_found",
+			Message: "threads.db not found",
+			Path:    dbPath,
+			Hint:    "Use Zed's assistant once, or pass --zed-db",
+		})
+	}
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				// SQLite writes land in the -wal file first, so react to any
+				// write in the directory that belongs to the database
+				if event.Op&(fsnotify.Write|fsnotify.Create) != 0 && strings.HasPrefix(event.Name, dbPath) {
+					processZedDB(dbPath)
+					tracker.Global.SetToolStatus(tracker.ToolStatus{
+						Name:    "Zed",
+						Tier:    tracker.TierFullTracking,
+						Status:  "active",
+						Message: "Watching threads database",
+					})
+				}
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Warnf("zed: watcher error: %v", err)
+			}
+		}
+	}()
+
+	// Watch the database's directory (fsnotify can't watch non-existent files).
+	// Unlike Crush, don't create it: Zed isn't project-scoped, so a missing
+	// directory means Zed isn't installed.
+	dir := filepath.Dir(dbPath)
+	if _, err := os.Stat(dir); err != nil {
+		log.Debugf("zed: %s not found", dir)
+		return nil
+	}
+	if err := watcher.Add(dir); err != nil {
+		log.Warnf("zed: failed to watch %s: %v", dir, err)
+		return err
+	}
+
+	return nil
+}
+
+// processZedDB reads new usage from every thread that changed since it was
+// last processed
+func processZedDB(dbPath string) {
+	if _, err := os.Stat(dbPath); err != nil {
+		return
+	}
+
+	db, err := openToolDB(dbPath)
+	if err != nil {
+		log.Warnf("zed: cannot open %s: %v", dbPath, err)
+		return
+	}
+	defer db.Close()
+
+	// One scan at a time, so overlapping write events never record a
+	// thread's requests twice
+	processedZedMu.Lock()
+	defer processedZedMu.Unlock()
+
+	rows, err := queryWithRetry(db, `SELECT id, updated_at, data_type, data FROM threads ORDER BY updated_at ASC`)
+	if isLocked(err) {
+		log.Warnf("zed: skipped reading %s: database stayed locked: %v", dbPath, err)
+		return
+	}
+	if err != nil {
+		log.Debugf("zed: cannot query threads in %s: %v", dbPath, err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id, updatedAt, dataType string
+		var data []byte
+		if err := rows.Scan(&id, &updatedAt, &dataType, &data); err != nil {
+			log.Debugf("zed: skipping unreadable thread row in %s: %v", dbPath, err)
+			continue
+		}
+
+		// Skip if already processed and not updated
+		progress, seen := processedZedThreads[id]
+		if seen && progress.updatedAt == updatedAt {
+			continue
+		}
+
+		raw, err := decodeZedThreadData(dataType, data)
+		if err != nil {
+			log.Debugf("zed: skipping thread %s: %v", id, err)
+			continue
+		}
+
+		var thread ZedThread
+		if err := json.Unmarshal(raw, &thread); err != nil {
+			log.Debugf("zed: cannot parse thread %s: %v", id, err)
+			continue
+		}
+
+		processedZedThreads[id] = recordZedThread(id, thread, updatedAt, progress)
+	}
+}
+
+// recordZedThread records a thread's usage since the progress it was last
+// processed with, and returns its progress now.
+// Zed stores no per-request time, so new requests are stamped with the
+// thread's updated_at.
+func recordZedThread(id string, thread ZedThread, updatedAt string, last zedThreadProgress) zedThreadProgress {
+	model := "zed-unknown"
+	if thread.Model != nil && thread.Model.Model != "" {
+		model = thread.Model.Model
+	}
+
+	ts, err := time.Parse(time.RFC3339, updatedAt)
+	if err != nil {
+		ts = time.Now()
+	}
+
+	progress := zedThreadProgress{updatedAt: updatedAt, requests: last.requests, cumulative: last.cumulative}
+
+	requests := thread.RequestTokenUsage
+	if len(requests) == 0 {
+		// Older threads only carry a running total; its growth since the
+		// thread was last read is recorded as one request
+		growth, ok := thread.CumulativeTokenUsage.since(last.cumulative)
+		if ok && !growth.isZero() {
+			recordZedRequest(fmt.Sprintf("%s@%s", id, updatedAt), model, thread.Model, growth, ts)
+		}
+		progress.cumulative = thread.CumulativeTokenUsage
+		return progress
+	}
+
+	for i := last.requests; i < len(requests); i++ {
+		usage := requests[i]
+		if usage.isZero() {
+			continue
+		}
+		recordZedRequest(fmt.Sprintf("%s#%d", id, i), model, thread.Model, usage, ts)
+	}
+	progress.requests = max(last.requests, len(requests))
+	return progress
+}
+
+// recordZedRequest records one request, keyed by its thread and position
+func recordZedRequest(key, model string, threadModel *ZedThreadModel, usage ZedTokenUsage, ts time.Time) {
+	// Cache writes are billed as input; reads at the cache rate
+	uncached := usage.InputTokens + usage.CacheCreationInputTokens
+	prompt := uncached + usage.CacheReadInputTokens
+
+	cost := pricing.CalculateCostWithCache(model, uncached, usage.CacheReadInputTokens, usage.OutputTokens, 0)
+	savings := pricing.CalculateCacheSavings(model, usage.CacheReadInputTokens)
+
+	display := model
+	if threadModel != nil && threadModel.Provider != "" {
+		display = model + " (" + threadModel.Provider + ")"
+	}
+
+	accepted := tracker.Global.AddToolUsage("Zed", tracker.Usage{
+		Model:            display,
+		PromptTokens:     prompt,
+		CompletionTokens: usage.OutputTokens,
+		TotalTokens:      prompt + usage.OutputTokens,
+		CacheReadTokens:  usage.CacheReadInputTokens,
+		Cost:             cost,
+		CacheSavings:     savings,
+		Timestamp:        ts,
+		EventKey:         key,
+	})
+	if accepted {
+		tracker.Global.IncrementToolEvents("Zed")
+	}
+}
+
+// decodeZedThreadData returns a thread's JSON. Recent Zed versions compress
+// threads with zstd.
+func decodeZedThreadData(dataType string, data []byte) ([]byte, error) {
+	switch dataType {
+	case "json":
+		return data, nil
+	case "zstd":
+		decoder, err := zstdDecoder()
+		if err != nil {
+			return nil, err
+		}
+		return decoder.DecodeAll(data, nil)
+	}
+	return nil, fmt.Errorf("unknown data type %q", dataType)
+}
+
+// ParseZedDBOnce does a one-time parse of Zed's threads database
+// Useful for backfilling history without starting a watcher
+func ParseZedDBOnce(dbPath string) error {
+	processZedDB(ZedDBPath(dbPath))
+	return nil
+}
@@ -0,0 +1,149 @@
+package parser
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/bangarangler/burnrate/internal/tracker"
+	"github.com/klauspost/compress/zstd"
+)
+
+// writeZedFixture creates a threads database like Zed's with JSON threads
+func writeZedFixture(t *testing.T, dbPath string, threads map[string][2]string) {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("failed to open fixture: %v", err)
+	}
+	defer db.Close()
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS threads (
+		id TEXT PRIMARY KEY, summary TEXT NOT NULL, updated_at TEXT NOT NULL,
+		data_type TEXT NOT NULL, data BLOB NOT NULL)`)
+	if err != nil {
+		t.Fatalf("failed to create fixture table: %v", err)
+	}
+	for id, thread := range threads {
+		_, err := db.Exec(`INSERT OR REPLACE INTO threads VALUES (?, 'summary', ?, 'json', ?)`,
+			id, thread[0], []byte(thread[1]))
+		if err != nil {
+			t.Fatalf("failed to insert fixture thread: %v", err)
+		}
+	}
+}
+
+func TestZedThreadsRecordEachRequestOnce(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "threads.db")
+
+	// Two assistant requests; the zero entries are user messages
+	writeZedFixture(t, dbPath, map[string][2]string{
+		"t1": {"2025-06-01T10:00:00Z", `{"model": {"provider": "anthropic", "model": "claude-sonnet-4-latest"},
+			"request_token_usage": [{}, {"input_tokens": 1000, "output_tokens": 200},
+				{}, {"input_tokens": 50, "output_tokens": 100, "cache_read_input_tokens": 1000}]}`},
+	})
+
+	tracker.Global.Reset()
+	defer tracker.Global.Reset()
+	processedZedThreads = make(map[string]zedThreadProgress)
+
+	// 1. Each non-empty request becomes one usage, priced from tokens
+	processZedDB(dbPath)
+	usages := tracker.Global.GetUsages()
+	if len(usages) != 2 {
+		t.Fatalf("Expected 2 usages, got %d: %+v", len(usages), usages)
+	}
+	if usages[0].Model != "claude-sonnet-4-latest (anthropic)" || usages[0].Tool != "Zed" {
+		t.Errorf("Expected a Zed claude-sonnet-4-latest usage, got %+v", usages[0])
+	}
+	if usages[0].Cost <= 0 {
+		t.Errorf("Expected the request to be priced, got $%f", usages[0].Cost)
+	}
+
+	// 2. Cache reads count toward prompt tokens and are priced at the cache rate
+	if usages[1].PromptTokens != 1050 || usages[1].CacheReadTokens != 1000 {
+		t.Errorf("Expected 1050 prompt tokens with 1000 cached, got %+v", usages[1])
+	}
+
+	// 3. Re-reading an unchanged database records nothing new
+	processZedDB(dbPath)
+	if got := len(tracker.Global.GetUsages()); got != 2 {
+		t.Errorf("Expected 2 usages after re-read, got %d", got)
+	}
+
+	// 4. When the thread grows, only the new request is recorded
+	writeZedFixture(t, dbPath, map[string][2]string{
+		"t1": {"2025-06-01T10:05:00Z", `{"model": {"provider": "anthropic", "model": "claude-sonnet-4-latest"},
+			"request_token_usage": [{}, {"input_tokens": 1000, "output_tokens": 200},
+				{}, {"input_tokens": 50, "output_tokens": 100, "cache_read_input_tokens": 1000},
+				{}, {"input_tokens": 70, "output_tokens": 30}]}`},
+	})
+	processZedDB(dbPath)
+	usages = tracker.Global.GetUsages()
+	if len(usages) != 3 || usages[2].CompletionTokens != 30 {
+		t.Errorf("Expected only the new request to be added, got %+v", usages)
+	}
+}
+
+func TestZedThreadWithOnlyCumulativeUsage(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "threads.db")
+	writeZedFixture(t, dbPath, map[string][2]string{
+		"old": {"2025-05-01T09:00:00Z", `{"model": null,
+			"cumulative_token_usage": {"input_tokens": 300, "output_tokens": 40}}`},
+	})
+
+	tracker.Global.Reset()
+	defer tracker.Global.Reset()
+	processedZedThreads = make(map[string]zedThreadProgress)
+
+	// 1. The running total is recorded as a single request
+	processZedDB(dbPath)
+	usages := tracker.Global.GetUsages()
+	if len(usages) != 1 || usages[0].PromptTokens != 300 || usages[0].Model != "zed-unknown" {
+		t.Errorf("Expected one zed-unknown usage of 300 prompt tokens, got %+v", usages)
+	}
+
+	// 2. When the total grows, only the growth is recorded
+	writeZedFixture(t, dbPath, map[string][2]string{
+		"old": {"2025-05-01T09:05:00Z", `{"model": null,
+			"cumulative_token_usage": {"input_tokens": 500, "output_tokens": 70}}`},
+	})
+	processZedDB(dbPath)
+	usages = tracker.Global.GetUsages()
+	if len(usages) != 2 || usages[1].PromptTokens != 200 || usages[1].CompletionTokens != 30 {
+		t.Errorf("Expected a second usage of 200 prompt and 30 completion tokens, got %+v", usages)
+	}
+}
+
+func TestZedDecodesZstdThreads(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "threads.db")
+	writeZedFixture(t, dbPath, nil)
+
+	encoder, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatalf("failed to create encoder: %v", err)
+	}
+	data := encoder.EncodeAll([]byte(`{"model": {"provider": "openai", "model": "gpt-4o"},
+		"request_token_usage": [{"input_tokens": 1000, "output_tokens": 100}]}`), nil)
+	encoder.Close()
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		t.Fatalf("failed to open fixture: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`INSERT INTO threads VALUES ('z1', 'summary', '2025-06-01T10:00:00Z', 'zstd', ?)`, data); err != nil {
+		t.Fatalf("failed to insert thread: %v", err)
+	}
+
+	tracker.Global.Reset()
+	defer tracker.Global.Reset()
+	processedZedThreads = make(map[string]zedThreadProgress)
+
+	processZedDB(dbPath)
+	usages := tracker.Global.GetUsages()
+	if len(usages) != 1 || usages[0].PromptTokens != 1000 || usages[0].Model != "gpt-4o (openai)" {
+		t.Errorf("Expected the compressed thread's gpt-4o request, got %+v", usages)
+	}
+}
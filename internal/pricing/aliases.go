@@ -0,0 +1,78 @@
+package pricing
+
+import (
+	"regexp"
+	"strings"
+)
+
+// modelAliases maps alternate spellings of a model to its key in ModelPricing.
+// Provider prefixes ("anthropic/", "gemini/", "openrouter/...") are stripped
+// before this lookup, so only the bare names need to be listed.
+var modelAliases = map[string]string{
+	// Anthropic: dotted vs dashed versions, "-latest" and dated snapshots
+	"claude-opus-4-5":          "claude-opus-4.5",
+	"claude-sonnet-4-5":        "claude-sonnet-4.5",
+	"claude-sonnet-4-0":        "claude-sonnet-4",
+	"claude-haiku-4-0":         "claude-haiku-4",
+	"claude-3-5-sonnet":        "claude-3-5-sonnet-20241022",
+	"claude-3.5-sonnet":        "claude-3-5-sonnet-20241022",
+	"claude-3-5-sonnet-latest": "claude-3-5-sonnet-20241022",
+	"claude-3-opus":            "claude-3-opus-20240229",
+	"claude-3-opus-latest":     "claude-3-opus-20240229",
+	"claude-3-sonnet":          "claude-3-sonnet-20240229",
+	"claude-3-haiku":           "claude-3-haiku-20240307",
+
+	// Gemini: "-latest" suffixes used by some clients, dated previews
+	"gemini-1.5-pro-latest":          "gemini-1.5-pro",
+	"gemini-1.5-flash-latest":        "gemini-1.5-flash",
+	"gemini-2.5-flash-preview-04-17": "gemini-2.5-flash-preview",
+	"gemini-2.5-flash-preview-05-20": "gemini-2.5-flash-preview",
+
+	// DeepSeek: the V3 model is served as deepseek-chat
+	"deepseek-v3": "deepseek-chat",
+}
+
+// versionSuffix matches snapshot suffixes like "-20250514" and "-latest"
+var versionSuffix = regexp.MustCompile(`-(\d{8}|latest)$`)
+
+// providerSuffix matches the " (provider)" parsers append to display names
+var providerSuffix = regexp.MustCompile(` \([^()]*\)$`)
+
+// Canonical returns the ModelPricing key for a model name as reported by a
+// tool, e.g. "anthropic/claude-sonnet-4" and "claude-sonnet-4-20250514" both
+// become "claude-sonnet-4". Display names such as "gpt-4o (openai)" resolve
+// too. A full ID that's priced itself is kept as is. Unknown names are
+// returned unchanged.
+func Canonical(model string) string {
+	pricesMu.RLock()
+	defer pricesMu.RUnlock()
+	return canonical(model)
+}
+
+// canonical is Canonical for callers already holding pricesMu
+func canonical(model string) string {
+	name := strings.ToLower(strings.TrimSpace(model))
+	name = providerSuffix.ReplaceAllString(name, "")
+	// An exact ID with its own price, such as an OpenRouter one, is kept
+	// rather than resolved to another provider's bare entry
+	if _, ok := ModelPricing[name]; ok {
+		return name
+	}
+	if target, ok := modelAliases[name]; ok {
+		return target
+	}
+
+	// Drop provider prefixes, keeping only the final path segment
+	bare := name[strings.LastIndex(name, "/")+1:]
+
+	for _, candidate := range []string{bare, versionSuffix.ReplaceAllString(bare, "")} {
+		if target, ok := modelAliases[candidate]; ok {
+			return target
+		}
+		if _, ok := ModelPricing[candidate]; ok {
+			return candidate
+		}
+	}
+
+	return model
+}
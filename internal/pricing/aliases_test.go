@@ -0,0 +1,102 @@
+package pricing
+
+import "testing"
+
+func TestCanonicalResolvesParserAliases(t *testing.T) {
+	tests := []struct {
+		model string
+		want  string
+	}{
+		// Aider / OpenRouter provider prefixes
+		{"anthropic/claude-sonnet-4", "claude-sonnet-4"},
+		{"openrouter/anthropic/claude-sonnet-4.5", "claude-sonnet-4.5"},
+		{"gemini/gemini-2.5-pro", "gemini-2.5-pro"},
+		{"deepseek/deepseek-chat", "deepseek-chat"},
+		{"anthropic/claude-3-5-sonnet", "claude-3-5-sonnet-20241022"},
+
+		// OpenCode / Crush dashed versions and dated snapshots
+		{"claude-sonnet-4-5", "claude-sonnet-4.5"},
+		{"claude-sonnet-4-20250514", "claude-sonnet-4"},
+		{"claude-sonnet-4-latest", "claude-sonnet-4"},
+		{"claude-opus-4-5-20251101", "claude-opus-4.5"},
+		{"claude-3-5-sonnet-latest", "claude-3-5-sonnet-20241022"},
+
+		// Display names with the provider appended, as stored in history
+		{"gpt-4o (openai)", "gpt-4o"},
+		{"claude-sonnet-4-5 (anthropic)", "claude-sonnet-4.5"},
+
+		// Already canonical, case-insensitive
+		{"gpt-4o", "gpt-4o"},
+		{"Claude-Sonnet-4.5", "claude-sonnet-4.5"},
+
+		// Unknown names pass through unchanged
+		{"mock/gpt-new", "mock/gpt-new"},
+		{"meta-llama/llama-3-8b:free", "meta-llama/llama-3-8b:free"},
+	}
+
+	for _, tt := range tests {
+		if got := Canonical(tt.model); got != tt.want {
+			t.Errorf("Canonical(%q) = %q, expected %q", tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestCanonicalKeepsExactPricedIDs(t *testing.T) {
+	const id = "openai/gpt-4o"
+	pricesMu.Lock()
+	ModelPricing[id] = ModelPrice{Input: 3, Output: 12}
+	pricesMu.Unlock()
+	defer func() {
+		pricesMu.Lock()
+		delete(ModelPricing, id)
+		pricesMu.Unlock()
+	}()
+
+	// 1. The exact ID keeps its own price, whatever its case
+	for _, model := range []string{id, "OpenAI/GPT-4o", "openai/gpt-4o (openrouter)"} {
+		if got := Canonical(model); got != id {
+			t.Errorf("Canonical(%q) = %q, expected %q", model, got, id)
+		}
+	}
+	if got, want := CalculateCost(id, 1_000_000, 0, 0), 3.0; got != want {
+		t.Errorf("Expected %s at its own $%.2f input price, got $%.2f", id, want, got)
+	}
+
+	// 2. Other prefixes still fall back to the bare entry
+	if got := Canonical("azure/gpt-4o"); got != "gpt-4o" {
+		t.Errorf("Expected azure/gpt-4o to resolve to gpt-4o, got %q", got)
+	}
+}
+
+func TestAliasesShareOnePrice(t *testing.T) {
+	want := CalculateCost("claude-sonnet-4.5", 1_000_000, 1_000_000, 0)
+	for _, alias := range []string{"anthropic/claude-sonnet-4.5", "claude-sonnet-4-5", "openrouter/anthropic/claude-sonnet-4-5"} {
+		if got := CalculateCost(alias, 1_000_000, 1_000_000, 0); got != want {
+			t.Errorf("Expected %s to cost $%.2f, got $%.2f", alias, want, got)
+		}
+	}
+
+	// Cache pricing follows the alias too
+	if got := CalculateCacheSavings("anthropic/claude-sonnet-4", 1_000_000); got != 2.70 {
+		t.Errorf("Expected $2.70 cache savings, got $%.2f", got)
+	}
+}
+
+func TestLocalModels(t *testing.T) {
+	for _, model := range []string{"ollama/llama3", "ollama_chat/qwen2.5-coder", "llama3.1:8b (ollama)", "Ollama/Mistral"} {
+		if !IsLocal(model) {
+			t.Errorf("Expected %s to be local", model)
+		}
+		if cost := CalculateCost(model, 1_000_000, 1_000_000, 0); cost != 0 {
+			t.Errorf("Expected %s to cost nothing per token, got $%.4f", model, cost)
+		}
+		if NeedsFallback(model) {
+			t.Errorf("Expected %s not to need the fallback price", model)
+		}
+	}
+	for _, model := range []string{"gpt-4o", "gpt-4o (openai)", "llama-3-70b"} {
+		if IsLocal(model) {
+			t.Errorf("Expected %s not to be local", model)
+		}
+	}
+}
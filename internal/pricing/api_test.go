@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestFetchPricing(t *testing.T) {
@@ -64,3 +66,261 @@ func TestFetchPricing(t *testing.T) {
 		t.Error("Hardcoded model 'gpt-4o' disappeared")
 	}
 }
+
+func TestFetchPricingRetriesTransientFailures(t *testing.T) {
+	// Fail twice with 503, then succeed
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, `{"data": [{"id": "mock/retry-model", "pricing": {"prompt": "0.000001", "completion": "0.000002"}}]}`)
+	}))
+	defer ts.Close()
+
+	originalURL := PricingAPIURL
+	originalBackoff := retryBackoff
+	PricingAPIURL = ts.URL
+	retryBackoff = time.Millisecond
+	lastFetchTime = time.Time{}
+	defer func() {
+		PricingAPIURL = originalURL
+		retryBackoff = originalBackoff
+	}()
+
+	if err := UpdatePricing(); err != nil {
+		t.Fatalf("UpdatePricing failed after retries: %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("Expected 3 attempts, got %d", attempts)
+	}
+	if LastFetchError() != nil {
+		t.Errorf("Expected no last error after success, got %v", LastFetchError())
+	}
+	if _, ok := ModelPricing["mock/retry-model"]; !ok {
+		t.Error("Model from retried fetch not found in pricing map")
+	}
+}
+
+func TestFetchPricingRecordsLastError(t *testing.T) {
+	// Client errors are not retried and are surfaced via LastFetchError
+	attempts := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	originalURL := PricingAPIURL
+	PricingAPIURL = ts.URL
+	lastFetchTime = time.Time{}
+	defer func() { PricingAPIURL = originalURL }()
+
+	if err := UpdatePricing(); err == nil {
+		t.Fatal("Expected UpdatePricing to fail on 404")
+	}
+	if attempts != 1 {
+		t.Errorf("Expected 1 attempt for non-retryable status, got %d", attempts)
+	}
+	if LastFetchError() == nil {
+		t.Error("Expected LastFetchError to be set")
+	}
+}
+
+func TestFetchPricingSendsBearerToken(t *testing.T) {
+	// A private gateway that rejects requests without the token
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintln(w, `{"data": [{"id": "gateway/team-model", "pricing": {"prompt": "0.000003", "completion": "0.000004"}}]}`)
+	}))
+	defer ts.Close()
+
+	originalURL := PricingAPIURL
+	PricingAPIURL = ts.URL
+	lastFetchTime = time.Time{}
+	defer func() {
+		PricingAPIURL = originalURL
+		PricingAPIToken = ""
+	}()
+
+	// 1. Without the token the gateway refuses
+	if err := UpdatePricing(); err == nil {
+		t.Fatal("Expected UpdatePricing to fail without a token")
+	}
+
+	// 2. With it, the gateway's models are priced
+	PricingAPIToken = "secret"
+	if err := UpdatePricing(); err != nil {
+		t.Fatalf("UpdatePricing failed with a token: %v", err)
+	}
+	p, ok := ModelPricing["gateway/team-model"]
+	if !ok {
+		t.Fatal("Model from the gateway not found in pricing map")
+	}
+	if p.Input != 3.0 || p.Output != 4.0 {
+		t.Errorf("Expected 3.0/4.0, got %f/%f", p.Input, p.Output)
+	}
+}
+
+func TestOfflineSkipsFetch(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer ts.Close()
+
+	originalURL := PricingAPIURL
+	PricingAPIURL = ts.URL
+	lastFetchTime = time.Time{}
+	Offline = true
+	defer func() {
+		PricingAPIURL = originalURL
+		Offline = false
+	}()
+
+	if err := UpdatePricing(); err != nil {
+		t.Fatalf("Expected no error offline, got %v", err)
+	}
+	if requests != 0 {
+		t.Errorf("Expected no pricing requests offline, got %d", requests)
+	}
+	if !GetStatus().Offline {
+		t.Error("Expected the status to report offline mode")
+	}
+}
+
+func TestCacheSavings(t *testing.T) {
+	// claude-sonnet-4.5: $3.00/M input, $0.30/M cache read
+	savings := CalculateCacheSavings("claude-sonnet-4.5", 1_000_000)
+	if diff := savings - 2.70; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("Expected $2.70 saved on 1M cached tokens, got %f", savings)
+	}
+
+	// Cost with cache = full price minus savings
+	full := CalculateCost("claude-sonnet-4.5", 1_500_000, 0, 0)
+	cached := CalculateCostWithCache("claude-sonnet-4.5", 500_000, 1_000_000, 0, 0)
+	if diff := (full - cached) - savings; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("Expected cached cost %f to be %f less than full %f", cached, savings, full)
+	}
+
+	// Models without cache pricing save nothing
+	if s := CalculateCacheSavings("llama-3.1-70b", 1_000_000); s != 0 {
+		t.Errorf("Expected no savings without cache pricing, got %f", s)
+	}
+}
+
+func TestReasoningPricing(t *testing.T) {
+	// o3-mini bills reasoning as output ($4.40/M)
+	if got := CalculateCost("o3-mini", 0, 0, 1_000_000); got != 4.40 {
+		t.Errorf("Expected reasoning billed as output ($4.40), got $%.2f", got)
+	}
+	if a, b := CalculateCost("o3-mini", 0, 1_000_000, 0), CalculateCost("o3-mini", 0, 0, 1_000_000); a != b {
+		t.Errorf("Expected reasoning and output to cost the same, got $%.2f vs $%.2f", a, b)
+	}
+
+	// Gemini 2.5 Flash preview bills thinking at $3.50/M vs $0.60/M output
+	got := CalculateCost("gemini-2.5-flash-preview-05-20", 0, 1_000_000, 1_000_000)
+	if diff := got - 4.10; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("Expected $0.60 output + $3.50 reasoning = $4.10, got $%.2f", got)
+	}
+
+	// Recorded completion tokens include the reasoning, which keeps its rate
+	recorded := CostFromTokens("gemini-2.5-flash-preview-05-20", 0, 0, 2_000_000, 1_000_000)
+	if diff := recorded - 4.10; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("Expected recorded tokens to cost $4.10 too, got $%.2f", recorded)
+	}
+}
+
+func TestConfigurableFallback(t *testing.T) {
+	defer func(fallback string) { FallbackModel = fallback }(FallbackModel)
+
+	// 1. Unknown models are priced at the default fallback's rates
+	if got, want := CalculateCost("mystery-model", 1_000_000, 0, 0), CalculateCost(DefaultFallbackModel, 1_000_000, 0, 0); got != want {
+		t.Errorf("Expected the default fallback's $%.2f, got $%.2f", want, got)
+	}
+
+	// 2. A configured fallback replaces it
+	FallbackModel = "gpt-4o"
+	if got := CalculateCost("mystery-model", 1_000_000, 0, 0); got != 2.50 {
+		t.Errorf("Expected gpt-4o's $2.50 input rate, got $%.2f", got)
+	}
+
+	// 3. A fallback without a price of its own uses the default
+	FallbackModel = "also-unknown"
+	if got, want := CalculateCost("mystery-model", 1_000_000, 0, 0), CalculateCost(DefaultFallbackModel, 1_000_000, 0, 0); got != want {
+		t.Errorf("Expected the default fallback's $%.2f, got $%.2f", want, got)
+	}
+}
+
+func TestUnknownCostMode(t *testing.T) {
+	defer func(fallback string) { FallbackModel = fallback }(FallbackModel)
+	FallbackModel = ""
+
+	// 1. Unknown models cost nothing and are reported as needing a fallback
+	if got := CalculateCost("mystery-model", 1_000_000, 1_000_000, 0); got != 0 {
+		t.Errorf("Expected $0 for an unpriced model, got $%.2f", got)
+	}
+	if !NeedsFallback("mystery-model") {
+		t.Error("Expected mystery-model to need a fallback")
+	}
+
+	// 2. Known and free models are unaffected
+	if got := CalculateCost("gpt-4o", 1_000_000, 0, 0); got != 2.50 {
+		t.Errorf("Expected gpt-4o at $2.50, got $%.2f", got)
+	}
+	if NeedsFallback("gpt-4o (openai)") || NeedsFallback("meta-llama/llama-3-8b:free") {
+		t.Error("Expected known and free models not to need a fallback")
+	}
+}
+
+func TestCalculateCostDuringFetch(t *testing.T) {
+	// Every fetch adds new models, so the map grows while it's read
+	var fetches int
+	var mu sync.Mutex
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		fetches++
+		n := fetches
+		mu.Unlock()
+		fmt.Fprintf(w, `{"data": [{"id": "mock/race-%d", "pricing": {"prompt": "0.000001", "completion": "0.000002", "input_cache_read": "0.0000001"}}]}`, n)
+	}))
+	defer ts.Close()
+
+	defer func(url string) { PricingAPIURL = url }(PricingAPIURL)
+	PricingAPIURL = ts.URL
+	defer func(file string) { CacheFile = file }(CacheFile)
+	CacheFile = ""
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range 20 {
+			statusMu.Lock()
+			lastFetchTime = time.Time{}
+			statusMu.Unlock()
+			if err := UpdatePricing(); err != nil {
+				t.Errorf("UpdatePricing failed: %v", err)
+				return
+			}
+		}
+	}()
+
+	// Run with -race: reads overlapping the fetches' writes are reported
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		CalculateCost("gpt-4o", 1000, 500, 0)
+		CalculateCostWithCache("mock/race-1", 1000, 500, 100, 0)
+		CalculateHypotheticalCost("race", 1000, 500)
+		Canonical("anthropic/claude-sonnet-4")
+		GetAvailableModels()
+	}
+}
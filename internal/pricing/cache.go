@@ -0,0 +1,141 @@
+package pricing
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bangarangler/burnrate/internal/log"
+)
+
+// CacheFile is where fetched pricing is persisted so an offline start can
+// still use the last known API prices. Empty disables the disk cache.
+var CacheFile string
+
+// Pricing sources reported by GetStatus
+const (
+	SourceAPI      = "api"      // Fetched from the pricing API this run
+	SourceCache    = "cache"    // Loaded from CacheFile after a failed fetch
+	SourceDefaults = "defaults" // Only the built-in ModelPricing table
+)
+
+// Status describes where the current prices came from and why they might be stale
+type Status struct {
+	Source    string    // SourceAPI, SourceCache, or SourceDefaults
+	UpdatedAt time.Time // When the prices in use were fetched (zero for defaults)
+	LastError error     // Error from the most recent failed fetch, if any
+	Offline   bool      // The API is disabled (see Offline), so nothing is stale
+}
+
+var (
+	cacheSource = SourceDefaults
+	cacheTime   time.Time
+)
+
+type pricingCache struct {
+	FetchedAt time.Time                `json:"fetched_at"`
+	Models    map[string]cachedPricing `json:"models"`
+}
+
+type cachedPricing struct {
+	Input     float64 `json:"input"`
+	Output    float64 `json:"output"`
+	CacheRead float64 `json:"cache_read,omitempty"`
+	Reasoning float64 `json:"reasoning,omitempty"`
+	Provider  string  `json:"provider"`
+}
+
+// GetStatus returns the current pricing source and freshness
+func GetStatus() Status {
+	statusMu.RLock()
+	defer statusMu.RUnlock()
+
+	status := Status{
+		Source:    cacheSource,
+		LastError: lastFetchError,
+		Offline:   Offline,
+	}
+	switch cacheSource {
+	case SourceAPI:
+		status.UpdatedAt = lastFetchTime
+	case SourceCache:
+		status.UpdatedAt = cacheTime
+	}
+	return status
+}
+
+// saveCache writes the fetched models to CacheFile. Failures are ignored since
+// the cache is only a fallback.
+func saveCache(models map[string]cachedPricing, fetchedAt time.Time) {
+	if CacheFile == "" {
+		return
+	}
+
+	data, err := json.Marshal(pricingCache{FetchedAt: fetchedAt, Models: models})
+	if err != nil {
+		log.Debugf("pricing: cannot encode cache: %v", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(CacheFile), 0755); err != nil {
+		log.Debugf("pricing: cannot create cache directory: %v", err)
+		return
+	}
+	if err := os.WriteFile(CacheFile, data, 0644); err != nil {
+		log.Debugf("pricing: cannot write cache %s: %v", CacheFile, err)
+	}
+}
+
+// LoadCache uses the prices last saved to CacheFile without fetching, for
+// commands that print once and shouldn't wait on the network. Prices already
+// fetched or loaded this run are kept. Reports whether the cache was read.
+func LoadCache() bool {
+	fetchMutex.Lock()
+	defer fetchMutex.Unlock()
+
+	statusMu.RLock()
+	source := cacheSource
+	statusMu.RUnlock()
+	return source == SourceDefaults && loadCache()
+}
+
+// loadCache merges CacheFile into ModelPricing. Only used when no fetch has
+// succeeded this run, so fresher API data is never overwritten.
+// Caller must hold fetchMutex.
+func loadCache() bool {
+	if CacheFile == "" {
+		return false
+	}
+
+	data, err := os.ReadFile(CacheFile)
+	if err != nil {
+		return false
+	}
+
+	var cache pricingCache
+	if err := json.Unmarshal(data, &cache); err != nil || len(cache.Models) == 0 {
+		log.Warnf("pricing: ignoring unreadable cache %s", CacheFile)
+		return false
+	}
+
+	pricesMu.Lock()
+	for id, p := range cache.Models {
+		ModelPricing[id] = ModelPrice{
+			Input:     p.Input,
+			Output:    p.Output,
+			Provider:  p.Provider,
+			Reasoning: p.Reasoning,
+		}
+		if p.CacheRead > 0 {
+			CacheReadPricing[id] = p.CacheRead
+		}
+	}
+	pricesMu.Unlock()
+	invalidateFuzzyIndex()
+
+	statusMu.Lock()
+	cacheSource = SourceCache
+	cacheTime = cache.FetchedAt
+	statusMu.Unlock()
+	return true
+}
@@ -0,0 +1,66 @@
+package pricing
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Family groups related models under one display name, such as every Claude
+// Sonnet release as "Claude Sonnet". Grouping is for display only; history
+// keeps each model's own name.
+type Family struct {
+	Name    string
+	Pattern *regexp.Regexp // Matched against the lowercased model name
+}
+
+// ModelFamilies are tried in order; the first match names a model's family.
+// More specific patterns come first (gpt-4o-mini before gpt-4o).
+var ModelFamilies = []Family{
+	{"Claude Opus", regexp.MustCompile(`claude.*opus`)},
+	{"Claude Sonnet", regexp.MustCompile(`claude.*sonnet`)},
+	{"Claude Haiku", regexp.MustCompile(`claude.*haiku`)},
+	{"GPT-4o mini", regexp.MustCompile(`gpt-4o-mini`)},
+	{"GPT-4o", regexp.MustCompile(`gpt-4o`)},
+	{"GPT-4.1", regexp.MustCompile(`gpt-4\.1`)},
+	{"GPT-5", regexp.MustCompile(`gpt-5`)},
+	{"Gemini Pro", regexp.MustCompile(`gemini.*pro`)},
+	{"Gemini Flash", regexp.MustCompile(`gemini.*flash`)},
+	{"DeepSeek", regexp.MustCompile(`deepseek`)},
+	{"Llama", regexp.MustCompile(`llama`)},
+}
+
+// FamilyOf returns the family a model belongs to, or the model itself when
+// no family matches
+func FamilyOf(model string) string {
+	name := strings.ToLower(strings.TrimSpace(model))
+	for _, family := range ModelFamilies {
+		if family.Pattern.MatchString(name) {
+			return family.Name
+		}
+	}
+	return model
+}
+
+// ParseFamilies parses a comma-separated list of name=pattern pairs such as
+// "Claude=^claude,Mistral=mistral|codestral", where each pattern is a regular
+// expression matched against lowercased model names
+func ParseFamilies(list string) ([]Family, error) {
+	var families []Family
+	for _, entry := range strings.Split(list, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		name, pattern, ok := strings.Cut(entry, "=")
+		name, pattern = strings.TrimSpace(name), strings.TrimSpace(pattern)
+		if !ok || name == "" || pattern == "" {
+			return nil, fmt.Errorf("model family %q is not name=pattern", entry)
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("model family %s: %w", name, err)
+		}
+		families = append(families, Family{Name: name, Pattern: re})
+	}
+	return families, nil
+}
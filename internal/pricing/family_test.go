@@ -0,0 +1,49 @@
+package pricing
+
+import "testing"
+
+func TestFamilyOfGroupsReleases(t *testing.T) {
+	tests := []struct {
+		model string
+		want  string
+	}{
+		{"claude-3-5-sonnet-20241022", "Claude Sonnet"},
+		{"claude-3-5-sonnet-latest", "Claude Sonnet"},
+		{"claude-sonnet-4.5 (anthropic)", "Claude Sonnet"},
+		{"anthropic/claude-opus-4-5", "Claude Opus"},
+		{"gpt-4o-mini", "GPT-4o mini"},
+		{"gpt-4o (openai)", "GPT-4o"},
+		{"gemini-2.5-flash-preview", "Gemini Flash"},
+		{"my-finetune", "my-finetune"},
+	}
+	for _, tt := range tests {
+		if got := FamilyOf(tt.model); got != tt.want {
+			t.Errorf("FamilyOf(%q) = %q, want %q", tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestParseFamilies(t *testing.T) {
+	// 1. Pairs parse in order, with patterns compiled
+	families, err := ParseFamilies(" Mistral = mistral|codestral, Claude=^claude,")
+	if err != nil {
+		t.Fatalf("ParseFamilies failed: %v", err)
+	}
+	if len(families) != 2 || families[0].Name != "Mistral" || !families[0].Pattern.MatchString("codestral-latest") {
+		t.Errorf("Expected Mistral then Claude, got %+v", families)
+	}
+
+	// 2. Custom families take precedence when placed first
+	defer func(saved []Family) { ModelFamilies = saved }(ModelFamilies)
+	ModelFamilies = append(families, ModelFamilies...)
+	if got := FamilyOf("claude-3-5-sonnet-20241022"); got != "Claude" {
+		t.Errorf("Expected the custom Claude family, got %q", got)
+	}
+
+	// 3. Malformed entries are errors
+	for _, bad := range []string{"no-pattern", "=x", "Bad=("} {
+		if _, err := ParseFamilies(bad); err == nil {
+			t.Errorf("Expected an error for %q", bad)
+		}
+	}
+}
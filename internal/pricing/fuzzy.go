@@ -0,0 +1,91 @@
+package pricing
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// fuzzyIndex finds a price for a name lookupPricing doesn't know, for
+// what-if comparisons, without lowercasing every ModelPricing key on each
+// miss: a name that matches a key ignoring case resolves to that key, or
+// else to the shortest key containing it
+type fuzzyIndex struct {
+	folded  map[string]string // Lowercased key -> key
+	keys    []string          // Lowercased keys, shortest first, then alphabetical
+	matches map[string]string // Lowercased name -> key it contains, or "" for none
+	size    int               // len(ModelPricing) when built
+}
+
+var (
+	fuzzyMu sync.Mutex
+	fuzzy   *fuzzyIndex // Nil until the first miss, and again after prices change
+)
+
+// invalidateFuzzyIndex drops the index, to be rebuilt from ModelPricing on
+// the next miss. Call it whenever prices are added.
+func invalidateFuzzyIndex() {
+	fuzzyMu.Lock()
+	fuzzy = nil
+	fuzzyMu.Unlock()
+}
+
+// buildFuzzyIndex indexes ModelPricing's keys. Caller must hold pricesMu.
+func buildFuzzyIndex() *fuzzyIndex {
+	idx := &fuzzyIndex{
+		folded:  make(map[string]string, len(ModelPricing)),
+		keys:    make([]string, 0, len(ModelPricing)),
+		matches: make(map[string]string),
+		size:    len(ModelPricing),
+	}
+	for k := range ModelPricing {
+		lower := strings.ToLower(k)
+		prev, seen := idx.folded[lower]
+		if !seen {
+			idx.keys = append(idx.keys, lower)
+		}
+		// Of keys differing only in case, keep the same one every build
+		if !seen || k < prev {
+			idx.folded[lower] = k
+		}
+	}
+	sort.Slice(idx.keys, func(i, j int) bool {
+		if len(idx.keys[i]) != len(idx.keys[j]) {
+			return len(idx.keys[i]) < len(idx.keys[j])
+		}
+		return idx.keys[i] < idx.keys[j]
+	})
+	return idx
+}
+
+// fuzzyMatch returns the ModelPricing key a model name matches (see
+// fuzzyIndex). Substring matches are remembered, so a name is only searched
+// for once per set of prices.
+func fuzzyMatch(model string) (string, bool) {
+	fuzzyMu.Lock()
+	defer fuzzyMu.Unlock()
+
+	// Rebuild after prices change, including writes that skipped
+	// invalidateFuzzyIndex
+	pricesMu.RLock()
+	if fuzzy == nil || fuzzy.size != len(ModelPricing) {
+		fuzzy = buildFuzzyIndex()
+	}
+	pricesMu.RUnlock()
+
+	name := strings.ToLower(model)
+	if key, ok := fuzzy.folded[name]; ok {
+		return key, true
+	}
+	key, ok := fuzzy.matches[name]
+	if !ok {
+		for _, lower := range fuzzy.keys {
+			if strings.Contains(lower, name) {
+				key = fuzzy.folded[lower]
+				break
+			}
+		}
+		fuzzy.matches[name] = key
+	}
+	return key, key != ""
+}
@@ -0,0 +1,84 @@
+package pricing
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestHypotheticalCostResolvesAliases(t *testing.T) {
+	price := func(model string) float64 {
+		p := ModelPricing[model]
+		return (p.Input + p.Output) * providerMultiplier(p.Provider)
+	}
+	tests := []struct {
+		model string
+		want  string // ModelPricing key it should be priced as
+	}{
+		// 1. Known names and their aliases
+		{"gpt-4o", "gpt-4o"},
+		{"anthropic/claude-sonnet-4", "claude-sonnet-4"},
+		{"claude-3-5-sonnet", "claude-3-5-sonnet-20241022"},
+		{"claude-sonnet-4-5-20250929", "claude-sonnet-4.5"},
+
+		// 2. Any case
+		{"GPT-4O-MINI", "gpt-4o-mini"},
+
+		// 3. Part of a name: the shortest key containing it
+		{"3-opus", "claude-3-opus-20240229"},
+		{"4o-mi", "gpt-4o-mini"},
+	}
+	for _, tt := range tests {
+		got, err := CalculateHypotheticalCost(tt.model, 1_000_000, 1_000_000)
+		if err != nil {
+			t.Errorf("Expected %s to be priced as %s, got %v", tt.model, tt.want, err)
+			continue
+		}
+		if want := price(tt.want); got != want {
+			t.Errorf("Expected %s to be priced as %s ($%.2f), got $%.2f", tt.model, tt.want, want, got)
+		}
+	}
+
+	// 4. Unknown names are an error
+	if _, err := CalculateHypotheticalCost("no-such-model", 1000, 1000); err == nil {
+		t.Errorf("Expected an error for an unknown model")
+	}
+}
+
+func TestFuzzyIndexFollowsNewPrices(t *testing.T) {
+	const model = "acme/Widget-XL"
+	defer delete(ModelPricing, model)
+
+	// 1. A miss is remembered as a miss
+	if _, ok := fuzzyMatch("widget-x"); ok {
+		t.Fatalf("Expected no match before the price exists")
+	}
+
+	// 2. Once prices change, the index is rebuilt and finds the new key
+	ModelPricing[model] = ModelPrice{Input: 1, Output: 2}
+	invalidateFuzzyIndex()
+	if key, ok := fuzzyMatch("widget-x"); !ok || key != model {
+		t.Errorf("Expected %s, got %q", model, key)
+	}
+	if key, ok := fuzzyMatch("ACME/WIDGET-XL"); !ok || key != model {
+		t.Errorf("Expected a case-insensitive match to %s, got %q", model, key)
+	}
+}
+
+func BenchmarkHypotheticalCostMiss(b *testing.B) {
+	// Hundreds of models, as after an API fetch
+	for i := range 500 {
+		ModelPricing[fmt.Sprintf("vendor%d/model-%d", i%20, i)] = ModelPrice{Input: 1, Output: 2}
+	}
+	defer func() {
+		for i := range 500 {
+			delete(ModelPricing, fmt.Sprintf("vendor%d/model-%d", i%20, i))
+		}
+		invalidateFuzzyIndex()
+	}()
+	invalidateFuzzyIndex()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		CalculateHypotheticalCost("model-499", 1_000_000, 1_000_000)
+	}
+}
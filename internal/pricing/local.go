@@ -0,0 +1,22 @@
+package pricing
+
+import "strings"
+
+// LocalProviders name the providers that serve models locally, such as
+// Ollama. Their models cost nothing per token; what they do cost (power, GPU
+// time) is attributed by the tracker from session time instead.
+var LocalProviders = []string{"ollama", "ollama_chat"}
+
+// IsLocal reports whether a model is served by a local provider, named
+// either as a prefix ("ollama/llama3", as Aider reports it) or as the
+// display suffix parsers append ("llama3.1:8b (ollama)")
+func IsLocal(model string) bool {
+	name := strings.ToLower(strings.TrimSpace(model))
+	for _, provider := range LocalProviders {
+		provider = strings.ToLower(provider)
+		if strings.HasPrefix(name, provider+"/") || strings.HasSuffix(name, " ("+provider+")") {
+			return true
+		}
+	}
+	return false
+}
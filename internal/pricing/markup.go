@@ -0,0 +1,22 @@
+package pricing
+
+import "strings"
+
+// ProviderMultipliers scale a provider's list prices to what is actually
+// paid, e.g. {"anthropic": 0.8} for a negotiated 20% discount or
+// {"openai": 1.15} for a reseller's markup. Keys are lowercase provider
+// names; providers without an entry pay list price.
+var ProviderMultipliers = map[string]float64{}
+
+// providerMultiplier returns the multiplier for a ModelPrice's provider. A
+// key matches the whole name or its first word, so "google" covers both the
+// API's "google" and the built-in "Google Gemini".
+func providerMultiplier(provider string) float64 {
+	name := strings.ToLower(provider)
+	for key, multiplier := range ProviderMultipliers {
+		if name == key || strings.HasPrefix(name, key+" ") {
+			return multiplier
+		}
+	}
+	return 1.0
+}
@@ -0,0 +1,46 @@
+package pricing
+
+import (
+	"math"
+	"testing"
+)
+
+func TestProviderMultipliersScaleCost(t *testing.T) {
+	defer func(saved map[string]float64) { ProviderMultipliers = saved }(ProviderMultipliers)
+
+	listSonnet := CalculateCost("claude-sonnet-4", 1_000_000, 100_000, 0)
+	listGPT := CalculateCost("gpt-4o", 1_000_000, 100_000, 0)
+	listGemini := CalculateCost("gemini-2.5-pro", 1_000_000, 100_000, 0)
+	listSavings := CalculateCacheSavings("claude-sonnet-4", 500_000)
+	listWhatIf, _ := CalculateHypotheticalCost("claude-sonnet-4", 1_000_000, 100_000)
+	listFallback := CalculateCost("mystery-model", 1_000_000, 0, 0)
+
+	ProviderMultipliers = map[string]float64{"anthropic": 0.8, "openai": 1.15, "google": 0.5}
+
+	// 1. Each provider's cost scales by its multiplier, including "google"
+	// matching the built-in "Google Gemini"
+	cases := []struct {
+		name      string
+		got, want float64
+	}{
+		{"claude-sonnet-4", CalculateCost("claude-sonnet-4", 1_000_000, 100_000, 0), listSonnet * 0.8},
+		{"gpt-4o", CalculateCost("gpt-4o", 1_000_000, 100_000, 0), listGPT * 1.15},
+		{"gemini-2.5-pro", CalculateCost("gemini-2.5-pro", 1_000_000, 100_000, 0), listGemini * 0.5},
+		{"cache savings", CalculateCacheSavings("claude-sonnet-4", 500_000), listSavings * 0.8},
+	}
+	for _, c := range cases {
+		if math.Abs(c.got-c.want) > 1e-9 {
+			t.Errorf("Expected %s to cost $%.4f, got $%.4f", c.name, c.want, c.got)
+		}
+	}
+
+	// 2. What-if comparisons use the contract price too
+	if whatIf, _ := CalculateHypotheticalCost("claude-sonnet-4", 1_000_000, 100_000); math.Abs(whatIf-listWhatIf*0.8) > 1e-9 {
+		t.Errorf("Expected what-if $%.4f, got $%.4f", listWhatIf*0.8, whatIf)
+	}
+
+	// 3. Fallback estimates for unknown models stay at list price
+	if got := CalculateCost("mystery-model", 1_000_000, 0, 0); got != listFallback {
+		t.Errorf("Expected the fallback estimate at list price $%.4f, got $%.4f", listFallback, got)
+	}
+}
@@ -0,0 +1,28 @@
+package pricing
+
+import "math"
+
+// Micros is an amount in whole micro-dollars (millionths of a dollar).
+// Costs are summed as Micros so that totals of many tiny per-event costs
+// are exact, and only converted to dollars for display.
+type Micros int64
+
+// ToMicros rounds a dollar amount to the nearest micro-dollar
+func ToMicros(usd float64) Micros {
+	return Micros(math.Round(usd * 1e6))
+}
+
+// USD converts back to dollars
+func (m Micros) USD() float64 {
+	return float64(m) / 1e6
+}
+
+// AddUSD adds dollar amounts in micro-dollars, so running totals kept as
+// float64 don't drift however many amounts are added
+func AddUSD(amounts ...float64) float64 {
+	var sum Micros
+	for _, usd := range amounts {
+		sum += ToMicros(usd)
+	}
+	return sum.USD()
+}
@@ -2,6 +2,7 @@
 package pricing
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -9,82 +10,142 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/bangarangler/burnrate/internal/log"
 )
 
-// Prices per 1M tokens (input / output) - latest as of Dec 2025
-var ModelPricing = map[string]struct {
-	Input    float64
-	Output   float64
-	Provider string // For display
-}{
+// ModelPrice is a model's price per 1M tokens
+type ModelPrice struct {
+	Input     float64
+	Output    float64
+	Provider  string  // For display
+	Reasoning float64 // Thinking tokens; 0 means they're billed as output
+}
+
+// DefaultsAsOf is when the built-in prices below were last checked
+const DefaultsAsOf = "Dec 2025"
+
+// Prices per 1M tokens (input / output / provider / reasoning) - latest as of DefaultsAsOf.
+// Keys are canonical names; see Canonical for the aliases that map onto them.
+var ModelPricing = map[string]ModelPrice{
 	// OpenAI
-	"gpt-5":         {2.00, 10.00, "OpenAI"},
-	"gpt-5.2":       {1.75, 14.00, "OpenAI"},
-	"gpt-4o":        {2.50, 10.00, "OpenAI"},
-	"gpt-4o-mini":   {0.15, 0.60, "OpenAI"},
-	"gpt-4-turbo":   {10.00, 30.00, "OpenAI"},
-	"gpt-4":         {30.00, 60.00, "OpenAI"},
-	"gpt-3.5-turbo": {0.50, 1.50, "OpenAI"},
-	"o1":            {15.00, 60.00, "OpenAI"},
-	"o1-preview":    {15.00, 60.00, "OpenAI"},
-	"o1-mini":       {3.00, 12.00, "OpenAI"},
-	"o3-mini":       {1.10, 4.40, "OpenAI"},
-
-	// Anthropic Claude (various naming conventions)
-	"claude-opus-4.5":             {5.00, 25.00, "Anthropic"},
-	"claude-sonnet-4.5":           {3.00, 15.00, "Anthropic"},
-	"claude-haiku-4":              {0.25, 1.25, "Anthropic"},
-	"claude-3-5-sonnet-20241022":  {3.00, 15.00, "Anthropic"},
-	"claude-3-5-sonnet-latest":    {3.00, 15.00, "Anthropic"},
-	"claude-3-opus-20240229":      {15.00, 75.00, "Anthropic"},
-	"claude-3-sonnet-20240229":    {3.00, 15.00, "Anthropic"},
-	"claude-3-haiku-20240307":     {0.25, 1.25, "Anthropic"},
-	"anthropic/claude-3-5-sonnet": {3.00, 15.00, "Anthropic"},
-	"anthropic/claude-sonnet-4":   {3.00, 15.00, "Anthropic"},
+	"gpt-5":         {2.00, 10.00, "OpenAI", 0},
+	"gpt-5.2":       {1.75, 14.00, "OpenAI", 0},
+	"gpt-4o":        {2.50, 10.00, "OpenAI", 0},
+	"gpt-4o-mini":   {0.15, 0.60, "OpenAI", 0},
+	"gpt-4-turbo":   {10.00, 30.00, "OpenAI", 0},
+	"gpt-4":         {30.00, 60.00, "OpenAI", 0},
+	"gpt-3.5-turbo": {0.50, 1.50, "OpenAI", 0},
+	"o1":            {15.00, 60.00, "OpenAI", 0},
+	"o1-preview":    {15.00, 60.00, "OpenAI", 0},
+	"o1-mini":       {3.00, 12.00, "OpenAI", 0},
+	"o3-mini":       {1.10, 4.40, "OpenAI", 0},
+
+	// Anthropic Claude
+	"claude-opus-4.5":            {5.00, 25.00, "Anthropic", 0},
+	"claude-sonnet-4.5":          {3.00, 15.00, "Anthropic", 0},
+	"claude-sonnet-4":            {3.00, 15.00, "Anthropic", 0},
+	"claude-haiku-4":             {0.25, 1.25, "Anthropic", 0},
+	"claude-3-5-sonnet-20241022": {3.00, 15.00, "Anthropic", 0},
+	"claude-3-opus-20240229":     {15.00, 75.00, "Anthropic", 0},
+	"claude-3-sonnet-20240229":   {3.00, 15.00, "Anthropic", 0},
+	"claude-3-haiku-20240307":    {0.25, 1.25, "Anthropic", 0},
 
 	// Groq (very low cost, uses OpenAI format)
-	"llama-3.1-405b": {0.59, 0.79, "Groq"},
-	"llama-3.1-70b":  {0.59, 0.79, "Groq"},
-	"mixtral-8x22b":  {0.27, 0.27, "Groq"},
+	"llama-3.1-405b": {0.59, 0.79, "Groq", 0},
+	"llama-3.1-70b":  {0.59, 0.79, "Groq", 0},
+	"mixtral-8x22b":  {0.27, 0.27, "Groq", 0},
 
 	// xAI Grok
-	"grok-4.1": {0.20, 0.50, "xAI Grok"},
-	"grok-4":   {6.00, 30.00, "xAI Grok"},
-
-	// Gemini (Google) - various naming conventions from Aider
-	"gemini-2.5-pro":          {4.00, 20.00, "Google Gemini"},
-	"gemini-2.5-flash":        {0.30, 2.50, "Google Gemini"},
-	"gemini/gemini-2.5-pro":   {4.00, 20.00, "Google Gemini"},
-	"gemini/gemini-2.5-flash": {0.30, 2.50, "Google Gemini"},
-	"gemini-1.5-pro":          {3.50, 10.50, "Google Gemini"},
-	"gemini-1.5-flash":        {0.075, 0.30, "Google Gemini"},
-	"gemini/gemini-1.5-pro":   {3.50, 10.50, "Google Gemini"},
-	"gemini/gemini-1.5-flash": {0.075, 0.30, "Google Gemini"},
+	"grok-4.1": {0.20, 0.50, "xAI Grok", 0},
+	"grok-4":   {6.00, 30.00, "xAI Grok", 0},
+
+	// Gemini (Google)
+	"gemini-2.5-pro":           {4.00, 20.00, "Google Gemini", 0},
+	"gemini-2.5-flash":         {0.30, 2.50, "Google Gemini", 0},
+	"gemini-2.5-flash-preview": {0.15, 0.60, "Google Gemini", 3.50}, // Thinking billed separately
+	"gemini-1.5-pro":           {3.50, 10.50, "Google Gemini", 0},
+	"gemini-1.5-flash":         {0.075, 0.30, "Google Gemini", 0},
 
 	// DeepSeek
-	"deepseek-chat":          {0.14, 0.28, "DeepSeek"},
-	"deepseek-coder":         {0.14, 0.28, "DeepSeek"},
-	"deepseek/deepseek-chat": {0.14, 0.28, "DeepSeek"},
+	"deepseek-chat":  {0.14, 0.28, "DeepSeek", 0},
+	"deepseek-coder": {0.14, 0.28, "DeepSeek", 0},
 
 	// Azure OpenAI / Copilot (same as OpenAI pricing)
 	// Just use the same model names as OpenAI
 }
 
-// PricingAPIURL is the endpoint for fetching model pricing
+// CacheReadPricing holds the discounted per-1M-token price for cached input
+// reads. Models not listed here bill cache reads at the full input rate.
+var CacheReadPricing = map[string]float64{
+	// OpenAI
+	"gpt-5":       0.20,
+	"gpt-5.2":     0.175,
+	"gpt-4o":      1.25,
+	"gpt-4o-mini": 0.075,
+	"o1":          7.50,
+	"o1-mini":     1.50,
+	"o3-mini":     0.55,
+
+	// Anthropic (cache reads are 10% of input)
+	"claude-opus-4.5":            0.50,
+	"claude-sonnet-4.5":          0.30,
+	"claude-sonnet-4":            0.30,
+	"claude-haiku-4":             0.025,
+	"claude-3-5-sonnet-20241022": 0.30,
+	"claude-3-opus-20240229":     1.50,
+	"claude-3-haiku-20240307":    0.025,
+
+	// Gemini
+	"gemini-2.5-pro":   1.00,
+	"gemini-2.5-flash": 0.075,
+
+	// DeepSeek
+	"deepseek-chat": 0.014,
+}
+
+// pricesMu guards ModelPricing and CacheReadPricing, which fetches and the
+// disk cache write to while parsers price requests from their own goroutines
+var pricesMu sync.RWMutex
+
+// PricingAPIURL is the endpoint for fetching model pricing. Any endpoint
+// returning OpenRouter's response shape works, e.g. a private gateway's.
 var PricingAPIURL = "https://openrouter.ai/api/v1/models"
 
+// PricingAPIToken, if set, is sent as a bearer token with pricing requests
+var PricingAPIToken string
+
 var (
-	lastFetchTime time.Time
-	fetchMutex    sync.Mutex
-	cacheDuration = 1 * time.Hour
+	lastFetchTime  time.Time
+	lastFetchError error
+	fetchMutex     sync.Mutex   // Serializes fetches
+	statusMu       sync.RWMutex // Protects lastFetchTime/lastFetchError so readers never wait on a fetch
+	cacheDuration  = 1 * time.Hour
 )
 
+// FetchTimeout bounds a single pricing request
+var FetchTimeout = 10 * time.Second
+
+// FetchRetries is how many times a transient failure (5xx, network error) is retried
+var FetchRetries = 2
+
+// retryBackoff is the delay before the first retry; it doubles on each attempt
+var retryBackoff = 500 * time.Millisecond
+
+var httpClient = &http.Client{}
+
+// Offline disables the pricing API entirely, for air-gapped machines: prices
+// come from the built-in defaults and the disk cache only
+var Offline bool
+
 type openRouterResponse struct {
 	Data []struct {
 		ID      string `json:"id"`
 		Pricing struct {
-			Prompt     string `json:"prompt"`
-			Completion string `json:"completion"`
+			Prompt            string `json:"prompt"`
+			Completion        string `json:"completion"`
+			InputCacheRead    string `json:"input_cache_read,omitempty"`
+			InternalReasoning string `json:"internal_reasoning,omitempty"`
 		} `json:"pricing"`
 		Name string `json:"name"`
 	} `json:"data"`
@@ -95,34 +156,49 @@ func UpdatePricing() error {
 	fetchMutex.Lock()
 	defer fetchMutex.Unlock()
 
+	if Offline {
+		statusMu.RLock()
+		source := cacheSource
+		statusMu.RUnlock()
+		if source == SourceDefaults && loadCache() {
+			log.Infof("pricing: offline, using disk cache %s", CacheFile)
+		}
+		return nil
+	}
+
 	// Rate limit checks (simple time-based cache)
-	if time.Since(lastFetchTime) < cacheDuration {
+	if time.Since(GetLastFetchTime()) < cacheDuration {
 		return nil
 	}
 
-	resp, err := http.Get(PricingAPIURL)
+	data, err := fetchWithRetry()
 	if err != nil {
-		return fmt.Errorf("failed to fetch pricing: %w", err)
-	}
-	defer resp.Body.Close()
+		statusMu.Lock()
+		lastFetchError = err
+		source := cacheSource
+		statusMu.Unlock()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("bad status: %s", resp.Status)
-	}
+		log.Warnf("pricing: fetch failed: %v", err)
 
-	var data openRouterResponse
-	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
-		return fmt.Errorf("failed to decode pricing data: %w", err)
+		// Fall back to the last prices we saved to disk
+		if source == SourceDefaults && loadCache() {
+			log.Infof("pricing: using disk cache %s", CacheFile)
+		}
+		return err
 	}
 
+	fetched := make(map[string]cachedPricing, len(data.Data))
+	pricesMu.Lock()
 	for _, model := range data.Data {
 		// OpenRouter pricing is per token, we store per 1M tokens
 		inputPrice, err := strconv.ParseFloat(model.Pricing.Prompt, 64)
 		if err != nil {
+			log.Debugf("pricing: skipping %s: bad prompt price %q", model.ID, model.Pricing.Prompt)
 			continue
 		}
 		outputPrice, err := strconv.ParseFloat(model.Pricing.Completion, 64)
 		if err != nil {
+			log.Debugf("pricing: skipping %s: bad completion price %q", model.ID, model.Pricing.Completion)
 			continue
 		}
 
@@ -140,73 +216,273 @@ func UpdatePricing() error {
 			provider = parts[0]
 		}
 
-		ModelPricing[model.ID] = struct {
-			Input    float64
-			Output   float64
-			Provider string
-		}{
-			Input:    inputPerM,
-			Output:   outputPerM,
-			Provider: provider,
+		// Only set a reasoning rate when it differs from output, so
+		// reasoning keeps following the output price otherwise
+		var reasoningPerM float64
+		if reasoning, err := strconv.ParseFloat(model.Pricing.InternalReasoning, 64); err == nil && reasoning > 0 {
+			if reasoning*1_000_000 != outputPerM {
+				reasoningPerM = reasoning * 1_000_000
+			}
+		}
+
+		ModelPricing[model.ID] = ModelPrice{
+			Input:     inputPerM,
+			Output:    outputPerM,
+			Provider:  provider,
+			Reasoning: reasoningPerM,
+		}
+
+		var cacheReadPerM float64
+		if cacheRead, err := strconv.ParseFloat(model.Pricing.InputCacheRead, 64); err == nil && cacheRead > 0 {
+			cacheReadPerM = cacheRead * 1_000_000
+			CacheReadPricing[model.ID] = cacheReadPerM
 		}
+
+		fetched[model.ID] = cachedPricing{Input: inputPerM, Output: outputPerM, CacheRead: cacheReadPerM, Reasoning: reasoningPerM, Provider: provider}
 	}
+	pricesMu.Unlock()
+
+	now := time.Now()
+	statusMu.Lock()
+	lastFetchTime = now
+	lastFetchError = nil
+	cacheSource = SourceAPI
+	statusMu.Unlock()
 
-	lastFetchTime = time.Now()
+	invalidateFuzzyIndex()
+	saveCache(fetched, now)
+	log.Debugf("pricing: fetched %d models", len(fetched))
 	return nil
 }
 
+// fetchWithRetry fetches pricing, retrying transient failures with exponential backoff
+func fetchWithRetry() (*openRouterResponse, error) {
+	var lastErr error
+	backoff := retryBackoff
+
+	for attempt := 0; attempt <= FetchRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+
+		data, retryable, err := fetchOnce()
+		if err == nil {
+			return data, nil
+		}
+		lastErr = err
+		if !retryable {
+			break
+		}
+	}
+
+	return nil, lastErr
+}
+
+// fetchOnce performs a single request bounded by FetchTimeout.
+// retryable reports whether the failure is worth another attempt.
+func fetchOnce() (data *openRouterResponse, retryable bool, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), FetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, PricingAPIURL, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to build pricing request: %w", err)
+	}
+	if PricingAPIToken != "" {
+		req.Header.Set("Authorization", "Bearer "+PricingAPIToken)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, true, fmt.Errorf("failed to fetch pricing: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 {
+		return nil, true, fmt.Errorf("bad status: %s", resp.Status)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("bad status: %s", resp.Status)
+	}
+
+	data = &openRouterResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(data); err != nil {
+		// A timeout mid-body is transient; malformed JSON is not
+		return nil, ctx.Err() != nil, fmt.Errorf("failed to decode pricing data: %w", err)
+	}
+
+	return data, false, nil
+}
+
+// Ping makes a single pricing request without updating any prices, to check
+// the API is reachable
+func Ping() error {
+	_, _, err := fetchOnce()
+	return err
+}
+
 // GetLastFetchTime returns the time of the last successful API fetch
 func GetLastFetchTime() time.Time {
-	fetchMutex.Lock()
-	defer fetchMutex.Unlock()
+	statusMu.RLock()
+	defer statusMu.RUnlock()
 	return lastFetchTime
 }
 
-func CalculateCost(model string, promptTokens, completionTokens int) float64 {
+// LastFetchError returns the error from the most recent failed fetch, or nil
+// if the last fetch succeeded (or none has been attempted)
+func LastFetchError() error {
+	statusMu.RLock()
+	defer statusMu.RUnlock()
+	return lastFetchError
+}
+
+// lookupPricing finds a model's prices by its canonical name, so every alias
+// of a model is billed the same. Names Canonical doesn't know (e.g. IDs only
+// the API returned) are looked up as-is.
+func lookupPricing(model string) (ModelPrice, bool) {
+	pricesMu.RLock()
+	defer pricesMu.RUnlock()
+	p, ok := ModelPricing[canonical(model)]
+	return p, ok
+}
+
+// priceOf returns the prices under a ModelPricing key, without resolving
+// aliases
+func priceOf(key string) ModelPrice {
+	pricesMu.RLock()
+	defer pricesMu.RUnlock()
+	return ModelPricing[key]
+}
+
+// DefaultFallbackModel prices unknown models unless configured otherwise:
+// the cheapest safe guess
+const DefaultFallbackModel = "gpt-4o-mini"
+
+// FallbackModel is the model whose prices CalculateCost uses for models it
+// doesn't know. Empty leaves unknown models unpriced at $0, for callers that
+// would rather show an unknown cost than an estimate (see NeedsFallback).
+var FallbackModel = DefaultFallbackModel
+
+// IsKnown reports whether there is a price for the model, rather than the
+// fallback CalculateCost uses for unknown models
+func IsKnown(model string) bool {
+	_, ok := lookupPricing(model)
+	return ok
+}
+
+// NeedsFallback reports whether CalculateCost can only estimate a model's
+// cost with FallbackModel (or not at all): it isn't free, isn't local, and
+// has no price
+func NeedsFallback(model string) bool {
+	return !strings.Contains(model, ":free") && !IsLocal(model) && !IsKnown(model)
+}
+
+// CalculateCost prices a request. completionTokens excludes reasoningTokens,
+// which are billed at the model's reasoning rate, or as output if it has none.
+func CalculateCost(model string, promptTokens, completionTokens, reasoningTokens int) float64 {
 	// Handle free models (OpenRouter :free suffix, etc.)
 	// Check for ":free" anywhere in the string (handles suffixes and ":free (Provider)" format)
 	if strings.Contains(model, ":free") {
 		return 0.0
 	}
+	// Local models have no per-token price (see IsLocal)
+	if IsLocal(model) {
+		return 0.0
+	}
 
-	p, ok := ModelPricing[model]
-	if !ok {
-		// Fallback to cheapest safe model
-		p = ModelPricing["gpt-4o-mini"]
+	// A fallback price is only an estimate, so no provider's multiplier
+	// applies to it
+	multiplier := 1.0
+	p, ok := lookupPricing(model)
+	if ok {
+		multiplier = providerMultiplier(p.Provider)
+	} else {
+		if FallbackModel == "" {
+			return 0.0
+		}
+		// A fallback without a price of its own falls back to the default
+		if p, ok = lookupPricing(FallbackModel); !ok {
+			p = priceOf(DefaultFallbackModel)
+		}
+	}
+
+	reasoningRate := p.Reasoning
+	if reasoningRate == 0 {
+		reasoningRate = p.Output
 	}
 
 	inputCost := float64(promptTokens) / 1_000_000 * p.Input
 	outputCost := float64(completionTokens) / 1_000_000 * p.Output
+	reasoningCost := float64(reasoningTokens) / 1_000_000 * reasoningRate
+
+	return (inputCost + outputCost + reasoningCost) * multiplier
+}
+
+// CalculateCostWithCache calculates cost when some input tokens were cache reads.
+// promptTokens excludes the cached tokens, which are billed at the model's
+// cache-read rate (or the full input rate if it has none).
+func CalculateCostWithCache(model string, promptTokens, cacheReadTokens, completionTokens, reasoningTokens int) float64 {
+	if strings.Contains(model, ":free") {
+		return 0.0
+	}
+
+	cost := CalculateCost(model, promptTokens+cacheReadTokens, completionTokens, reasoningTokens)
+	return cost - CalculateCacheSavings(model, cacheReadTokens)
+}
 
-	return inputCost + outputCost
+// CostFromTokens prices a request from its recorded token counts, the way
+// parsers price them: promptTokens includes cacheReadTokens, and
+// completionTokens includes reasoningTokens and any cache writes, which are
+// billed as output.
+func CostFromTokens(model string, promptTokens, cacheReadTokens, completionTokens, reasoningTokens int) float64 {
+	return CalculateCostWithCache(model, promptTokens-cacheReadTokens, cacheReadTokens,
+		completionTokens-reasoningTokens, reasoningTokens)
+}
+
+// CalculateCacheSavings returns how much cheaper cacheReadTokens were than
+// billing them at the full input rate. Zero for models without cache pricing.
+func CalculateCacheSavings(model string, cacheReadTokens int) float64 {
+	if cacheReadTokens <= 0 || strings.Contains(model, ":free") {
+		return 0.0
+	}
+
+	pricesMu.RLock()
+	cacheRate, ok := CacheReadPricing[canonical(model)]
+	pricesMu.RUnlock()
+	if !ok {
+		return 0.0
+	}
+	p, ok := lookupPricing(model)
+	if !ok || p.Input <= cacheRate {
+		return 0.0
+	}
+
+	return float64(cacheReadTokens) / 1_000_000 * (p.Input - cacheRate) * providerMultiplier(p.Provider)
 }
 
 // CalculateHypotheticalCost calculates what the cost would have been with a different model
 func CalculateHypotheticalCost(targetModel string, promptTokens, completionTokens int) (float64, error) {
-	p, ok := ModelPricing[targetModel]
+	p, ok := lookupPricing(targetModel)
 	if !ok {
-		// Try fuzzy matching or common aliases
-		for k, v := range ModelPricing {
-			if strings.EqualFold(k, targetModel) || strings.Contains(strings.ToLower(k), strings.ToLower(targetModel)) {
-				p = v
-				ok = true
-				break
-			}
-		}
-		if !ok {
+		key, found := fuzzyMatch(targetModel)
+		if !found {
 			return 0, fmt.Errorf("model %s not found", targetModel)
 		}
+		p = priceOf(key)
 	}
 
 	inputCost := float64(promptTokens) / 1_000_000 * p.Input
 	outputCost := float64(completionTokens) / 1_000_000 * p.Output
 
-	return inputCost + outputCost, nil
+	return (inputCost + outputCost) * providerMultiplier(p.Provider), nil
 }
 
 // GetAvailableModels returns a list of model IDs available for comparison
 func GetAvailableModels() []string {
-	var models []string
+	pricesMu.RLock()
+	defer pricesMu.RUnlock()
+	models := make([]string, 0, len(ModelPricing))
 	for k := range ModelPricing {
 		models = append(models, k)
 	}
@@ -0,0 +1,23 @@
+package pricing
+
+import "strings"
+
+// ProviderOf returns the provider a model was served by, lowercased: the
+// one a tool reported, as the display suffix parsers append ("gpt-4o
+// (openai)") or a prefix ("anthropic/claude-sonnet-4.5"), or else the one
+// in the pricing map. It's "" when neither knows.
+func ProviderOf(model string) string {
+	name := strings.TrimSpace(model)
+	if strings.HasSuffix(name, ")") {
+		if open := strings.LastIndex(name, " ("); open >= 0 {
+			return strings.ToLower(name[open+2 : len(name)-1])
+		}
+	}
+	if slash := strings.Index(name, "/"); slash > 0 {
+		return strings.ToLower(name[:slash])
+	}
+	if p, ok := lookupPricing(name); ok {
+		return strings.ToLower(p.Provider)
+	}
+	return ""
+}
@@ -0,0 +1,18 @@
+package pricing
+
+import "testing"
+
+func TestProviderOf(t *testing.T) {
+	tests := map[string]string{
+		"claude-sonnet-4.5 (anthropic)": "anthropic", // OpenCode, Crush, and Zed's suffix
+		"llama3.1:8b (ollama)":          "ollama",
+		"openrouter/anthropic/claude-3": "openrouter", // Aider's prefix
+		"gpt-4o":                        "openai",     // From the pricing map
+		"my-private-model":              "",
+	}
+	for model, want := range tests {
+		if got := ProviderOf(model); got != want {
+			t.Errorf("ProviderOf(%q): expected %q, got %q", model, want, got)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package pricing
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Tier is a rough capability class, for suggesting cheaper models that can
+// plausibly do the same work. It's coarse and advisory: models in a tier
+// aren't interchangeable for every task.
+type Tier int
+
+const (
+	TierUnknown Tier = iota
+	TierBudget
+	TierStandard
+	TierFrontier
+)
+
+func (t Tier) String() string {
+	switch t {
+	case TierBudget:
+		return "budget"
+	case TierStandard:
+		return "standard"
+	case TierFrontier:
+		return "frontier"
+	}
+	return "unknown"
+}
+
+// ParseTier reads a tier by name: budget, standard, or frontier
+func ParseTier(s string) (Tier, error) {
+	for _, t := range []Tier{TierBudget, TierStandard, TierFrontier} {
+		if strings.EqualFold(strings.TrimSpace(s), t.String()) {
+			return t, nil
+		}
+	}
+	return TierUnknown, fmt.Errorf("invalid tier %q (want budget, standard, or frontier)", s)
+}
+
+// FamilyTiers places the built-in model families (see ModelFamilies) in a
+// tier. Families not listed, including custom ones, are TierUnknown.
+var FamilyTiers = map[string]Tier{
+	"Claude Opus":   TierFrontier,
+	"Claude Sonnet": TierFrontier,
+	"GPT-5":         TierFrontier,
+	"Gemini Pro":    TierFrontier,
+	"GPT-4o":        TierStandard,
+	"GPT-4.1":       TierStandard,
+	"DeepSeek":      TierStandard,
+	"Claude Haiku":  TierBudget,
+	"GPT-4o mini":   TierBudget,
+	"Gemini Flash":  TierBudget,
+	"Llama":         TierBudget,
+}
+
+// TierOf returns the tier of a model's family
+func TierOf(model string) Tier {
+	return FamilyTiers[FamilyOf(model)]
+}
+
+// Request is one request's tokens, split the way CalculateCostWithCache
+// takes them
+type Request struct {
+	Prompt     int // Uncached prompt tokens
+	CacheRead  int
+	Completion int // Excluding reasoning
+	Reasoning  int
+}
+
+// Candidate is what a set of requests would have cost on one model
+type Candidate struct {
+	Model  string
+	Family string
+	Tier   Tier
+	Cost   float64
+}
+
+// RankCandidates prices each request on every model that's at least minTier
+// and, when families is given, in one of those families (matched without
+// regard to case), cheapest first. Each request is priced on its own, so
+// models' cache and reasoning rates apply to the requests that used them.
+// Free, local, and unpriced models aren't candidates; models without a
+// known tier are only candidates when their family is named.
+func RankCandidates(requests []Request, minTier Tier, families []string) []Candidate {
+	var candidates []Candidate
+	for _, model := range GetAvailableModels() {
+		p := priceOf(model)
+		if p.Input <= 0 && p.Output <= 0 || strings.Contains(model, ":free") || IsLocal(model) {
+			continue
+		}
+
+		family := FamilyOf(model)
+		tier := TierOf(model)
+		named := false
+		for _, f := range families {
+			if strings.EqualFold(strings.TrimSpace(f), family) {
+				named = true
+			}
+		}
+		if len(families) > 0 && !named || tier == TierUnknown && !named || tier != TierUnknown && tier < minTier {
+			continue
+		}
+
+		var cost float64
+		for _, r := range requests {
+			cost += CalculateCostWithCache(model, r.Prompt, r.CacheRead, r.Completion, r.Reasoning)
+		}
+		candidates = append(candidates, Candidate{Model: model, Family: family, Tier: tier, Cost: cost})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Cost != candidates[j].Cost {
+			return candidates[i].Cost < candidates[j].Cost
+		}
+		return candidates[i].Model < candidates[j].Model
+	})
+	return candidates
+}
@@ -0,0 +1,69 @@
+package pricing
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTier(t *testing.T) {
+	for in, want := range map[string]Tier{"budget": TierBudget, " Standard ": TierStandard, "FRONTIER": TierFrontier} {
+		if got, err := ParseTier(in); err != nil || got != want {
+			t.Errorf("Expected %q to parse as %s, got %s (%v)", in, want, got, err)
+		}
+	}
+	if _, err := ParseTier("huge"); err == nil {
+		t.Errorf("Expected an error for an unknown tier")
+	}
+}
+
+func TestRankCandidates(t *testing.T) {
+	defer func(prices map[string]ModelPrice, cache map[string]float64) {
+		ModelPricing, CacheReadPricing = prices, cache
+		invalidateFuzzyIndex()
+	}(ModelPricing, CacheReadPricing)
+	ModelPricing = map[string]ModelPrice{
+		"claude-opus-4":      {15.00, 75.00, "Anthropic", 0},
+		"claude-sonnet-4":    {3.00, 15.00, "Anthropic", 0},
+		"gpt-5":              {1.25, 10.00, "OpenAI", 0},
+		"gpt-4o-mini":        {0.15, 0.60, "OpenAI", 0},
+		"my-finetune":        {0.10, 0.10, "Custom", 0},
+		"deepseek-chat:free": {0, 0, "DeepSeek", 0},
+	}
+	CacheReadPricing = map[string]float64{"claude-sonnet-4": 0.30}
+	invalidateFuzzyIndex()
+
+	// Mostly cached prompts, which Sonnet reads at a tenth of its input rate
+	requests := []Request{
+		{Prompt: 1_000, CacheRead: 900_000, Completion: 2_000},
+		{Prompt: 5_000, CacheRead: 100_000, Completion: 1_000},
+	}
+	models := func(candidates []Candidate) string {
+		var names []string
+		for _, c := range candidates {
+			names = append(names, c.Model)
+		}
+		return strings.Join(names, " ")
+	}
+
+	// 1. Frontier and up: cache pricing makes Sonnet cheaper than GPT-5
+	got := RankCandidates(requests, TierFrontier, nil)
+	if want := "claude-sonnet-4 gpt-5 claude-opus-4"; models(got) != want {
+		t.Errorf("Expected %q, got %q", want, models(got))
+	}
+	if want := CalculateCostWithCache("claude-sonnet-4", 1_000, 900_000, 2_000, 0) +
+		CalculateCostWithCache("claude-sonnet-4", 5_000, 100_000, 1_000, 0); got[0].Cost != want {
+		t.Errorf("Expected Sonnet to cost %f, got %f", want, got[0].Cost)
+	}
+
+	// 2. Any tier: budget models join; free and untiered ones don't
+	got = RankCandidates(requests, TierBudget, nil)
+	if want := "gpt-4o-mini claude-sonnet-4 gpt-5 claude-opus-4"; models(got) != want {
+		t.Errorf("Expected %q, got %q", want, models(got))
+	}
+
+	// 3. Families narrow the candidates, and naming one admits untiered models
+	got = RankCandidates(requests, TierFrontier, []string{"gpt-5", "my-finetune"})
+	if want := "my-finetune gpt-5"; models(got) != want {
+		t.Errorf("Expected %q, got %q", want, models(got))
+	}
+}
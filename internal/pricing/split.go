@@ -0,0 +1,45 @@
+package pricing
+
+// CostSplit is a request's cost by the kind of token it was spent on
+type CostSplit struct {
+	Input     float64 // Uncached prompt tokens
+	CacheRead float64 // Prompt tokens served from cache
+	Output    float64 // Completion tokens, excluding reasoning
+	Reasoning float64 // Thinking tokens
+}
+
+// Total is the sum of the split's parts
+func (s CostSplit) Total() float64 {
+	return s.Input + s.CacheRead + s.Output + s.Reasoning
+}
+
+// Add returns the sum of two splits, part by part
+func (s CostSplit) Add(o CostSplit) CostSplit {
+	return CostSplit{
+		Input:     s.Input + o.Input,
+		CacheRead: s.CacheRead + o.CacheRead,
+		Output:    s.Output + o.Output,
+		Reasoning: s.Reasoning + o.Reasoning,
+	}
+}
+
+// Scale returns the split with every part multiplied by f
+func (s CostSplit) Scale(f float64) CostSplit {
+	return CostSplit{
+		Input:     s.Input * f,
+		CacheRead: s.CacheRead * f,
+		Output:    s.Output * f,
+		Reasoning: s.Reasoning * f,
+	}
+}
+
+// SplitCost prices each kind of token in a request separately, taking the
+// same counts as CalculateCostWithCache, whose result the parts add up to
+func SplitCost(model string, promptTokens, cacheReadTokens, completionTokens, reasoningTokens int) CostSplit {
+	return CostSplit{
+		Input:     CalculateCost(model, promptTokens, 0, 0),
+		CacheRead: CalculateCost(model, cacheReadTokens, 0, 0) - CalculateCacheSavings(model, cacheReadTokens),
+		Output:    CalculateCost(model, 0, completionTokens, 0),
+		Reasoning: CalculateCost(model, 0, 0, reasoningTokens),
+	}
+}
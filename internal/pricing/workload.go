@@ -0,0 +1,72 @@
+package pricing
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Workload is a hypothetical set of requests, summed, for pricing before
+// anything has run
+type Workload struct {
+	Requests         int
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// ReadWorkload reads a CSV of prompt,completion token pairs, one request per
+// line. A header line, blank lines, and lines starting with # are skipped.
+// Every bad line is reported, by line number, in the returned error.
+func ReadWorkload(r io.Reader) (Workload, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1 // Checked per line so the error names the line
+	reader.TrimLeadingSpace = true
+
+	var w Workload
+	var errs []error
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line, _ := reader.FieldPos(0)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if len(record) != 2 {
+			errs = append(errs, fmt.Errorf("line %d: expected prompt,completion, got %d fields", line, len(record)))
+			continue
+		}
+		prompt, promptErr := strconv.Atoi(strings.TrimSpace(record[0]))
+		completion, completionErr := strconv.Atoi(strings.TrimSpace(record[1]))
+		if promptErr != nil || completionErr != nil {
+			if line == 1 && w.Requests == 0 && len(errs) == 0 {
+				continue // Header, e.g. "prompt,completion"
+			}
+			errs = append(errs, fmt.Errorf("line %d: token counts must be whole numbers, got %q", line, strings.Join(record, ",")))
+			continue
+		}
+		if prompt < 0 || completion < 0 {
+			errs = append(errs, fmt.Errorf("line %d: token counts can't be negative", line))
+			continue
+		}
+
+		w.Requests++
+		w.PromptTokens += prompt
+		w.CompletionTokens += completion
+	}
+
+	if len(errs) > 0 {
+		return w, errors.Join(errs...)
+	}
+	if w.Requests == 0 {
+		return w, errors.New("no requests in workload")
+	}
+	return w, nil
+}
@@ -0,0 +1,48 @@
+package pricing
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadWorkloadSumsRequests(t *testing.T) {
+	csv := `prompt,completion
+# Planning a code review bot
+12000,800
+ 8000, 400
+
+3000,200
+`
+	w, err := ReadWorkload(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("ReadWorkload failed: %v", err)
+	}
+	if w.Requests != 3 || w.PromptTokens != 23000 || w.CompletionTokens != 1400 {
+		t.Errorf("Expected 3 requests with 23000/1400 tokens, got %+v", w)
+	}
+}
+
+func TestReadWorkloadReportsEveryBadLine(t *testing.T) {
+	csv := `1000,100
+lots,100
+2000
+-5,10
+`
+	_, err := ReadWorkload(strings.NewReader(csv))
+	if err == nil {
+		t.Fatal("Expected an error for a malformed workload")
+	}
+	for _, want := range []string{"line 2:", "line 3:", "line 4:"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected the error to mention %s, got %v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "line 1:") {
+		t.Errorf("Expected line 1 to be fine, got %v", err)
+	}
+
+	// An empty file is an error too
+	if _, err := ReadWorkload(strings.NewReader("prompt,completion\n")); err == nil {
+		t.Error("Expected an error for a workload with no requests")
+	}
+}
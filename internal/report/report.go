@@ -0,0 +1,176 @@
+// Package report builds spend summaries to share outside the dashboard, such
+// as a weekly digest posted to a chat webhook or mailed from cron.
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bangarangler/burnrate/internal/config"
+	"github.com/bangarangler/burnrate/internal/storage"
+	"github.com/bangarangler/burnrate/internal/tracker"
+)
+
+// topModelCount is how many of the most expensive models a report lists
+const topModelCount = 5
+
+// ModelSpend is one model's share of a report's spend
+type ModelSpend struct {
+	Model            string  `json:"model"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	Cost             float64 `json:"cost"`
+}
+
+// Report summarizes one window of history. Costs are in USD.
+type Report struct {
+	Window      string       `json:"window"` // "today", "week", "month", or "all"
+	GeneratedAt time.Time    `json:"generated_at"`
+	Total       float64      `json:"total"`
+	Previous    float64      `json:"previous"`         // Spend in the equivalent prior period (0 if none)
+	Budget      float64      `json:"budget,omitempty"` // Daily budget scaled to the window (0 for all time)
+	Models      int          `json:"models"`           // Distinct models used
+	TopModels   []ModelSpend `json:"top_models"`       // Most expensive first
+}
+
+// Build summarizes a window from history, using the same aggregation as
+// 'burnrate stats'
+func Build(window string, cfg *config.Config, now time.Time) (Report, error) {
+	usages, total, err := tracker.Global.GetHistoricalUsage(window)
+	if err != nil {
+		return Report{}, err
+	}
+
+	r := Report{Window: window, GeneratedAt: now, Total: total, Models: len(usages)}
+	for i, u := range usages {
+		if i == topModelCount {
+			break
+		}
+		r.TopModels = append(r.TopModels, ModelSpend{
+			Model:            u.Model,
+			PromptTokens:     u.PromptTokens,
+			CompletionTokens: u.CompletionTokens,
+			Cost:             u.Cost,
+		})
+	}
+
+	if window != "all" {
+		r.Budget = cfg.WindowBudget(window, now)
+		if _, r.Previous, err = storage.GetPeriodComparison(window); err != nil {
+			return Report{}, err
+		}
+	}
+	return r, nil
+}
+
+// Change returns the percentage change from the prior period, and false when
+// there is nothing to compare with
+func (r Report) Change() (float64, bool) {
+	if r.Previous <= 0 {
+		return 0, false
+	}
+	return (r.Total - r.Previous) / r.Previous * 100, true
+}
+
+// Title is a one-line headline, e.g. "burnrate week report: $12.34"
+func (r Report) Title() string {
+	return fmt.Sprintf("burnrate %s report: %s", r.Window, config.FormatMoneyCents(r.Total))
+}
+
+// lines returns the report's label/value rows shared by every text format
+func (r Report) lines() [][2]string {
+	var rows [][2]string
+
+	spend := config.FormatMoneyCents(r.Total)
+	if r.Budget > 0 {
+		spend += fmt.Sprintf(" of %s budget (%.0f%%)", config.FormatMoneyCents(r.Budget), r.Total/r.Budget*100)
+		if r.Total > r.Budget {
+			spend += ", over budget"
+		}
+	}
+	rows = append(rows, [2]string{"Spend", spend})
+
+	if r.Window != "all" {
+		label := map[string]string{"today": "yesterday", "week": "last week", "month": "last month"}[r.Window]
+		change := "no spend"
+		if pct, ok := r.Change(); ok {
+			arrow := "▲"
+			if pct < 0 {
+				arrow = "▼"
+			}
+			change = fmt.Sprintf("%s %.0f%% (%s)", arrow, math.Abs(pct), config.FormatMoneyCents(r.Previous))
+		}
+		rows = append(rows, [2]string{"vs " + label, change})
+	}
+
+	rows = append(rows, [2]string{"Models used", fmt.Sprintf("%d", r.Models)})
+	return rows
+}
+
+// Text renders a plain-text digest for terminals and email
+func (r Report) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s (%s)\n\n", r.Title(), r.GeneratedAt.Format("Mon Jan 2 2006"))
+	for _, row := range r.lines() {
+		fmt.Fprintf(&b, "%-14s %s\n", row[0], row[1])
+	}
+	if len(r.TopModels) > 0 {
+		b.WriteString("\nTop models:\n")
+		for _, m := range r.TopModels {
+			fmt.Fprintf(&b, "  %-35s %s\n", m.Model, config.FormatMoneyCents(m.Cost))
+		}
+	}
+	return b.String()
+}
+
+// Markdown renders the digest as Markdown, e.g. for a wiki or chat
+func (r Report) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n\n", r.Title())
+	for _, row := range r.lines() {
+		fmt.Fprintf(&b, "- **%s:** %s\n", row[0], row[1])
+	}
+	if len(r.TopModels) > 0 {
+		b.WriteString("\n| Model | Input | Output | Cost |\n|---|--:|--:|--:|\n")
+		for _, m := range r.TopModels {
+			fmt.Fprintf(&b, "| %s | %d | %d | %s |\n", m.Model, m.PromptTokens, m.CompletionTokens, config.FormatMoneyCents(m.Cost))
+		}
+	}
+	fmt.Fprintf(&b, "\n_Generated %s_\n", r.GeneratedAt.Format(time.RFC1123))
+	return b.String()
+}
+
+// webhookTimeout bounds how long Send waits for the webhook
+var webhookTimeout = 10 * time.Second
+
+// Send posts the report as JSON to a webhook. The payload's "text" field
+// holds the plain-text digest, which chat webhooks such as Slack's incoming
+// webhooks display as the message.
+func Send(url string, r Report) error {
+	payload := struct {
+		Text string `json:"text"`
+		Report
+	}{r.Text(), r}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: webhookTimeout}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
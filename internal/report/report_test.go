@@ -0,0 +1,82 @@
+package report
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sampleReport() Report {
+	return Report{
+		Window:      "week",
+		GeneratedAt: time.Date(2025, 6, 6, 17, 0, 0, 0, time.UTC),
+		Total:       12.50,
+		Previous:    10.00,
+		Budget:      35.00,
+		Models:      2,
+		TopModels: []ModelSpend{
+			{Model: "claude-sonnet-4", PromptTokens: 90_000, CompletionTokens: 4_000, Cost: 10.00},
+			{Model: "gpt-4o", PromptTokens: 20_000, CompletionTokens: 1_000, Cost: 2.50},
+		},
+	}
+}
+
+func TestTextDigest(t *testing.T) {
+	text := sampleReport().Text()
+
+	// 1. Total, budget status, and week-over-week change
+	for _, want := range []string{"burnrate week report: $12.50", "of $35.00 budget (36%)", "vs last week", "▲ 25% ($10.00)"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in:\n%s", want, text)
+		}
+	}
+
+	// 2. Top models, most expensive first
+	if strings.Index(text, "claude-sonnet-4") > strings.Index(text, "gpt-4o") {
+		t.Errorf("Expected claude-sonnet-4 before gpt-4o in:\n%s", text)
+	}
+
+	// 3. No prior spend means no percentage
+	r := sampleReport()
+	r.Previous = 0
+	if _, ok := r.Change(); ok {
+		t.Error("Expected no change without prior spend")
+	}
+	if !strings.Contains(r.Text(), "no spend") {
+		t.Errorf("Expected \"no spend\" for the prior week in:\n%s", r.Text())
+	}
+}
+
+func TestSendPostsJSON(t *testing.T) {
+	var got map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Expected a JSON request, got %s", r.Header.Get("Content-Type"))
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+	}))
+	defer ts.Close()
+
+	// 1. The payload carries the digest as "text" alongside the report fields
+	if err := Send(ts.URL, sampleReport()); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if text, _ := got["text"].(string); !strings.Contains(text, "burnrate week report") {
+		t.Errorf("Expected the digest in text, got %q", text)
+	}
+	if got["total"] != 12.5 || got["window"] != "week" {
+		t.Errorf("Expected the report fields, got %v", got)
+	}
+
+	// 2. A non-2xx response is an error
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "no", http.StatusForbidden)
+	}))
+	defer failing.Close()
+	if err := Send(failing.URL, sampleReport()); err == nil {
+		t.Error("Expected an error for a 403 response")
+	}
+}
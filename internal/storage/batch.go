@@ -0,0 +1,219 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bangarangler/burnrate/internal/log"
+	"github.com/bangarangler/burnrate/internal/pricing"
+)
+
+// eventColumns are the usage_events columns an event is written to, in
+// insertArgs' order
+const eventColumns = `timestamp, tool, model, prompt_tokens, completion_tokens, cost, cache_read_tokens,
+	cache_savings, tag, provider, project, reasoning_tokens, cost_micros, event_key`
+
+// insertEventQuery inserts one usage event, skipping a repeat via the unique
+// indexes: a keyed event by its tool and key, a key-less one by its
+// timestamp, tool, model, and tokens (see migrate)
+const insertEventQuery = `
+	INSERT OR IGNORE INTO usage_events (` + eventColumns + `)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+// adoptEventQuery gives the key of the event being written to a key-less
+// row recorded for the same event, if there's one. OR IGNORE leaves it alone
+// when a row already has the key. It takes the key, then sameEventArgs.
+const adoptEventQuery = `
+	UPDATE OR IGNORE usage_events SET event_key = ?
+	WHERE id = (SELECT id FROM usage_events WHERE ` + sameEventCondition + ` LIMIT 1)
+	`
+
+// sameEventCondition matches the key-less rows that look like an event:
+// same timestamp, tool, model, and token counts. Repeats an older version
+// recorded carry a placeholder key (see addNaturalKeyIndex) and count as
+// key-less here.
+const sameEventCondition = `(event_key IS NULL OR event_key LIKE '` + legacyKeyPrefix + `%')
+	AND timestamp = ? AND tool = ? AND model = ? AND prompt_tokens = ? AND completion_tokens = ?`
+
+// insertArgs returns the event's values in eventColumns' order
+func (e UsageEvent) insertArgs() []any {
+	tag := sql.NullString{String: e.Tag, Valid: e.Tag != ""}
+	provider := sql.NullString{String: e.Provider, Valid: e.Provider != ""}
+	project := sql.NullString{String: e.Project, Valid: e.Project != ""}
+	key := sql.NullString{String: e.EventKey, Valid: e.EventKey != ""}
+	return []any{e.Timestamp, e.Tool, e.Model, e.PromptTokens, e.CompletionTokens, e.Cost,
+		e.CacheReadTokens, e.CacheSavings, tag, provider, project, e.ReasoningTokens, pricing.ToMicros(e.Cost), key}
+}
+
+// sameEventArgs returns the event's values for sameEventCondition
+func (e UsageEvent) sameEventArgs() []any {
+	return []any{e.Timestamp, e.Tool, e.Model, e.PromptTokens, e.CompletionTokens}
+}
+
+// execer is a *sql.DB or *sql.Tx
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// insertEvent writes one event unless it's already recorded, returning
+// whether a row was inserted. A keyed event is recorded once per tool and
+// key; if an older version recorded it without a key, that row takes the
+// key instead of a second row being added. An event without a key is
+// skipped when a key-less row for the same timestamp, tool, model, and
+// tokens exists, since nothing else tells the two apart.
+func insertEvent(db execer, e UsageEvent) (bool, error) {
+	if e.EventKey != "" {
+		result, err := db.Exec(adoptEventQuery, append([]any{e.EventKey}, e.sameEventArgs()...)...)
+		if err != nil {
+			return false, err
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			return false, nil
+		}
+	}
+
+	result, err := db.Exec(insertEventQuery, e.insertArgs()...)
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// BatchWriter buffers usage events and writes each batch in one transaction,
+// instead of one transaction per event as RecordEvent does. A batch is
+// written once it holds size events or interval has passed.
+type BatchWriter struct {
+	size     int
+	interval time.Duration
+
+	mu      sync.Mutex
+	pending []UsageEvent
+	stopped bool // Set once the final flush has taken pending
+
+	full chan struct{} // Signals a full batch
+	done chan struct{} // Closed after the final flush
+}
+
+// StartBatchWriter starts writing events in batches of up to size, at least
+// every interval, until ctx is cancelled. The events still buffered then are
+// flushed; Wait blocks until they're written. Writes after that go straight
+// to RecordEvent, so no event is lost on a clean shutdown.
+func StartBatchWriter(ctx context.Context, size int, interval time.Duration) *BatchWriter {
+	w := &BatchWriter{
+		size:     size,
+		interval: interval,
+		full:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	go w.run(ctx)
+	return w
+}
+
+// Write queues an event for the next batch
+func (w *BatchWriter) Write(e UsageEvent) {
+	w.mu.Lock()
+	if w.stopped {
+		w.mu.Unlock()
+		if _, err := RecordEvent(e); err != nil {
+			log.Warnf("storage: failed to record %s usage: %v", e.Tool, err)
+		}
+		return
+	}
+	w.pending = append(w.pending, e)
+	full := len(w.pending) >= w.size
+	w.mu.Unlock()
+
+	if full {
+		select {
+		case w.full <- struct{}{}:
+		default: // A flush is already due
+		}
+	}
+}
+
+// Wait blocks until the writer has stopped and flushed its last batch
+func (w *BatchWriter) Wait() {
+	<-w.done
+}
+
+func (w *BatchWriter) run(ctx context.Context) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.mu.Lock()
+			w.stopped = true
+			w.mu.Unlock()
+			w.flush()
+			return
+		case <-ticker.C:
+			w.flush()
+		case <-w.full:
+			w.flush()
+		}
+	}
+}
+
+// flush writes the pending events. A failed batch is logged and dropped, as
+// a failed RecordEvent is.
+func (w *BatchWriter) flush() {
+	w.mu.Lock()
+	events := w.pending
+	w.pending = nil
+	w.mu.Unlock()
+
+	if len(events) == 0 {
+		return
+	}
+	inserted, err := RecordEvents(events)
+	if err != nil {
+		log.Warnf("storage: failed to record %d events: %v", len(events), err)
+		return
+	}
+	log.Debugf("storage: recorded %d events in a batch (%d duplicates)", inserted, len(events)-inserted)
+}
+
+// RecordEvents writes events in one transaction, like RecordEvent does for
+// one. Returns how many rows were inserted.
+func RecordEvents(events []UsageEvent) (int, error) {
+	if DB == nil {
+		return 0, fmt.Errorf("database not initialized")
+	}
+	if readOnly.Load() {
+		return 0, nil
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	inserted := 0
+	for _, e := range events {
+		ok, err := insertEvent(tx, e)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			inserted++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return inserted, nil
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func lifetimeEvents(t *testing.T) int {
+	t.Helper()
+	_, events, _, err := GetLifetimeStats()
+	if err != nil {
+		t.Fatalf("GetLifetimeStats failed: %v", err)
+	}
+	return events
+}
+
+func TestBatchWriterFlushesFullBatchesAndOnShutdown(t *testing.T) {
+	setupTestDB(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	w := StartBatchWriter(ctx, 3, time.Hour)
+
+	event := func(i int) UsageEvent {
+		return UsageEvent{Timestamp: int64(1_750_000_000 + i), Tool: "Aider", Model: "gpt-4o", PromptTokens: 100, Cost: 0.01}
+	}
+
+	// 1. A full batch is written without waiting for the interval
+	for i := 0; i < 3; i++ {
+		w.Write(event(i))
+	}
+	deadline := time.Now().Add(time.Second)
+	for lifetimeEvents(t) != 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the full batch written, got %d rows", lifetimeEvents(t))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// 2. A partial batch waits, then is flushed on shutdown
+	w.Write(event(3))
+	w.Write(event(4))
+	cancel()
+	w.Wait()
+	if got := lifetimeEvents(t); got != 5 {
+		t.Errorf("Expected the partial batch flushed on shutdown, got %d rows", got)
+	}
+
+	// 3. Writes after shutdown go straight to the database
+	w.Write(event(5))
+	if got := lifetimeEvents(t); got != 6 {
+		t.Errorf("Expected a late write recorded directly, got %d rows", got)
+	}
+}
+
+func BenchmarkRecordEvent(b *testing.B) {
+	setupTestDB(b)
+	for i := 0; i < b.N; i++ {
+		if _, err := RecordEvent(UsageEvent{Timestamp: int64(i), Tool: "Aider", Model: "gpt-4o", PromptTokens: 100}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBatchWriter(b *testing.B) {
+	setupTestDB(b)
+	ctx, cancel := context.WithCancel(context.Background())
+	w := StartBatchWriter(ctx, 100, time.Second)
+	for i := 0; i < b.N; i++ {
+		w.Write(UsageEvent{Timestamp: int64(i), Tool: "Aider", Model: "gpt-4o", PromptTokens: 100})
+	}
+	cancel()
+	w.Wait()
+}
@@ -5,26 +5,107 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"sync/atomic"
 	"time"
 
+	"github.com/bangarangler/burnrate/internal/log"
+	"github.com/bangarangler/burnrate/internal/pricing"
+
 	_ "github.com/mattn/go-sqlite3"
 )
 
 var DB *sql.DB
 
-// InitDB initializes the SQLite database for historical tracking
+// readOnly is set when another process (the daemon) owns history writes.
+// Only one process may record live usage at a time, otherwise a dashboard and
+// a daemon watching the same logs would each insert every event.
+var readOnly atomic.Bool
+
+// SetReadOnly stops (or resumes) recording usage from this process
+func SetReadOnly(v bool) {
+	readOnly.Store(v)
+}
+
+// IsReadOnly reports whether this process has yielded writes to another
+func IsReadOnly() bool {
+	return readOnly.Load()
+}
+
+// historyDisabled says why history isn't being kept across runs (see
+// HistoryDisabled), or is "" when it is
+var historyDisabled atomic.Value
+
+// HistoryDisabled returns why history won't outlive this run, e.g.
+// "read-only home", or "" when it's recorded to ~/.burnrate as usual
+func HistoryDisabled() string {
+	reason, _ := historyDisabled.Load().(string)
+	return reason
+}
+
+// InitDB initializes the SQLite database for historical tracking. If
+// ~/.burnrate can't be written, as in some CI runners and sandboxes, it falls
+// back to a database in a temporary directory, so the live session and this
+// run's history still work, and HistoryDisabled says so.
 func InitDB() error {
+	historyDisabled.Store("")
 	home, err := os.UserHomeDir()
 	if err != nil {
+		historyDisabled.Store("no home directory")
 		return fmt.Errorf("failed to get home directory: %w", err)
 	}
 
 	dbDir := filepath.Join(home, ".burnrate")
-	if err := os.MkdirAll(dbDir, 0755); err != nil {
+	err = checkDirWritable(dbDir)
+	if err == nil {
+		if err := openDB(dbDir); err != nil {
+			historyDisabled.Store("database unavailable")
+			return err
+		}
+		return nil
+	}
+
+	historyDisabled.Store("read-only home")
+	tmp, tmpErr := os.MkdirTemp("", "burnrate-history-")
+	if tmpErr != nil {
+		return err
+	}
+	if err := openDB(tmp); err != nil {
+		return err
+	}
+	log.Warnf("history disabled: %v; recording this run to %s instead", err, tmp)
+	return nil
+}
+
+// checkDirWritable makes sure history can be recorded in dir: that it can
+// be created, and it and any existing database in it can be written.
+// Checking the files, rather than writing to the database, can't mistake a
+// database another process has locked for a read-only one.
+func checkDirWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
 		return fmt.Errorf("failed to create db directory: %w", err)
 	}
 
-	dbPath := filepath.Join(dbDir, "history.db")
+	probe, err := os.CreateTemp(dir, ".write-check-*")
+	if err != nil {
+		return fmt.Errorf("db directory is not writable: %w", err)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	db, err := os.OpenFile(filepath.Join(dir, "history.db"), os.O_WRONLY, 0)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("database is not writable: %w", err)
+	}
+	return db.Close()
+}
+
+// openDB opens (creating if needed) the history database in dir
+func openDB(dir string) error {
+	dbPath := filepath.Join(dir, "history.db")
 	db, err := sql.Open("sqlite3", dbPath)
 	if err != nil {
 		return fmt.Errorf("failed to open database: %w", err)
@@ -38,6 +119,26 @@ func InitDB() error {
 	return createTables()
 }
 
+// CheckWritable verifies the database accepts writes by inserting a row
+// inside a transaction that is rolled back, so nothing is recorded
+func CheckWritable() error {
+	if DB == nil {
+		return fmt.Errorf("database not initialized")
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+	INSERT INTO usage_events (timestamp, tool, model, prompt_tokens, completion_tokens, cost, cost_micros)
+	VALUES (0, 'burnrate-doctor', 'probe', 0, 0, 0, 0)
+	`)
+	return err
+}
+
 func createTables() error {
 	query := `
 	CREATE TABLE IF NOT EXISTS usage_events (
@@ -55,77 +156,470 @@ func createTables() error {
 	if err != nil {
 		return fmt.Errorf("failed to create tables: %w", err)
 	}
+	return migrate()
+}
+
+// migrate brings databases created by older versions up to the current schema
+func migrate() error {
+	if err := addMissingColumns(); err != nil {
+		return err
+	}
+	if err := backfillCostMicros(); err != nil {
+		return err
+	}
+	if err := addEventKeyIndex(); err != nil {
+		return err
+	}
+	return addNaturalKeyIndex()
+}
+
+// addMissingColumns adds columns introduced after the original schema
+func addMissingColumns() error {
+	columns := []struct {
+		name string
+		def  string
+	}{
+		{"cache_read_tokens", "INTEGER DEFAULT 0"},
+		{"cache_savings", "REAL DEFAULT 0.0"},
+		{"tag", "TEXT"},      // NULL for untagged events
+		{"provider", "TEXT"}, // NULL when unknown, and for events recorded before it was stored
+		{"project", "TEXT"},  // NULL when the tool's working directory is unknown
+		{"reasoning_tokens", "INTEGER DEFAULT 0"},
+		{"cost_micros", "INTEGER"}, // NULL until backfilled from cost
+		{"event_key", "TEXT"},      // NULL for events without one, and those recorded before it was stored
+	}
+
+	existing := make(map[string]bool)
+	rows, err := DB.Query(`PRAGMA table_info(usage_events)`)
+	if err != nil {
+		return fmt.Errorf("failed to read schema: %w", err)
+	}
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var dflt sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &dflt, &pk); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to read schema: %w", err)
+		}
+		existing[name] = true
+	}
+	rows.Close()
+
+	for _, col := range columns {
+		if existing[col.name] {
+			continue
+		}
+		if _, err := DB.Exec(fmt.Sprintf("ALTER TABLE usage_events ADD COLUMN %s %s", col.name, col.def)); err != nil {
+			return fmt.Errorf("failed to add column %s: %w", col.name, err)
+		}
+	}
+	return nil
+}
+
+// backfillCostMicros sets cost_micros, the exact cost that totals are summed
+// from, on rows recorded before it was stored (or by an older version since)
+func backfillCostMicros() error {
+	if _, err := DB.Exec(`
+	UPDATE usage_events SET cost_micros = CAST(ROUND(cost * 1000000) AS INTEGER)
+	WHERE cost_micros IS NULL
+	`); err != nil {
+		return fmt.Errorf("failed to backfill cost_micros: %w", err)
+	}
+	return nil
+}
+
+// addEventKeyIndex stores each keyed event once per tool and key (see
+// UsageEvent.EventKey)
+func addEventKeyIndex() error {
+	if _, err := DB.Exec(`
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_usage_event_key
+	ON usage_events(tool, event_key) WHERE event_key IS NOT NULL
+	`); err != nil {
+		return fmt.Errorf("failed to create unique index: %w", err)
+	}
 	return nil
 }
 
-// RecordUsage writes a single usage event to the database
-func RecordUsage(tool, model string, prompt, completion int, cost float64) error {
+// legacyKeyPrefix starts the placeholder keys given to repeated key-less
+// rows when the natural key index is added
+const legacyKeyPrefix = "legacy:"
+
+// addNaturalKeyIndex stores each key-less event once per timestamp, tool,
+// model, and tokens, so double writes are ignored by the database itself,
+// independent of the parsers' in-memory dedup. An earlier version indexed
+// keyed events that way too, merging distinct requests made in the same
+// second; that index is replaced. Repeats recorded before either index may
+// be such requests, so rather than being deleted they get a placeholder key
+// that keeps them out of the index.
+func addNaturalKeyIndex() error {
+	if _, err := DB.Exec(`DROP INDEX IF EXISTS idx_usage_natural_key`); err != nil {
+		return fmt.Errorf("failed to drop old natural key index: %w", err)
+	}
+
+	var count int
+	err := DB.QueryRow(`
+	SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name = 'idx_usage_keyless_event'
+	`).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("failed to read schema: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin migration: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+	UPDATE usage_events SET event_key = ? || id
+	WHERE event_key IS NULL AND id NOT IN (
+		SELECT MIN(id) FROM usage_events WHERE event_key IS NULL
+		GROUP BY timestamp, tool, model, prompt_tokens, completion_tokens
+	)
+	`, legacyKeyPrefix); err != nil {
+		return fmt.Errorf("failed to mark repeated events: %w", err)
+	}
+	if _, err := tx.Exec(`
+	CREATE UNIQUE INDEX idx_usage_keyless_event
+	ON usage_events(timestamp, tool, model, prompt_tokens, completion_tokens) WHERE event_key IS NULL
+	`); err != nil {
+		return fmt.Errorf("failed to create natural key index: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// UsageEvent is a single row in usage_events
+type UsageEvent struct {
+	Timestamp        int64 // Unix seconds
+	Tool             string
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+	CacheReadTokens  int     // Portion of PromptTokens served from cache
+	ReasoningTokens  int     // Portion of CompletionTokens spent thinking
+	Cost             float64 // Actual cost
+	CacheSavings     float64 // Cost avoided by cache reads vs full input price
+	Tag              string  // Session label for attribution; empty is stored as NULL
+	Provider         string  // Lowercased, see pricing.ProviderOf; empty is stored as NULL
+	Project          string  // Repository or directory worked in; empty is stored as NULL
+
+	// Identifies the event in its tool's logs, e.g. an Aider log entry, so
+	// it's stored once however often it's read. Empty is stored as NULL.
+	EventKey string
+}
+
+// RecordUsage writes a single usage event to the database.
+// Returns whether a row was inserted (false if it was a duplicate).
+func RecordUsage(tool, model string, prompt, completion int, cost float64) (bool, error) {
+	return RecordUsageAt(time.Now().Unix(), tool, model, prompt, completion, cost)
+}
+
+// RecordUsageAt writes a usage event with an explicit unix timestamp.
+// Parsers pass the event's own time so that re-reading a log (e.g. a backfill
+// after the dashboard already recorded it) finds the existing row and skips it.
+func RecordUsageAt(timestamp int64, tool, model string, prompt, completion int, cost float64) (bool, error) {
+	return RecordEvent(UsageEvent{
+		Timestamp:        timestamp,
+		Tool:             tool,
+		Model:            model,
+		PromptTokens:     prompt,
+		CompletionTokens: completion,
+		Cost:             cost,
+		Provider:         pricing.ProviderOf(model),
+	})
+}
+
+// RecordEvent writes a usage event with INSERT OR IGNORE. An event already
+// recorded (by its key, or for key-less events by timestamp, tool, model,
+// and tokens; see insertEvent) is skipped by the unique indexes. Returns
+// whether a row was actually inserted. It's a no-op while the process is
+// read-only (see SetReadOnly).
+func RecordEvent(e UsageEvent) (bool, error) {
 	if DB == nil {
-		return fmt.Errorf("database not initialized")
+		return false, fmt.Errorf("database not initialized")
+	}
+	if readOnly.Load() {
+		return false, nil
 	}
 
-	query := `
-	INSERT INTO usage_events (timestamp, tool, model, prompt_tokens, completion_tokens, cost)
-	VALUES (?, ?, ?, ?, ?, ?)
-	`
-	_, err := DB.Exec(query, time.Now().Unix(), tool, model, prompt, completion, cost)
-	return err
+	return insertEvent(DB, e)
 }
 
-// GetUsageSummary returns aggregated usage for a specific time window
-// since is a unix timestamp
-func GetUsageSummary(since int64) (map[string]struct {
+// ModelSummary is aggregated usage for one model over a time window
+type ModelSummary struct {
 	PromptTokens     int
 	CompletionTokens int
+	CacheReadTokens  int
+	ReasoningTokens  int
 	Cost             float64
-}, float64, error) {
+	CacheSavings     float64
+}
+
+// GetUsageSummary returns aggregated usage for a specific time window
+// since is a unix timestamp
+func GetUsageSummary(since int64) (map[string]ModelSummary, float64, error) {
+	return GetFilteredUsageSummary(since, UsageFilter{})
+}
+
+// UsageFilter narrows a usage query. Empty fields match everything.
+type UsageFilter struct {
+	Tool     string
+	Tag      string
+	Provider string // Lowercase
+	Project  string
+}
+
+// GetFilteredUsageSummary is GetUsageSummary restricted by filter
+func GetFilteredUsageSummary(since int64, filter UsageFilter) (map[string]ModelSummary, float64, error) {
 	if DB == nil {
 		return nil, 0, fmt.Errorf("database not initialized")
 	}
 
 	query := `
-	SELECT model, SUM(prompt_tokens), SUM(completion_tokens), SUM(cost)
+	SELECT model, SUM(prompt_tokens), SUM(completion_tokens), COALESCE(SUM(cost_micros), 0),
+		SUM(cache_read_tokens), SUM(cache_savings), SUM(reasoning_tokens)
 	FROM usage_events
 	WHERE timestamp >= ?
+		AND (? = '' OR tool = ?)
+		AND (? = '' OR tag = ?)
+		AND (? = '' OR provider = ?)
+		AND (? = '' OR project = ?)
 	GROUP BY model
-	ORDER BY SUM(cost) DESC
+	ORDER BY SUM(cost_micros) DESC
 	`
 
-	rows, err := DB.Query(query, since)
+	rows, err := DB.Query(query, since, filter.Tool, filter.Tool, filter.Tag, filter.Tag, filter.Provider, filter.Provider,
+		filter.Project, filter.Project)
 	if err != nil {
 		return nil, 0, err
 	}
 	defer rows.Close()
 
-	usageByModel := make(map[string]struct {
-		PromptTokens     int
-		CompletionTokens int
-		Cost             float64
-	})
-	var totalCost float64
+	usageByModel := make(map[string]ModelSummary)
+	var total pricing.Micros
 
 	for rows.Next() {
 		var model string
-		var prompt, completion int
-		var cost float64
+		var summary ModelSummary
+		var cost pricing.Micros
 
-		if err := rows.Scan(&model, &prompt, &completion, &cost); err != nil {
+		if err := rows.Scan(&model, &summary.PromptTokens, &summary.CompletionTokens, &cost,
+			&summary.CacheReadTokens, &summary.CacheSavings, &summary.ReasoningTokens); err != nil {
 			return nil, 0, err
 		}
 
-		usageByModel[model] = struct {
-			PromptTokens     int
-			CompletionTokens int
-			Cost             float64
-		}{
-			PromptTokens:     prompt,
-			CompletionTokens: completion,
-			Cost:             cost,
+		summary.Cost = cost.USD()
+		usageByModel[model] = summary
+		total += cost
+	}
+
+	return usageByModel, total.USD(), nil
+}
+
+// GetUsageSummaryByProvider returns aggregated usage per provider since a
+// unix timestamp. Events without a known provider are reported under "".
+func GetUsageSummaryByProvider(since int64) (map[string]ModelSummary, float64, error) {
+	return getUsageSummaryBy("provider", since, UsageFilter{})
+}
+
+// GetUsageSummaryByProject returns aggregated usage per project since a unix
+// timestamp, restricted by filter. Events without a known project are
+// reported under "".
+func GetUsageSummaryByProject(since int64, filter UsageFilter) (map[string]ModelSummary, float64, error) {
+	return getUsageSummaryBy("project", since, filter)
+}
+
+// getUsageSummaryBy aggregates usage since a unix timestamp by a nullable
+// text column, with NULL reported under ""
+func getUsageSummaryBy(column string, since int64, filter UsageFilter) (map[string]ModelSummary, float64, error) {
+	if DB == nil {
+		return nil, 0, fmt.Errorf("database not initialized")
+	}
+
+	rows, err := DB.Query(fmt.Sprintf(`
+	SELECT COALESCE(%[1]s, ''), SUM(prompt_tokens), SUM(completion_tokens), COALESCE(SUM(cost_micros), 0),
+		SUM(cache_read_tokens), SUM(cache_savings)
+	FROM usage_events
+	WHERE timestamp >= ?
+		AND (? = '' OR tool = ?)
+		AND (? = '' OR tag = ?)
+		AND (? = '' OR provider = ?)
+		AND (? = '' OR project = ?)
+	GROUP BY COALESCE(%[1]s, '')
+	`, column), since, filter.Tool, filter.Tool, filter.Tag, filter.Tag, filter.Provider, filter.Provider,
+		filter.Project, filter.Project)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	byKey := make(map[string]ModelSummary)
+	var total pricing.Micros
+	for rows.Next() {
+		var key string
+		var summary ModelSummary
+		var cost pricing.Micros
+		if err := rows.Scan(&key, &summary.PromptTokens, &summary.CompletionTokens, &cost,
+			&summary.CacheReadTokens, &summary.CacheSavings); err != nil {
+			return nil, 0, err
+		}
+		summary.Cost = cost.USD()
+		byKey[key] = summary
+		total += cost
+	}
+	return byKey, total.USD(), rows.Err()
+}
+
+// GetTokenTotals returns the prompt and completion tokens recorded since a
+// unix timestamp, summed across every model and tool
+func GetTokenTotals(since int64) (prompt, completion int, err error) {
+	if DB == nil {
+		return 0, 0, fmt.Errorf("database not initialized")
+	}
+
+	err = DB.QueryRow(`
+	SELECT COALESCE(SUM(prompt_tokens), 0), COALESCE(SUM(completion_tokens), 0)
+	FROM usage_events
+	WHERE timestamp >= ?
+	`, since).Scan(&prompt, &completion)
+	if err != nil {
+		return 0, 0, err
+	}
+	return prompt, completion, nil
+}
+
+// GetSpendByTag returns total cost per tag since a timestamp. Untagged
+// events are reported under "".
+func GetSpendByTag(since int64) (map[string]float64, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	rows, err := DB.Query(`
+	SELECT COALESCE(tag, ''), COALESCE(SUM(cost_micros), 0)
+	FROM usage_events
+	WHERE timestamp >= ?
+	GROUP BY COALESCE(tag, '')
+	`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	spend := make(map[string]float64)
+	for rows.Next() {
+		var tag string
+		var cost pricing.Micros
+		if err := rows.Scan(&tag, &cost); err != nil {
+			return nil, err
 		}
-		totalCost += cost
+		spend[tag] = cost.USD()
 	}
+	return spend, rows.Err()
+}
+
+// modelEventsLimit caps GetEventsForModel so a busy model's history stays
+// quick to load and scroll
+const modelEventsLimit = 500
 
-	return usageByModel, totalCost, nil
+// GetEventsForModel returns a model's individual events since a timestamp,
+// newest first, up to the most recent 500
+func GetEventsForModel(model string, since int64) ([]UsageEvent, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	rows, err := DB.Query(`
+	SELECT timestamp, tool, model, prompt_tokens, completion_tokens, cache_read_tokens, cost,
+		cache_savings, COALESCE(tag, '')
+	FROM usage_events
+	WHERE model = ? AND timestamp >= ?
+	ORDER BY timestamp DESC, id DESC
+	LIMIT ?
+	`, model, since, modelEventsLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []UsageEvent
+	for rows.Next() {
+		var e UsageEvent
+		if err := rows.Scan(&e.Timestamp, &e.Tool, &e.Model, &e.PromptTokens, &e.CompletionTokens,
+			&e.CacheReadTokens, &e.Cost, &e.CacheSavings, &e.Tag); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// GetTopEvents returns the most expensive individual events since a
+// timestamp, costliest first (newest first among equal costs)
+func GetTopEvents(since int64, limit int) ([]UsageEvent, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	rows, err := DB.Query(`
+	SELECT timestamp, tool, model, prompt_tokens, completion_tokens, cache_read_tokens, cost,
+		cache_savings, COALESCE(tag, '')
+	FROM usage_events
+	WHERE timestamp >= ?
+	ORDER BY cost DESC, timestamp DESC
+	LIMIT ?
+	`, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []UsageEvent
+	for rows.Next() {
+		var e UsageEvent
+		if err := rows.Scan(&e.Timestamp, &e.Tool, &e.Model, &e.PromptTokens, &e.CompletionTokens,
+			&e.CacheReadTokens, &e.Cost, &e.CacheSavings, &e.Tag); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// GetEvents returns every event since a timestamp, oldest first, for
+// analysis that needs each request rather than totals
+func GetEvents(since int64) ([]UsageEvent, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	rows, err := DB.Query(`
+	SELECT timestamp, tool, model, prompt_tokens, completion_tokens, cache_read_tokens,
+		reasoning_tokens, cost, cache_savings, COALESCE(tag, '')
+	FROM usage_events
+	WHERE timestamp >= ?
+	ORDER BY timestamp, id
+	`, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []UsageEvent
+	for rows.Next() {
+		var e UsageEvent
+		if err := rows.Scan(&e.Timestamp, &e.Tool, &e.Model, &e.PromptTokens, &e.CompletionTokens,
+			&e.CacheReadTokens, &e.ReasoningTokens, &e.Cost, &e.CacheSavings, &e.Tag); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
 }
 
 // DailySpend represents the total cost for a specific day
@@ -135,39 +629,80 @@ type DailySpend struct {
 }
 
 // GetDailyUsage returns aggregated usage for the last N days, sorted by date ascending
+// Days are calendar days in the configured timezone (see SetLocation)
 func GetDailyUsage(days int) ([]DailySpend, error) {
-	if DB == nil {
-		return nil, fmt.Errorf("database not initialized")
+	// Get N days of history including today
+	cutoff := StartOfDay(time.Now()).AddDate(0, 0, -days+1).Unix()
+
+	keys, totals, err := sumCostBy(cutoff, DayKey)
+	if err != nil {
+		return nil, err
 	}
 
-	// Get N days of history including today
-	cutoff := time.Now().AddDate(0, 0, -days+1).Truncate(24 * time.Hour).Unix()
+	results := make([]DailySpend, 0, len(keys))
+	for _, day := range keys {
+		results = append(results, DailySpend{Date: day, Cost: totals[day]})
+	}
+	return results, nil
+}
+
+// GetLifetimeStats returns the all-time total cost, event count, and the unix
+// timestamp of the first recorded event. An empty database yields zeros.
+func GetLifetimeStats() (total float64, events int, firstTs int64, err error) {
+	if DB == nil {
+		return 0, 0, 0, fmt.Errorf("database not initialized")
+	}
 
-	// We want to ensure we have entries for all days, even if 0 cost.
-	// But for now, let's just get the data we have and fill gaps in the UI or here.
-	// SQLITE's unixepoch requires seconds.
 	query := `
-	SELECT date(timestamp, 'unixepoch', 'localtime') as day, SUM(cost) 
-	FROM usage_events 
-	WHERE timestamp >= ? 
-	GROUP BY day 
-	ORDER BY day ASC
+	SELECT COALESCE(SUM(cost_micros), 0), COUNT(*), COALESCE(MIN(timestamp), 0)
+	FROM usage_events
 	`
+	var micros pricing.Micros
+	if err := DB.QueryRow(query).Scan(&micros, &events, &firstTs); err != nil {
+		return 0, 0, 0, err
+	}
+	return micros.USD(), events, firstTs, nil
+}
+
+// HourlySpend represents the total cost for a specific hour
+type HourlySpend struct {
+	Hour string // Formatted "2006-01-02 15" in the configured timezone
+	Cost float64
+}
+
+// GetHourlyUsage returns aggregated usage for the last N hours (including the
+// current one), sorted by hour ascending
+func GetHourlyUsage(hours int) ([]HourlySpend, error) {
+	cutoff := StartOfHour(time.Now()).Add(-time.Duration(hours-1) * time.Hour).Unix()
 
-	rows, err := DB.Query(query, cutoff)
+	keys, totals, err := sumCostBy(cutoff, HourKey)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var results []DailySpend
-	for rows.Next() {
-		var day string
-		var cost float64
-		if err := rows.Scan(&day, &cost); err != nil {
-			return nil, err
-		}
-		results = append(results, DailySpend{Date: day, Cost: cost})
+	results := make([]HourlySpend, 0, len(keys))
+	for _, hour := range keys {
+		results = append(results, HourlySpend{Hour: hour, Cost: totals[hour]})
 	}
 	return results, nil
 }
+
+// GetHourOfDayHistogram totals cost by hour of the day (0-23, in the
+// configured timezone) across every event since a timestamp, to show which
+// hours the spend falls in
+func GetHourOfDayHistogram(since int64) ([24]float64, error) {
+	var hours [24]float64
+
+	_, totals, err := sumCostBy(since, func(t time.Time) string {
+		return t.In(Location()).Format("15")
+	})
+	if err != nil {
+		return hours, err
+	}
+	for key, cost := range totals {
+		if hour, err := strconv.Atoi(key); err == nil {
+			hours[hour] = cost
+		}
+	}
+	return hours, nil
+}
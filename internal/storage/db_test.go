@@ -0,0 +1,532 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// setupTestDB points the database at a fresh temp home directory
+func setupTestDB(t testing.TB) {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	if err := InitDB(); err != nil {
+		t.Fatalf("InitDB failed: %v", err)
+	}
+	t.Cleanup(func() {
+		DB.Close()
+		DB = nil
+	})
+}
+
+func TestDailyUsageRespectsLocalMidnight(t *testing.T) {
+	setupTestDB(t)
+
+	// Pin a zone far from UTC so local and UTC dates disagree around midnight
+	loc := time.FixedZone("UTC-5", -5*60*60)
+	SetLocation(loc)
+	defer SetLocation(nil)
+
+	midnight := StartOfDay(time.Now())
+	if midnight.Location() != loc || midnight.Hour() != 0 {
+		t.Fatalf("StartOfDay returned %v, expected midnight in %v", midnight, loc)
+	}
+
+	// One event just before local midnight, one just after
+	before := midnight.Add(-1 * time.Minute)
+	after := midnight.Add(1 * time.Minute)
+	if _, err := RecordUsageAt(before.Unix(), "Test", "m", 1, 1, 1.0); err != nil {
+		t.Fatalf("RecordUsageAt failed: %v", err)
+	}
+	if _, err := RecordUsageAt(after.Unix(), "Test", "m", 2, 2, 2.0); err != nil {
+		t.Fatalf("RecordUsageAt failed: %v", err)
+	}
+
+	days, err := GetDailyUsage(2)
+	if err != nil {
+		t.Fatalf("GetDailyUsage failed: %v", err)
+	}
+	if len(days) != 2 {
+		t.Fatalf("Expected 2 days, got %d: %+v", len(days), days)
+	}
+
+	yesterday := before.In(loc).Format("2006-01-02")
+	today := after.In(loc).Format("2006-01-02")
+	if days[0].Date != yesterday || days[0].Cost != 1.0 {
+		t.Errorf("Expected %s = $1.00, got %s = $%.2f", yesterday, days[0].Date, days[0].Cost)
+	}
+	if days[1].Date != today || days[1].Cost != 2.0 {
+		t.Errorf("Expected %s = $2.00, got %s = $%.2f", today, days[1].Date, days[1].Cost)
+	}
+
+	// "Since midnight" must only include the event after midnight
+	summary, total, err := GetUsageSummary(midnight.Unix())
+	if err != nil {
+		t.Fatalf("GetUsageSummary failed: %v", err)
+	}
+	if total != 2.0 || summary["m"].PromptTokens != 2 {
+		t.Errorf("Expected only the post-midnight event today, got total $%.2f", total)
+	}
+}
+
+func TestRecordSameEventTwiceYieldsOneRow(t *testing.T) {
+	setupTestDB(t)
+
+	// e.g. the dashboard and a sync both reading the same log line
+	ts := time.Now().Unix()
+	for i := 0; i < 2; i++ {
+		inserted, err := RecordUsageAt(ts, "Aider", "gpt-4o", 100, 50, 0.01)
+		if err != nil {
+			t.Fatalf("RecordUsageAt failed: %v", err)
+		}
+		if want := i == 0; inserted != want {
+			t.Errorf("Write %d: expected inserted=%v, got %v", i+1, want, inserted)
+		}
+	}
+
+	_, events, _, err := GetLifetimeStats()
+	if err != nil {
+		t.Fatalf("GetLifetimeStats failed: %v", err)
+	}
+	if events != 1 {
+		t.Errorf("Expected 1 row, got %d", events)
+	}
+
+	// A read-only process (daemon owns writes) records nothing
+	SetReadOnly(true)
+	defer SetReadOnly(false)
+	if _, err := RecordUsageAt(ts+1, "Aider", "gpt-4o", 100, 50, 0.01); err != nil {
+		t.Fatalf("RecordUsageAt failed: %v", err)
+	}
+	if _, events, _, _ = GetLifetimeStats(); events != 1 {
+		t.Errorf("Expected read-only write to be skipped, got %d rows", events)
+	}
+}
+
+func TestMigrationKeepsSameSecondRows(t *testing.T) {
+	setupTestDB(t)
+
+	// 1. Simulate a database from before event keys, with the old natural
+	// key index and two same-second requests recorded without it
+	for _, index := range []string{"idx_usage_event_key", "idx_usage_keyless_event"} {
+		if _, err := DB.Exec("DROP INDEX " + index); err != nil {
+			t.Fatalf("failed to drop index: %v", err)
+		}
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := DB.Exec(`INSERT INTO usage_events (timestamp, tool, model, prompt_tokens, completion_tokens, cost)
+			VALUES (1735000000, 'Aider', 'gpt-4o', 100, 50, 0.01)`); err != nil {
+			t.Fatalf("failed to insert row: %v", err)
+		}
+	}
+	if _, err := DB.Exec(`CREATE INDEX idx_usage_natural_key
+		ON usage_events(timestamp, tool, model, prompt_tokens, completion_tokens)`); err != nil {
+		t.Fatalf("failed to create old index: %v", err)
+	}
+
+	// 2. Migrating keeps both rows and swaps the index
+	if err := migrate(); err != nil {
+		t.Fatalf("migrate failed: %v", err)
+	}
+	if _, events, _, _ := GetLifetimeStats(); events != 2 {
+		t.Errorf("Expected both rows kept, got %d", events)
+	}
+	index := func(name string) bool {
+		var count int
+		DB.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND name = ?`, name).Scan(&count)
+		return count > 0
+	}
+	if index("idx_usage_natural_key") || !index("idx_usage_keyless_event") {
+		t.Error("Expected the old natural key index replaced by the key-less one")
+	}
+	if inserted, _ := RecordUsageAt(1735000000, "Aider", "gpt-4o", 100, 50, 0.01); inserted {
+		t.Error("Expected a key-less repeat to be ignored")
+	}
+
+	// 3. Rereading the events with keys takes over the old rows instead of
+	// adding new ones
+	for _, key := range []string{"a#1", "a#2", "a#1"} {
+		inserted, err := RecordEvent(UsageEvent{Timestamp: 1735000000, Tool: "Aider", Model: "gpt-4o",
+			PromptTokens: 100, CompletionTokens: 50, Cost: 0.01, EventKey: key})
+		if err != nil {
+			t.Fatalf("RecordEvent failed: %v", err)
+		}
+		if inserted {
+			t.Errorf("Expected %s to match an existing row", key)
+		}
+	}
+	if _, events, _, _ := GetLifetimeStats(); events != 2 {
+		t.Errorf("Expected still 2 rows, got %d", events)
+	}
+}
+
+func TestSameSecondEventsStoredByKey(t *testing.T) {
+	setupTestDB(t)
+
+	event := UsageEvent{Timestamp: 1735000000, Tool: "Aider", Model: "gpt-4o",
+		PromptTokens: 100, CompletionTokens: 50, Cost: 0.01}
+
+	// 1. Two same-second requests with identical tokens are both stored,
+	// as their keys differ
+	for _, key := range []string{"a#1", "a#2"} {
+		event.EventKey = key
+		if inserted, err := RecordEvent(event); err != nil || !inserted {
+			t.Errorf("Expected %s to be inserted, got %v (err %v)", key, inserted, err)
+		}
+	}
+
+	// 2. A repeated key is skipped, in a batch too
+	if inserted, _ := RecordEvent(event); inserted {
+		t.Error("Expected a repeated key to be skipped")
+	}
+	event.EventKey = "a#3"
+	if n, err := RecordEvents([]UsageEvent{event, event}); err != nil || n != 1 {
+		t.Errorf("Expected 1 of 2 batched repeats inserted, got %d (err %v)", n, err)
+	}
+
+	// 3. Key-less events still dedup on their timestamp, tool, model, and
+	// tokens
+	event.EventKey = ""
+	event.Timestamp++
+	for i, want := range []bool{true, false} {
+		if inserted, _ := RecordEvent(event); inserted != want {
+			t.Errorf("Expected key-less write %d inserted=%v, got %v", i+1, want, inserted)
+		}
+	}
+	if _, events, _, _ := GetLifetimeStats(); events != 4 {
+		t.Errorf("Expected 4 rows, got %d", events)
+	}
+}
+
+func TestToolUsageSummaryFiltersByTool(t *testing.T) {
+	setupTestDB(t)
+
+	ts := time.Now().Unix()
+	RecordUsageAt(ts, "Aider", "gpt-4o", 100, 50, 1.0)
+	RecordUsageAt(ts, "OpenCode", "gpt-4o", 200, 100, 2.0)
+	RecordUsageAt(ts, "OpenCode", "claude-sonnet-4", 10, 10, 0.5)
+
+	summary, total, err := GetFilteredUsageSummary(0, UsageFilter{Tool: "OpenCode"})
+	if err != nil {
+		t.Fatalf("GetFilteredUsageSummary failed: %v", err)
+	}
+	if total != 2.5 || len(summary) != 2 || summary["gpt-4o"].PromptTokens != 200 {
+		t.Errorf("Expected only OpenCode usage ($2.50 across 2 models), got $%.2f: %+v", total, summary)
+	}
+
+	// An empty tool means every tool
+	if _, total, _ := GetFilteredUsageSummary(0, UsageFilter{}); total != 3.5 {
+		t.Errorf("Expected $3.50 across all tools, got $%.2f", total)
+	}
+}
+
+func TestTaggedEventsFilterAndGroup(t *testing.T) {
+	setupTestDB(t)
+
+	ts := time.Now().Unix()
+	RecordEvent(UsageEvent{Timestamp: ts, Tool: "Aider", Model: "gpt-4o", PromptTokens: 1, Cost: 1.0, Tag: "client-a"})
+	RecordEvent(UsageEvent{Timestamp: ts, Tool: "Aider", Model: "gpt-4o", PromptTokens: 2, Cost: 2.0, Tag: "client-b"})
+	RecordEvent(UsageEvent{Timestamp: ts, Tool: "Aider", Model: "gpt-4o", PromptTokens: 3, Cost: 4.0})
+
+	// 1. A tag filter only sees that tag's events
+	if _, total, _ := GetFilteredUsageSummary(0, UsageFilter{Tag: "client-a"}); total != 1.0 {
+		t.Errorf("Expected $1.00 for client-a, got $%.2f", total)
+	}
+
+	// 2. Untagged events are still included without a filter
+	if _, total, _ := GetUsageSummary(0); total != 7.0 {
+		t.Errorf("Expected $7.00 unfiltered, got $%.2f", total)
+	}
+
+	// 3. Spend groups by tag, with untagged under ""
+	spend, err := GetSpendByTag(0)
+	if err != nil {
+		t.Fatalf("GetSpendByTag failed: %v", err)
+	}
+	if spend["client-a"] != 1.0 || spend["client-b"] != 2.0 || spend[""] != 4.0 {
+		t.Errorf("Unexpected spend by tag: %v", spend)
+	}
+}
+
+func TestProviderIsStoredAndQueried(t *testing.T) {
+	setupTestDB(t)
+
+	ts := time.Now().Unix()
+	RecordUsageAt(ts, "OpenCode", "claude-sonnet-4 (anthropic)", 100, 10, 1.0)
+	RecordUsageAt(ts, "Aider", "gpt-4o", 200, 20, 2.0)
+	RecordUsageAt(ts, "Aider", "my-private-model", 300, 30, 4.0)
+
+	// 1. Each event's provider is stored, and unknown ones are grouped under ""
+	byProvider, total, err := GetUsageSummaryByProvider(0)
+	if err != nil {
+		t.Fatalf("GetUsageSummaryByProvider failed: %v", err)
+	}
+	if total != 7.0 || byProvider["anthropic"].Cost != 1.0 || byProvider["openai"].PromptTokens != 200 || byProvider[""].Cost != 4.0 {
+		t.Errorf("Expected anthropic $1, openai 200 tokens, and unknown $4, got %+v", byProvider)
+	}
+
+	// 2. The provider filter only sees that provider's events
+	summary, total, _ := GetFilteredUsageSummary(0, UsageFilter{Provider: "anthropic"})
+	if total != 1.0 || len(summary) != 1 {
+		t.Errorf("Expected only the anthropic event, got $%.2f: %+v", total, summary)
+	}
+}
+
+func TestProjectIsStoredAndQueried(t *testing.T) {
+	setupTestDB(t)
+
+	ts := time.Now().Unix()
+	RecordEvent(UsageEvent{Timestamp: ts, Tool: "Crush", Model: "gpt-4o", PromptTokens: 100, Cost: 1.0, Project: "github.com/acme/api"})
+	RecordEvent(UsageEvent{Timestamp: ts, Tool: "OpenCode", Model: "gpt-4o", PromptTokens: 200, Cost: 2.0, Project: "github.com/acme/api"})
+	RecordEvent(UsageEvent{Timestamp: ts, Tool: "Aider", Model: "gpt-4o", PromptTokens: 300, Cost: 4.0})
+
+	// 1. Spend is summed per project, with unknown projects under ""
+	byProject, total, err := GetUsageSummaryByProject(0, UsageFilter{})
+	if err != nil {
+		t.Fatalf("GetUsageSummaryByProject failed: %v", err)
+	}
+	if total != 7.0 || byProject["github.com/acme/api"].Cost != 3.0 || byProject[""].PromptTokens != 300 {
+		t.Errorf("Expected acme/api $3 and unknown 300 tokens, got %+v", byProject)
+	}
+
+	// 2. The project filter only sees that project's events
+	summary, total, _ := GetFilteredUsageSummary(0, UsageFilter{Project: "github.com/acme/api", Tool: "Crush"})
+	if total != 1.0 || summary["gpt-4o"].PromptTokens != 100 {
+		t.Errorf("Expected only the Crush event in acme/api, got $%.2f: %+v", total, summary)
+	}
+}
+
+func TestGetEventsForModelNewestFirst(t *testing.T) {
+	setupTestDB(t)
+
+	now := time.Now().Unix()
+	RecordUsageAt(now-300, "Aider", "gpt-4o", 10, 5, 0.1)
+	RecordUsageAt(now-100, "Crush", "gpt-4o", 20, 10, 0.2)
+	RecordUsageAt(now-200, "Aider", "claude-sonnet-4", 30, 15, 0.3)
+	RecordUsageAt(now-90000, "Aider", "gpt-4o", 40, 20, 0.4) // Outside the window
+
+	events, err := GetEventsForModel("gpt-4o", now-3600)
+	if err != nil {
+		t.Fatalf("GetEventsForModel failed: %v", err)
+	}
+
+	// 1. Only the model's events in the window
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d: %+v", len(events), events)
+	}
+
+	// 2. Newest first, with their own tool and tokens
+	if events[0].Tool != "Crush" || events[0].PromptTokens != 20 {
+		t.Errorf("Expected the Crush event first, got %+v", events[0])
+	}
+	if events[1].Timestamp != now-300 {
+		t.Errorf("Expected the older event second, got %+v", events[1])
+	}
+}
+
+func TestGetTopEventsCostliestFirst(t *testing.T) {
+	setupTestDB(t)
+
+	now := time.Now().Unix()
+	RecordUsageAt(now-300, "Aider", "gpt-4o", 10, 5, 0.10)
+	RecordUsageAt(now-200, "Crush", "claude-opus-4", 20, 10, 2.50)
+	RecordUsageAt(now-100, "Aider", "claude-sonnet-4", 30, 15, 0.75)
+	RecordUsageAt(now-90000, "Aider", "claude-opus-4", 40, 20, 9.00) // Outside the window
+
+	events, err := GetTopEvents(now-3600, 2)
+	if err != nil {
+		t.Fatalf("GetTopEvents failed: %v", err)
+	}
+
+	// 1. Limited to the window and the requested count
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d: %+v", len(events), events)
+	}
+
+	// 2. Costliest first
+	if events[0].Cost != 2.50 || events[1].Cost != 0.75 {
+		t.Errorf("Expected $2.50 then $0.75, got %+v", events)
+	}
+}
+
+func TestHourOfDayHistogramUsesLocalHours(t *testing.T) {
+	setupTestDB(t)
+
+	loc := time.FixedZone("UTC+9", 9*60*60)
+	SetLocation(loc)
+	defer SetLocation(nil)
+
+	// Two days' events at 09:xx and 23:xx local, plus one before the window
+	day := time.Date(2025, 6, 10, 0, 0, 0, 0, loc)
+	events := []struct {
+		at   time.Time
+		cost float64
+	}{
+		{day.Add(9*time.Hour + 5*time.Minute), 1.0},
+		{day.Add(23*time.Hour + 59*time.Minute), 2.0},
+		{day.AddDate(0, 0, 1).Add(9*time.Hour + 30*time.Minute), 0.5},
+		{day.Add(-time.Hour), 4.0},
+	}
+	for _, e := range events {
+		if _, err := RecordUsageAt(e.at.Unix(), "Test", "m", 1, 1, e.cost); err != nil {
+			t.Fatalf("RecordUsageAt failed: %v", err)
+		}
+	}
+
+	hours, err := GetHourOfDayHistogram(day.Unix())
+	if err != nil {
+		t.Fatalf("GetHourOfDayHistogram failed: %v", err)
+	}
+
+	// 1. Events land in their local hour, summed across days
+	if hours[9] != 1.5 || hours[23] != 2.0 {
+		t.Errorf("Expected $1.50 at 09 and $2.00 at 23, got $%.2f and $%.2f", hours[9], hours[23])
+	}
+	// 2. Nothing else, including the 00:00 UTC hour and the event before since
+	var total float64
+	for _, cost := range hours {
+		total += cost
+	}
+	if total != 3.5 {
+		t.Errorf("Expected $3.50 in total, got $%.2f: %v", total, hours)
+	}
+}
+
+func TestGetTokenTotalsSumsWindow(t *testing.T) {
+	setupTestDB(t)
+
+	now := time.Now()
+	RecordUsageAt(now.Add(-2*time.Hour).Unix(), "Aider", "gpt-4o", 1000, 200, 0.01)
+	RecordUsageAt(now.Add(-time.Hour).Unix(), "OpenCode", "claude-sonnet-4", 3000, 400, 0.02)
+	RecordUsageAt(now.AddDate(0, 0, -3).Unix(), "Aider", "gpt-4o", 9000, 900, 0.05)
+
+	// 1. Only events since the timestamp count, across models and tools
+	prompt, completion, err := GetTokenTotals(now.Add(-3 * time.Hour).Unix())
+	if err != nil {
+		t.Fatalf("GetTokenTotals failed: %v", err)
+	}
+	if prompt != 4000 || completion != 600 {
+		t.Errorf("Expected 4000 in / 600 out, got %d / %d", prompt, completion)
+	}
+
+	// 2. An empty window is zero, not an error
+	prompt, completion, err = GetTokenTotals(now.Add(time.Hour).Unix())
+	if err != nil || prompt != 0 || completion != 0 {
+		t.Errorf("Expected zeros and no error, got %d / %d, %v", prompt, completion, err)
+	}
+}
+
+func TestInitDBFallsBackWhenHomeIsReadOnly(t *testing.T) {
+	// A file where ~/.burnrate should be makes it unwritable, even for root
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("TMPDIR", t.TempDir())
+	if err := os.WriteFile(filepath.Join(home, ".burnrate"), nil, 0644); err != nil {
+		t.Fatalf("failed to block the db dir: %v", err)
+	}
+	t.Cleanup(func() {
+		if DB != nil {
+			DB.Close()
+			DB = nil
+		}
+		historyDisabled.Store("")
+	})
+
+	// 1. InitDB still succeeds, and says history is disabled
+	if err := InitDB(); err != nil {
+		t.Fatalf("Expected a fallback database, got %v", err)
+	}
+	if reason := HistoryDisabled(); reason != "read-only home" {
+		t.Errorf("Expected history disabled for a read-only home, got %q", reason)
+	}
+
+	// 2. The fallback database records and reads back this run's usage
+	if _, err := RecordUsageAt(time.Now().Unix(), "Test", "m", 1, 1, 1.5); err != nil {
+		t.Fatalf("RecordUsageAt failed: %v", err)
+	}
+	if _, total, err := GetUsageSummary(0); err != nil || total != 1.5 {
+		t.Errorf("Expected $1.50 recorded, got $%.2f (%v)", total, err)
+	}
+
+	// 3. A writable home keeps history as usual
+	if err := os.Remove(filepath.Join(home, ".burnrate")); err != nil {
+		t.Fatalf("failed to unblock the db dir: %v", err)
+	}
+	DB.Close()
+	if err := InitDB(); err != nil {
+		t.Fatalf("InitDB failed: %v", err)
+	}
+	if reason := HistoryDisabled(); reason != "" {
+		t.Errorf("Expected history enabled, got %q", reason)
+	}
+}
+
+func TestGetEventsOldestFirst(t *testing.T) {
+	setupTestDB(t)
+
+	now := time.Now().Unix()
+	RecordEvent(UsageEvent{Timestamp: now - 100, Tool: "OpenCode", Model: "gpt-5", PromptTokens: 30,
+		CompletionTokens: 15, CacheReadTokens: 10, ReasoningTokens: 5, Cost: 0.75})
+	RecordUsageAt(now-200, "Aider", "gpt-4o", 20, 10, 0.10)
+	RecordUsageAt(now-90000, "Aider", "gpt-4o", 40, 20, 9.00) // Outside the window
+
+	events, err := GetEvents(now - 3600)
+	if err != nil {
+		t.Fatalf("GetEvents failed: %v", err)
+	}
+
+	// 1. Every event in the window, oldest first
+	if len(events) != 2 || events[0].Model != "gpt-4o" || events[1].Model != "gpt-5" {
+		t.Fatalf("Expected gpt-4o then gpt-5, got %+v", events)
+	}
+
+	// 2. With each request's token split
+	if e := events[1]; e.CacheReadTokens != 10 || e.ReasoningTokens != 5 {
+		t.Errorf("Expected 10 cache read and 5 reasoning tokens, got %d and %d", e.CacheReadTokens, e.ReasoningTokens)
+	}
+}
+
+func TestCostTotalsAreExact(t *testing.T) {
+	setupTestDB(t)
+
+	// 1. Ten thousand hundredth-of-a-cent events total exactly $1
+	ts := time.Now().Unix() - 20_000
+	events := make([]UsageEvent, 10_000)
+	for i := range events {
+		events[i] = UsageEvent{Timestamp: ts + int64(i), Tool: "Aider", Model: "gpt-4o-mini",
+			PromptTokens: 10, CompletionTokens: 5, Cost: 0.0001}
+	}
+	if _, err := RecordEvents(events); err != nil {
+		t.Fatalf("RecordEvents failed: %v", err)
+	}
+	if _, total, err := GetFilteredUsageSummary(0, UsageFilter{}); err != nil || total != 1.0 {
+		t.Errorf("Expected a summary total of exactly $1, got %.17f (err %v)", total, err)
+	}
+	if total, _, _, err := GetLifetimeStats(); err != nil || total != 1.0 {
+		t.Errorf("Expected a lifetime total of exactly $1, got %.17f (err %v)", total, err)
+	}
+
+	// 2. Rows from before cost_micros was stored are backfilled from cost
+	if _, err := DB.Exec(`UPDATE usage_events SET cost_micros = NULL`); err != nil {
+		t.Fatalf("clearing cost_micros failed: %v", err)
+	}
+	if err := migrate(); err != nil {
+		t.Fatalf("migrate failed: %v", err)
+	}
+	if total, _, _, _ := GetLifetimeStats(); total != 1.0 {
+		t.Errorf("Expected backfilled rows to total exactly $1, got %.17f", total)
+	}
+}
+
+func TestLifetimeStatsEmptyDatabase(t *testing.T) {
+	setupTestDB(t)
+
+	total, events, firstTs, err := GetLifetimeStats()
+	if err != nil {
+		t.Fatalf("GetLifetimeStats failed: %v", err)
+	}
+	if total != 0 || events != 0 || firstTs != 0 {
+		t.Errorf("Expected zeros for an empty database, got $%f, %d events, first %d", total, events, firstTs)
+	}
+}
@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+)
+
+// forecastDays is how many full days the forecast averages over
+const forecastDays = 7
+
+// MonthForecast projects this month's spend from recent history
+type MonthForecast struct {
+	SoFar     float64 // Spent this month until now
+	DailyRate float64 // Trailing average spend per day
+	MonthEnd  float64 // SoFar plus the rest of the month at the trailing rate
+	Days      float64 // Days of history behind DailyRate (0 = nothing to forecast from)
+}
+
+// GetMonthForecast projects month-end spend from the trailing week's average
+// (see getMonthForecastAt)
+func GetMonthForecast() (MonthForecast, error) {
+	return getMonthForecastAt(time.Now())
+}
+
+// getMonthForecastAt adds the rest of the month, at the average daily spend
+// of the last forecastDays full days, to what's been spent so far. Any seven
+// days hold five weekdays and two weekend days, so each gets its own
+// average, and the remaining days are projected at the one that matches.
+// When history starts within that window, the time since the first event
+// (a fraction for its partial first day) is averaged over instead. The rest
+// of today counts for the fraction of the day left.
+func getMonthForecastAt(now time.Time) (MonthForecast, error) {
+	if DB == nil {
+		return MonthForecast{}, fmt.Errorf("database not initialized")
+	}
+
+	today := StartOfDay(now)
+	monthStart, _ := WindowStart("month", now)
+	trailingStart := today.AddDate(0, 0, -forecastDays)
+	since := monthStart
+	if trailingStart.Before(since) {
+		since = trailingStart
+	}
+
+	_, totals, err := sumCostBy(since.Unix(), DayKey)
+	if err != nil {
+		return MonthForecast{}, err
+	}
+	var firstTs int64
+	if err := DB.QueryRow(`SELECT COALESCE(MIN(timestamp), 0) FROM usage_events`).Scan(&firstTs); err != nil {
+		return MonthForecast{}, err
+	}
+
+	var f MonthForecast
+	for day := monthStart; !day.After(today); day = day.AddDate(0, 0, 1) {
+		f.SoFar += totals[DayKey(day)]
+	}
+	f.MonthEnd = f.SoFar
+	if firstTs == 0 {
+		return f, nil
+	}
+
+	var weekdays, weekends float64
+	for day := trailingStart; day.Before(today); day = day.AddDate(0, 0, 1) {
+		if isWeekend(day) {
+			weekends += totals[DayKey(day)]
+		} else {
+			weekdays += totals[DayKey(day)]
+		}
+	}
+
+	f.Days = forecastDays
+	if first := time.Unix(firstTs, 0); first.After(trailingStart) {
+		f.Days = today.Sub(first).Hours() / 24
+	}
+	if f.Days <= 0 {
+		// History started today; there's no full day to go on yet
+		f.Days = 0
+		return f, nil
+	}
+
+	f.DailyRate = (weekdays + weekends) / f.Days
+	weekdayRate, weekendRate := f.DailyRate, f.DailyRate
+	if f.Days == forecastDays {
+		weekdayRate, weekendRate = weekdays/5, weekends/2
+	}
+	rate := func(day time.Time) float64 {
+		if isWeekend(day) {
+			return weekendRate
+		}
+		return weekdayRate
+	}
+
+	tomorrow := today.AddDate(0, 0, 1)
+	f.MonthEnd += rate(today) * tomorrow.Sub(now).Hours() / tomorrow.Sub(today).Hours()
+	nextMonth := monthStart.AddDate(0, 1, 0)
+	for day := tomorrow; day.Before(nextMonth); day = day.AddDate(0, 0, 1) {
+		f.MonthEnd += rate(day)
+	}
+	return f, nil
+}
+
+// isWeekend reports whether a day falls on Saturday or Sunday
+func isWeekend(day time.Time) bool {
+	weekday := day.In(Location()).Weekday()
+	return weekday == time.Saturday || weekday == time.Sunday
+}
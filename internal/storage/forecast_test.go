@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestMonthForecastFromTrailingWeek(t *testing.T) {
+	setupTestDB(t)
+	SetLocation(time.UTC)
+	defer SetLocation(nil)
+
+	// Wednesday noon, June 18. The trailing week is June 11-17: five
+	// weekdays at $2 and a weekend at $0.50 a day, after $1 a day before it
+	now := time.Date(2025, 6, 18, 12, 0, 0, 0, time.UTC)
+	for day := 1; day <= 17; day++ {
+		at := time.Date(2025, 6, day, 12, 0, 0, 0, time.UTC)
+		cost := 1.0
+		if day >= 11 {
+			cost = 2.0
+			if isWeekend(at) {
+				cost = 0.5
+			}
+		}
+		RecordUsageAt(at.Unix(), "Test", "m", 1, 1, cost)
+	}
+	RecordUsageAt(now.Add(-2*time.Hour).Unix(), "Test", "m", 1, 1, 1)
+
+	f, err := getMonthForecastAt(now)
+	if err != nil {
+		t.Fatalf("getMonthForecastAt failed: %v", err)
+	}
+
+	// 1. Spend so far covers the whole month, today included
+	if math.Abs(f.SoFar-22) > 1e-9 {
+		t.Errorf("Expected $22.00 so far, got $%.4f", f.SoFar)
+	}
+
+	// 2. The rate averages the full trailing week
+	if f.Days != 7 || math.Abs(f.DailyRate-11.0/7) > 1e-9 {
+		t.Errorf("Expected 7 days at $%.4f/day, got %v at $%.4f", 11.0/7, f.Days, f.DailyRate)
+	}
+
+	// 3. Half of today at the weekday rate ($1), then 8 weekdays ($16) and
+	// 4 weekend days ($2) to June 30
+	if math.Abs(f.MonthEnd-41) > 1e-9 {
+		t.Errorf("Expected $41.00 at month-end, got $%.4f", f.MonthEnd)
+	}
+}
+
+func TestMonthForecastWithShortHistory(t *testing.T) {
+	setupTestDB(t)
+	SetLocation(time.UTC)
+	defer SetLocation(nil)
+
+	now := time.Date(2025, 6, 18, 12, 0, 0, 0, time.UTC)
+
+	// 1. History that starts today has no full day to average
+	RecordUsageAt(now.Add(-2*time.Hour).Unix(), "Test", "m", 1, 1, 1)
+	f, err := getMonthForecastAt(now)
+	if err != nil {
+		t.Fatalf("getMonthForecastAt failed: %v", err)
+	}
+	if f.Days != 0 || f.MonthEnd != f.SoFar {
+		t.Errorf("Expected no forecast beyond $%.2f, got %+v", f.SoFar, f)
+	}
+
+	// 2. Tracking that began Sunday evening averages over 2.25 days,
+	// without the weekday/weekend split
+	RecordUsageAt(time.Date(2025, 6, 15, 18, 0, 0, 0, time.UTC).Unix(), "Test", "m", 1, 1, 0.9)
+	RecordUsageAt(time.Date(2025, 6, 16, 12, 0, 0, 0, time.UTC).Unix(), "Test", "m", 1, 1, 0.9)
+	RecordUsageAt(time.Date(2025, 6, 17, 12, 0, 0, 0, time.UTC).Unix(), "Test", "m", 1, 1, 0.9)
+	f, err = getMonthForecastAt(now)
+	if err != nil {
+		t.Fatalf("getMonthForecastAt failed: %v", err)
+	}
+	if f.Days != 2.25 || math.Abs(f.DailyRate-1.2) > 1e-9 {
+		t.Errorf("Expected 2.25 days at $1.20/day, got %v at $%.4f", f.Days, f.DailyRate)
+	}
+	// $3.70 so far, then half of today and 12 more days at $1.20
+	if math.Abs(f.MonthEnd-18.7) > 1e-9 {
+		t.Errorf("Expected $18.70 at month-end, got $%.4f", f.MonthEnd)
+	}
+}
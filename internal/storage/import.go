@@ -0,0 +1,289 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ImportRow is a single usage event in an import file.
+// CSV files use the same names as header columns.
+type ImportRow struct {
+	Timestamp        json.RawMessage `json:"timestamp"` // Unix seconds or RFC3339
+	Tool             string          `json:"tool"`
+	Model            string          `json:"model"`
+	PromptTokens     int             `json:"prompt_tokens"`
+	CompletionTokens int             `json:"completion_tokens"`
+	Cost             float64         `json:"cost"`
+}
+
+// ImportError reports rows that were skipped during an import.
+// Rows that parsed correctly are still imported.
+type ImportError struct {
+	Skipped []string // "line N: reason"
+}
+
+func (e *ImportError) Error() string {
+	return fmt.Sprintf("skipped %d malformed rows", len(e.Skipped))
+}
+
+// importEvent is a validated row ready for insertion
+type importEvent struct {
+	timestamp        int64
+	tool             string
+	model            string
+	promptTokens     int
+	completionTokens int
+	cost             float64
+}
+
+// ImportEvents reads usage events from r in "csv" or "json" format and inserts
+// them into the database. Rows already present (same timestamp, tool, model,
+// and token counts) are skipped so re-importing a file is harmless.
+// Returns the number of rows inserted. Malformed rows are skipped and reported
+// via an *ImportError alongside the count.
+func ImportEvents(r io.Reader, format string) (int, error) {
+	if DB == nil {
+		return 0, fmt.Errorf("database not initialized")
+	}
+
+	var events []importEvent
+	var skipped []string
+	var err error
+
+	switch strings.ToLower(format) {
+	case "csv":
+		events, skipped, err = readCSVEvents(r)
+	case "json":
+		events, skipped, err = readJSONEvents(r)
+	default:
+		return 0, fmt.Errorf("unsupported format: %s (use csv or json)", format)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin import: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Rows that are already recorded are skipped (see insertEvent)
+	imported := 0
+	for _, ev := range events {
+		inserted, err := insertEvent(tx, UsageEvent{
+			Timestamp:        ev.timestamp,
+			Tool:             ev.tool,
+			Model:            ev.model,
+			PromptTokens:     ev.promptTokens,
+			CompletionTokens: ev.completionTokens,
+			Cost:             ev.cost,
+		})
+		if err != nil {
+			return 0, fmt.Errorf("failed to insert event: %w", err)
+		}
+		if inserted {
+			imported++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit import: %w", err)
+	}
+
+	if len(skipped) > 0 {
+		return imported, &ImportError{Skipped: skipped}
+	}
+	return imported, nil
+}
+
+// readCSVEvents parses a CSV file with a header row naming the columns
+func readCSVEvents(r io.Reader) ([]importEvent, []string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1 // Validate row width ourselves
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+
+	cols := make(map[string]int)
+	for i, name := range header {
+		cols[strings.TrimSpace(strings.ToLower(name))] = i
+	}
+	for _, required := range []string{"timestamp", "tool", "model"} {
+		if _, ok := cols[required]; !ok {
+			return nil, nil, fmt.Errorf("CSV header missing required column %q", required)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := cols[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var events []importEvent
+	var skipped []string
+	line := 1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("line %d: %v", line, err))
+			continue
+		}
+		if len(record) != len(header) {
+			skipped = append(skipped, fmt.Sprintf("line %d: expected %d columns, got %d", line, len(header), len(record)))
+			continue
+		}
+
+		ev, err := parseImportFields(
+			field(record, "timestamp"),
+			field(record, "tool"),
+			field(record, "model"),
+			field(record, "prompt_tokens"),
+			field(record, "completion_tokens"),
+			field(record, "cost"),
+		)
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("line %d: %v", line, err))
+			continue
+		}
+		events = append(events, ev)
+	}
+
+	return events, skipped, nil
+}
+
+// readJSONEvents parses either a JSON array of rows or one JSON object per line
+func readJSONEvents(r io.Reader) ([]importEvent, []string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var events []importEvent
+	var skipped []string
+
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var raw []json.RawMessage
+		if err := json.Unmarshal(trimmed, &raw); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse JSON array: %w", err)
+		}
+		for i, item := range raw {
+			ev, err := parseImportJSON(item)
+			if err != nil {
+				skipped = append(skipped, fmt.Sprintf("item %d: %v", i+1, err))
+				continue
+			}
+			events = append(events, ev)
+		}
+		return events, skipped, nil
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	buf := make([]byte, 0, 64*1024)
+	scanner.Buffer(buf, 1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		ev, err := parseImportJSON([]byte(text))
+		if err != nil {
+			skipped = append(skipped, fmt.Sprintf("line %d: %v", line, err))
+			continue
+		}
+		events = append(events, ev)
+	}
+
+	return events, skipped, scanner.Err()
+}
+
+func parseImportJSON(data []byte) (importEvent, error) {
+	var row ImportRow
+	if err := json.Unmarshal(data, &row); err != nil {
+		return importEvent{}, err
+	}
+
+	// Timestamp may be a number or a string
+	ts := strings.Trim(string(row.Timestamp), `"`)
+
+	return parseImportFields(ts, row.Tool, row.Model,
+		strconv.Itoa(row.PromptTokens), strconv.Itoa(row.CompletionTokens),
+		strconv.FormatFloat(row.Cost, 'f', -1, 64))
+}
+
+// parseImportFields validates a row and converts it to an importEvent
+func parseImportFields(ts, tool, model, prompt, completion, cost string) (importEvent, error) {
+	ev := importEvent{tool: tool, model: model}
+
+	if tool == "" {
+		return ev, fmt.Errorf("missing tool")
+	}
+	if model == "" {
+		return ev, fmt.Errorf("missing model")
+	}
+
+	timestamp, err := parseImportTimestamp(ts)
+	if err != nil {
+		return ev, err
+	}
+	ev.timestamp = timestamp
+
+	if ev.promptTokens, err = parseImportInt(prompt, "prompt_tokens"); err != nil {
+		return ev, err
+	}
+	if ev.completionTokens, err = parseImportInt(completion, "completion_tokens"); err != nil {
+		return ev, err
+	}
+
+	if cost != "" {
+		ev.cost, err = strconv.ParseFloat(cost, 64)
+		if err != nil || ev.cost < 0 {
+			return ev, fmt.Errorf("invalid cost %q", cost)
+		}
+	}
+
+	return ev, nil
+}
+
+func parseImportTimestamp(ts string) (int64, error) {
+	if ts == "" || ts == "null" {
+		return 0, fmt.Errorf("missing timestamp")
+	}
+	if n, err := strconv.ParseInt(ts, 10, 64); err == nil && n > 0 {
+		return n, nil
+	}
+	if t, err := time.Parse(time.RFC3339, ts); err == nil {
+		return t.Unix(), nil
+	}
+	return 0, fmt.Errorf("invalid timestamp %q", ts)
+}
+
+func parseImportInt(s, name string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s %q", name, s)
+	}
+	return n, nil
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestImportCSVSkipsMalformedRows(t *testing.T) {
+	setupTestDB(t)
+
+	csv := `timestamp,tool,model,prompt_tokens,completion_tokens,cost
+1735000000,Aider,gpt-4o,100,50,0.01
+2025-01-01T00:00:10Z,OpenCode,claude-sonnet-4,200,100,0.02
+not-a-time,Aider,gpt-4o,100,50,0.01
+1735000020,,gpt-4o,100,50,0.01
+1735000030,Aider,gpt-4o,-5,50,0.01
+1735000040,Aider,gpt-4o
+`
+
+	// 1. The good rows are imported
+	imported, err := ImportEvents(strings.NewReader(csv), "csv")
+	if imported != 2 {
+		t.Errorf("Expected 2 rows imported, got %d", imported)
+	}
+	if _, events, _, _ := GetLifetimeStats(); events != 2 {
+		t.Errorf("Expected 2 rows stored, got %d", events)
+	}
+
+	// 2. The malformed ones are reported by line
+	var importErr *ImportError
+	if !errors.As(err, &importErr) {
+		t.Fatalf("Expected an ImportError, got %v", err)
+	}
+	want := []string{"line 4: invalid timestamp", "line 5: missing tool", "line 6: invalid prompt_tokens",
+		"line 7: expected 6 columns"}
+	if len(importErr.Skipped) != len(want) {
+		t.Fatalf("Expected %d skipped rows, got %v", len(want), importErr.Skipped)
+	}
+	for i, prefix := range want {
+		if !strings.HasPrefix(importErr.Skipped[i], prefix) {
+			t.Errorf("Expected skipped row %q, got %q", prefix, importErr.Skipped[i])
+		}
+	}
+
+	// 3. A header without a required column rejects the file
+	if _, err := ImportEvents(strings.NewReader("timestamp,model\n1735000000,gpt-4o\n"), "csv"); err == nil ||
+		errors.As(err, &importErr) {
+		t.Errorf("Expected a missing column error, got %v", err)
+	}
+}
+
+func TestImportJSONSkipsMalformedRows(t *testing.T) {
+	setupTestDB(t)
+
+	// 1. One object per line
+	lines := `{"timestamp": 1735000000, "tool": "Aider", "model": "gpt-4o", "prompt_tokens": 100, "completion_tokens": 50, "cost": 0.01}
+{"timestamp": "2025-01-01T00:00:10Z", "tool": "OpenCode", "model": "claude-sonnet-4", "cost": 0.02}
+{"timestamp": 1735000020, "tool": "Aider"}
+not json
+`
+	imported, err := ImportEvents(strings.NewReader(lines), "json")
+	var importErr *ImportError
+	if imported != 2 || !errors.As(err, &importErr) || len(importErr.Skipped) != 2 {
+		t.Fatalf("Expected 2 imported and 2 skipped, got %d (%v)", imported, err)
+	}
+	if !strings.HasPrefix(importErr.Skipped[0], "line 3: missing model") ||
+		!strings.HasPrefix(importErr.Skipped[1], "line 4:") {
+		t.Errorf("Expected lines 3 and 4 skipped, got %v", importErr.Skipped)
+	}
+
+	// 2. An array, with items counted from 1
+	array := `[{"timestamp": 1735000100, "tool": "Aider", "model": "gpt-4o", "cost": 0.01},
+		{"timestamp": -1, "tool": "Aider", "model": "gpt-4o"}]`
+	imported, err = ImportEvents(strings.NewReader(array), "json")
+	if imported != 1 || !errors.As(err, &importErr) || len(importErr.Skipped) != 1 ||
+		!strings.HasPrefix(importErr.Skipped[0], "item 2: invalid timestamp") {
+		t.Errorf("Expected item 2 skipped, got %d imported (%v)", imported, err)
+	}
+}
+
+func TestImportTwiceInsertsNothing(t *testing.T) {
+	setupTestDB(t)
+
+	csv := `timestamp,tool,model,prompt_tokens,completion_tokens,cost
+1735000000,Aider,gpt-4o,100,50,0.01
+1735000010,Aider,gpt-4o,100,50,0.01
+`
+	if imported, err := ImportEvents(strings.NewReader(csv), "csv"); err != nil || imported != 2 {
+		t.Fatalf("Expected 2 rows imported, got %d (%v)", imported, err)
+	}
+	if imported, err := ImportEvents(strings.NewReader(csv), "csv"); err != nil || imported != 0 {
+		t.Errorf("Expected nothing imported the second time, got %d (%v)", imported, err)
+	}
+	if _, events, _, _ := GetLifetimeStats(); events != 2 {
+		t.Errorf("Expected 2 rows stored, got %d", events)
+	}
+}
@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bangarangler/burnrate/internal/pricing"
+)
+
+// WindowStart returns the start of a "today", "week" (last 7 calendar days,
+// including today), "month" (calendar month to date), or "all" window
+func WindowStart(window string, now time.Time) (time.Time, error) {
+	today := StartOfDay(now)
+
+	switch window {
+	case "today":
+		return today, nil
+	case "week":
+		return today.AddDate(0, 0, -6), nil
+	case "month":
+		return today.AddDate(0, 0, -today.Day()+1), nil
+	case "all":
+		return time.Unix(0, 0), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid window: %s", window)
+}
+
+// previousPeriod returns the window before the current one, cut off at the
+// same elapsed point so a partial period is compared with a partial period:
+// today until now vs yesterday until this time, and so on. The prior month
+// is clamped to its own end (March 31 compares with all of February).
+func previousPeriod(window string, now time.Time) (start, end time.Time, err error) {
+	current, err := WindowStart(window, now)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	switch window {
+	case "today":
+		start, end = current.AddDate(0, 0, -1), now.AddDate(0, 0, -1)
+	case "week":
+		start, end = current.AddDate(0, 0, -7), now.AddDate(0, 0, -7)
+	case "month":
+		start, end = current.AddDate(0, -1, 0), now.AddDate(0, -1, 0)
+	default:
+		return time.Time{}, time.Time{}, fmt.Errorf("no previous period for window: %s", window)
+	}
+
+	if end.After(current) {
+		end = current
+	}
+	return start, end, nil
+}
+
+// GetPeriodComparison returns spend in the current window and in the
+// equivalent prior window (see previousPeriod). previous is 0 when nothing
+// was recorded before.
+func GetPeriodComparison(window string) (current, previous float64, err error) {
+	return getPeriodComparisonAt(window, time.Now())
+}
+
+func getPeriodComparisonAt(window string, now time.Time) (current, previous float64, err error) {
+	if DB == nil {
+		return 0, 0, fmt.Errorf("database not initialized")
+	}
+
+	currentStart, err := WindowStart(window, now)
+	if err != nil {
+		return 0, 0, err
+	}
+	prevStart, prevEnd, err := previousPeriod(window, now)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// The current window is open-ended, matching GetUsageSummary's totals
+	var currentMicros, previousMicros pricing.Micros
+	err = DB.QueryRow(`SELECT COALESCE(SUM(cost_micros), 0) FROM usage_events WHERE timestamp >= ?`,
+		currentStart.Unix()).Scan(&currentMicros)
+	if err != nil {
+		return 0, 0, err
+	}
+	err = DB.QueryRow(`SELECT COALESCE(SUM(cost_micros), 0) FROM usage_events WHERE timestamp >= ? AND timestamp < ?`,
+		prevStart.Unix(), prevEnd.Unix()).Scan(&previousMicros)
+	if err != nil {
+		return 0, 0, err
+	}
+	return currentMicros.USD(), previousMicros.USD(), nil
+}
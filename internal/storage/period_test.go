@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWeekOverWeekComparison(t *testing.T) {
+	setupTestDB(t)
+	SetLocation(time.UTC)
+	defer SetLocation(nil)
+
+	// Wednesday afternoon: this week is Jun 5 00:00 onward, last week is
+	// May 29 00:00 until Jun 4 15:00
+	now := time.Date(2025, 6, 11, 15, 0, 0, 0, time.UTC)
+	events := []struct {
+		at   time.Time
+		cost float64
+	}{
+		{time.Date(2025, 6, 5, 0, 30, 0, 0, time.UTC), 4},    // This week
+		{time.Date(2025, 6, 4, 10, 0, 0, 0, time.UTC), 2},    // Last week
+		{time.Date(2025, 5, 29, 0, 10, 0, 0, time.UTC), 1},   // Last week, first minutes
+		{time.Date(2025, 6, 4, 16, 0, 0, 0, time.UTC), 8},    // Last week, but later in the day than now
+		{time.Date(2025, 5, 28, 23, 59, 0, 0, time.UTC), 16}, // Before last week
+	}
+	for _, e := range events {
+		if _, err := RecordUsageAt(e.at.Unix(), "Test", "m", 1, 1, e.cost); err != nil {
+			t.Fatalf("RecordUsageAt failed: %v", err)
+		}
+	}
+
+	current, previous, err := getPeriodComparisonAt("week", now)
+	if err != nil {
+		t.Fatalf("getPeriodComparisonAt failed: %v", err)
+	}
+
+	// 1. Current week only counts events since Jun 5
+	if current != 4 {
+		t.Errorf("Expected current $4.00, got $%.2f", current)
+	}
+
+	// 2. Last week is cut off at the same point in the week as now
+	if previous != 3 {
+		t.Errorf("Expected previous $3.00, got $%.2f", previous)
+	}
+}
+
+func TestMonthOverMonthComparison(t *testing.T) {
+	setupTestDB(t)
+	SetLocation(time.UTC)
+	defer SetLocation(nil)
+
+	// 1. Mid-month compares with the same day last month
+	start, end, err := previousPeriod("month", time.Date(2025, 3, 15, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("previousPeriod failed: %v", err)
+	}
+	if want := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC); !start.Equal(want) {
+		t.Errorf("Expected previous start %v, got %v", want, start)
+	}
+	if want := time.Date(2025, 2, 15, 12, 0, 0, 0, time.UTC); !end.Equal(want) {
+		t.Errorf("Expected previous end %v, got %v", want, end)
+	}
+
+	// 2. March 31 has no February counterpart, so all of February is used
+	now := time.Date(2025, 3, 31, 12, 0, 0, 0, time.UTC)
+	_, end, err = previousPeriod("month", now)
+	if err != nil {
+		t.Fatalf("previousPeriod failed: %v", err)
+	}
+	if want := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC); !end.Equal(want) {
+		t.Errorf("Expected previous end clamped to %v, got %v", want, end)
+	}
+
+	// 3. Events on either side of the month boundary land in the right period
+	RecordUsageAt(time.Date(2025, 1, 31, 12, 0, 0, 0, time.UTC).Unix(), "Test", "m", 1, 1, 7)
+	RecordUsageAt(time.Date(2025, 2, 28, 23, 0, 0, 0, time.UTC).Unix(), "Test", "m", 1, 1, 2)
+	RecordUsageAt(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC).Unix(), "Test", "m", 1, 1, 5)
+
+	current, previous, err := getPeriodComparisonAt("month", now)
+	if err != nil {
+		t.Fatalf("getPeriodComparisonAt failed: %v", err)
+	}
+	if current != 5 || previous != 2 {
+		t.Errorf("Expected current $5.00 and previous $2.00, got $%.2f and $%.2f", current, previous)
+	}
+}
+
+func TestPeriodComparisonWithoutPriorData(t *testing.T) {
+	setupTestDB(t)
+
+	now := time.Now()
+	RecordUsageAt(now.Unix(), "Test", "m", 1, 1, 1.5)
+
+	// 1. Nothing recorded yesterday
+	current, previous, err := getPeriodComparisonAt("today", now)
+	if err != nil {
+		t.Fatalf("getPeriodComparisonAt failed: %v", err)
+	}
+	if current != 1.5 || previous != 0 {
+		t.Errorf("Expected current $1.50 and previous $0, got $%.2f and $%.2f", current, previous)
+	}
+
+	// 2. All time has nothing to compare against
+	if _, _, err := getPeriodComparisonAt("all", now); err == nil {
+		t.Error("Expected an error comparing the all-time window")
+	}
+}
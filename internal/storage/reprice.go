@@ -0,0 +1,113 @@
+package storage
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/bangarangler/burnrate/internal/pricing"
+)
+
+// RepriceChange summarizes the re-priced events of one model
+type RepriceChange struct {
+	Model   string
+	Events  int
+	OldCost float64
+	NewCost float64
+}
+
+// RepriceFunc returns an event's new cost and cache savings, or ok=false to
+// leave the event as it is
+type RepriceFunc func(e UsageEvent) (cost, savings float64, ok bool)
+
+// repriceEpsilon ignores float noise when comparing old and new costs
+const repriceEpsilon = 1e-9
+
+// RepriceEvents recomputes the cost of every stored event matching filter.
+// Events whose cost doesn't change are left alone. With dryRun nothing is
+// written, but the changes are still reported. Returns the changes per
+// model, largest change first.
+func RepriceEvents(filter UsageFilter, reprice RepriceFunc, dryRun bool) ([]RepriceChange, error) {
+	if DB == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+	if readOnly.Load() && !dryRun {
+		return nil, fmt.Errorf("history is read-only while the daemon is recording")
+	}
+
+	tx, err := DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`
+	SELECT id, timestamp, tool, model, prompt_tokens, completion_tokens, cache_read_tokens, reasoning_tokens, cost,
+		cache_savings, COALESCE(tag, ''), COALESCE(provider, ''), COALESCE(project, '')
+	FROM usage_events
+	WHERE (? = '' OR tool = ?)
+		AND (? = '' OR tag = ?)
+		AND (? = '' OR provider = ?)
+		AND (? = '' OR project = ?)
+	`, filter.Tool, filter.Tool, filter.Tag, filter.Tag, filter.Provider, filter.Provider, filter.Project, filter.Project)
+	if err != nil {
+		return nil, err
+	}
+
+	type update struct {
+		id            int64
+		cost, savings float64
+	}
+	var updates []update
+	byModel := make(map[string]*RepriceChange)
+
+	for rows.Next() {
+		var id int64
+		var e UsageEvent
+		if err := rows.Scan(&id, &e.Timestamp, &e.Tool, &e.Model, &e.PromptTokens, &e.CompletionTokens,
+			&e.CacheReadTokens, &e.ReasoningTokens, &e.Cost, &e.CacheSavings, &e.Tag, &e.Provider, &e.Project); err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		cost, savings, ok := reprice(e)
+		if !ok || math.Abs(cost-e.Cost) < repriceEpsilon {
+			continue
+		}
+		updates = append(updates, update{id, cost, savings})
+
+		change, seen := byModel[e.Model]
+		if !seen {
+			change = &RepriceChange{Model: e.Model}
+			byModel[e.Model] = change
+		}
+		change.Events++
+		change.OldCost += e.Cost
+		change.NewCost += cost
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if !dryRun {
+		for _, u := range updates {
+			if _, err := tx.Exec(`UPDATE usage_events SET cost = ?, cost_micros = ?, cache_savings = ? WHERE id = ?`,
+				u.cost, pricing.ToMicros(u.cost), u.savings, u.id); err != nil {
+				return nil, err
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, err
+		}
+	}
+
+	changes := make([]RepriceChange, 0, len(byModel))
+	for _, c := range byModel {
+		changes = append(changes, *c)
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return math.Abs(changes[i].NewCost-changes[i].OldCost) > math.Abs(changes[j].NewCost-changes[j].OldCost)
+	})
+	return changes, nil
+}
@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRepriceEventsDryRunAndApply(t *testing.T) {
+	setupTestDB(t)
+
+	RecordUsageAt(1000, "Aider", "new-model", 1000, 100, 0.50)
+	RecordUsageAt(2000, "Aider", "new-model", 2000, 200, 1.00)
+	RecordUsageAt(3000, "Aider", "llama:free", 1000, 100, 0.25)
+	RecordUsageAt(4000, "OpenCode", "gpt-4o", 1000, 100, 0.10)
+
+	// Price new-model at $0.001/input token, free models at zero, leave the rest
+	reprice := func(e UsageEvent) (float64, float64, bool) {
+		switch {
+		case e.Model == "new-model":
+			return float64(e.PromptTokens) / 1000, 0, true
+		case strings.Contains(e.Model, ":free"):
+			return 0, 0, true
+		}
+		return 0, 0, false
+	}
+	total := func() float64 {
+		_, sum, err := GetUsageSummary(0)
+		if err != nil {
+			t.Fatalf("GetUsageSummary failed: %v", err)
+		}
+		return sum
+	}
+
+	// 1. A dry run reports the changes without writing them
+	changes, err := RepriceEvents(UsageFilter{}, reprice, true)
+	if err != nil {
+		t.Fatalf("RepriceEvents failed: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("Expected changes for 2 models, got %+v", changes)
+	}
+	if c := changes[0]; c.Model != "new-model" || c.Events != 2 || c.OldCost != 1.50 || c.NewCost != 3.00 {
+		t.Errorf("Expected new-model $1.50 -> $3.00 over 2 events first, got %+v", c)
+	}
+	if got := total(); got != 1.85 {
+		t.Errorf("Expected the dry run to leave $1.85 recorded, got $%.2f", got)
+	}
+
+	// 2. Applying updates the stored costs
+	if _, err := RepriceEvents(UsageFilter{}, reprice, false); err != nil {
+		t.Fatalf("RepriceEvents failed: %v", err)
+	}
+	if got := total(); got < 3.0999 || got > 3.1001 {
+		t.Errorf("Expected $3.10 recorded after repricing, got $%.4f", got)
+	}
+
+	// 3. Nothing is left to change
+	if changes, _ := RepriceEvents(UsageFilter{}, reprice, true); len(changes) != 0 {
+		t.Errorf("Expected no further changes, got %+v", changes)
+	}
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"sort"
+	"time"
+
+	"github.com/bangarangler/burnrate/internal/pricing"
+)
+
+// Session is a run of activity: events with no gap between them longer
+// than the one sessions were split by
+type Session struct {
+	Start    time.Time // First event
+	End      time.Time // Last event
+	Requests int
+	Cost     float64
+	Models   map[string]float64 // Cost by model
+}
+
+// Duration is the time from the session's first event to its last
+func (s Session) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// TopModel is the model the most was spent on, ties going to the first
+// name alphabetically
+func (s Session) TopModel() string {
+	names := make([]string, 0, len(s.Models))
+	for name := range s.Models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	top := ""
+	for _, name := range names {
+		if top == "" || s.Models[name] > s.Models[top] {
+			top = name
+		}
+	}
+	return top
+}
+
+// SplitSessions groups events, oldest first, into sessions: a new session
+// starts whenever more than gap passes between one event and the next
+func SplitSessions(events []UsageEvent, gap time.Duration) []Session {
+	var sessions []Session
+	for _, e := range events {
+		at := time.Unix(e.Timestamp, 0)
+		if n := len(sessions); n == 0 || at.Sub(sessions[n-1].End) > gap {
+			sessions = append(sessions, Session{Start: at, End: at, Models: make(map[string]float64)})
+		}
+		s := &sessions[len(sessions)-1]
+		s.End = at
+		s.Requests++
+		s.Cost = pricing.AddUSD(s.Cost, e.Cost)
+		s.Models[e.Model] = pricing.AddUSD(s.Models[e.Model], e.Cost)
+	}
+	return sessions
+}
+
+// GetSessions returns today's sessions, split by gap (see SplitSessions),
+// oldest first
+func GetSessions(gap time.Duration) ([]Session, error) {
+	return GetSessionsSince(StartOfDay(time.Now()).Unix(), gap)
+}
+
+// GetSessionsSince returns the sessions since a timestamp, oldest first. A
+// session already running at since only counts from there.
+func GetSessionsSince(since int64, gap time.Duration) ([]Session, error) {
+	events, err := GetEvents(since)
+	if err != nil {
+		return nil, err
+	}
+	return SplitSessions(events, gap), nil
+}
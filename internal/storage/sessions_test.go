@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSplitSessionsByGap(t *testing.T) {
+	start := time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC).Unix()
+	at := func(minutes int) int64 { return start + int64(minutes)*60 }
+	events := []UsageEvent{
+		{Timestamp: at(0), Model: "gpt-4o", Cost: 0.10},
+		{Timestamp: at(20), Model: "claude-sonnet-4", Cost: 0.50},
+		{Timestamp: at(50), Model: "gpt-4o", Cost: 0.20}, // 30m later: still the same session
+		{Timestamp: at(81), Model: "gpt-4o", Cost: 0.05}, // 31m later: a new one
+		{Timestamp: at(300), Model: "claude-opus-4", Cost: 2.00},
+	}
+
+	sessions := SplitSessions(events, 30*time.Minute)
+
+	// 1. A gap of exactly the limit continues a session; longer starts one
+	if len(sessions) != 3 {
+		t.Fatalf("Expected 3 sessions, got %d: %+v", len(sessions), sessions)
+	}
+
+	// 2. Each covers its first to last event, with their cost and count
+	first := sessions[0]
+	if !first.Start.Equal(time.Unix(at(0), 0)) || !first.End.Equal(time.Unix(at(50), 0)) {
+		t.Errorf("Expected the first session from 9:00 to 9:50, got %s to %s", first.Start, first.End)
+	}
+	if first.Duration() != 50*time.Minute {
+		t.Errorf("Expected 50m, got %s", first.Duration())
+	}
+	if first.Requests != 3 || first.Cost < 0.7999 || first.Cost > 0.8001 {
+		t.Errorf("Expected 3 requests costing $0.80, got %d costing %f", first.Requests, first.Cost)
+	}
+
+	// 3. The model mix is kept, with the costliest model on top
+	if len(first.Models) != 2 || first.TopModel() != "claude-sonnet-4" {
+		t.Errorf("Expected claude-sonnet-4 on top of 2 models, got %q of %v", first.TopModel(), first.Models)
+	}
+
+	// 4. A lone event is a session of its own
+	if last := sessions[2]; last.Requests != 1 || last.Duration() != 0 || last.TopModel() != "claude-opus-4" {
+		t.Errorf("Expected a single claude-opus-4 request, got %+v", last)
+	}
+
+	// 5. No events, no sessions
+	if got := SplitSessions(nil, 30*time.Minute); len(got) != 0 {
+		t.Errorf("Expected no sessions, got %d", len(got))
+	}
+}
+
+func TestGetSessionsSinceReadsHistory(t *testing.T) {
+	setupTestDB(t)
+
+	now := time.Now().Unix()
+	RecordUsageAt(now-7200, "Aider", "gpt-4o", 10, 5, 0.10)
+	RecordUsageAt(now-7000, "Aider", "gpt-4o", 10, 5, 0.10)
+	RecordUsageAt(now-60, "OpenCode", "gpt-5", 10, 5, 0.30)
+	RecordUsageAt(now-90000, "Aider", "gpt-4o", 10, 5, 9.00) // Outside the window
+
+	sessions, err := GetSessionsSince(now-3*3600, 30*time.Minute)
+	if err != nil {
+		t.Fatalf("GetSessionsSince failed: %v", err)
+	}
+	if len(sessions) != 2 || sessions[0].Requests != 2 || sessions[1].TopModel() != "gpt-5" {
+		t.Errorf("Expected 2 sessions, oldest first, got %+v", sessions)
+	}
+}
@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/bangarangler/burnrate/internal/pricing"
+)
+
+// location is the timezone used for every day and hour boundary, so "today"
+// in the tracker, the daily chart, and parser date buckets all agree.
+var (
+	location   = time.Local
+	locationMu sync.RWMutex
+)
+
+// SetLocation sets the timezone used for day and hour buckets (default: local)
+func SetLocation(loc *time.Location) {
+	if loc == nil {
+		loc = time.Local
+	}
+	locationMu.Lock()
+	defer locationMu.Unlock()
+	location = loc
+}
+
+// Location returns the timezone used for day and hour buckets
+func Location() *time.Location {
+	locationMu.RLock()
+	defer locationMu.RUnlock()
+	return location
+}
+
+// StartOfDay returns midnight of t's day in the configured timezone
+func StartOfDay(t time.Time) time.Time {
+	t = t.In(Location())
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// StartOfHour returns the start of t's hour in the configured timezone
+func StartOfHour(t time.Time) time.Time {
+	t = t.In(Location())
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+}
+
+// DayKey formats t as a "2006-01-02" day in the configured timezone
+func DayKey(t time.Time) string {
+	return t.In(Location()).Format("2006-01-02")
+}
+
+// HourKey formats t as a "2006-01-02 15" hour in the configured timezone
+func HourKey(t time.Time) string {
+	return t.In(Location()).Format("2006-01-02 15")
+}
+
+// sumCostBy totals cost per bucket for events since a unix timestamp.
+// Bucketing happens in Go rather than SQLite's 'localtime' so that the
+// configured timezone (not just the process TZ) is honored.
+// Returns bucket keys in ascending order alongside the totals.
+func sumCostBy(since int64, key func(time.Time) string) ([]string, map[string]float64, error) {
+	if DB == nil {
+		return nil, nil, fmt.Errorf("database not initialized")
+	}
+
+	rows, err := DB.Query(`SELECT timestamp, COALESCE(cost_micros, 0) FROM usage_events WHERE timestamp >= ?`, since)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	micros := make(map[string]pricing.Micros)
+	for rows.Next() {
+		var ts int64
+		var cost pricing.Micros
+		if err := rows.Scan(&ts, &cost); err != nil {
+			return nil, nil, err
+		}
+		micros[key(time.Unix(ts, 0))] += cost
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	keys := make([]string, 0, len(micros))
+	totals := make(map[string]float64, len(micros))
+	for k, cost := range micros {
+		keys = append(keys, k)
+		totals[k] = cost.USD()
+	}
+	sort.Strings(keys)
+	return keys, totals, nil
+}
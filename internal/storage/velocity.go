@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"sort"
+	"time"
+)
+
+// typicalBurnDays is how far back the typical burn rate looks
+const typicalBurnDays = 14
+
+// minTypicalBurnHours is the fewest active hours a typical burn rate is
+// taken from; with less history there's no usual to compare against
+const minTypicalBurnHours = 5
+
+// GetTypicalBurnRate returns the usual spend per active hour (see
+// getTypicalBurnRateAt). ok is false until there's enough history.
+func GetTypicalBurnRate() (perHour float64, ok bool, err error) {
+	return getTypicalBurnRateAt(time.Now())
+}
+
+// getTypicalBurnRateAt takes the median spend of the hours with any spend
+// in the typicalBurnDays before the current hour. Idle hours are left out,
+// so the rate is what an hour of work usually costs, and the median keeps
+// one unusually expensive hour from raising it. The current hour is left
+// out too, so the live session isn't compared with itself.
+func getTypicalBurnRateAt(now time.Time) (perHour float64, ok bool, err error) {
+	hour := StartOfHour(now)
+	since := hour.AddDate(0, 0, -typicalBurnDays)
+
+	keys, totals, err := sumCostBy(since.Unix(), HourKey)
+	if err != nil {
+		return 0, false, err
+	}
+
+	current := HourKey(hour)
+	var hourly []float64
+	for _, key := range keys {
+		if key < current && totals[key] > 0 {
+			hourly = append(hourly, totals[key])
+		}
+	}
+	if len(hourly) < minTypicalBurnHours {
+		return 0, false, nil
+	}
+
+	sort.Float64s(hourly)
+	mid := len(hourly) / 2
+	if len(hourly)%2 == 0 {
+		return (hourly[mid-1] + hourly[mid]) / 2, true, nil
+	}
+	return hourly[mid], true, nil
+}
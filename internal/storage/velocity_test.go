@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTypicalBurnRateIsMedianActiveHour(t *testing.T) {
+	setupTestDB(t)
+	SetLocation(time.UTC)
+	defer SetLocation(nil)
+
+	now := time.Date(2025, 6, 18, 12, 30, 0, 0, time.UTC)
+	hoursAgo := func(h int) int64 { return now.Add(-time.Duration(h) * time.Hour).Unix() }
+
+	// 1. Cold start: four active hours aren't enough to call usual
+	for h, cost := range map[int]float64{2: 1, 5: 2, 30: 3, 100: 4} {
+		RecordUsageAt(hoursAgo(h), "Test", "m", 1, 1, cost)
+	}
+	if _, ok, err := getTypicalBurnRateAt(now); err != nil || ok {
+		t.Fatalf("Expected no typical rate from four hours, got ok=%v (%v)", ok, err)
+	}
+
+	// 2. A fifth hour, whose two events add up, gives the median hour
+	RecordUsageAt(hoursAgo(200), "Test", "m", 1, 1, 60)
+	RecordUsageAt(hoursAgo(200)+60, "Test", "m", 1, 1, 40)
+	rate, ok, err := getTypicalBurnRateAt(now)
+	if err != nil || !ok || rate != 3 {
+		t.Errorf("Expected $3.00/hr, got $%.2f (ok=%v, %v)", rate, ok, err)
+	}
+
+	// 3. The current hour and anything over two weeks old don't count
+	RecordUsageAt(now.Unix(), "Test", "m", 1, 1, 500)
+	RecordUsageAt(hoursAgo(15*24), "Test", "m", 1, 1, 500)
+	RecordUsageAt(hoursAgo(16*24), "Test", "m", 1, 1, 500)
+	if rate, _, _ := getTypicalBurnRateAt(now); rate != 3 {
+		t.Errorf("Expected $3.00/hr still, got $%.2f", rate)
+	}
+}
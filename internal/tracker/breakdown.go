@@ -0,0 +1,42 @@
+package tracker
+
+import "github.com/bangarangler/burnrate/internal/pricing"
+
+// CostBreakdown is spend split by the kind of token it went on. Other is
+// cost that can't be put down to tokens: local models' hourly cost, and
+// models without a price.
+type CostBreakdown struct {
+	pricing.CostSplit
+	Other float64
+}
+
+// BreakdownCost splits usages' cost into input, cache, output, and
+// reasoning. Each usage's recorded cost is divided in proportion to its
+// parts at current rates, so the split adds up to what was actually
+// recorded, including costs the tool reported itself.
+func BreakdownCost(usages []Usage) CostBreakdown {
+	var b CostBreakdown
+	for _, u := range usages {
+		split := pricing.SplitCost(u.Model, u.PromptTokens-u.CacheReadTokens, u.CacheReadTokens,
+			u.CompletionTokens-u.ReasoningTokens, u.ReasoningTokens)
+		if total := split.Total(); total > 0 {
+			b.CostSplit = b.CostSplit.Add(split.Scale(u.Cost / total))
+		} else {
+			b.Other += u.Cost
+		}
+	}
+	return b
+}
+
+// GetCostBreakdown splits the session's or a window's spend by token type
+// (see BreakdownCost)
+func (t *Tracker) GetCostBreakdown(window string) (CostBreakdown, error) {
+	if window == "session" {
+		return BreakdownCost(t.GetUsages()), nil
+	}
+	usages, _, err := t.GetHistoricalUsage(window)
+	if err != nil {
+		return CostBreakdown{}, err
+	}
+	return BreakdownCost(usages), nil
+}
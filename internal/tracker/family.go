@@ -0,0 +1,47 @@
+package tracker
+
+import (
+	"sort"
+
+	"github.com/bangarangler/burnrate/internal/pricing"
+)
+
+// GroupByFamily merges usages into one per model family (see
+// pricing.FamilyOf), summing tokens and cost, costliest first. Each merged
+// usage's Model is the family name; timestamps and tools aren't kept.
+func GroupByFamily(usages []Usage) []Usage {
+	return groupUsages(usages, func(u Usage) string { return pricing.FamilyOf(u.Model) })
+}
+
+// GroupByProject merges usages into one per project, like GroupByFamily.
+// Usage without a known project is merged under "".
+func GroupByProject(usages []Usage) []Usage {
+	return groupUsages(usages, func(u Usage) string { return u.Project })
+}
+
+// groupUsages merges usages with the same key, which becomes each merged
+// usage's Model, costliest first
+func groupUsages(usages []Usage, key func(Usage) string) []Usage {
+	index := make(map[string]int)
+	var grouped []Usage
+	for _, u := range usages {
+		k := key(u)
+		i, ok := index[k]
+		if !ok {
+			i = len(grouped)
+			index[k] = i
+			grouped = append(grouped, Usage{Model: k})
+		}
+		g := &grouped[i]
+		g.PromptTokens += u.PromptTokens
+		g.CompletionTokens += u.CompletionTokens
+		g.TotalTokens += u.TotalTokens
+		g.CacheReadTokens += u.CacheReadTokens
+		g.ReasoningTokens += u.ReasoningTokens
+		g.Cost += u.Cost
+		g.CacheSavings += u.CacheSavings
+	}
+
+	sort.SliceStable(grouped, func(i, j int) bool { return grouped[i].Cost > grouped[j].Cost })
+	return grouped
+}
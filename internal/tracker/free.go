@@ -0,0 +1,41 @@
+package tracker
+
+import "github.com/bangarangler/burnrate/internal/pricing"
+
+// How the usage table shows free models (see FilterFree)
+const (
+	FreeModelsShow  = "show"  // A row per model, as for paid ones
+	FreeModelsGroup = "group" // One FreeModelsRow with their token totals
+	FreeModelsHide  = "hide"  // No rows
+)
+
+// FreeModelsRow is the Model of the usage FilterFree merges free usages into
+const FreeModelsRow = "Free models"
+
+// IsFree reports whether a usage cost nothing because its model is free or
+// local, rather than because it has no price (see pricing.NeedsFallback)
+func IsFree(u Usage) bool {
+	return u.Cost == 0 && !pricing.NeedsFallback(u.Model)
+}
+
+// FilterFree applies a free models mode to usage rows: FreeModelsHide drops
+// free usages, FreeModelsGroup merges them into one FreeModelsRow after the
+// others, and any other mode returns the rows unchanged
+func FilterFree(usages []Usage, mode string) []Usage {
+	if mode != FreeModelsHide && mode != FreeModelsGroup {
+		return usages
+	}
+
+	var kept, free []Usage
+	for _, u := range usages {
+		if IsFree(u) {
+			free = append(free, u)
+		} else {
+			kept = append(kept, u)
+		}
+	}
+	if mode == FreeModelsGroup && len(free) > 0 {
+		kept = append(kept, groupUsages(free, func(Usage) string { return FreeModelsRow })...)
+	}
+	return kept
+}
@@ -0,0 +1,73 @@
+package tracker
+
+import (
+	"sort"
+	"time"
+)
+
+// ProvisionalTTL is how long an in-flight request is shown without an update.
+// A request that never finishes (cancelled, or the tool crashed) would
+// otherwise stay in flight forever.
+const ProvisionalTTL = 10 * time.Minute
+
+// provisionalKey identifies an in-flight request within its tool
+func provisionalKey(tool, key string) string {
+	return tool + "/" + key
+}
+
+// SetProvisional records or updates an estimate for a request that is still
+// streaming, under a key the tool's parser will finalize it with (such as a
+// message ID). Provisional usage is never recorded to history and doesn't
+// count toward any total; it's replaced once the request is finalized (see
+// ClearProvisional). Timestamp is when the request started.
+func (t *Tracker) SetProvisional(tool, key string, usage Usage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	usage.Tool = tool
+	if usage.Cost == 0 && usage.PromptTokens+usage.CompletionTokens > 0 {
+		usage.Cost = priceFromTokens(usage)
+	}
+	if t.provisional == nil {
+		t.provisional = make(map[string]provisionalUsage)
+	}
+	// Updates keep the start time, so elapsed time doesn't restart on each
+	k := provisionalKey(tool, key)
+	if prev, ok := t.provisional[k]; ok && !prev.Timestamp.IsZero() && prev.Timestamp.Before(usage.Timestamp) {
+		usage.Timestamp = prev.Timestamp
+	}
+	t.provisional[k] = provisionalUsage{Usage: usage, updatedAt: t.clock()}
+}
+
+// ClearProvisional drops a request's in-flight estimate, once its final usage
+// has been recorded
+func (t *Tracker) ClearProvisional(tool, key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.provisional, provisionalKey(tool, key))
+}
+
+// GetProvisional returns the requests still in flight, oldest first. Ones not
+// updated within ProvisionalTTL are dropped.
+func (t *Tracker) GetProvisional() []Usage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.clock()
+	var usages []Usage
+	for key, p := range t.provisional {
+		if now.Sub(p.updatedAt) > ProvisionalTTL {
+			delete(t.provisional, key)
+			continue
+		}
+		usages = append(usages, p.Usage)
+	}
+	sort.Slice(usages, func(i, j int) bool { return usages[i].Timestamp.Before(usages[j].Timestamp) })
+	return usages
+}
+
+// provisionalUsage is an in-flight estimate and when it was last updated
+type provisionalUsage struct {
+	Usage
+	updatedAt time.Time
+}
@@ -0,0 +1,98 @@
+package tracker
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bangarangler/burnrate/internal/pricing"
+)
+
+// ReplayTool is the tool replayed events without one are recorded under
+const ReplayTool = "Simulated"
+
+// ReadReplay reads usage events to replay, one JSON Usage per line, and
+// returns them oldest first. Blank lines are skipped; every malformed line
+// is reported, by line number, in the returned error.
+func ReadReplay(r io.Reader) ([]Usage, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var events []Usage
+	var errs []error
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var u Usage
+		if err := json.Unmarshal([]byte(text), &u); err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %w", line, err))
+			continue
+		}
+		if u.Model == "" {
+			errs = append(errs, fmt.Errorf("line %d: missing model", line))
+			continue
+		}
+		events = append(events, u)
+	}
+	if err := scanner.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Timestamp.Before(events[j].Timestamp) })
+	return events, nil
+}
+
+// Replay adds events to the tracker as if they were arriving now, waiting
+// between them for the gap between their timestamps divided by speed (0
+// replays without waiting). Events without a cost are priced from their
+// tokens, and those without a tool are recorded as ReplayTool. each, if
+// set, is called with every event as recorded. Replay stops early when ctx
+// is done and returns how many events it added.
+func (t *Tracker) Replay(ctx context.Context, events []Usage, speed float64, each func(Usage)) int {
+	for i, u := range events {
+		if i > 0 && speed > 0 {
+			gap := u.Timestamp.Sub(events[i-1].Timestamp)
+			if gap > 0 {
+				select {
+				case <-time.After(time.Duration(float64(gap) / speed)):
+				case <-ctx.Done():
+					return i
+				}
+			}
+		}
+		if ctx.Err() != nil {
+			return i
+		}
+
+		tool := u.Tool
+		if tool == "" {
+			tool = ReplayTool
+		}
+		if u.TotalTokens == 0 {
+			u.TotalTokens = u.PromptTokens + u.CompletionTokens
+		}
+		if u.Cost == 0 {
+			u.Cost = pricing.CalculateCost(u.Model, u.PromptTokens, u.CompletionTokens, 0)
+		}
+		u.Timestamp = t.clock()
+		if t.AddToolUsage(tool, u) {
+			t.IncrementToolEvents(tool)
+		}
+		if each != nil {
+			u.Tool = tool
+			each(u)
+		}
+	}
+	return len(events)
+}
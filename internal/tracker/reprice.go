@@ -0,0 +1,71 @@
+package tracker
+
+import (
+	"math"
+	"time"
+
+	"github.com/bangarangler/burnrate/internal/pricing"
+)
+
+// priceFromTokens prices a usage from its tokens at the current rates
+// (see pricing.CostFromTokens)
+func priceFromTokens(u Usage) float64 {
+	return pricing.CostFromTokens(u.Model, u.PromptTokens, u.CacheReadTokens, u.CompletionTokens, u.ReasoningTokens)
+}
+
+// isFallbackPriced reports whether an unpriced model's cost is our estimate
+// at the fallback model's rates, rather than a cost the tool reported
+func isFallbackPriced(u Usage) bool {
+	return u.Cost > 0 && math.Abs(u.Cost-priceFromTokens(u)) < 1e-9
+}
+
+// RepriceFallbacks re-prices the session's fallback-priced events whose
+// model has a real price now, such as after a pricing fetch, and adjusts the
+// session and tool totals. Only the in-memory session changes; history keeps
+// the cost it was recorded with (see 'burnrate reprice'). A re-priced event
+// is no longer a fallback, so repeated calls don't adjust it twice. Returns
+// the net change.
+func (t *Tracker) RepriceFallbacks() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var delta float64
+	t.unpricedEvents = 0
+	for i := range t.SessionUsages {
+		u := &t.SessionUsages[i]
+		if pricing.NeedsFallback(u.Model) {
+			t.unpricedEvents++
+			continue
+		}
+		if !u.Fallback {
+			continue
+		}
+
+		cost, old := priceFromTokens(*u), u.Cost
+		change := cost - old
+		u.Cost = cost
+		u.CacheSavings = pricing.CalculateCacheSavings(u.Model, u.CacheReadTokens)
+		u.Fallback = false
+
+		t.sessionMicros += pricing.ToMicros(cost) - pricing.ToMicros(old)
+		t.SessionCost = t.sessionMicros.USD()
+		if status, ok := t.ToolStatuses[u.Tool]; ok {
+			status.TotalCost = pricing.AddUSD(status.TotalCost, cost, -old)
+		}
+		delta += change
+	}
+
+	if delta != 0 {
+		t.priceAdjustment = delta
+		t.priceAdjustedAt = t.clock()
+	}
+	return delta
+}
+
+// PriceAdjustment returns the net change to the session total from the
+// latest re-pricing that changed it, and when that happened (zero if never)
+func (t *Tracker) PriceAdjustment() (float64, time.Time) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.priceAdjustment, t.priceAdjustedAt
+}
@@ -3,8 +3,12 @@ package tracker
 
 import (
 	"fmt"
+	"github.com/bangarangler/burnrate/internal/config"
+	"github.com/bangarangler/burnrate/internal/log"
+	"github.com/bangarangler/burnrate/internal/pricing"
 	"github.com/bangarangler/burnrate/internal/storage"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 )
@@ -19,67 +23,304 @@ const (
 
 // ToolStatus represents the current state of a tracked tool
 type ToolStatus struct {
-	Name          string    // Display name: "OpenCode", "Copilot", etc.
-	Tier          ToolTier  // Support tier
-	Status        string    // "active", "partial", "configured", "not_found"
-	Message       string    // Human-readable explanation
-	DashboardURL  string    // External dashboard URL (Tier 2 tools)
-	EventCount    int       // Number of events tracked this session
-	LastEventTime time.Time // Timestamp of last event
-	TotalCost     float64   // Total cost tracked for this tool in current session
+	Name          string    `json:"name"`          // Display name: "OpenCode", "Copilot", etc.
+	Tier          ToolTier  `json:"tier"`          // Support tier
+	Status        string    `json:"status"`        // "active", "idle", "partial", "configured", "not_found"
+	Message       string    `json:"message"`       // Human-readable explanation
+	DashboardURL  string    `json:"dashboard_url"` // External dashboard URL (Tier 2 tools)
+	EventCount    int       `json:"event_count"`   // Number of events tracked this session
+	LastEventTime time.Time `json:"last_event"`    // Timestamp of last event
+	TotalCost     float64   `json:"total_cost"`    // Total cost tracked for this tool in current session
+
+	// Events whose reported cost disagreed with our price (see RecordCostMismatch)
+	CostMismatches int `json:"cost_mismatches,omitempty"`
+
+	// Events skipped for costing more than the per-event ceiling (see SetMaxEventCost)
+	RejectedEvents int `json:"rejected_events,omitempty"`
+
+	// Where the detector looked, and how to get the tool tracked, for tools
+	// that aren't (shown on first run)
+	Path string `json:"path,omitempty"`
+	Hint string `json:"hint,omitempty"`
+
+	// The latest status changes, oldest first, up to ToolHistorySize, to
+	// tell a tool that briefly failed from one that never did
+	History []ToolTransition `json:"history,omitempty"`
+}
+
+// ToolTransition is one change of a tool's status
+type ToolTransition struct {
+	Time    time.Time `json:"time"`
+	From    string    `json:"from"` // Empty for the tool's first status
+	To      string    `json:"to"`
+	Message string    `json:"message,omitempty"` // The new status's message
+}
+
+// ToolHistorySize is how many transitions each tool's History keeps
+const ToolHistorySize = 20
+
+// recordTransition adds a change to the status's history, dropping the
+// oldest beyond ToolHistorySize. Unchanged statuses aren't recorded.
+func (s *ToolStatus) recordTransition(at time.Time, from string) {
+	if from == s.Status {
+		return
+	}
+	history := append(s.History, ToolTransition{Time: at, From: from, To: s.Status, Message: s.Message})
+	if len(history) > ToolHistorySize {
+		history = history[len(history)-ToolHistorySize:]
+	}
+	s.History = history
 }
 
 type Usage struct {
+	Tool             string    `json:"tool,omitempty"` // Empty for usage aggregated across tools
 	Model            string    `json:"model"`
 	PromptTokens     int       `json:"prompt_tokens"`
 	CompletionTokens int       `json:"completion_tokens"`
 	TotalTokens      int       `json:"total_tokens"`
+	CacheReadTokens  int       `json:"cache_read_tokens,omitempty"` // Portion of PromptTokens served from cache
+	ReasoningTokens  int       `json:"reasoning_tokens,omitempty"`  // Portion of CompletionTokens spent thinking
 	Cost             float64   `json:"cost"`
+	CacheSavings     float64   `json:"cache_savings,omitempty"` // Cost avoided by cache reads
 	Timestamp        time.Time `json:"timestamp"`
+	Project          string    `json:"project,omitempty"` // Repository or directory worked in, see parser.ProjectKey
+
+	// Priced at the fallback model's rates, so RepriceFallbacks may correct it
+	Fallback bool `json:"fallback,omitempty"`
+
+	// Identifies the event in its tool's logs, so history stores it once
+	// however often it's read (see storage.UsageEvent)
+	EventKey string `json:"event_key,omitempty"`
+
+	// How many requests a usage merged from older ones stands for (see
+	// Snapshot); 0 for a single request
+	Requests int `json:"requests,omitempty"`
+}
+
+// requests is how many requests the usage stands for
+func (u Usage) requests() int {
+	return max(u.Requests, 1)
 }
 
 type Tracker struct {
 	mu            sync.RWMutex
-	SessionCost   float64
+	SessionCost   float64 // sessionMicros in dollars
 	SessionUsages []Usage
 	StartTime     time.Time
 	ToolStatuses  map[string]*ToolStatus
+	tag           string // Stamped on every recorded event (see SetTag)
+
+	// The session cost is summed in whole micro-dollars, so that adding many
+	// tiny event costs doesn't accumulate float error
+	sessionMicros pricing.Micros
+
+	// Idle detection. Once no event has arrived for idleThreshold, further
+	// time is idle and excluded from the active duration and burn rate.
+	idleThreshold time.Duration
+	lastEventTime time.Time     // When the latest event arrived (zero before the first)
+	idleTotal     time.Duration // Idle time from gaps that have already ended
+
+	// An active tool without an event for this long is shown as idle (see
+	// RefreshToolStatuses)
+	toolActiveThreshold time.Duration
+
+	toolSort string // Order of GetToolStatuses (see SetToolSort)
+
+	// Large context detection: the session's biggest request by input tokens
+	largeContextThreshold int
+	largestRequest        Usage
+
+	// Events for models without a known price (see UnpricedEvents)
+	unpricedEvents int
+
+	// A single event costing more than this is assumed to be a parsing bug
+	// and is skipped (0 = no ceiling)
+	maxEventCost float64
+
+	// Net change from the latest RepriceFallbacks that changed anything
+	priceAdjustment float64
+	priceAdjustedAt time.Time
+
+	// Estimates for requests still streaming (see SetProvisional)
+	provisional map[string]provisionalUsage
+
+	// Local models are billed for running time (see SetLocalCost)
+	localCostPerHour    float64
+	localCostPerRequest float64
+	lastLocalEvent      time.Time // Latest local event's timestamp
+
+	subMu       sync.Mutex
+	subscribers []chan Usage
+
+	batch *storage.BatchWriter // Batches history writes when set (see SetBatchWriter)
+
+	now func() time.Time // Clock for every time the tracker reads (nil = time.Now)
 }
 
-var Global = &Tracker{
-	StartTime:    time.Now(),
-	ToolStatuses: make(map[string]*ToolStatus),
+// subscriberBuffer is how many events a slow subscriber can fall behind
+// before new events are dropped for it
+const subscriberBuffer = 1024
+
+// DefaultIdleThreshold is how long without events before a session is idle
+const DefaultIdleThreshold = 5 * time.Minute
+
+// DefaultToolActiveThreshold is how long an active tool can go without an
+// event before it's shown as idle
+const DefaultToolActiveThreshold = 15 * time.Minute
+
+// DefaultMaxEventCost is the most a single event can cost, in USD, before
+// it's rejected as an outlier
+const DefaultMaxEventCost = 50.0
+
+var Global = NewTracker(time.Now)
+
+// NewTracker returns an empty tracker that reads the time from now. Tests
+// pass a fake clock to control durations and burn rates.
+func NewTracker(now func() time.Time) *Tracker {
+	return &Tracker{
+		StartTime:     now(),
+		ToolStatuses:  make(map[string]*ToolStatus),
+		idleThreshold: DefaultIdleThreshold,
+		maxEventCost:  DefaultMaxEventCost,
+		now:           now,
+
+		toolActiveThreshold: DefaultToolActiveThreshold,
+	}
+}
+
+// clock returns the current time from the tracker's clock
+func (t *Tracker) clock() time.Time {
+	if t.now == nil {
+		return time.Now()
+	}
+	return t.now()
 }
 
 // AddUsage adds a new usage entry and updates the session cost
 func (t *Tracker) AddUsage(model string, prompt, completion int, cost float64) {
-	t.mu.Lock()
-	defer t.mu.Unlock()
-
-	// Try to determine the tool name (this is a bit hacky, better to pass it in)
-	// For now, we'll infer it or default to "Unknown" if we can't find a better way easily without breaking API
-	// Ideally, AddUsage should take a toolName parameter.
-	// Since we can't change the signature easily without updating all callers, let's defer DB writing to a new method
-	// or update the signature. Given we control the codebase, let's update the signature.
-
-	usage := Usage{
+	t.addUsage(Usage{
 		Model:            model,
 		PromptTokens:     prompt,
 		CompletionTokens: completion,
 		TotalTokens:      prompt + completion,
 		Cost:             cost,
-		Timestamp:        time.Now(),
-	}
+		Timestamp:        t.clock(),
+	})
+}
 
+// addUsage records a usage and returns it as recorded, with a local model's
+// cost replaced by its running cost. Outliers over the per-event ceiling
+// aren't recorded, and ok is false.
+func (t *Tracker) addUsage(usage Usage) (recorded Usage, ok bool) {
+	t.mu.Lock()
+	if t.maxEventCost > 0 && usage.Cost > t.maxEventCost {
+		t.mu.Unlock()
+		log.Warnf("tracker: skipped %s event costing $%.2f, over the $%.2f per-event ceiling (%d prompt, %d completion tokens)",
+			usage.Model, usage.Cost, t.maxEventCost, usage.PromptTokens, usage.CompletionTokens)
+		return usage, false
+	}
+	if pricing.IsLocal(usage.Model) && (t.localCostPerHour > 0 || t.localCostPerRequest > 0) {
+		usage.Cost = t.localCost(usage.Timestamp)
+	}
+	if pricing.NeedsFallback(usage.Model) {
+		usage.Fallback = isFallbackPriced(usage)
+	}
 	t.SessionUsages = append(t.SessionUsages, usage)
-	t.SessionCost += cost
+	t.sessionMicros += pricing.ToMicros(usage.Cost)
+	t.SessionCost = t.sessionMicros.USD()
+	t.recordArrival(t.clock())
+	if usage.PromptTokens > t.largestRequest.PromptTokens {
+		t.largestRequest = usage
+	}
+	if pricing.NeedsFallback(usage.Model) {
+		t.unpricedEvents++
+	}
+	large := t.largeContextThreshold > 0 && usage.PromptTokens > t.largeContextThreshold
+	log.Debugf("💸 +$%.4f (%s) | Total: $%.4f", usage.Cost, usage.Model, t.SessionCost)
+	t.mu.Unlock()
+
+	if large {
+		log.Warnf("tracker: large context: %d input tokens to %s ($%.4f)", usage.PromptTokens, usage.Model, usage.Cost)
+	}
+
+	t.publish(usage)
+	return usage, true
+}
+
+// Subscribe returns a channel that receives every usage event added from now
+// on, and a function that unsubscribes and closes it. Events are dropped for
+// a subscriber that falls too far behind rather than blocking parsers.
+func (t *Tracker) Subscribe() (<-chan Usage, func()) {
+	ch := make(chan Usage, subscriberBuffer)
+
+	t.subMu.Lock()
+	t.subscribers = append(t.subscribers, ch)
+	t.subMu.Unlock()
+
+	unsubscribe := func() {
+		t.subMu.Lock()
+		defer t.subMu.Unlock()
+		for i, sub := range t.subscribers {
+			if sub == ch {
+				t.subscribers = append(t.subscribers[:i], t.subscribers[i+1:]...)
+				close(ch)
+				return
+			}
+		}
+	}
+	return ch, unsubscribe
+}
 
-	fmt.Printf("💸 +$%.4f (%s) | Total: $%.4f\n", cost, model, t.SessionCost)
+// publish delivers a usage event to every subscriber without blocking
+func (t *Tracker) publish(usage Usage) {
+	t.subMu.Lock()
+	defer t.subMu.Unlock()
+
+	for _, ch := range t.subscribers {
+		select {
+		case ch <- usage:
+		default:
+			log.Warnf("tracker: subscriber is behind, dropped %s event", usage.Model)
+		}
+	}
 }
 
 // AddUsageWithTool adds usage and records it to the database
 func (t *Tracker) AddUsageWithTool(tool, model string, prompt, completion int, cost float64) {
-	t.AddUsage(model, prompt, completion, cost)
+	t.AddUsageWithToolAt(t.clock(), tool, model, prompt, completion, cost)
+}
+
+// AddUsageWithToolAt adds usage and records it to the database under the
+// event's own timestamp, so the same event read twice maps to the same row
+func (t *Tracker) AddUsageWithToolAt(ts time.Time, tool, model string, prompt, completion int, cost float64) {
+	t.AddToolUsage(tool, Usage{
+		Model:            model,
+		PromptTokens:     prompt,
+		CompletionTokens: completion,
+		TotalTokens:      prompt + completion,
+		Cost:             cost,
+		Timestamp:        ts,
+	})
+}
+
+// AddToolUsage adds a fully populated usage (including cache details) for a
+// tool and records it to the database. Returns false if the usage was
+// rejected (see SetMaxEventCost), so it's counted as rejected rather than as
+// one of the tool's events.
+func (t *Tracker) AddToolUsage(tool string, usage Usage) bool {
+	if usage.Timestamp.IsZero() {
+		usage.Timestamp = t.clock()
+	}
+	usage.Tool = tool
+	usage, ok := t.addUsage(usage)
+	if !ok {
+		t.mu.Lock()
+		if status, exists := t.ToolStatuses[tool]; exists {
+			status.RejectedEvents++
+		}
+		t.mu.Unlock()
+		return false
+	}
 
 	// Update tool stats
 	t.mu.Lock()
@@ -90,21 +331,75 @@ func (t *Tracker) AddUsageWithTool(tool, model string, prompt, completion int, c
 	// If not, we should probably auto-register it?
 	// For now, let's assume parsers register tools. But we can be safe.
 	if status, ok := t.ToolStatuses[tool]; ok {
-		status.TotalCost += cost
+		status.TotalCost = pricing.AddUSD(status.TotalCost, usage.Cost)
 	} else {
 		// Auto-register if not present (defensive)
 		t.ToolStatuses[tool] = &ToolStatus{
 			Name:      tool,
 			Tier:      TierFullTracking,
 			Status:    "active",
-			TotalCost: cost,
+			TotalCost: usage.Cost,
 		}
 	}
+	tag := t.tag
+	batch := t.batch
 	t.mu.Unlock()
 
-	// Record to history DB
-	// We ignore errors here to avoid disrupting the UI flow, but we could log them
-	_ = storage.RecordUsage(tool, model, prompt, completion, cost)
+	// Record to history DB (optional - it may have failed to open). Failures
+	// are logged rather than returned so a DB problem never disrupts the UI flow.
+	if storage.DB == nil {
+		return true
+	}
+	event := storage.UsageEvent{
+		Timestamp:        usage.Timestamp.Unix(),
+		Tool:             tool,
+		Model:            usage.Model,
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		CacheReadTokens:  usage.CacheReadTokens,
+		ReasoningTokens:  usage.ReasoningTokens,
+		Cost:             usage.Cost,
+		CacheSavings:     usage.CacheSavings,
+		Tag:              tag,
+		Provider:         pricing.ProviderOf(usage.Model),
+		Project:          usage.Project,
+		EventKey:         usage.EventKey,
+	}
+	if batch != nil {
+		batch.Write(event)
+		return true
+	}
+	inserted, err := storage.RecordEvent(event)
+	if err != nil {
+		log.Warnf("tracker: failed to record %s usage: %v", tool, err)
+	} else if !inserted && !storage.IsReadOnly() {
+		log.Debugf("tracker: %s event at %s already recorded", tool, usage.Timestamp.Format(time.RFC3339))
+	}
+	return true
+}
+
+// SetBatchWriter sends history writes through w, which writes them in
+// batches, instead of recording each event as it arrives. Nil restores
+// per-event writes.
+func (t *Tracker) SetBatchWriter(w *storage.BatchWriter) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.batch = w
+}
+
+// SetTag labels every event recorded from now on, e.g. with a client name.
+// An empty tag records events untagged.
+func (t *Tracker) SetTag(tag string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.tag = strings.TrimSpace(tag)
+}
+
+// Tag returns the current session tag
+func (t *Tracker) Tag() string {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tag
 }
 
 // GetSessionCost returns the current session cost safely
@@ -123,7 +418,8 @@ func (t *Tracker) GetBurnRatePerHour() float64 {
 		return 0
 	}
 
-	duration := time.Since(t.StartTime).Hours()
+	active, _ := t.durationsAt(t.clock())
+	duration := active.Hours()
 	if duration <= 0 {
 		return 0
 	}
@@ -131,6 +427,64 @@ func (t *Tracker) GetBurnRatePerHour() float64 {
 	return t.SessionCost / duration
 }
 
+// SetIdleThreshold sets how long without events before the session counts
+// as idle. Zero disables idle detection.
+func (t *Tracker) SetIdleThreshold(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.idleThreshold = d
+}
+
+// SessionDurations returns the session's active time (wall time minus idle
+// gaps) and its wall-clock time
+func (t *Tracker) SessionDurations() (active, wall time.Duration) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.durationsAt(t.clock())
+}
+
+// IsIdle reports whether no event has arrived within the idle threshold
+func (t *Tracker) IsIdle() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.idleThreshold > 0 && t.idleSince(t.clock()) > 0
+}
+
+// recordArrival closes the current gap, banking any idle part of it.
+// Callers must hold t.mu.
+func (t *Tracker) recordArrival(at time.Time) {
+	t.idleTotal += t.idleSince(at)
+	t.lastEventTime = at
+}
+
+// idleSince returns the idle part of the gap between the latest event (or
+// the session start) and now: whatever exceeds the threshold. The first
+// idleThreshold of a gap still counts as active, since reading a response
+// or editing between requests is work.
+func (t *Tracker) idleSince(now time.Time) time.Duration {
+	if t.idleThreshold <= 0 {
+		return 0
+	}
+	last := t.lastEventTime
+	if last.IsZero() || last.Before(t.StartTime) {
+		last = t.StartTime
+	}
+	if gap := now.Sub(last); gap > t.idleThreshold {
+		return gap - t.idleThreshold
+	}
+	return 0
+}
+
+// durationsAt computes SessionDurations as of now. Callers must hold t.mu.
+func (t *Tracker) durationsAt(now time.Time) (active, wall time.Duration) {
+	wall = now.Sub(t.StartTime)
+	active = wall - t.idleTotal - t.idleSince(now)
+	if active < 0 {
+		active = 0
+	}
+	return active, wall
+}
+
 // GetUsages returns a safe copy of the current usages for display in the TUI
 func (t *Tracker) GetUsages() []Usage {
 	t.mu.RLock()
@@ -148,18 +502,147 @@ func (t *Tracker) Reset() {
 	defer t.mu.Unlock()
 
 	t.SessionCost = 0
+	t.sessionMicros = 0
 	t.SessionUsages = nil
-	t.StartTime = time.Now()
+	t.StartTime = t.clock()
+	t.lastEventTime = time.Time{}
+	t.idleTotal = 0
+	t.largestRequest = Usage{}
+	t.unpricedEvents = 0
+	t.lastLocalEvent = time.Time{}
+	t.priceAdjustment = 0
+	t.priceAdjustedAt = time.Time{}
+	t.provisional = nil
+}
+
+// StartedBeforeToday reports whether the session began on an earlier
+// calendar day than the tracker's clock, in the configured timezone
+func (t *Tracker) StartedBeforeToday() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.StartTime.Before(storage.StartOfDay(t.clock()))
+}
+
+// SetMaxEventCost sets the most a single event can cost, in USD, before
+// it's rejected as an outlier. Zero disables the ceiling.
+func (t *Tracker) SetMaxEventCost(usd float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.maxEventCost = usd
 }
 
-// GetSummary returns a formatted string summary (useful for future commands)
+// SetLargeContextThreshold sets how many input tokens in one request count
+// as a large context. Zero disables the warning.
+func (t *Tracker) SetLargeContextThreshold(tokens int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.largeContextThreshold = tokens
+}
+
+// LargestContext returns the session's request with the most input tokens,
+// and whether it exceeds the large context threshold
+func (t *Tracker) LargestContext() (Usage, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	large := t.largeContextThreshold > 0 && t.largestRequest.PromptTokens > t.largeContextThreshold
+	return t.largestRequest, large
+}
+
+// SetLocalCost sets what local models cost to run: perHour for the time
+// between their requests and a flat perRequest for each one. Both zero keeps
+// local models at their token price, which is nothing.
+func (t *Tracker) SetLocalCost(perHour, perRequest float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.localCostPerHour = perHour
+	t.localCostPerRequest = perRequest
+}
+
+// localCost prices a local request at ts: the flat per-request cost plus the
+// time since the previous local request. Like idle detection, only the first
+// idle threshold of a gap counts, so a model left loaded overnight isn't
+// billed for the night. Callers must hold t.mu.
+func (t *Tracker) localCost(ts time.Time) float64 {
+	cost := t.localCostPerRequest
+	if !t.lastLocalEvent.IsZero() && ts.After(t.lastLocalEvent) {
+		limit := t.idleThreshold
+		if limit <= 0 {
+			limit = DefaultIdleThreshold
+		}
+		cost += t.localCostPerHour * min(ts.Sub(t.lastLocalEvent), limit).Hours()
+	}
+	if ts.After(t.lastLocalEvent) {
+		t.lastLocalEvent = ts
+	}
+	return cost
+}
+
+// UnpricedEvents returns how many session events were for models without a
+// known price. Their cost is the tool's own, an estimate at the fallback
+// model's rates, or $0 without a fallback, so it says how far to trust the
+// session total.
+func (t *Tracker) UnpricedEvents() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.unpricedEvents
+}
+
+// summaryTopModels is how many models GetSummary lists
+const summaryTopModels = 5
+
+// GetSummary returns the session as a few lines of plain text: its cost,
+// burn rate, call count, and duration, then its costliest models
 func (t *Tracker) GetSummary() string {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
 
-	rate := t.GetBurnRatePerHour()
-	return fmt.Sprintf("Session: $%.4f | Burn rate: $%.2f/hr | Calls: %d",
-		t.SessionCost, rate, len(t.SessionUsages))
+	active, wall := t.durationsAt(t.clock())
+	rate := 0.0
+	if len(t.SessionUsages) > 0 && active > 0 {
+		rate = t.SessionCost / active.Hours()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Session:   %s\n", config.FormatMoney(t.SessionCost))
+	fmt.Fprintf(&b, "Burn rate: %s/hr\n", config.FormatMoneyCents(rate))
+	total := 0
+	calls := make(map[string]int)
+	for _, u := range t.SessionUsages {
+		total += u.requests()
+		calls[u.Model] += u.requests()
+	}
+	fmt.Fprintf(&b, "Calls:     %d\n", total)
+	fmt.Fprintf(&b, "Duration:  %s active (%s since start)\n", formatDuration(active), formatDuration(wall))
+
+	models := groupUsages(t.SessionUsages, func(u Usage) string { return u.Model })
+	if len(models) > summaryTopModels {
+		models = models[:summaryTopModels]
+	}
+	if len(models) > 0 {
+		b.WriteString("Top models:\n")
+	}
+	width := 0
+	for _, m := range models {
+		width = max(width, len(m.Model))
+	}
+	for _, m := range models {
+		noun := "calls"
+		if calls[m.Model] == 1 {
+			noun = "call"
+		}
+		fmt.Fprintf(&b, "  %-*s  %10s  %d %s\n", width, m.Model, config.FormatMoney(m.Cost), calls[m.Model], noun)
+	}
+	return b.String()
+}
+
+// formatDuration renders d as hours and minutes, e.g. "1h 5m"
+func formatDuration(d time.Duration) string {
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+	if hours > 0 {
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	}
+	return fmt.Sprintf("%dm", minutes)
 }
 
 // SetToolStatus sets or updates the status for a tool
@@ -170,10 +653,32 @@ func (t *Tracker) SetToolStatus(status ToolStatus) {
 	if t.ToolStatuses == nil {
 		t.ToolStatuses = make(map[string]*ToolStatus)
 	}
+	from := ""
+	status.History = nil
+	if prev, ok := t.ToolStatuses[status.Name]; ok {
+		from = prev.Status
+		status.History = prev.History
+	}
+	status.recordTransition(t.clock(), from)
 	t.ToolStatuses[status.Name] = &status
 }
 
-// GetToolStatuses returns all tool statuses sorted by name
+// Tool panel orders (see SetToolSort)
+const (
+	ToolSortName     = "name"     // Alphabetical
+	ToolSortActivity = "activity" // Latest event first, then costliest
+	ToolSortTier     = "tier"     // Tracked tools by activity, then detection-only, then not found
+)
+
+// SetToolSort sets the order GetToolStatuses returns tools in: ToolSortName,
+// ToolSortActivity, or ToolSortTier (the default)
+func (t *Tracker) SetToolSort(mode string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.toolSort = mode
+}
+
+// GetToolStatuses returns all tool statuses in the tool sort order
 func (t *Tracker) GetToolStatuses() []*ToolStatus {
 	t.mu.RLock()
 	defer t.mu.RUnlock()
@@ -182,13 +687,52 @@ func (t *Tracker) GetToolStatuses() []*ToolStatus {
 	for _, s := range t.ToolStatuses {
 		statuses = append(statuses, s)
 	}
+	sortToolStatuses(statuses, t.toolSort)
+	return statuses
+}
 
-	// Sort by name for consistent display
-	sort.Slice(statuses, func(i, j int) bool {
-		return statuses[i].Name < statuses[j].Name
-	})
+// sortToolStatuses orders statuses for the tools panel (see SetToolSort).
+// Ties fall back to the name, so the order never shifts between ticks.
+func sortToolStatuses(statuses []*ToolStatus, mode string) {
+	byName := func(a, b *ToolStatus) bool { return a.Name < b.Name }
+	byActivity := func(a, b *ToolStatus) bool {
+		if !a.LastEventTime.Equal(b.LastEventTime) {
+			return a.LastEventTime.After(b.LastEventTime)
+		}
+		if a.TotalCost != b.TotalCost {
+			return a.TotalCost > b.TotalCost
+		}
+		return byName(a, b)
+	}
 
-	return statuses
+	var less func(a, b *ToolStatus) bool
+	switch mode {
+	case ToolSortName:
+		less = byName
+	case ToolSortActivity:
+		less = byActivity
+	default:
+		less = func(a, b *ToolStatus) bool {
+			if ga, gb := toolGroup(a), toolGroup(b); ga != gb {
+				return ga < gb
+			}
+			return byActivity(a, b)
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool { return less(statuses[i], statuses[j]) })
+}
+
+// toolGroup is a tool's place in ToolSortTier order: tracked tools, then
+// detection-only ones, then tools that weren't found
+func toolGroup(s *ToolStatus) int {
+	switch {
+	case s.Status == "not_found":
+		return 2
+	case s.Tier == TierDetectionOnly:
+		return 1
+	default:
+		return 0
+	}
 }
 
 // IncrementToolEvents increments the event count and updates last event time for a tool
@@ -201,7 +745,59 @@ func (t *Tracker) IncrementToolEvents(toolName string) {
 	}
 	if status, ok := t.ToolStatuses[toolName]; ok {
 		status.EventCount++
-		status.LastEventTime = time.Now()
+		status.LastEventTime = t.clock()
+		if status.Status == "idle" {
+			status.Status = "active"
+			status.recordTransition(status.LastEventTime, "idle")
+		}
+	}
+}
+
+// SetToolActiveThreshold sets how long an active tool can go without an
+// event before RefreshToolStatuses shows it as idle. Zero keeps tools
+// active for good.
+func (t *Tracker) SetToolActiveThreshold(d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.toolActiveThreshold = d
+}
+
+// RefreshToolStatuses downgrades active tools whose latest event (or, before
+// their first, the session's start) is older than the active threshold to
+// idle. A tool's next event makes it active again. Call it on each tick, so
+// "active" always means the tool is producing events now.
+func (t *Tracker) RefreshToolStatuses() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.toolActiveThreshold <= 0 {
+		return
+	}
+	now := t.clock()
+	for _, status := range t.ToolStatuses {
+		if status.Status != "active" {
+			continue
+		}
+		last := status.LastEventTime
+		if last.Before(t.StartTime) {
+			last = t.StartTime
+		}
+		if now.Sub(last) > t.toolActiveThreshold {
+			status.Status = "idle"
+			status.recordTransition(now, "active")
+		}
+	}
+}
+
+// RecordCostMismatch flags an event whose tool-reported cost was far from
+// our own price for its tokens, e.g. because the tool used another
+// provider's rates
+func (t *Tracker) RecordCostMismatch(toolName string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if status, ok := t.ToolStatuses[toolName]; ok {
+		status.CostMismatches++
 	}
 }
 
@@ -216,23 +812,90 @@ func (t *Tracker) GetToolStatus(toolName string) *ToolStatus {
 	return t.ToolStatuses[toolName]
 }
 
-// GetHistoricalUsage returns usage summary for Today or Week from DB
+// windowStart returns the Unix time a Today, Week, Month, or All window starts
+func (t *Tracker) windowStart(window string) (int64, error) {
+	// Window boundaries come from storage so they match the daily chart
+	start, err := storage.WindowStart(window, t.clock())
+	if err != nil {
+		return 0, err
+	}
+	return start.Unix(), nil
+}
+
+// GetSpendByTag returns spend per session tag within a window ("" for untagged)
+func (t *Tracker) GetSpendByTag(window string) (map[string]float64, error) {
+	since, err := t.windowStart(window)
+	if err != nil {
+		return nil, err
+	}
+	return storage.GetSpendByTag(since)
+}
+
+// GetUsageByProvider returns spend and tokens per provider within a window,
+// most expensive first. Events without a known provider are under "".
+func (t *Tracker) GetUsageByProvider(window string) ([]Usage, float64, error) {
+	return t.getUsageBy(window, storage.GetUsageSummaryByProvider)
+}
+
+// GetUsageByProject returns spend and tokens per project within a window,
+// restricted by filter, most expensive first. Events without a known project
+// are under "".
+func (t *Tracker) GetUsageByProject(window string, filter storage.UsageFilter) ([]Usage, float64, error) {
+	return t.getUsageBy(window, func(since int64) (map[string]storage.ModelSummary, float64, error) {
+		return storage.GetUsageSummaryByProject(since, filter)
+	})
+}
+
+// getUsageBy returns a window's usage as grouped by summarize, with each
+// group's key in Model, most expensive first
+func (t *Tracker) getUsageBy(window string, summarize func(since int64) (map[string]storage.ModelSummary, float64, error)) ([]Usage, float64, error) {
+	since, err := t.windowStart(window)
+	if err != nil {
+		return nil, 0, err
+	}
+	summary, total, err := summarize(since)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	usages := make([]Usage, 0, len(summary))
+	for key, s := range summary {
+		usages = append(usages, Usage{
+			Model:            key,
+			PromptTokens:     s.PromptTokens,
+			CompletionTokens: s.CompletionTokens,
+			TotalTokens:      s.PromptTokens + s.CompletionTokens,
+			CacheReadTokens:  s.CacheReadTokens,
+			Cost:             s.Cost,
+			CacheSavings:     s.CacheSavings,
+		})
+	}
+	sort.Slice(usages, func(i, j int) bool { return usages[i].Cost > usages[j].Cost })
+	return usages, total, nil
+}
+
+// GetTokenTotals returns the prompt and completion tokens within a window
+func (t *Tracker) GetTokenTotals(window string) (prompt, completion int, err error) {
+	since, err := t.windowStart(window)
+	if err != nil {
+		return 0, 0, err
+	}
+	return storage.GetTokenTotals(since)
+}
+
+// GetHistoricalUsage returns usage summary for Today, Week, Month, or All time from DB
 func (t *Tracker) GetHistoricalUsage(window string) ([]Usage, float64, error) {
-	var since int64
-	now := time.Now()
-
-	switch window {
-	case "today":
-		// Midnight today
-		since = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).Unix()
-	case "week":
-		// 7 days ago
-		since = now.AddDate(0, 0, -7).Unix()
-	default:
-		return nil, 0, fmt.Errorf("invalid window: %s", window)
+	return t.GetFilteredHistoricalUsage(window, storage.UsageFilter{})
+}
+
+// GetFilteredHistoricalUsage is GetHistoricalUsage restricted to a tool and/or tag
+func (t *Tracker) GetFilteredHistoricalUsage(window string, filter storage.UsageFilter) ([]Usage, float64, error) {
+	since, err := t.windowStart(window)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	summary, total, err := storage.GetUsageSummary(since)
+	summary, total, err := storage.GetFilteredUsageSummary(since, filter)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -241,11 +904,15 @@ func (t *Tracker) GetHistoricalUsage(window string) ([]Usage, float64, error) {
 	var usages []Usage
 	for model, data := range summary {
 		usages = append(usages, Usage{
+			Tool:             filter.Tool,
 			Model:            model,
 			PromptTokens:     data.PromptTokens,
 			CompletionTokens: data.CompletionTokens,
 			TotalTokens:      data.PromptTokens + data.CompletionTokens,
+			CacheReadTokens:  data.CacheReadTokens,
+			ReasoningTokens:  data.ReasoningTokens,
 			Cost:             data.Cost,
+			CacheSavings:     data.CacheSavings,
 		})
 	}
 
@@ -257,7 +924,166 @@ func (t *Tracker) GetHistoricalUsage(window string) ([]Usage, float64, error) {
 	return usages, total, nil
 }
 
-// GetDailySpend returns the daily spend for the last N days
+// GetLifetimeStats returns the all-time spend, event count, and first event time
+func (t *Tracker) GetLifetimeStats() (float64, int, time.Time, error) {
+	total, events, firstTs, err := storage.GetLifetimeStats()
+	if err != nil {
+		return 0, 0, time.Time{}, err
+	}
+
+	var first time.Time
+	if firstTs > 0 {
+		first = time.Unix(firstTs, 0)
+	}
+	return total, events, first, nil
+}
+
+// GetDailySpend returns the spend for each of the last N days (including
+// today), oldest first. Days without usage are included with zero cost so
+// charts have no gaps.
 func (t *Tracker) GetDailySpend(days int) ([]storage.DailySpend, error) {
-	return storage.GetDailyUsage(days)
+	sparse, err := storage.GetDailyUsage(days)
+	if err != nil {
+		return nil, err
+	}
+
+	byDay := make(map[string]float64, len(sparse))
+	for _, ds := range sparse {
+		byDay[ds.Date] = ds.Cost
+	}
+
+	start := storage.StartOfDay(t.clock()).AddDate(0, 0, -days+1)
+	dense := make([]storage.DailySpend, 0, days)
+	for i := 0; i < days; i++ {
+		key := storage.DayKey(start.AddDate(0, 0, i))
+		dense = append(dense, storage.DailySpend{Date: key, Cost: byDay[key]})
+	}
+	return dense, nil
+}
+
+// GetHourlySpend returns the spend for each of the last N hours, oldest first.
+// Hours without usage are included with zero cost so charts have no gaps.
+func (t *Tracker) GetHourlySpend(hours int) ([]storage.HourlySpend, error) {
+	sparse, err := storage.GetHourlyUsage(hours)
+	if err != nil {
+		return nil, err
+	}
+
+	byHour := make(map[string]float64, len(sparse))
+	for _, hs := range sparse {
+		byHour[hs.Hour] = hs.Cost
+	}
+
+	start := storage.StartOfHour(t.clock()).Add(-time.Duration(hours-1) * time.Hour)
+	dense := make([]storage.HourlySpend, 0, hours)
+	for i := 0; i < hours; i++ {
+		key := storage.HourKey(start.Add(time.Duration(i) * time.Hour))
+		dense = append(dense, storage.HourlySpend{Hour: key, Cost: byHour[key]})
+	}
+	return dense, nil
+}
+
+// Snapshot is a serializable copy of a tracker's session, used to share a
+// background daemon's state with an attached dashboard
+type Snapshot struct {
+	StartTime    time.Time    `json:"start_time"`
+	SessionCost  float64      `json:"session_cost"`
+	Usages       []Usage      `json:"usages"`
+	ToolStatuses []ToolStatus `json:"tool_statuses"`
+	Tag          string       `json:"tag,omitempty"`
+
+	// Idle detection state, so an attached dashboard shows the same active time
+	LastEventTime time.Time     `json:"last_event_time,omitempty"`
+	IdleTotal     time.Duration `json:"idle_total,omitempty"`
+}
+
+// SnapshotUsages is how many of the latest usages a Snapshot carries one by
+// one. Older ones are merged per tool, model, and project, so a long
+// session's snapshot stays small enough to publish every few seconds.
+var SnapshotUsages = 1000
+
+// Snapshot returns a copy of the current session and tool statuses
+func (t *Tracker) Snapshot() Snapshot {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	snap := Snapshot{
+		StartTime:   t.StartTime,
+		SessionCost: t.SessionCost,
+		Tag:         t.tag,
+
+		LastEventTime: t.lastEventTime,
+		IdleTotal:     t.idleTotal,
+	}
+	recent := t.SessionUsages
+	if len(recent) > SnapshotUsages {
+		split := len(recent) - SnapshotUsages
+		snap.Usages = mergeOlderUsages(recent[:split])
+		recent = recent[split:]
+	}
+	snap.Usages = append(snap.Usages, recent...)
+	for _, s := range t.ToolStatuses {
+		snap.ToolStatuses = append(snap.ToolStatuses, *s)
+	}
+	return snap
+}
+
+// mergeOlderUsages merges usages of the same tool, model, and project into
+// one each, in order of their first request. A merged usage carries its
+// latest timestamp and how many requests it stands for.
+func mergeOlderUsages(usages []Usage) []Usage {
+	type key struct{ tool, model, project string }
+	index := make(map[key]int)
+	var merged []Usage
+	for _, u := range usages {
+		k := key{u.Tool, u.Model, u.Project}
+		i, ok := index[k]
+		if !ok {
+			i = len(merged)
+			index[k] = i
+			merged = append(merged, Usage{Tool: u.Tool, Model: u.Model, Project: u.Project})
+		}
+		m := &merged[i]
+		m.PromptTokens += u.PromptTokens
+		m.CompletionTokens += u.CompletionTokens
+		m.TotalTokens += u.TotalTokens
+		m.CacheReadTokens += u.CacheReadTokens
+		m.ReasoningTokens += u.ReasoningTokens
+		m.Cost = pricing.AddUSD(m.Cost, u.Cost)
+		m.CacheSavings = pricing.AddUSD(m.CacheSavings, u.CacheSavings)
+		m.Requests += u.requests()
+		if u.Timestamp.After(m.Timestamp) {
+			m.Timestamp = u.Timestamp
+		}
+	}
+	return merged
+}
+
+// LoadSnapshot replaces the current session and tool statuses with a snapshot
+func (t *Tracker) LoadSnapshot(snap Snapshot) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.StartTime = snap.StartTime
+	t.sessionMicros = pricing.ToMicros(snap.SessionCost)
+	t.SessionCost = t.sessionMicros.USD()
+	t.SessionUsages = snap.Usages
+	t.tag = snap.Tag
+	t.lastEventTime = snap.LastEventTime
+	t.idleTotal = snap.IdleTotal
+	t.largestRequest = Usage{}
+	t.unpricedEvents = 0
+	for _, u := range snap.Usages {
+		if u.Requests == 0 && u.PromptTokens > t.largestRequest.PromptTokens {
+			t.largestRequest = u
+		}
+		if pricing.NeedsFallback(u.Model) {
+			t.unpricedEvents += u.requests()
+		}
+	}
+	t.ToolStatuses = make(map[string]*ToolStatus, len(snap.ToolStatuses))
+	for i := range snap.ToolStatuses {
+		status := snap.ToolStatuses[i]
+		t.ToolStatuses[status.Name] = &status
+	}
 }
@@ -0,0 +1,752 @@
+package tracker
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bangarangler/burnrate/internal/pricing"
+	"github.com/bangarangler/burnrate/internal/storage"
+)
+
+func TestGetDailySpendZeroFills(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := storage.InitDB(); err != nil {
+		t.Fatalf("InitDB failed: %v", err)
+	}
+	defer func() {
+		storage.DB.Close()
+		storage.DB = nil
+	}()
+
+	// Spend on two of the last 7 days: 5 days ago and today
+	today := storage.StartOfDay(time.Now()).Add(time.Hour)
+	fiveDaysAgo := today.AddDate(0, 0, -5)
+	storage.RecordUsageAt(fiveDaysAgo.Unix(), "Test", "m", 1, 1, 1.5)
+	storage.RecordUsageAt(today.Unix(), "Test", "m", 2, 2, 2.5)
+
+	spend, err := Global.GetDailySpend(7)
+	if err != nil {
+		t.Fatalf("GetDailySpend failed: %v", err)
+	}
+
+	// 1. Dense series with one entry per day
+	if len(spend) != 7 {
+		t.Fatalf("Expected 7 days, got %d: %+v", len(spend), spend)
+	}
+
+	// 2. Sorted ascending, ending today
+	for i := 1; i < len(spend); i++ {
+		if spend[i].Date <= spend[i-1].Date {
+			t.Errorf("Days not ascending: %s after %s", spend[i].Date, spend[i-1].Date)
+		}
+	}
+	if spend[6].Date != storage.DayKey(today) {
+		t.Errorf("Expected last day %s, got %s", storage.DayKey(today), spend[6].Date)
+	}
+
+	// 3. Active days carry their cost, the rest are zero
+	for i, ds := range spend {
+		want := 0.0
+		switch i {
+		case 1:
+			want = 1.5
+		case 6:
+			want = 2.5
+		}
+		if ds.Cost != want {
+			t.Errorf("Day %d (%s): expected $%.2f, got $%.2f", i, ds.Date, want, ds.Cost)
+		}
+	}
+}
+
+func TestSubscribeReceivesUsage(t *testing.T) {
+	trk := &Tracker{StartTime: time.Now(), ToolStatuses: make(map[string]*ToolStatus)}
+
+	events, unsubscribe := trk.Subscribe()
+	trk.AddToolUsage("Aider", Usage{Model: "gpt-4o", PromptTokens: 10, CompletionTokens: 5, Cost: 0.01})
+
+	select {
+	case u := <-events:
+		if u.Tool != "Aider" || u.Model != "gpt-4o" || u.PromptTokens != 10 {
+			t.Errorf("Expected the Aider gpt-4o event, got %+v", u)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected an event, got none")
+	}
+
+	// After unsubscribing the channel is closed and nothing more is sent
+	unsubscribe()
+	trk.AddToolUsage("Aider", Usage{Model: "gpt-4o"})
+	if _, ok := <-events; ok {
+		t.Error("Expected the channel to be closed after unsubscribe")
+	}
+}
+
+func TestActiveDurationExcludesIdleGaps(t *testing.T) {
+	start := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	trk := &Tracker{StartTime: start, idleThreshold: 5 * time.Minute}
+
+	// Events at 1m and 21m: the 20m gap between them is 15m idle
+	trk.recordArrival(start.Add(1 * time.Minute))
+	trk.recordArrival(start.Add(21 * time.Minute))
+
+	// 1. Within the threshold after the last event, nothing more is idle
+	active, wall := trk.durationsAt(start.Add(24 * time.Minute))
+	if wall != 24*time.Minute {
+		t.Errorf("Expected wall 24m, got %v", wall)
+	}
+	if active != 9*time.Minute {
+		t.Errorf("Expected active 9m, got %v", active)
+	}
+
+	// 2. An open gap past the threshold counts too: 9m since 21m is 4m idle
+	active, _ = trk.durationsAt(start.Add(30 * time.Minute))
+	if active != 11*time.Minute {
+		t.Errorf("Expected active 11m, got %v", active)
+	}
+	if trk.idleSince(start.Add(30*time.Minute)) == 0 {
+		t.Error("Expected the session to be idle 9m after the last event")
+	}
+
+	// 3. A zero threshold disables idle detection
+	off := &Tracker{StartTime: start}
+	off.recordArrival(start.Add(1 * time.Minute))
+	active, wall = off.durationsAt(start.Add(30 * time.Minute))
+	if active != wall {
+		t.Errorf("Expected active to equal wall with detection off, got %v of %v", active, wall)
+	}
+}
+
+func TestLargestContextTracksWorstRequest(t *testing.T) {
+	trk := &Tracker{StartTime: time.Now(), largeContextThreshold: 150_000}
+
+	// 1. Below the threshold the largest request is kept but not flagged
+	trk.AddUsage("gpt-4o", 20_000, 100, 0.05)
+	if largest, large := trk.LargestContext(); large || largest.PromptTokens != 20_000 {
+		t.Errorf("Expected an unflagged 20k request, got %+v (large=%v)", largest, large)
+	}
+
+	// 2. A request over the threshold becomes the flagged worst offender
+	trk.AddUsage("claude-sonnet-4", 180_000, 500, 0.54)
+	trk.AddUsage("gpt-4o", 160_000, 100, 0.40)
+	largest, large := trk.LargestContext()
+	if !large || largest.PromptTokens != 180_000 || largest.Cost != 0.54 {
+		t.Errorf("Expected the flagged 180k request, got %+v (large=%v)", largest, large)
+	}
+
+	// 3. Reset clears it
+	trk.Reset()
+	if largest, large := trk.LargestContext(); large || largest.PromptTokens != 0 {
+		t.Errorf("Expected no largest request after reset, got %+v", largest)
+	}
+}
+
+// fakeClock is a settable clock for NewTracker
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) Now() time.Time          { return c.t }
+func (c *fakeClock) Advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func TestBurnRateFollowsTheClock(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)}
+	trk := NewTracker(clock.Now)
+	trk.SetIdleThreshold(0)
+
+	// 1. No usage means no burn rate
+	clock.Advance(time.Hour)
+	if rate := trk.GetBurnRatePerHour(); rate != 0 {
+		t.Errorf("Expected $0/hr before any usage, got $%.2f", rate)
+	}
+
+	// 2. $3 over two hours is $1.50/hr, and events are stamped by the clock
+	clock.Advance(time.Hour)
+	trk.AddUsage("gpt-4o", 1000, 500, 3.0)
+	if rate := trk.GetBurnRatePerHour(); rate != 1.5 {
+		t.Errorf("Expected $1.50/hr, got $%.2f", rate)
+	}
+	if usages := trk.GetUsages(); !usages[0].Timestamp.Equal(clock.Now()) {
+		t.Errorf("Expected the event stamped %v, got %v", clock.Now(), usages[0].Timestamp)
+	}
+
+	// 3. The rate falls as time passes without spend
+	clock.Advance(2 * time.Hour)
+	if rate := trk.GetBurnRatePerHour(); rate != 0.75 {
+		t.Errorf("Expected $0.75/hr, got $%.2f", rate)
+	}
+
+	// 4. Reset restarts the session at the clock's time
+	trk.Reset()
+	if !trk.StartTime.Equal(clock.Now()) {
+		t.Errorf("Expected the session to restart at %v, got %v", clock.Now(), trk.StartTime)
+	}
+}
+
+func TestBurnRateExcludesIdleTime(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)}
+	trk := NewTracker(clock.Now)
+	trk.SetIdleThreshold(5 * time.Minute)
+
+	// $1 at 5m, then a 2h break, then $1 more: only 5m of the break is active
+	clock.Advance(5 * time.Minute)
+	trk.AddUsage("gpt-4o", 100, 100, 1.0)
+	clock.Advance(2 * time.Hour)
+	trk.AddUsage("gpt-4o", 100, 100, 1.0)
+	clock.Advance(5 * time.Minute)
+
+	active, wall := trk.SessionDurations()
+	if active != 15*time.Minute || wall != 130*time.Minute {
+		t.Errorf("Expected 15m active of 130m, got %v of %v", active, wall)
+	}
+	if rate := trk.GetBurnRatePerHour(); rate != 8.0 {
+		t.Errorf("Expected $8.00/hr over 15m active, got $%.2f", rate)
+	}
+}
+
+func TestUnpricedEventsCounted(t *testing.T) {
+	trk := NewTracker(time.Now)
+
+	trk.AddUsage("gpt-4o", 100, 50, 0.01)
+	trk.AddUsage("mystery-model", 100, 50, 0.01)
+	trk.AddUsage("llama-3-8b:free", 100, 50, 0)
+	trk.AddUsage("mystery-model", 100, 50, 0.01)
+
+	// 1. Only models without a price count; free ones are priced at zero
+	if n := trk.UnpricedEvents(); n != 2 {
+		t.Errorf("Expected 2 unpriced events, got %d", n)
+	}
+
+	// 2. The count survives a snapshot round trip and clears on reset
+	other := NewTracker(time.Now)
+	other.LoadSnapshot(trk.Snapshot())
+	if n := other.UnpricedEvents(); n != 2 {
+		t.Errorf("Expected 2 unpriced events after loading a snapshot, got %d", n)
+	}
+	trk.Reset()
+	if n := trk.UnpricedEvents(); n != 0 {
+		t.Errorf("Expected no unpriced events after reset, got %d", n)
+	}
+}
+
+func TestSnapshotMergesOlderUsages(t *testing.T) {
+	defer func(n int) { SnapshotUsages = n }(SnapshotUsages)
+	SnapshotUsages = 2
+
+	trk := NewTracker(time.Now)
+	for i := 0; i < 3; i++ {
+		trk.AddUsage("gpt-4o", 100, 50, 0.01)
+	}
+	trk.AddUsage("claude-sonnet-4", 200, 20, 0.02)
+	trk.AddUsage("gpt-4o", 100, 50, 0.01)
+	trk.AddUsage("claude-sonnet-4", 200, 20, 0.02)
+
+	// 1. The latest usages are kept; the older ones merge per model
+	snap := trk.Snapshot()
+	if len(snap.Usages) != 4 {
+		t.Fatalf("Expected 2 merged and 2 recent usages, got %+v", snap.Usages)
+	}
+	merged := snap.Usages[0]
+	if merged.Model != "gpt-4o" || merged.Requests != 3 || merged.PromptTokens != 300 {
+		t.Errorf("Expected 3 gpt-4o requests merged, got %+v", merged)
+	}
+
+	// 2. An attached tracker still has every request and dollar
+	other := NewTracker(time.Now)
+	other.LoadSnapshot(snap)
+	var cost float64
+	for _, u := range other.GetUsages() {
+		cost += u.Cost
+	}
+	if cost < 0.0799 || cost > 0.0801 {
+		t.Errorf("Expected $0.08 across the usages, got $%.4f", cost)
+	}
+	if summary := other.GetSummary(); !strings.Contains(summary, "Calls:     6") {
+		t.Errorf("Expected 6 calls in the summary, got:\n%s", summary)
+	}
+}
+
+func TestLocalModelsBilledForTime(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)}
+	trk := NewTracker(clock.Now)
+	trk.SetIdleThreshold(5 * time.Minute)
+	trk.SetLocalCost(0.60, 0.01)
+
+	// 1. The first request costs the flat fee, whatever the tool reported
+	trk.AddUsage("llama3.1:8b (ollama)", 1000, 500, 0.25)
+	// 2. The next, 2m later, adds 2m of running time at $0.60/hr
+	clock.Advance(2 * time.Minute)
+	trk.AddUsage("ollama/llama3", 1000, 500, 0)
+	// 3. After a 1h break, only the idle threshold's 5m is billed
+	clock.Advance(time.Hour)
+	trk.AddUsage("ollama/llama3", 1000, 500, 0)
+	// 4. Cloud models keep their token price
+	trk.AddUsage("gpt-4o", 1000, 500, 0.50)
+
+	want := []float64{0.01, 0.01 + 0.02, 0.01 + 0.05, 0.50}
+	for i, u := range trk.GetUsages() {
+		if math.Abs(u.Cost-want[i]) > 1e-9 {
+			t.Errorf("Expected event %d (%s) to cost $%.4f, got $%.4f", i+1, u.Model, want[i], u.Cost)
+		}
+	}
+	if n := trk.UnpricedEvents(); n != 0 {
+		t.Errorf("Expected local models not to count as unpriced, got %d", n)
+	}
+}
+
+func TestRepriceFallbacksOnceModelIsPriced(t *testing.T) {
+	const model = "acme-coder-9"
+	defer delete(pricing.ModelPricing, model)
+
+	trk := NewTracker(time.Now)
+	estimate := pricing.CalculateCostWithCache(model, 800_000, 200_000, 100_000, 0)
+	trk.AddToolUsage("Aider", Usage{Model: model, PromptTokens: 1_000_000, CacheReadTokens: 200_000,
+		CompletionTokens: 100_000, Cost: estimate})
+	// The tool's own cost for an unknown model is not an estimate, so it stays
+	trk.AddToolUsage("Aider", Usage{Model: model, PromptTokens: 1000, CompletionTokens: 100, Cost: 0.5})
+	trk.AddToolUsage("Aider", Usage{Model: "gpt-4o", PromptTokens: 1000, CompletionTokens: 100, Cost: 0.01})
+
+	// 1. Nothing changes while the model still has no price
+	if delta := trk.RepriceFallbacks(); delta != 0 {
+		t.Fatalf("Expected no adjustment before pricing, got %f", delta)
+	}
+
+	// 2. Once priced, the estimate is replaced and the totals follow
+	pricing.ModelPricing[model] = pricing.ModelPrice{Input: 3, Output: 15, Provider: "Acme"}
+	real := pricing.CalculateCostWithCache(model, 800_000, 200_000, 100_000, 0)
+	delta := trk.RepriceFallbacks()
+	if math.Abs(delta-(real-estimate)) > 1e-9 {
+		t.Errorf("Expected an adjustment of %f, got %f", real-estimate, delta)
+	}
+	if want := real + 0.5 + 0.01; math.Abs(trk.GetSessionCost()-want) > 1e-9 {
+		t.Errorf("Expected session cost %f, got %f", want, trk.GetSessionCost())
+	}
+	if status := trk.GetToolStatus("Aider"); math.Abs(status.TotalCost-(real+0.5+0.01)) > 1e-9 {
+		t.Errorf("Expected Aider's total to follow, got %f", status.TotalCost)
+	}
+	if usages := trk.GetUsages(); usages[1].Cost != 0.5 {
+		t.Errorf("Expected the reported cost to stay at 0.5, got %f", usages[1].Cost)
+	}
+	if got, at := trk.PriceAdjustment(); got != delta || at.IsZero() {
+		t.Errorf("Expected the adjustment %f to be recorded, got %f at %v", delta, got, at)
+	}
+
+	// 3. A second fetch doesn't adjust again
+	if again := trk.RepriceFallbacks(); again != 0 {
+		t.Errorf("Expected no further adjustment, got %f", again)
+	}
+}
+
+func TestProvisionalUsageIsReplacedWhenFinalized(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)}
+	trk := NewTracker(clock.Now)
+	started := clock.t
+
+	// 1. An in-flight request is listed but counts toward nothing
+	trk.SetProvisional("OpenCode", "msg_1", Usage{Model: "gpt-4o", Timestamp: started})
+	clock.Advance(5 * time.Second)
+	trk.SetProvisional("OpenCode", "msg_1", Usage{Model: "gpt-4o", CompletionTokens: 500, Timestamp: clock.t})
+	inFlight := trk.GetProvisional()
+	if len(inFlight) != 1 || inFlight[0].CompletionTokens != 500 || inFlight[0].Cost == 0 {
+		t.Fatalf("Expected one priced in-flight request, got %+v", inFlight)
+	}
+	if !inFlight[0].Timestamp.Equal(started) {
+		t.Errorf("Expected the start time to be kept, got %v", inFlight[0].Timestamp)
+	}
+	if cost := trk.GetSessionCost(); cost != 0 {
+		t.Errorf("Expected no session cost from an estimate, got %f", cost)
+	}
+
+	// 2. Finalizing replaces it with the recorded usage
+	trk.AddUsageWithTool("OpenCode", "gpt-4o", 1000, 800, 0.02)
+	trk.ClearProvisional("OpenCode", "msg_1")
+	if inFlight := trk.GetProvisional(); len(inFlight) != 0 {
+		t.Errorf("Expected nothing in flight once finalized, got %+v", inFlight)
+	}
+
+	// 3. A request that never finishes expires
+	trk.SetProvisional("OpenCode", "msg_2", Usage{Model: "gpt-4o", Timestamp: clock.t})
+	clock.Advance(ProvisionalTTL + time.Second)
+	if inFlight := trk.GetProvisional(); len(inFlight) != 0 {
+		t.Errorf("Expected the stale request to expire, got %+v", inFlight)
+	}
+}
+
+func TestGroupByFamilySumsReleases(t *testing.T) {
+	grouped := GroupByFamily([]Usage{
+		{Model: "claude-3-5-sonnet-20241022", PromptTokens: 100, Cost: 0.10},
+		{Model: "gpt-4o", PromptTokens: 50, Cost: 0.30},
+		{Model: "claude-sonnet-4.5 (anthropic)", PromptTokens: 200, Cost: 0.25},
+	})
+
+	if len(grouped) != 2 {
+		t.Fatalf("Expected 2 families, got %+v", grouped)
+	}
+	if grouped[0].Model != "Claude Sonnet" || grouped[0].PromptTokens != 300 || math.Abs(grouped[0].Cost-0.35) > 1e-9 {
+		t.Errorf("Expected Claude Sonnet first with 300 tokens and $0.35, got %+v", grouped[0])
+	}
+	if grouped[1].Model != "GPT-4o" {
+		t.Errorf("Expected GPT-4o second, got %+v", grouped[1])
+	}
+}
+
+func TestFilterFreeHidesOrGroupsZeroCostRows(t *testing.T) {
+	defer func(model string) { pricing.FallbackModel = model }(pricing.FallbackModel)
+	pricing.FallbackModel = ""
+
+	usages := []Usage{
+		{Model: "gpt-4o", PromptTokens: 100, CompletionTokens: 10, Cost: 0.30},
+		{Model: "deepseek/deepseek-r1:free", PromptTokens: 200, CompletionTokens: 20},
+		{Model: "llama3 (ollama)", PromptTokens: 300, CompletionTokens: 30},
+		{Model: "mystery-model", PromptTokens: 50, CompletionTokens: 5}, // Unpriced, not free
+	}
+	models := func(usages []Usage) string {
+		var names []string
+		for _, u := range usages {
+			names = append(names, u.Model)
+		}
+		return strings.Join(names, ", ")
+	}
+
+	// 1. Shown by default
+	if got := FilterFree(usages, FreeModelsShow); len(got) != 4 {
+		t.Errorf("Expected all 4 rows, got %s", models(got))
+	}
+
+	// 2. Hidden: free and local rows go, an unknown cost stays
+	if got, want := models(FilterFree(usages, FreeModelsHide)), "gpt-4o, mystery-model"; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	// 3. Grouped: one row after the others, with their token totals
+	grouped := FilterFree(usages, FreeModelsGroup)
+	if got, want := models(grouped), "gpt-4o, mystery-model, "+FreeModelsRow; got != want {
+		t.Fatalf("Expected %q, got %q", want, got)
+	}
+	if free := grouped[2]; free.PromptTokens != 500 || free.CompletionTokens != 50 || free.Cost != 0 {
+		t.Errorf("Expected 500 in / 50 out at $0, got %+v", free)
+	}
+}
+
+func TestStartedBeforeTodayAtMidnight(t *testing.T) {
+	now := time.Date(2024, 5, 1, 23, 59, 0, 0, storage.Location())
+	trk := NewTracker(func() time.Time { return now })
+
+	// 1. A session started earlier the same day is today's
+	if trk.StartedBeforeToday() {
+		t.Error("Expected a session started today not to need a reset")
+	}
+
+	// 2. Once the clock passes midnight it's from the previous day
+	now = now.Add(2 * time.Minute)
+	if !trk.StartedBeforeToday() {
+		t.Error("Expected a session from yesterday to need a reset")
+	}
+
+	// 3. Resetting starts today's session
+	trk.Reset()
+	if trk.StartedBeforeToday() {
+		t.Error("Expected the reset session to start today")
+	}
+}
+
+func TestOutlierEventsAreRejected(t *testing.T) {
+	trk := NewTracker(time.Now)
+	trk.SetToolStatus(ToolStatus{Name: "Aider", Tier: TierFullTracking, Status: "active"})
+	trk.SetMaxEventCost(10)
+
+	// 1. An event under the ceiling is recorded
+	trk.AddUsageWithTool("Aider", "gpt-4o", 1000, 100, 2.50)
+
+	// 2. One over it is skipped and counted on the tool
+	trk.AddUsageWithTool("Aider", "gpt-4o", 1000, 100, 9000)
+	if got := trk.GetSessionCost(); got != 2.50 {
+		t.Errorf("Expected the outlier left out of the session cost, got %f", got)
+	}
+	if n := len(trk.GetUsages()); n != 1 {
+		t.Errorf("Expected 1 recorded usage, got %d", n)
+	}
+	if n := trk.GetToolStatus("Aider").RejectedEvents; n != 1 {
+		t.Errorf("Expected 1 rejected event, got %d", n)
+	}
+
+	// 3. A zero ceiling turns rejection off
+	trk.SetMaxEventCost(0)
+	trk.AddUsageWithTool("Aider", "gpt-4o", 1000, 100, 9000)
+	if got := trk.GetSessionCost(); got != 9002.50 {
+		t.Errorf("Expected the event recorded with no ceiling, got %f", got)
+	}
+}
+
+func TestReplayFeedsEventsInOrder(t *testing.T) {
+	log := `{"tool": "Aider", "model": "gpt-4o", "prompt_tokens": 1000, "completion_tokens": 100, "cost": 0.5, "timestamp": "2024-05-01T10:00:02Z"}
+
+{"model": "gpt-4o", "prompt_tokens": 1000000, "completion_tokens": 0, "timestamp": "2024-05-01T10:00:00Z"}
+`
+	events, err := ReadReplay(strings.NewReader(log))
+	if err != nil {
+		t.Fatalf("ReadReplay failed: %v", err)
+	}
+
+	// 1. Events replay oldest first, priced and tooled when the log leaves it out
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	trk := NewTracker(func() time.Time { return now })
+	var tools []string
+	n := trk.Replay(context.Background(), events, 1000, func(u Usage) { tools = append(tools, u.Tool) })
+	if n != 2 || strings.Join(tools, ",") != ReplayTool+",Aider" {
+		t.Fatalf("Expected 2 events from %s then Aider, got %d: %v", ReplayTool, n, tools)
+	}
+	if got := trk.GetSessionCost(); math.Abs(got-3.0) > 1e-9 {
+		t.Errorf("Expected $2.50 priced from 1M gpt-4o tokens plus $0.50, got %f", got)
+	}
+	if ts := trk.GetUsages()[0].Timestamp; !ts.Equal(now) {
+		t.Errorf("Expected events recorded at the replay's time, got %s", ts)
+	}
+
+	// 2. A cancelled replay stops before the next event
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if n := NewTracker(time.Now).Replay(ctx, events, 1, nil); n != 0 {
+		t.Errorf("Expected a cancelled replay to add nothing, got %d", n)
+	}
+
+	// 3. Malformed lines are reported by number
+	if _, err := ReadReplay(strings.NewReader("{\"model\": \"gpt-4o\"}\nnot json\n{}\n")); err == nil ||
+		!strings.Contains(err.Error(), "line 2") || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("Expected errors for lines 2 and 3, got %v", err)
+	}
+}
+
+func TestBreakdownCostSplitsByTokenType(t *testing.T) {
+	model := "split-test-model"
+	pricing.ModelPricing[model] = pricing.ModelPrice{Input: 2, Output: 8, Reasoning: 16}
+	pricing.CacheReadPricing[model] = 0.5
+	defer delete(pricing.ModelPricing, model)
+	defer delete(pricing.CacheReadPricing, model)
+
+	// 1M prompt tokens (a quarter cached) and 1M completion tokens (half
+	// reasoning): $1.50 input, $0.125 cache, $4 output, and $8 reasoning
+	u := Usage{Model: model, PromptTokens: 1_000_000, CacheReadTokens: 250_000,
+		CompletionTokens: 1_000_000, ReasoningTokens: 500_000}
+	u.Cost = 1.5 + 0.125 + 4 + 8
+
+	// 1. Each kind of token is priced at its own rate
+	b := BreakdownCost([]Usage{u})
+	if math.Abs(b.Input-1.5) > 1e-9 || math.Abs(b.CacheRead-0.125) > 1e-9 ||
+		math.Abs(b.Output-4) > 1e-9 || math.Abs(b.Reasoning-8) > 1e-9 {
+		t.Errorf("Expected $1.50 / $0.125 / $4 / $8, got %+v", b)
+	}
+
+	// 2. A reported cost is divided in the same proportions
+	u.Cost = (1.5 + 0.125 + 4 + 8) / 2
+	b = BreakdownCost([]Usage{u})
+	if math.Abs(b.Output-2) > 1e-9 || math.Abs(b.Total()-u.Cost) > 1e-9 {
+		t.Errorf("Expected output to be half, $2, and the parts to add up to %f, got %+v", u.Cost, b)
+	}
+
+	// 3. Cost that no token rate explains, such as a local model's hourly
+	// cost, is kept apart
+	b = BreakdownCost([]Usage{{Model: "ollama/llama3", PromptTokens: 100, CompletionTokens: 10, Cost: 0.3}})
+	if b.Other != 0.3 || b.Total() != 0 {
+		t.Errorf("Expected $0.30 of other cost, got %+v", b)
+	}
+}
+
+func TestGetSummaryListsTopModels(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)}
+	trk := NewTracker(clock.Now)
+	trk.SetIdleThreshold(0)
+
+	trk.AddUsage("gpt-4o", 1000, 500, 1.0)
+	trk.AddUsage("claude-3-5-sonnet", 1000, 500, 2.0)
+	trk.AddUsage("gpt-4o", 1000, 500, 2.0)
+	clock.Advance(90 * time.Minute)
+
+	summary := trk.GetSummary()
+
+	// 1. Totals: $5 over 1.5h is $3.33/hr
+	for _, want := range []string{"$5.00", "$3.33/hr", "Calls:     3", "1h 30m"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Expected summary to contain %q, got:\n%s", want, summary)
+		}
+	}
+
+	// 2. Models are listed costliest first, with their call counts
+	gpt := strings.Index(summary, "gpt-4o")
+	claude := strings.Index(summary, "claude-3-5-sonnet")
+	if gpt < 0 || claude < 0 || gpt > claude {
+		t.Errorf("Expected gpt-4o listed before claude-3-5-sonnet, got:\n%s", summary)
+	}
+	if !strings.Contains(summary, "2 calls") || !strings.Contains(summary, "1 call\n") {
+		t.Errorf("Expected per-model call counts, got:\n%s", summary)
+	}
+}
+
+func TestToolStatusGoesIdleWithoutEvents(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)}
+	trk := NewTracker(clock.Now)
+	trk.SetToolActiveThreshold(10 * time.Minute)
+	trk.SetToolStatus(ToolStatus{Name: "Aider", Tier: TierFullTracking, Status: "active"})
+	trk.SetToolStatus(ToolStatus{Name: "Crush", Tier: TierFullTracking, Status: "not_found"})
+
+	// 1. Within the threshold of the session's start, the tool stays active
+	clock.Advance(5 * time.Minute)
+	trk.RefreshToolStatuses()
+	if s := trk.GetToolStatus("Aider").Status; s != "active" {
+		t.Errorf("Expected active, got %s", s)
+	}
+
+	// 2. An event restarts the clock
+	trk.IncrementToolEvents("Aider")
+	clock.Advance(8 * time.Minute)
+	trk.RefreshToolStatuses()
+	if s := trk.GetToolStatus("Aider").Status; s != "active" {
+		t.Errorf("Expected active 8m after an event, got %s", s)
+	}
+
+	// 3. Past the threshold it's idle; other statuses are left alone
+	clock.Advance(3 * time.Minute)
+	trk.RefreshToolStatuses()
+	if s := trk.GetToolStatus("Aider").Status; s != "idle" {
+		t.Errorf("Expected idle 11m after an event, got %s", s)
+	}
+	if s := trk.GetToolStatus("Crush").Status; s != "not_found" {
+		t.Errorf("Expected not_found untouched, got %s", s)
+	}
+
+	// 4. The next event makes it active again
+	trk.IncrementToolEvents("Aider")
+	if s := trk.GetToolStatus("Aider").Status; s != "active" {
+		t.Errorf("Expected active after a new event, got %s", s)
+	}
+
+	// 5. A zero threshold keeps tools active for good
+	trk.SetToolActiveThreshold(0)
+	clock.Advance(24 * time.Hour)
+	trk.RefreshToolStatuses()
+	if s := trk.GetToolStatus("Aider").Status; s != "active" {
+		t.Errorf("Expected active with no threshold, got %s", s)
+	}
+}
+
+func TestToolStatusSortModes(t *testing.T) {
+	start := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	trk := NewTracker(func() time.Time { return start })
+	for _, s := range []ToolStatus{
+		{Name: "Aider", Tier: TierFullTracking, Status: "active", LastEventTime: start.Add(time.Minute), TotalCost: 1},
+		{Name: "Codex", Tier: TierFullTracking, Status: "not_found"},
+		{Name: "Copilot", Tier: TierDetectionOnly, Status: "configured"},
+		{Name: "Crush", Tier: TierFullTracking, Status: "active"},
+		{Name: "OpenCode", Tier: TierFullTracking, Status: "active", LastEventTime: start.Add(time.Hour), TotalCost: 0.5},
+		{Name: "Tabnine", Tier: TierDetectionOnly, Status: "not_found"},
+		{Name: "Zed", Tier: TierFullTracking, Status: "active", LastEventTime: start.Add(time.Minute), TotalCost: 3},
+	} {
+		trk.SetToolStatus(s)
+	}
+	names := func() string {
+		var names []string
+		for _, s := range trk.GetToolStatuses() {
+			names = append(names, s.Name)
+		}
+		return strings.Join(names, " ")
+	}
+
+	tests := []struct {
+		mode string
+		want string
+	}{
+		// 1. Alphabetical
+		{ToolSortName, "Aider Codex Copilot Crush OpenCode Tabnine Zed"},
+
+		// 2. Latest event first, ties broken by cost, then name
+		{ToolSortActivity, "OpenCode Zed Aider Codex Copilot Crush Tabnine"},
+
+		// 3. Tracked tools by activity, then detection-only, then not found
+		{ToolSortTier, "OpenCode Zed Aider Crush Copilot Codex Tabnine"},
+
+		// 4. Tier is the default
+		{"", "OpenCode Zed Aider Crush Copilot Codex Tabnine"},
+	}
+	for _, tt := range tests {
+		trk.SetToolSort(tt.mode)
+		if got := names(); got != tt.want {
+			t.Errorf("Expected %q sorted as %q, got %q", tt.mode, tt.want, got)
+		}
+	}
+}
+
+func TestToolStatusHistoryKeepsLatestTransitions(t *testing.T) {
+	clock := &fakeClock{t: time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)}
+	trk := NewTracker(clock.Now)
+	trk.SetToolActiveThreshold(10 * time.Minute)
+	history := func() []ToolTransition { return trk.GetToolStatus("Crush").History }
+
+	// 1. The first status is recorded from nothing; repeats aren't recorded
+	trk.SetToolStatus(ToolStatus{Name: "Crush", Status: "active", Message: "Watching database"})
+	trk.SetToolStatus(ToolStatus{Name: "Crush", Status: "active", Message: "Watching database"})
+	if h := history(); len(h) != 1 || h[0].From != "" || h[0].To != "active" || h[0].Message != "Watching database" {
+		t.Fatalf("Expected one transition to active, got %+v", h)
+	}
+
+	// 2. A brief failure and recovery shows as two transitions, in order
+	clock.Advance(time.Second)
+	trk.SetToolStatus(ToolStatus{Name: "Crush", Status: "not_found", Message: ".crush/crush.db not found"})
+	clock.Advance(time.Second)
+	trk.SetToolStatus(ToolStatus{Name: "Crush", Status: "active", Message: "Watching database"})
+	h := history()
+	if len(h) != 3 || h[1].From != "active" || h[1].To != "not_found" || h[2].From != "not_found" || h[2].To != "active" {
+		t.Fatalf("Expected active -> not_found -> active, got %+v", h)
+	}
+	if !h[1].Time.Equal(clock.t.Add(-time.Second)) {
+		t.Errorf("Expected the failure at %s, got %s", clock.t.Add(-time.Second), h[1].Time)
+	}
+
+	// 3. Going idle and back are transitions too
+	clock.Advance(11 * time.Minute)
+	trk.RefreshToolStatuses()
+	trk.IncrementToolEvents("Crush")
+	if h := history(); len(h) != 5 || h[3].To != "idle" || h[4].From != "idle" || h[4].To != "active" {
+		t.Errorf("Expected active -> idle -> active, got %+v", h[3:])
+	}
+
+	// 4. Only the latest ToolHistorySize are kept
+	for i := 0; i < ToolHistorySize; i++ {
+		clock.Advance(time.Second)
+		trk.SetToolStatus(ToolStatus{Name: "Crush", Status: fmt.Sprintf("status-%d", i)})
+	}
+	h = history()
+	if len(h) != ToolHistorySize {
+		t.Fatalf("Expected %d transitions, got %d", ToolHistorySize, len(h))
+	}
+	last := fmt.Sprintf("status-%d", ToolHistorySize-1)
+	if h[0].From != "active" || h[0].To != "status-0" || h[len(h)-1].To != last {
+		t.Errorf("Expected active -> status-0 through %s, got %+v ... %+v", last, h[0], h[len(h)-1])
+	}
+}
+
+func TestSessionCostSumsTinyEventsExactly(t *testing.T) {
+	trk := &Tracker{StartTime: time.Now()}
+
+	// 1. Ten thousand hundredth-of-a-cent events add up to exactly $1, where
+	// summing the floats directly drifts
+	var floatSum float64
+	for i := 0; i < 10_000; i++ {
+		trk.AddUsage("gpt-4o-mini", 10, 5, 0.0001)
+		floatSum += 0.0001
+	}
+	if floatSum == 1.0 {
+		t.Fatal("Expected the naive float sum to drift, so the test proves nothing")
+	}
+	if got := trk.GetSessionCost(); got != 1.0 {
+		t.Errorf("Expected a session cost of exactly $1, got %.17f", got)
+	}
+
+	// 2. Reset starts the sum over
+	trk.Reset()
+	trk.AddUsage("gpt-4o-mini", 10, 5, 0.0003)
+	if got := trk.GetSessionCost(); got != 0.0003 {
+		t.Errorf("Expected $0.0003 after reset, got %.17f", got)
+	}
+}
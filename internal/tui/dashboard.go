@@ -2,17 +2,26 @@
 package tui
 
 import (
+	"cmp"
+	"context"
+	"errors"
 	"fmt"
+	"math"
+	"net"
 	"strings"
 	"time"
 
 	"github.com/bangarangler/burnrate/internal/config"
+	"github.com/bangarangler/burnrate/internal/daemon"
+	"github.com/bangarangler/burnrate/internal/log"
 	"github.com/bangarangler/burnrate/internal/pricing"
+	"github.com/bangarangler/burnrate/internal/storage"
 	"github.com/bangarangler/burnrate/internal/tracker"
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/progress"
 	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -22,7 +31,24 @@ type KeyMap struct {
 	SessionView key.Binding
 	TodayView   key.Binding
 	WeekView    key.Binding
+	MonthView   key.Binding
+	AllView     key.Binding
 	WhatIf      key.Binding
+	Filter      key.Binding
+	ToolFilter  key.Binding
+	Group       key.Binding
+	FreeModels  key.Binding
+	BudgetBasis key.Binding
+	Tag         key.Binding
+	FocusTools  key.Binding
+	OpenURL     key.Binding
+	Events      key.Binding
+	Top         key.Binding
+	Sessions    key.Binding
+	Tail        key.Binding
+	Compact     key.Binding
+	Snapshot    key.Binding
+	Dismiss     key.Binding
 	Reset       key.Binding
 	Quit        key.Binding
 	Back        key.Binding
@@ -43,10 +69,78 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("w"),
 			key.WithHelp("w", "week"),
 		),
+		MonthView: key.NewBinding(
+			key.WithKeys("m"),
+			key.WithHelp("m", "month"),
+		),
+		AllView: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "all time"),
+		),
 		WhatIf: key.NewBinding(
 			key.WithKeys("W"),
 			key.WithHelp("W", "what-if"),
 		),
+		Filter: key.NewBinding(
+			key.WithKeys("/"),
+			key.WithHelp("/", "filter models"),
+		),
+		ToolFilter: key.NewBinding(
+			key.WithKeys("f"),
+			key.WithHelp("f", "filter tool"),
+		),
+		Group: key.NewBinding(
+			key.WithKeys("g"),
+			key.WithHelp("g", "group by"),
+		),
+		FreeModels: key.NewBinding(
+			key.WithKeys("F"),
+			key.WithHelp("F", "free models"),
+		),
+		BudgetBasis: key.NewBinding(
+			key.WithKeys("b"),
+			key.WithHelp("b", "budget basis"),
+		),
+		Tag: key.NewBinding(
+			key.WithKeys("T"),
+			key.WithHelp("T", "set tag"),
+		),
+		FocusTools: key.NewBinding(
+			key.WithKeys("tab"),
+			key.WithHelp("tab", "select tool"),
+		),
+		OpenURL: key.NewBinding(
+			key.WithKeys("enter", "o"),
+			key.WithHelp("enter/o", "open dashboard"),
+		),
+		Events: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "model events"),
+		),
+		Top: key.NewBinding(
+			key.WithKeys("x"),
+			key.WithHelp("x", "top requests"),
+		),
+		Sessions: key.NewBinding(
+			key.WithKeys("S"),
+			key.WithHelp("S", "sessions"),
+		),
+		Tail: key.NewBinding(
+			key.WithKeys("+", "-"),
+			key.WithHelp("+/-", "more/fewer events"),
+		),
+		Compact: key.NewBinding(
+			key.WithKeys("c"),
+			key.WithHelp("c", "compact"),
+		),
+		Snapshot: key.NewBinding(
+			key.WithKeys("p"),
+			key.WithHelp("p", "save snapshot"),
+		),
+		Dismiss: key.NewBinding(
+			key.WithKeys("d"),
+			key.WithHelp("d", "dismiss alert"),
+		),
 		Reset: key.NewBinding(
 			key.WithKeys("r"),
 			key.WithHelp("r", "reset"),
@@ -64,14 +158,16 @@ func DefaultKeyMap() KeyMap {
 
 // ShortHelp returns keybindings to be shown in the mini help view
 func (k KeyMap) ShortHelp() []key.Binding {
-	return []key.Binding{k.SessionView, k.TodayView, k.WeekView, k.WhatIf, k.Reset, k.Quit}
+	return []key.Binding{k.SessionView, k.TodayView, k.WeekView, k.MonthView, k.AllView, k.WhatIf, k.Compact, k.Reset, k.Quit}
 }
 
 // FullHelp returns keybindings for the expanded help view
 func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
-		{k.SessionView, k.TodayView, k.WeekView},
-		{k.WhatIf, k.Reset, k.Quit},
+		{k.SessionView, k.TodayView, k.WeekView, k.MonthView, k.AllView},
+		{k.Filter, k.ToolFilter, k.Group, k.FreeModels, k.BudgetBasis, k.FocusTools, k.OpenURL, k.Tag},
+		{k.Events, k.Top, k.Sessions, k.Tail, k.Back},
+		{k.WhatIf, k.Compact, k.Snapshot, k.Dismiss, k.Reset, k.Quit},
 	}
 }
 
@@ -107,6 +203,28 @@ var (
 				Foreground(mutedColor).
 				SetString("○")
 
+	statusDotOfflineStyle = lipgloss.NewStyle().
+				Foreground(infoColor).
+				SetString("●")
+
+	idleStyle = lipgloss.NewStyle().
+			Foreground(warningColor).
+			Italic(true)
+
+	largeContextStyle = lipgloss.NewStyle().
+				Foreground(warningColor).
+				Bold(true)
+
+	unpricedStyle = lipgloss.NewStyle().
+			Foreground(warningColor)
+
+	historyDisabledStyle = lipgloss.NewStyle().
+				Foreground(warningColor)
+
+	velocityAlertStyle = lipgloss.NewStyle().
+				Foreground(warningColor).
+				Bold(true)
+
 	boxStyle = lipgloss.NewStyle().
 			Border(lipgloss.RoundedBorder()).
 			BorderForeground(borderColor).
@@ -150,6 +268,12 @@ var (
 			Foreground(activeTabColor).
 			Padding(0, 1)
 
+	limitBannerStyle = lipgloss.NewStyle().
+				Bold(true).
+				Foreground(lipgloss.Color("231")).
+				Background(errorColor).
+				Align(lipgloss.Center)
+
 	modalStyle = lipgloss.NewStyle().
 			Border(lipgloss.RoundedBorder()).
 			BorderForeground(primaryColor).
@@ -158,24 +282,139 @@ var (
 )
 
 type model struct {
-	table       table.Model
-	progress    progress.Model
-	help        help.Model
-	keys        KeyMap
-	total       float64
-	burnRate    float64
-	startTime   time.Time
-	activeView  string // "session", "today", "week"
-	config      *config.Config
-	pricingTime time.Time
-	showWhatIf  bool
-	width       int
-	height      int
-}
-
-func InitialModel() model {
+	table         table.Model
+	progress      progress.Model
+	help          help.Model
+	keys          KeyMap
+	total         float64
+	burnRate      float64
+	cacheSavings  float64
+	activeView    string // "session", "today", "week", "month", "all"
+	config        *config.Config
+	pricingStatus pricing.Status
+	showWhatIf    bool
+	compact       bool // Single-line view for small panes
+	toolsFocused  bool // Arrow keys move the tool cursor instead of the table
+	toolCursor    int  // Selected row in the tools panel
+	width         int
+	height        int
+
+	// Table filters, cleared when the window changes
+	usages      []tracker.Usage // Current window's rows before the model filter
+	filterInput textinput.Model // Model substring filter, opened with "/"
+	toolFilter  string          // Only show this tool's usage ("" for all)
+
+	// Session tag editor, opened with "T"
+	tagInput textinput.Model
+
+	// Drill-down into one model's individual events, opened with enter
+	tableMode   tableMode
+	eventsModel string
+	eventsTable table.Model
+
+	// The most expensive individual requests, toggled with x
+	topTable table.Model
+
+	// Runs of activity split by the session gap, toggled with S, and how
+	// many there have been today
+	sessionsTable table.Model
+	sessionsToday int
+
+	// Hard limit banner. The banner shows once today's spend reaches a new
+	// multiple of DailyHardLimit that hasn't been dismissed yet.
+	todaySpend     float64
+	limitDismissed int // Highest limit multiple the user dismissed
+
+	// Session cap (see checkSessionCap)
+	capCost      float64
+	capTriggered bool // Fired for the current crossing
+	capDismissed bool // Banner dismissed with d
+
+	// Spend in the equivalent prior period (Today/Week/Month views)
+	previousTotal float64
+
+	// Today/Week/Month totals for the summary line, refreshed every
+	// windowTotalsInterval rather than queried on each tick
+	windowTotals   map[string]float64
+	windowTotalsAt time.Time
+
+	// Lifetime stats (All view). A failed refresh keeps the last good
+	// values, and the error is only logged when it changes
+	lifetimeEvents int
+	lifetimeFirst  time.Time
+	lifetimeErr    string
+
+	// Projected month-end spend (Month view)
+	forecast storage.MonthForecast
+
+	// Usual spend per active hour, to compare the burn rate with (see
+	// refreshTypicalBurn)
+	typicalBurn   float64
+	typicalBurnOK bool // Enough history to have a usual
+	typicalBurnAt time.Time
+
+	// The window's spend by token type
+	costSplit tracker.CostBreakdown
+
+	// Tokens in the window (historical views)
+	promptTotal     int
+	completionTotal int
+
+	// Usage table rows per "model", "family", or "project", cycled with g.
+	// Historical views grouped by project are queried per project, since
+	// their per-model rows don't keep it.
+	groupBy       string
+	projectUsages []tracker.Usage
+
+	// How free models' rows are shown: "show", "group", or "hide", cycled
+	// with F (see tracker.FilterFree)
+	freeModels string
+
+	// Most rows the events and top views list, changed with + and -
+	// (see tailEvents), and how many events the events view has in all
+	tail        int
+	eventsTotal int
+
+	// Each usage row's full name, as its Model cell may be truncated
+	rowNames []string
+
+	// Budget the bar is measured against: "day", "week", or "month", cycled
+	// with b ("" = the active tab's own)
+	budgetBasis string
+
+	// Latest snapshot saved with p, confirmed in the footer
+	snapshotPath string
+	snapshotErr  error
+	snapshotAt   time.Time
+
+	// Requests still streaming (session view, if ShowInFlight)
+	inFlight []tracker.Usage
+
+	// First-run help, shown once on the first tick if nothing is tracked
+	firstRunChecked bool
+	showFirstRun    bool
+
+	// Session shown in the dashboard: tracker.Global, except in tests
+	tracker *tracker.Tracker
+
+	// Visible rows of each table, for the scroll indicator
+	tableScroll    tableScroll
+	eventsScroll   tableScroll
+	topScroll      tableScroll
+	sessionsScroll tableScroll
+}
+
+// InitialModel returns the dashboard's starting state under cfg, which the
+// caller loads so flags can override it, showing tracker.Global
+func InitialModel(cfg *config.Config) model {
+	return InitialModelWith(cfg, tracker.Global)
+}
+
+// InitialModelWith returns the dashboard's starting state showing trk's
+// session, so tests can render controlled data
+func InitialModelWith(cfg *config.Config, trk *tracker.Tracker) model {
 	columns := []table.Column{
-		{Title: "Model", Width: 35},
+		{Title: "Model", Width: defaultModelWidth},
 		{Title: "Input", Width: 10},
 		{Title: "Output", Width: 10},
 		{Title: "Cost", Width: 10},
@@ -187,7 +426,103 @@ func InitialModel() model {
 		table.WithFocused(true),
 		table.WithHeight(8),
 	)
+	t.SetStyles(tableStyles())
+
+	prog := progress.New(progress.WithDefaultGradient())
+	prog.Width = 30
+
+	filter := textinput.New()
+	filter.Prompt = "/"
+	filter.Placeholder = "model"
+	filter.CharLimit = 64
+
+	tag := textinput.New()
+	tag.Prompt = "tag: "
+	tag.Placeholder = "label (empty to clear)"
+	tag.CharLimit = 64
+
+	m := model{
+		table:         t,
+		progress:      prog,
+		help:          help.New(),
+		keys:          DefaultKeyMap(),
+		activeView:    "session",
+		config:        cfg,
+		tracker:       trk,
+		filterInput:   filter,
+		tagInput:      tag,
+		eventsTable:   newEventsTable(),
+		topTable:      newTopTable(),
+		sessionsTable: newSessionsTable(),
+		budgetBasis:   cfg.BudgetBasis,
+		freeModels:    cmp.Or(cfg.FreeModels, tracker.FreeModelsShow),
+		tail:          cmp.Or(cfg.EventTail, defaultTail),
+	}
+	m.setGroupBy("model")
+	if cfg.GroupByFamily {
+		m.setGroupBy("family")
+	}
+	return m
+}
+
+// groupings are the usage table's row kinds, in the order g cycles them
+var groupings = []string{"model", "family", "project"}
+
+// setGroupBy switches the usage table between a row per model, per model
+// family (see pricing.FamilyOf), or per project
+func (m *model) setGroupBy(groupBy string) {
+	m.groupBy = groupBy
+	columns := m.table.Columns()
+	columns[0].Title = strings.ToUpper(groupBy[:1]) + groupBy[1:]
+	m.table.SetColumns(columns)
+}
+
+// nextGrouping returns the grouping after current, wrapping around
+func nextGrouping(current string) string {
+	for i, g := range groupings {
+		if g == current && i+1 < len(groupings) {
+			return groupings[i+1]
+		}
+	}
+	return groupings[0]
+}
+
+// freeModelsModes are the ways free models' rows are shown, in the order F
+// cycles them
+var freeModelsModes = []string{tracker.FreeModelsShow, tracker.FreeModelsGroup, tracker.FreeModelsHide}
+
+// nextFreeModels returns the free models mode after current
+func nextFreeModels(current string) string {
+	for i, mode := range freeModelsModes {
+		if mode == current && i+1 < len(freeModelsModes) {
+			return freeModelsModes[i+1]
+		}
+	}
+	return freeModelsModes[0]
+}
+
+// historyAvailable reports whether there's a history database to query.
+// Without one (it failed to open, or a simulation isn't persisted) only the
+// session has usage to show.
+func historyAvailable() bool {
+	return storage.DB != nil
+}
+
+// loadProjectUsages queries a historical view's usage per project, when
+// the table is grouped by project
+func (m *model) loadProjectUsages() {
+	m.projectUsages = nil
+	if m.groupBy != "project" || m.activeView == "session" || !historyAvailable() {
+		return
+	}
+	usages, _, err := m.tracker.GetUsageByProject(m.activeView, storage.UsageFilter{Tool: m.toolFilter})
+	if err == nil {
+		m.projectUsages = usages
+	}
+}
 
+// tableStyles returns the styles shared by the usage and events tables
+func tableStyles() table.Styles {
 	s := table.DefaultStyles()
 	s.Header = s.Header.
 		BorderStyle(lipgloss.NormalBorder()).
@@ -199,20 +534,13 @@ func InitialModel() model {
 		Foreground(lipgloss.Color("229")).
 		Background(lipgloss.Color("57")).
 		Bold(false)
-	t.SetStyles(s)
-
-	prog := progress.New(progress.WithDefaultGradient())
-	prog.Width = 30
+	return s
+}
 
-	return model{
-		table:      t,
-		progress:   prog,
-		help:       help.New(),
-		keys:       DefaultKeyMap(),
-		startTime:  time.Now(),
-		activeView: "session",
-		config:     config.Load(),
-	}
+// WithCompact starts the dashboard in compact mode
+func (m model) WithCompact(compact bool) model {
+	m.compact = compact
+	return m
 }
 
 func (m model) Init() tea.Cmd {
@@ -230,37 +558,103 @@ type tickMsg time.Time
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tickMsg:
-		m.pricingTime = pricing.GetLastFetchTime()
+		m.pricingStatus = pricing.GetStatus()
+		m.tracker.RefreshToolStatuses()
+		if !m.firstRunChecked {
+			m.firstRunChecked = true
+			m.showFirstRun = m.nothingTracked()
+		}
 
 		var usages []tracker.Usage
 		var err error
 
-		switch m.activeView {
-		case "session":
-			m.total = tracker.Global.GetSessionCost()
-			usages = tracker.Global.GetUsages()
+		switch view := m.activeView; {
+		case view == "session":
+			m.total = m.tracker.GetSessionCost()
+			usages = m.tracker.GetUsages()
 			// Burn rate only relevant for session view
-			m.burnRate = tracker.Global.GetBurnRatePerHour()
+			m.burnRate = m.tracker.GetBurnRatePerHour()
+			m.refreshTypicalBurn(time.Now())
+			if m.config.ShowInFlight {
+				m.inFlight = m.tracker.GetProvisional()
+			}
+
+		case !historyAvailable():
+			// The other views only have history to show
+			m.total = 0
+			m.burnRate = m.tracker.GetBurnRatePerHour()
+
+		case view == "today" || view == "week" || view == "month":
+			usages, m.total, err = m.tracker.GetHistoricalUsage(m.activeView)
+			if err != nil {
+				// Fallback or error handling
+			}
+			// Only shown in compact mode; the full historical views have no burn rate
+			m.burnRate = m.tracker.GetBurnRatePerHour()
+			_, m.previousTotal, err = storage.GetPeriodComparison(m.activeView)
+			if err != nil {
+				m.previousTotal = 0
+			}
+			if m.activeView == "month" {
+				m.forecast, _ = storage.GetMonthForecast()
+			}
 
-		case "today", "week":
-			usages, m.total, err = tracker.Global.GetHistoricalUsage(m.activeView)
+		case view == "all":
+			usages, _, err = m.tracker.GetHistoricalUsage(m.activeView)
 			if err != nil {
 				// Fallback or error handling
 			}
-			m.burnRate = 0 // Not applicable for historical views
+			total, events, first, err := m.tracker.GetLifetimeStats()
+			if err != nil {
+				if err.Error() != m.lifetimeErr {
+					log.Warnf("tui: failed to load lifetime stats: %v", err)
+					m.lifetimeErr = err.Error()
+				}
+			} else {
+				m.total, m.lifetimeEvents, m.lifetimeFirst = total, events, first
+				m.lifetimeErr = ""
+			}
+			m.burnRate = m.tracker.GetBurnRatePerHour()
 		}
 
-		rows := []table.Row{}
+		if m.activeView != "session" && historyAvailable() {
+			m.promptTotal, m.completionTotal, _ = m.tracker.GetTokenTotals(m.activeView)
+		}
+
+		m.updateTodaySpend()
+		m.refreshWindowTotals(time.Now())
+
+		m.cacheSavings = 0
 		for _, u := range usages {
-			rows = append(rows, table.Row{
-				u.Model,
-				formatTokens(u.PromptTokens),
-				formatTokens(u.CompletionTokens),
-				fmt.Sprintf("$%.4f", u.Cost),
-			})
+			m.cacheSavings += u.CacheSavings
+		}
+		m.costSplit = tracker.BreakdownCost(usages)
+
+		// Stats cover the whole window; the tool filter only narrows the table
+		if m.toolFilter != "" {
+			usages = m.toolUsages(usages)
+		}
+		m.usages = usages
+		m.loadProjectUsages()
+
+		// Rebuilding the rows must not move the selection or scroll
+		// position, or lower rows can't be read on a busy session
+		cursor, top := m.table.Cursor(), m.tableScroll.top
+		m.refreshRows()
+		m.tableScroll.restore(&m.table, cursor, top)
+
+		switch m.tableMode {
+		case eventsMode:
+			m.loadEvents()
+		case topMode:
+			m.loadTop()
+		case sessionsMode:
+			m.loadSessions()
 		}
-		m.table.SetRows(rows)
 
+		if capCmd := m.checkSessionCap(); capCmd != nil {
+			return m, tea.Batch(tickCmd(), capCmd)
+		}
 		return m, tickCmd()
 
 	case tea.WindowSizeMsg:
@@ -269,47 +663,209 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.height = msg.Height
 
 	case tea.KeyMsg:
+		// Any key dismisses the first-run help
+		if m.showFirstRun {
+			m.showFirstRun = false
+			if msg.String() == "ctrl+c" {
+				return m, tea.Quit
+			}
+			return m, nil
+		}
+
+		// While editing the tag, keys go to the input rather than the shortcuts
+		if m.tagInput.Focused() {
+			switch msg.String() {
+			case "ctrl+c":
+				return m, tea.Quit
+			case "enter":
+				m.tracker.SetTag(m.tagInput.Value())
+				m.tagInput.Blur()
+			case "esc":
+				m.tagInput.Blur()
+			default:
+				var cmd tea.Cmd
+				m.tagInput, cmd = m.tagInput.Update(msg)
+				return m, cmd
+			}
+			return m, nil
+		}
+
+		// While typing a filter, keys go to the input rather than the shortcuts
+		if m.filterInput.Focused() {
+			switch msg.String() {
+			case "ctrl+c":
+				return m, tea.Quit
+			case "enter":
+				m.filterInput.Blur()
+			case "esc":
+				m.filterInput.SetValue("")
+				m.filterInput.Blur()
+			default:
+				var cmd tea.Cmd
+				m.filterInput, cmd = m.filterInput.Update(msg)
+				m.refreshRows()
+				return m, cmd
+			}
+			m.refreshRows()
+			return m, nil
+		}
+
 		switch msg.String() {
 		case "q", "ctrl+c":
 			return m, tea.Quit
 		case "r":
-			tracker.Global.Reset()
-			m.startTime = time.Now()
+			// An attached dashboard shows the daemon's session, so the
+			// daemon starts the new one and this dashboard follows
+			if storage.IsReadOnly() {
+				if err := daemon.RequestReset(); err != nil {
+					log.Warnf("failed to request a reset from the daemon: %v", err)
+				}
+				return m, nil
+			}
+			m.tracker.Reset()
 			return m, nil
 		case "s":
-			m.activeView = "session"
+			m.setView("session")
 		case "t":
-			m.activeView = "today"
+			m.setView("today")
 		case "w":
-			m.activeView = "week"
+			m.setView("week")
+		case "m":
+			m.setView("month")
+		case "a":
+			m.setView("all")
+		case "/":
+			m.filterInput.Focus()
+			return m, textinput.Blink
+		case "f":
+			m.toolFilter = m.nextToolFilter()
+			return m, nil
+		case "T":
+			// An attached daemon owns writes, so its tag can't be changed here
+			if storage.IsReadOnly() {
+				return m, nil
+			}
+			m.tagInput.SetValue(m.tracker.Tag())
+			m.tagInput.CursorEnd()
+			m.tagInput.Focus()
+			return m, textinput.Blink
 		case "W":
 			m.showWhatIf = true
+		case "c":
+			m.compact = !m.compact
+		case "g":
+			m.setGroupBy(nextGrouping(m.groupBy))
+			m.loadProjectUsages()
+			m.refreshRows()
+			return m, nil
+		case "F":
+			m.freeModels = nextFreeModels(m.freeModels)
+			m.refreshRows()
+			return m, nil
+		case "b":
+			m.budgetBasis = nextBudgetBasis(m.budgetBasis)
+		case "p":
+			m.snapshotAt = time.Now()
+			m.snapshotPath, m.snapshotErr = saveSnapshot(SnapshotDir(), m.View(), m.snapshotAt)
+			return m, nil
+		case "d":
+			// d dismisses the limit banner, then the cap banner, as they say
+			if level := m.limitLevel(); level > m.limitDismissed {
+				m.limitDismissed = level
+				return m, nil
+			}
+			if m.renderCapBanner() != "" {
+				m.capDismissed = true
+			}
+			return m, nil
+		case "x":
+			m.toggleTop()
+			return m, nil
+		case "S":
+			m.toggleSessions()
+			return m, nil
+		case "+":
+			m.resizeTail(tailStep)
+			return m, nil
+		case "-":
+			m.resizeTail(-tailStep)
+			return m, nil
+		case "tab":
+			m.toolsFocused = !m.toolsFocused
+			if m.toolsFocused {
+				m.table.Blur()
+			} else {
+				m.table.Focus()
+			}
+			return m, nil
+		case "up", "k":
+			if m.toolsFocused {
+				if m.toolCursor > 0 {
+					m.toolCursor--
+				}
+				return m, nil
+			}
+		case "down", "j":
+			if m.toolsFocused {
+				if m.toolCursor < len(m.tracker.GetToolStatuses())-1 {
+					m.toolCursor++
+				}
+				return m, nil
+			}
+		case "enter", "o":
+			if m.toolsFocused {
+				return m, m.openSelectedTool()
+			}
+			// A family or project row has no events of its own to open
+			if msg.String() == "enter" && m.tableMode == aggregateMode && m.groupBy == "model" {
+				if i := m.table.Cursor(); i >= 0 && i < len(m.rowNames) {
+					m.openEvents(m.rowNames[i])
+				}
+				return m, nil
+			}
 		case "esc":
 			if m.showWhatIf {
 				m.showWhatIf = false
 				return m, nil
 			}
+			if m.tableMode != aggregateMode {
+				m.tableMode = aggregateMode
+				return m, nil
+			}
 		case "?":
 			m.help.ShowAll = !m.help.ShowAll
 		}
 	}
 
 	var cmd tea.Cmd
-	m.table, cmd = m.table.Update(msg)
+	switch m.tableMode {
+	case eventsMode:
+		m.eventsTable, cmd = m.eventsTable.Update(msg)
+		m.eventsScroll.follow(m.eventsTable)
+	case topMode:
+		m.topTable, cmd = m.topTable.Update(msg)
+		m.topScroll.follow(m.topTable)
+	case sessionsMode:
+		m.sessionsTable, cmd = m.sessionsTable.Update(msg)
+		m.sessionsScroll.follow(m.sessionsTable)
+	default:
+		m.table, cmd = m.table.Update(msg)
+		m.tableScroll.follow(m.table)
+	}
 	return m, cmd
 }
 
 func (m model) View() string {
-	// Header with Pricing Status
-	pricingStatus := statusDotStaleStyle.Render()
-	if time.Since(m.pricingTime) < 1*time.Hour && !m.pricingTime.IsZero() {
-		pricingStatus = statusDotStyle.Render()
+	if m.compact {
+		return m.renderCompact()
 	}
 
+	// Header with Pricing Status
 	header := lipgloss.JoinHorizontal(lipgloss.Center,
 		titleStyle.Render("burnrate"),
 		subtitleStyle.Render(" Real-time AI Spend Monitor  "),
-		pricingStatus,
+		m.renderPricingStatus(),
+		renderHistoryStatus(),
 	)
 
 	// Tabs
@@ -317,68 +873,144 @@ func (m model) View() string {
 		m.renderTab("Session", "session"),
 		m.renderTab("Today", "today"),
 		m.renderTab("Week", "week"),
+		m.renderTab("Month", "month"),
+		m.renderTab("All", "all"),
 	)
 
 	// Session stats row (Context sensitive)
 	var stats string
 	if m.activeView == "session" {
-		duration := time.Since(m.startTime)
-		durationStr := formatDuration(duration)
+		active, wall := m.tracker.SessionDurations()
+		durationStr := formatDuration(active)
+		if wall-active >= time.Minute {
+			// Show wall time too once idle gaps have been excluded
+			durationStr += statLabelStyle.Render(" of " + formatDuration(wall))
+		}
+		if m.tracker.IsIdle() {
+			durationStr += " " + idleStyle.Render("idle")
+		}
 
-		stats = statsBoxStyle.Render(
-			lipgloss.JoinHorizontal(lipgloss.Center,
-				statLabelStyle.Render("Total ")+statValueStyle.Render(fmt.Sprintf("$%.4f", m.total)),
-				"    ",
-				statLabelStyle.Render("Burn ")+statValueStyle.Render(fmt.Sprintf("$%.2f/hr", m.burnRate)),
-				"    ",
-				statLabelStyle.Render("Duration ")+statValueStyle.Render(durationStr),
-			),
+		unpriced := m.tracker.UnpricedEvents()
+		total := config.FormatMoney(m.total)
+		if unpriced > 0 && pricing.FallbackModel == "" {
+			total += " + ??"
+		}
+
+		sessionStats := lipgloss.JoinHorizontal(lipgloss.Center,
+			statLabelStyle.Render("Total ")+statValueStyle.Render(total),
+			"    ",
+			statLabelStyle.Render("Burn ")+statValueStyle.Render(config.FormatMoneyCents(m.burnRate)+"/hr")+m.renderVelocity(),
+			"    ",
+			statLabelStyle.Render("Duration ")+statValueStyle.Render(durationStr),
+			"    ",
+			statLabelStyle.Render("Cache saved ")+statValueStyle.Render(config.FormatMoney(m.cacheSavings)),
 		)
-	} else {
-		// Budget Bar for Today/Week
-		pct := m.total / m.config.DailyBudget
-		if m.activeView == "week" {
-			pct = m.total / (m.config.DailyBudget * 7)
+		if split := m.renderCostSplit(); split != "" {
+			sessionStats = lipgloss.JoinVertical(lipgloss.Left, sessionStats, split)
+		}
+		if largest, large := m.tracker.LargestContext(); large {
+			warning := fmt.Sprintf("Large context: %s tokens (%s) · %s",
+				formatTokens(largest.PromptTokens), config.FormatMoney(largest.Cost), largest.Model)
+			sessionStats = lipgloss.JoinVertical(lipgloss.Left, sessionStats, largeContextStyle.Render(warning))
+		}
+		if unpriced > 0 {
+			note := fmt.Sprintf("%d events from models without a price, estimated at %s rates", unpriced, pricing.FallbackModel)
+			if pricing.FallbackModel == "" {
+				note = fmt.Sprintf("%d events from models without a price are not in the total", unpriced)
+			}
+			sessionStats = lipgloss.JoinVertical(lipgloss.Left, sessionStats, unpricedStyle.Render(note))
 		}
+		if delta, at := m.tracker.PriceAdjustment(); !at.IsZero() && time.Since(at) < priceNoteDuration {
+			sign := "+"
+			if delta < 0 {
+				sign = ""
+			}
+			note := "Pricing updated: session total adjusted by " + sign + config.FormatMoneyCents(delta)
+			sessionStats = lipgloss.JoinVertical(lipgloss.Left, sessionStats, statLabelStyle.Render(note))
+		}
+		stats = statsBoxStyle.Render(sessionStats)
+	} else if m.activeView == "all" {
+		lines := []string{
+			m.renderLifetimeStats(),
+			m.renderTokenTotals(),
+			statLabelStyle.Render("Cache saved ") + statValueStyle.Render(config.FormatMoney(m.cacheSavings)),
+		}
+		if split := m.renderCostSplit(); split != "" {
+			lines = append(lines, split)
+		}
+		stats = statsBoxStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+	} else {
+		// Budget Bar for Today/Week/Month
+		budget := m.windowBudget()
+		pct := m.total / budget
 		if pct > 1.0 {
 			pct = 1.0
 		}
 
 		prog := m.progress.ViewAs(pct)
-		limit := fmt.Sprintf("/$%.2f", m.config.DailyBudget)
-		if m.activeView == "week" {
-			limit = fmt.Sprintf("/$%.2f", m.config.DailyBudget*7)
-		}
-
-		stats = statsBoxStyle.Render(
-			lipgloss.JoinVertical(lipgloss.Center,
-				lipgloss.JoinHorizontal(lipgloss.Center,
-					statLabelStyle.Render("Spend ")+statValueStyle.Render(fmt.Sprintf("$%.4f", m.total)),
-					statLabelStyle.Render(limit),
-				),
-				prog,
+		limit := "/" + config.FormatMoneyCents(budget)
+		if label := m.budgetBasisLabel(); label != "" {
+			prog = lipgloss.JoinVertical(lipgloss.Center, prog,
+				statLabelStyle.Render(viewLabels[m.activeView]+" vs "+label))
+		}
+
+		lines := []string{
+			lipgloss.JoinHorizontal(lipgloss.Center,
+				statLabelStyle.Render("Spend ")+statValueStyle.Render(config.FormatMoney(m.total)),
+				statLabelStyle.Render(limit),
+				"  ",
+				m.renderComparison(),
 			),
-		)
+			prog,
+			m.renderTokenTotals(),
+			statLabelStyle.Render("Cache saved ") + statValueStyle.Render(config.FormatMoney(m.cacheSavings)),
+		}
+		if split := m.renderCostSplit(); split != "" {
+			lines = append(lines, split)
+		}
+		if m.activeView == "today" {
+			lines = append(lines, m.renderSessionsToday())
+		}
+		if m.activeView == "month" {
+			lines = append(lines, m.renderForecast(m.budgetFor("month")))
+		}
+		stats = statsBoxStyle.Render(lipgloss.JoinVertical(lipgloss.Center, lines...))
 	}
 
-	// Historical Spend Chart (Today/Week only)
+	// Historical Spend Chart (historical views only)
 	var chart string
 	if m.activeView != "session" {
-		chart = m.renderHistoryChart()
+		// The chart takes whatever width the stats box leaves
+		chart = m.renderHistoryChart(m.width - lipgloss.Width(stats) - 2)
 	}
 
 	// Tools panel (Always visible)
 	toolsPanel := m.renderToolsPanel()
 
-	// Usage table
-	usageTable := tableBoxStyle.Render(m.table.View())
+	// Usage table, with the active filters above it
+	usageTable := renderTableBox(m.table, m.tableScroll)
+	if m.tableMode == eventsMode {
+		usageTable = m.renderEvents()
+	} else if m.tableMode == topMode {
+		usageTable = m.renderTop()
+	} else if m.tableMode == sessionsMode {
+		usageTable = m.renderSessions()
+	} else if filterStatus := m.renderFilterStatus(); filterStatus != "" {
+		usageTable = lipgloss.JoinVertical(lipgloss.Left, filterStatus, usageTable)
+	}
 
 	// Footer
 	footer := footerStyle.Render(m.help.View(m.keys))
+	if note := m.renderSnapshotNote(); note != "" {
+		footer = footerStyle.Render(note)
+	}
 
 	// Layout depends on view
 	var mainContent string
 	if m.activeView == "session" {
+		if inFlight := m.renderInFlight(); inFlight != "" {
+			usageTable = lipgloss.JoinVertical(lipgloss.Left, usageTable, inFlight)
+		}
 		mainContent = lipgloss.JoinVertical(lipgloss.Left,
 			stats,
 			"",
@@ -400,22 +1032,509 @@ func (m model) View() string {
 		)
 	}
 
+	if m.showFirstRun {
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.renderFirstRun())
+	}
+
 	if m.showWhatIf {
 		modal := m.renderWhatIfModal()
 		// Use manual placement or lipgloss.Place
 		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, modal)
 	}
 
+	banner := m.renderLimitBanner()
+	if capBanner := m.renderCapBanner(); capBanner != "" {
+		if banner != "" {
+			banner += "\n"
+		}
+		banner += capBanner
+	}
+
 	return lipgloss.JoinVertical(lipgloss.Left,
-		"",
+		banner,
 		header,
-		tabs,
+		lipgloss.JoinHorizontal(lipgloss.Bottom, tabs, m.renderTag()),
 		"",
 		mainContent,
+		m.renderWindowTotals(),
 		footer,
 	)
 }
 
+// renderTag shows the session tag, or the tag editor while it is open
+func (m model) renderTag() string {
+	if m.tagInput.Focused() {
+		return "  " + m.tagInput.View()
+	}
+	if tag := m.tracker.Tag(); tag != "" {
+		return "  " + statLabelStyle.Render("tag ") + statValueStyle.Render(tag)
+	}
+	return ""
+}
+
+// renderComparison shows the change from the prior period, e.g.
+// "▲ 18% vs last week", or "—" when nothing was spent then
+func (m model) renderComparison() string {
+	label := map[string]string{"today": "yesterday", "week": "last week", "month": "last month"}[m.activeView]
+	if m.previousTotal <= 0 {
+		return statLabelStyle.Render("— vs " + label)
+	}
+
+	change := (m.total - m.previousTotal) / m.previousTotal * 100
+	arrow, style := "▲", lipgloss.NewStyle().Foreground(errorColor)
+	if change < 0 {
+		arrow, style = "▼", lipgloss.NewStyle().Foreground(successColor)
+	}
+	return style.Render(fmt.Sprintf("%s %.0f%%", arrow, math.Abs(change))) + statLabelStyle.Render(" vs "+label)
+}
+
+// renderTokenTotals renders "Tokens 1.2M in / 340K out" for the window
+func (m model) renderTokenTotals() string {
+	return statLabelStyle.Render("Tokens ") + statValueStyle.Render(formatTokens(m.promptTotal)) +
+		statLabelStyle.Render(" in / ") + statValueStyle.Render(formatTokens(m.completionTotal)) +
+		statLabelStyle.Render(" out")
+}
+
+// renderCostSplit renders where the window's spend went by token type, e.g.
+// "Input $1.20 · Output $3.40 · Reasoning $0.80". Cache, reasoning, and other
+// cost only appear when there is some; it's empty before any spend.
+func (m model) renderCostSplit() string {
+	s := m.costSplit
+	if s.Total()+s.Other == 0 {
+		return ""
+	}
+
+	parts := []string{
+		statLabelStyle.Render("Input ") + statValueStyle.Render(config.FormatMoney(s.Input)),
+	}
+	if s.CacheRead > 0 {
+		parts = append(parts, statLabelStyle.Render("Cache ")+statValueStyle.Render(config.FormatMoney(s.CacheRead)))
+	}
+	parts = append(parts, statLabelStyle.Render("Output ")+statValueStyle.Render(config.FormatMoney(s.Output)))
+	if s.Reasoning > 0 {
+		parts = append(parts, statLabelStyle.Render("Reasoning ")+statValueStyle.Render(config.FormatMoney(s.Reasoning)))
+	}
+	if s.Other > 0 {
+		parts = append(parts, statLabelStyle.Render("Other ")+statValueStyle.Render(config.FormatMoney(s.Other)))
+	}
+	return strings.Join(parts, statLabelStyle.Render(" · "))
+}
+
+// renderForecast shows the projected month-end spend against the month's
+// budget, or a note while there isn't a full day of history to go on
+func (m model) renderForecast(budget float64) string {
+	if m.forecast.Days == 0 {
+		return statLabelStyle.Render("Forecast month-end: needs a day of history")
+	}
+
+	style := statValueStyle
+	if m.forecast.MonthEnd > budget {
+		style = lipgloss.NewStyle().Foreground(errorColor).Bold(true)
+	}
+	return statLabelStyle.Render("Forecast month-end: ") + style.Render(config.FormatMoneyCents(m.forecast.MonthEnd)) +
+		statLabelStyle.Render(" (budget "+config.FormatMoneyCents(budget)+")")
+}
+
+// priceNoteDuration is how long the note about a re-priced session total
+// stays up
+const priceNoteDuration = 5 * time.Minute
+
+// windowTotalsInterval is how often the summary line re-queries history
+const windowTotalsInterval = 10 * time.Second
+
+// summaryWindows are the windows shown in the summary line, in order
+var summaryWindows = []string{"session", "today", "week", "month"}
+
+// refreshWindowTotals re-queries the Today/Week/Month totals once they are
+// older than windowTotalsInterval
+func (m *model) refreshWindowTotals(now time.Time) {
+	if m.windowTotals != nil && now.Sub(m.windowTotalsAt) < windowTotalsInterval {
+		return
+	}
+
+	totals := make(map[string]float64)
+	if historyAvailable() {
+		for _, window := range summaryWindows[1:] {
+			if _, total, err := m.tracker.GetHistoricalUsage(window); err == nil {
+				totals[window] = total
+			}
+		}
+	}
+	m.windowTotals = totals
+	m.windowTotalsAt = now
+	m.refreshSessionsToday()
+}
+
+// renderWindowTotals shows every window's spend side by side, each colored by
+// its share of the budget, e.g. "Session $0.12 · Today $1.23 · Week $4.56 · ..."
+func (m model) renderWindowTotals() string {
+	labels := map[string]string{"session": "Session", "today": "Today", "week": "Week", "month": "Month"}
+
+	var parts []string
+	for _, window := range summaryWindows {
+		total := m.windowTotals[window]
+		switch {
+		case window == m.activeView:
+			total = m.total // Fresher than the cache
+		case window == "session":
+			total = m.tracker.GetSessionCost()
+		}
+		style := lipgloss.NewStyle().Foreground(barColor(total, m.budgetFor(window)))
+		parts = append(parts, statLabelStyle.Render(labels[window]+" ")+style.Render(config.FormatMoneyCents(total)))
+	}
+	return " " + strings.Join(parts, statLabelStyle.Render(" · "))
+}
+
+// setView switches the active window and clears the table filters
+func (m *model) setView(view string) {
+	if m.activeView == view {
+		return
+	}
+	m.activeView = view
+	m.toolFilter = ""
+	m.filterInput.SetValue("")
+
+	// Model events belong to the old window; the top requests and sessions
+	// follow the new one
+	switch m.tableMode {
+	case topMode:
+		m.loadTop()
+	case sessionsMode:
+		m.loadSessions()
+	default:
+		m.tableMode = aggregateMode
+	}
+}
+
+// toolUsages narrows the window's usages to toolFilter. Session usages carry
+// their tool; historical views are re-queried per tool.
+func (m model) toolUsages(usages []tracker.Usage) []tracker.Usage {
+	if m.activeView == "session" {
+		var filtered []tracker.Usage
+		for _, u := range usages {
+			if u.Tool == m.toolFilter {
+				filtered = append(filtered, u)
+			}
+		}
+		return filtered
+	}
+
+	filtered, _, err := m.tracker.GetFilteredHistoricalUsage(m.activeView, storage.UsageFilter{Tool: m.toolFilter})
+	if err != nil {
+		return nil
+	}
+	return filtered
+}
+
+// nextToolFilter cycles all tools -> each known tool -> all tools
+func (m model) nextToolFilter() string {
+	statuses := m.tracker.GetToolStatuses()
+	if m.toolFilter == "" {
+		if len(statuses) == 0 {
+			return ""
+		}
+		return statuses[0].Name
+	}
+	for i, s := range statuses {
+		if s.Name == m.toolFilter && i+1 < len(statuses) {
+			return statuses[i+1].Name
+		}
+	}
+	return ""
+}
+
+// refreshRows rebuilds the table from usages, applying the model filter and
+// then the grouping. Grouped by project, the filter matches project names.
+func (m *model) refreshRows() {
+	query := strings.ToLower(strings.TrimSpace(m.filterInput.Value()))
+	matches := func(u tracker.Usage) bool {
+		return query == "" || strings.Contains(strings.ToLower(u.Model), query)
+	}
+
+	usages := []tracker.Usage{}
+	switch m.groupBy {
+	case "project":
+		grouped := m.projectUsages
+		if m.activeView == "session" {
+			grouped = tracker.GroupByProject(m.usages)
+		}
+		for _, u := range grouped {
+			if u.Model == "" {
+				u.Model = "(unknown)"
+			}
+			if matches(u) {
+				usages = append(usages, u)
+			}
+		}
+	default:
+		for _, u := range m.usages {
+			if matches(u) {
+				usages = append(usages, u)
+			}
+		}
+		usages = tracker.FilterFree(usages, m.freeModels)
+		if m.groupBy == "family" {
+			usages = tracker.GroupByFamily(usages)
+		}
+	}
+
+	longest := 0
+	for _, u := range usages {
+		longest = max(longest, lipgloss.Width(u.Model))
+	}
+	columns := m.table.Columns()
+	columns[0].Width = modelColumnWidth(longest, m.width)
+	m.table.SetColumns(columns)
+
+	rows := []table.Row{}
+	m.rowNames = m.rowNames[:0]
+	for _, u := range usages {
+		m.rowNames = append(m.rowNames, u.Model)
+		cost := formatCost(u.Model, u.Cost)
+		if m.groupBy == "project" {
+			// A project name isn't a model, so it's never unpriced
+			cost = config.FormatMoney(u.Cost)
+		}
+		rows = append(rows, table.Row{
+			truncateCell(u.Model, columns[0].Width),
+			formatTokens(u.PromptTokens),
+			formatTokens(u.CompletionTokens),
+			cost,
+		})
+	}
+	m.table.SetRows(rows)
+	m.tableScroll.follow(m.table)
+}
+
+// Usage table Model column widths. It widens past the default to fit long
+// names while the terminal has room, but never narrows below minModelWidth.
+const (
+	defaultModelWidth = 35
+	minModelWidth     = 20
+	maxModelWidth     = 60
+
+	// The table's other columns, the cells' padding, and its box's border
+	usageTableChrome = 3*10 + 4*2 + 2
+)
+
+// modelColumnWidth sizes the Model column for names up to longest cells wide
+// in a terminal termWidth wide (0 when not yet known)
+func modelColumnWidth(longest, termWidth int) int {
+	width := min(max(defaultModelWidth, longest), maxModelWidth)
+	if termWidth > 0 {
+		width = min(width, termWidth-usageTableChrome)
+	}
+	return max(width, minModelWidth)
+}
+
+// renderInFlight lists the requests still streaming, one line each, e.g.
+// " ⋯ gpt-4o (openai) · OpenCode · 12s · ~$0.0012 so far". They're estimates,
+// so they're kept out of the table and its totals.
+func (m model) renderInFlight() string {
+	if !m.config.ShowInFlight || len(m.inFlight) == 0 {
+		return ""
+	}
+
+	var lines []string
+	for _, u := range m.inFlight {
+		estimate := "waiting for tokens"
+		if u.PromptTokens+u.CompletionTokens > 0 {
+			estimate = "~" + formatEventCost(u.Model, u.Cost) + " so far"
+		}
+		elapsed := time.Since(u.Timestamp).Round(time.Second)
+		lines = append(lines, idleStyle.Render(fmt.Sprintf(" ⋯ %s · %s · %s · %s", u.Model, u.Tool, elapsed, estimate)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderFilterStatus describes active filters above the table, or "" if none
+func (m model) renderFilterStatus() string {
+	freeFiltered := m.freeModels != tracker.FreeModelsShow && m.groupBy != "project"
+	if !m.filterInput.Focused() && m.filterInput.Value() == "" && m.toolFilter == "" && !freeFiltered {
+		return ""
+	}
+
+	var parts []string
+	if m.filterInput.Focused() {
+		parts = append(parts, m.filterInput.View())
+	} else if m.filterInput.Value() != "" {
+		parts = append(parts, statLabelStyle.Render("model ")+statValueStyle.Render(m.filterInput.Value()))
+	}
+	if m.toolFilter != "" {
+		parts = append(parts, statLabelStyle.Render("tool ")+statValueStyle.Render(m.toolFilter))
+	}
+	if freeFiltered {
+		label := map[string]string{tracker.FreeModelsGroup: "grouped", tracker.FreeModelsHide: "hidden"}[m.freeModels]
+		parts = append(parts, statLabelStyle.Render("free models ")+statValueStyle.Render(label))
+	}
+	parts = append(parts, statLabelStyle.Render(fmt.Sprintf("(%d rows)", len(m.table.Rows()))))
+
+	return " " + strings.Join(parts, "  ")
+}
+
+// updateTodaySpend refreshes today's spend for the hard limit check. It falls
+// back to the session cost when history is unavailable.
+func (m *model) updateTodaySpend() {
+	if m.config.DailyHardLimit <= 0 {
+		return
+	}
+
+	if m.activeView == "today" {
+		m.todaySpend = m.total
+	} else if !historyAvailable() {
+		m.todaySpend = m.tracker.GetSessionCost()
+	} else if _, total, err := m.tracker.GetHistoricalUsage("today"); err == nil {
+		m.todaySpend = total
+	} else {
+		m.todaySpend = m.tracker.GetSessionCost()
+	}
+
+	// A new day (or a reset) re-arms the banner
+	if m.limitLevel() == 0 {
+		m.limitDismissed = 0
+	}
+}
+
+// limitLevel is how many whole multiples of the hard limit today's spend has
+// reached: 0 below the limit, 1 past it, 2 past double, and so on
+func (m model) limitLevel() int {
+	if m.config.DailyHardLimit <= 0 {
+		return 0
+	}
+	return int(m.todaySpend / m.config.DailyHardLimit)
+}
+
+// renderLimitBanner renders a full-width red warning while today's spend is
+// over the hard limit and the current level hasn't been dismissed. Otherwise
+// it renders the blank line that normally tops the dashboard.
+func (m model) renderLimitBanner() string {
+	level := m.limitLevel()
+	if level == 0 || level <= m.limitDismissed {
+		return ""
+	}
+
+	spent, limit := config.FormatMoneyCents(m.todaySpend), config.FormatMoneyCents(m.config.DailyHardLimit)
+	text := fmt.Sprintf("HARD LIMIT EXCEEDED: %s spent today (limit %s)  [d] dismiss", spent, limit)
+	if level > 1 {
+		text = fmt.Sprintf("HARD LIMIT EXCEEDED %dx: %s spent today (limit %s)  [d] dismiss", level, spent, limit)
+	}
+
+	width := m.width
+	if width <= 0 {
+		width = lipgloss.Width(text) + 4
+	}
+	return limitBannerStyle.Width(width).Render(text)
+}
+
+// viewLabels name each view for display
+var viewLabels = map[string]string{
+	"session": "Session",
+	"today":   "Today",
+	"week":    "Week",
+	"month":   "Month",
+	"all":     "All",
+}
+
+// renderCompact renders the active window's spend, budget use, burn rate, and
+// pricing freshness on one line, or two if the pane is narrow, e.g.
+// "● Today $1.2345 · 25% of $5.00 · $0.42/hr"
+func (m model) renderCompact() string {
+	spend := m.pricingDot() + " " +
+		activeTabStyle.UnsetPadding().Render(viewLabels[m.activeView]) + " " +
+		statValueStyle.Render(config.FormatMoney(m.total))
+
+	parts := []string{}
+	if m.activeView != "all" {
+		budget := m.windowBudget()
+		pct := 0.0
+		if budget > 0 {
+			pct = m.total / budget * 100
+		}
+		of := config.FormatMoneyCents(budget)
+		if label := m.budgetBasisLabel(); label != "" {
+			of += " " + label
+		}
+		parts = append(parts, statLabelStyle.Render(fmt.Sprintf("%.0f%% of %s", pct, of)))
+	}
+	parts = append(parts, statLabelStyle.Render(config.FormatMoneyCents(m.burnRate)+"/hr"))
+
+	sep := statLabelStyle.Render(" · ")
+	line := spend + sep + strings.Join(parts, sep)
+	if m.width > 0 && lipgloss.Width(line) > m.width {
+		return spend + "\n" + strings.Join(parts, sep)
+	}
+	return line
+}
+
+// pricingDot is green when prices were fetched from the API within the
+// pricing freshness window (an hour by default), and blue in offline mode,
+// where prices can't go stale
+func (m model) pricingDot() string {
+	status := m.pricingStatus
+	if status.Offline {
+		return statusDotOfflineStyle.Render()
+	}
+	if status.Source == pricing.SourceAPI && time.Since(status.UpdatedAt) < m.config.PricingFreshness {
+		return statusDotStyle.Render()
+	}
+	return statusDotStaleStyle.Render()
+}
+
+// renderHistoryStatus warns when history isn't being kept, e.g. "history
+// disabled (read-only home)": the historical views then only show this run
+func renderHistoryStatus() string {
+	reason := storage.HistoryDisabled()
+	if reason == "" {
+		return ""
+	}
+	return "  " + historyDisabledStyle.Render("history disabled ("+reason+")")
+}
+
+// renderPricingStatus renders the freshness dot plus why pricing is stale, e.g.
+// "Pricing: updated 2h ago (network error)"
+func (m model) renderPricingStatus() string {
+	status := m.pricingStatus
+	dot := m.pricingDot()
+
+	var text string
+	switch {
+	case status.Offline && status.Source == pricing.SourceCache:
+		text = "Pricing: offline mode, using disk cache from " + formatRelativeTime(status.UpdatedAt)
+	case status.Offline:
+		text = "Pricing: offline mode (defaults)"
+	case status.Source == pricing.SourceAPI:
+		text = "Pricing: updated " + formatRelativeTime(status.UpdatedAt)
+	case status.Source == pricing.SourceCache:
+		text = "Pricing: offline, using disk cache from " + formatRelativeTime(status.UpdatedAt)
+	default:
+		text = "Pricing: offline, using built-in defaults"
+		if status.LastError == nil {
+			text = "Pricing: using built-in defaults (never fetched)"
+		}
+	}
+	if status.LastError != nil {
+		text += " (" + describeFetchError(status.LastError) + ")"
+	}
+
+	return dot + " " + statLabelStyle.Render(text)
+}
+
+// describeFetchError shortens a pricing fetch error for the header
+func describeFetchError(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timed out"
+	case errors.As(err, &netErr):
+		return "network error"
+	case strings.Contains(err.Error(), "bad status"):
+		return strings.TrimPrefix(err.Error(), "bad status: ")
+	case strings.Contains(err.Error(), "decode"):
+		return "invalid response"
+	}
+	return "network error"
+}
+
 func (m model) renderWhatIfModal() string {
 	if !m.showWhatIf {
 		return ""
@@ -427,10 +1546,10 @@ func (m model) renderWhatIfModal() string {
 
 	switch m.activeView {
 	case "session":
-		usages = tracker.Global.GetUsages()
-		currentCost = tracker.Global.GetSessionCost()
-	case "today", "week":
-		u, c, err := tracker.Global.GetHistoricalUsage(m.activeView)
+		usages = m.tracker.GetUsages()
+		currentCost = m.tracker.GetSessionCost()
+	case "today", "week", "month", "all":
+		u, c, err := m.tracker.GetHistoricalUsage(m.activeView)
 		if err == nil {
 			usages = u
 			currentCost = c
@@ -448,7 +1567,7 @@ func (m model) renderWhatIfModal() string {
 	lines = append(lines, titleStyle.Render("What-If Analysis"))
 	lines = append(lines, subtitleStyle.Render(fmt.Sprintf("Comparing cost for %s data", m.activeView)))
 	lines = append(lines, "")
-	lines = append(lines, fmt.Sprintf("Current Cost: $%.4f", currentCost))
+	lines = append(lines, "Current Cost: "+config.FormatMoney(currentCost))
 	lines = append(lines, "")
 
 	// Header
@@ -465,19 +1584,19 @@ func (m model) renderWhatIfModal() string {
 		}
 
 		diff := cost - currentCost
-		diffStr := fmt.Sprintf("+$%.4f", diff)
+		diffStr := "+" + config.FormatMoney(diff)
 		color := warningColor
 		if diff < 0 {
-			diffStr = fmt.Sprintf("-$%.4f", -diff)
+			diffStr = config.FormatMoney(diff)
 			color = successColor
 		} else if diff == 0 {
 			diffStr = "="
 			color = mutedColor
 		}
 
-		row := fmt.Sprintf("%-25s | $%.4f  | %s",
+		row := fmt.Sprintf("%-25s | %-10s | %s",
 			model,
-			cost,
+			config.FormatMoney(cost),
 			lipgloss.NewStyle().Foreground(color).Render(diffStr),
 		)
 		lines = append(lines, row)
@@ -490,64 +1609,322 @@ func (m model) renderWhatIfModal() string {
 	return modalStyle.Render(content)
 }
 
-func (m model) renderHistoryChart() string {
-	days := 7
-	if m.activeView == "week" {
-		days = 7
-	} else if m.activeView == "today" {
-		days = 7 // Show context for today as well
+// renderLifetimeStats renders "Lifetime: $X across N events since <date>"
+func (m model) renderLifetimeStats() string {
+	since := "never"
+	if !m.lifetimeFirst.IsZero() {
+		since = m.lifetimeFirst.Format("2006-01-02")
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Center,
+		statLabelStyle.Render("Lifetime ")+statValueStyle.Render(config.FormatMoney(m.total)),
+		statLabelStyle.Render(" across "),
+		statValueStyle.Render(fmt.Sprintf("%d", m.lifetimeEvents)),
+		statLabelStyle.Render(" events since "),
+		statValueStyle.Render(since),
+	)
+}
+
+// windowBudget is the budget the active window's spend is shown against:
+// the chosen basis's, or else the window's own
+func (m model) windowBudget() float64 {
+	if m.budgetBasis != "" {
+		return m.budgetFor(m.budgetBasis)
+	}
+	return m.budgetFor(m.activeView)
+}
+
+// budgetBases are the budgets b cycles through, after the active tab's own
+var budgetBases = []string{"day", "week", "month"}
+
+// nextBudgetBasis returns the basis after current, wrapping back to ""
+func nextBudgetBasis(current string) string {
+	for i, basis := range budgetBases {
+		if basis == current {
+			if i+1 < len(budgetBases) {
+				return budgetBases[i+1]
+			}
+			return ""
+		}
+	}
+	return budgetBases[0]
+}
+
+// budgetBasisLabel names a chosen basis, e.g. "monthly budget", or is ""
+// while the bar follows the active tab
+func (m model) budgetBasisLabel() string {
+	switch m.budgetBasis {
+	case "day":
+		return "daily budget"
+	case "week":
+		return "weekly budget"
+	case "month":
+		return "monthly budget"
+	}
+	return ""
+}
+
+// budgetFor scales the daily budget to a window
+func (m model) budgetFor(window string) float64 {
+	return m.config.WindowBudget(window, time.Now())
+}
+
+// chartBucket is one bar in the history chart
+type chartBucket struct {
+	label string
+	cost  float64
+}
+
+// renderHistoryChart shows spend over the range matching the active window:
+// hourly for today, daily for week and month, and the last 30 days for all
+// time. width is the space available, border included.
+func (m model) renderHistoryChart(width int) string {
+	var title string
+	var buckets []chartBucket
+	budget := m.config.DailyBudget // Per-bar budget for color thresholds
+
+	switch m.activeView {
+	case "today":
+		hourly, err := m.tracker.GetHourlySpend(24)
+		if err != nil || len(hourly) == 0 {
+			return chartBoxStyle.Render("No history available")
+		}
+		title = "Today (Last 24 Hours)"
+		budget = m.config.DailyBudget / 24
+		for _, hs := range hourly {
+			// Hour is "2006-01-02 15"; label with just the hour
+			buckets = append(buckets, chartBucket{label: hs.Hour[len(hs.Hour)-2:], cost: hs.Cost})
+		}
+
+	default:
+		days := 7
+		title = "History (Last 7 Days)"
+		if m.activeView == "month" {
+			days = time.Now().Day()
+			title = "This Month"
+		} else if m.activeView == "all" {
+			days = 30
+			title = "History (Last 30 Days)"
+		}
+
+		daily, err := m.tracker.GetDailySpend(days)
+		if err != nil || len(daily) == 0 {
+			return chartBoxStyle.Render("No history available")
+		}
+		for _, ds := range daily {
+			// Parse date to just show short Day (or day of month for longer ranges)
+			t, _ := time.Parse("2006-01-02", ds.Date)
+			label := t.Format("Mon")
+			if days > 7 {
+				label = t.Format("02")
+			}
+			buckets = append(buckets, chartBucket{label: label, cost: ds.Cost})
+		}
 	}
 
-	dailySpends, err := tracker.Global.GetDailySpend(days)
-	if err != nil || len(dailySpends) == 0 {
-		return chartBoxStyle.Render("No history available")
+	if len(buckets) > 7 {
+		chart := m.renderColumnChart(title, buckets, budget)
+		if m.activeView == "month" {
+			chart += "\n\n" + m.renderHourOfDay()
+		}
+		return chartBoxStyle.Render(chart)
 	}
+	return chartBoxStyle.Render(m.renderBarChart(title, buckets, budget, width-chartBoxStyle.GetHorizontalFrameSize()))
+}
+
+// barColor colors a bar by how close it is to its budget
+func barColor(cost, budget float64) lipgloss.Color {
+	if cost > budget {
+		return errorColor
+	} else if cost > budget*0.8 {
+		return warningColor
+	}
+	return successColor
+}
+
+// Bar chart widths: the bars get what's left of the chart's width after the
+// label and amount columns, within these bounds
+const (
+	minBarWidth = 10
+	maxBarWidth = 60
+)
 
+// renderBarChart draws one horizontal bar per bucket under an axis showing
+// the scale. When the per-bar budget is within the scale, a marker on each
+// bar shows where it is. width is the space available for the chart's lines.
+func (m model) renderBarChart(title string, buckets []chartBucket, budget float64, width int) string {
 	// Find max for scaling
 	var maxCost float64
-	for _, ds := range dailySpends {
-		if ds.Cost > maxCost {
-			maxCost = ds.Cost
+	for _, b := range buckets {
+		if b.cost > maxCost {
+			maxCost = b.cost
 		}
 	}
-	if maxCost == 0 {
-		maxCost = 1.0 // Avoid div by zero
+	scale := maxCost
+	if scale == 0 {
+		scale = 1.0 // Avoid div by zero
 	}
 
-	// Simple ASCII Chart
-	var bars []string
-	bars = append(bars, lipgloss.NewStyle().Bold(true).Render("History (Last 7 Days)"))
+	amountWidth := lipgloss.Width(config.FormatMoneyCents(maxCost))
+	barWidth := width - 4 - 1 - amountWidth // Label column and spacing
+	barWidth = max(minBarWidth, min(barWidth, maxBarWidth))
 
-	for _, ds := range dailySpends {
-		// Parse date to just show Day/Month or short Day
-		t, _ := time.Parse("2006-01-02", ds.Date)
-		label := t.Format("Mon")
+	// Column of the budget marker, or -1 when the budget is off the scale
+	marker := -1
+	if budget > 0 && budget <= maxCost {
+		marker = min(int(budget/scale*float64(barWidth)), barWidth-1)
+	}
+	markerStyle := lipgloss.NewStyle().Foreground(highlightColor)
+
+	var lines []string
+	lines = append(lines, lipgloss.NewStyle().Bold(true).Render(title))
+
+	// Axis: zero at the left end of the bars, the max at the right
+	low, high := config.FormatMoneyCents(0), config.FormatMoneyCents(maxCost)
+	gap := max(1, barWidth-lipgloss.Width(low)-lipgloss.Width(high))
+	lines = append(lines, statLabelStyle.Render("    "+low+strings.Repeat(" ", gap)+high))
 
-		barLen := int((ds.Cost / maxCost) * 20)
-		if barLen == 0 && ds.Cost > 0 {
+	for _, b := range buckets {
+		barLen := int((b.cost / scale) * float64(barWidth))
+		if barLen == 0 && b.cost > 0 {
 			barLen = 1
 		}
 
-		barChar := "▇"
-		bar := strings.Repeat(barChar, barLen)
-
-		// Color based on budget ratio (rough approx)
-		color := successColor
-		if ds.Cost > m.config.DailyBudget {
-			color = errorColor
-		} else if ds.Cost > m.config.DailyBudget*0.8 {
-			color = warningColor
+		// cells draws columns [from, to) of the bar, padded to full width
+		barStyle := lipgloss.NewStyle().Foreground(barColor(b.cost, budget))
+		cells := func(from, to int) string {
+			filled := max(0, min(barLen, to)-from)
+			return barStyle.Render(strings.Repeat("▇", filled)) + strings.Repeat(" ", to-from-filled)
+		}
+		bar := cells(0, barWidth)
+		if marker >= 0 {
+			bar = cells(0, marker) + markerStyle.Render("│") + cells(marker+1, barWidth)
 		}
 
-		line := fmt.Sprintf("%s %s $%.2f",
-			lipgloss.NewStyle().Width(3).Render(label),
-			lipgloss.NewStyle().Foreground(color).Render(bar),
-			ds.Cost,
+		line := fmt.Sprintf("%s %s %s",
+			lipgloss.NewStyle().Width(3).Render(b.label),
+			bar,
+			config.FormatMoneyCents(b.cost),
 		)
-		bars = append(bars, line)
+		lines = append(lines, line)
+	}
+
+	if marker >= 0 {
+		lines = append(lines, statLabelStyle.Render("    ")+markerStyle.Render("│")+
+			statLabelStyle.Render(" budget "+config.FormatMoneyCents(budget)))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// chartHeight is the number of rows used by the column chart
+const chartHeight = 4
+
+// blockLevels are the partial blocks for a chart cell, from empty to full
+var blockLevels = []string{" ", "▁", "▂", "▃", "▄", "▅", "▆", "▇", "█"}
+
+// renderColumnChart draws one vertical column per bucket, for ranges too long
+// to list one bar per line. Labels are shown every few columns.
+func (m model) renderColumnChart(title string, buckets []chartBucket, budget float64) string {
+	var maxCost, total float64
+	for _, b := range buckets {
+		total += b.cost
+		if b.cost > maxCost {
+			maxCost = b.cost
+		}
+	}
+	scale := maxCost
+	if scale == 0 {
+		scale = 1.0 // Avoid div by zero
+	}
+
+	// Height of each column in eighths of a row
+	heights := make([]int, len(buckets))
+	for i, b := range buckets {
+		heights[i] = int((b.cost / scale) * float64(chartHeight*8))
+		if heights[i] == 0 && b.cost > 0 {
+			heights[i] = 1
+		}
+	}
+
+	var lines []string
+	lines = append(lines, lipgloss.NewStyle().Bold(true).Render(title))
+
+	for row := chartHeight - 1; row >= 0; row-- {
+		var line strings.Builder
+		for i, b := range buckets {
+			fill := heights[i] - row*8
+			if fill < 0 {
+				fill = 0
+			} else if fill > 8 {
+				fill = 8
+			}
+			cell := strings.Repeat(blockLevels[fill], 2)
+			line.WriteString(lipgloss.NewStyle().Foreground(barColor(b.cost, budget)).Render(cell))
+		}
+		lines = append(lines, line.String())
+	}
+
+	// Label every few columns so they don't collide
+	step := 6
+	if len(buckets) <= 16 {
+		step = 2
+	}
+	var labels strings.Builder
+	for i := 0; i < len(buckets); i += step {
+		labels.WriteString(lipgloss.NewStyle().Width(step * 2).Render(buckets[i].label))
+	}
+	lines = append(lines, statLabelStyle.Render(labels.String()))
+	lines = append(lines, statLabelStyle.Render(fmt.Sprintf("max %s  total %s", config.FormatMoneyCents(maxCost), config.FormatMoneyCents(total))))
+
+	return strings.Join(lines, "\n")
+}
+
+// renderHourOfDay draws the month's spend by hour of the day as one row of
+// 24 cells, each shaded by its share of the busiest hour
+func (m model) renderHourOfDay() string {
+	title := lipgloss.NewStyle().Bold(true).Render("By Hour of Day")
+
+	since, err := storage.WindowStart("month", time.Now())
+	if err != nil {
+		return title + "\n" + statLabelStyle.Render("No history available")
+	}
+	hours, err := storage.GetHourOfDayHistogram(since.Unix())
+	if err != nil {
+		return title + "\n" + statLabelStyle.Render("No history available")
+	}
+
+	peak := 0
+	for hour, cost := range hours {
+		if cost > hours[peak] {
+			peak = hour
+		}
+	}
+	if hours[peak] == 0 {
+		return title + "\n" + statLabelStyle.Render("No spend this month")
+	}
+
+	var cells strings.Builder
+	for _, cost := range hours {
+		level := int(cost / hours[peak] * 8)
+		if level == 0 && cost > 0 {
+			level = 1
+		}
+		cells.WriteString(strings.Repeat(blockLevels[level], 2))
+	}
+
+	// Label every six hours, two characters per cell
+	var labels strings.Builder
+	for hour := 0; hour < 24; hour += 6 {
+		labels.WriteString(lipgloss.NewStyle().Width(12).Render(fmt.Sprintf("%02d", hour)))
 	}
 
-	return chartBoxStyle.Render(strings.Join(bars, "\n"))
+	return strings.Join([]string{
+		title,
+		lipgloss.NewStyle().Foreground(primaryColor).Render(cells.String()),
+		statLabelStyle.Render(labels.String()),
+		statLabelStyle.Render(fmt.Sprintf("peak %02d:00  %s", peak, config.FormatMoneyCents(hours[peak]))),
+	}, "\n")
 }
 
 func (m model) renderTab(label, key string) string {
@@ -558,7 +1935,7 @@ func (m model) renderTab(label, key string) string {
 }
 
 func (m model) renderToolsPanel() string {
-	statuses := tracker.Global.GetToolStatuses()
+	statuses := m.tracker.GetToolStatuses()
 
 	if len(statuses) == 0 {
 		return toolsBoxStyle.Render(
@@ -567,16 +1944,85 @@ func (m model) renderToolsPanel() string {
 	}
 
 	var lines []string
-	for _, s := range statuses {
-		line := formatToolStatus(s)
-		lines = append(lines, line)
+	for i, s := range statuses {
+		selected := m.toolsFocused && i == m.selectedToolIndex(len(statuses))
+		lines = append(lines, formatToolStatus(s, selected))
+	}
+	if m.toolsFocused {
+		lines = append(lines, renderToolHistory(statuses[m.selectedToolIndex(len(statuses))])...)
 	}
 
 	content := strings.Join(lines, "\n")
 	return toolsBoxStyle.Render(content)
 }
 
-func formatToolStatus(s *tracker.ToolStatus) string {
+// toolHistoryLines is how many of the selected tool's status changes the
+// tools panel lists
+const toolHistoryLines = 5
+
+// renderToolHistory lists a tool's latest status changes, newest first,
+// e.g. "09:14:03  not_found -> active  Watching database", so a tool that
+// briefly failed and recovered can be told from one that never failed
+func renderToolHistory(s *tracker.ToolStatus) []string {
+	muted := lipgloss.NewStyle().Foreground(mutedColor)
+	lines := []string{"", statValueStyle.Render(s.Name) + muted.Render(" status history")}
+	if len(s.History) == 0 {
+		return append(lines, muted.Render("  No changes yet"))
+	}
+	for i := len(s.History) - 1; i >= 0 && i >= len(s.History)-toolHistoryLines; i-- {
+		tr := s.History[i]
+		change := tr.To
+		if tr.From != "" {
+			change = tr.From + " -> " + tr.To
+		}
+		line := "  " + muted.Render(tr.Time.In(storage.Location()).Format("15:04:05")) + "  " + change
+		if tr.Message != "" {
+			line += "  " + muted.Render(tr.Message)
+		}
+		lines = append(lines, line)
+	}
+	if hidden := len(s.History) - toolHistoryLines; hidden > 0 {
+		lines = append(lines, muted.Render(fmt.Sprintf("  %d earlier", hidden)))
+	}
+	return lines
+}
+
+// selectedToolIndex clamps the cursor in case tools were removed since it moved
+func (m model) selectedToolIndex(count int) int {
+	if m.toolCursor >= count {
+		return count - 1
+	}
+	return m.toolCursor
+}
+
+// openSelectedTool opens the selected tool's DashboardURL in the browser.
+// Tools without a URL are ignored.
+func (m model) openSelectedTool() tea.Cmd {
+	statuses := m.tracker.GetToolStatuses()
+	if len(statuses) == 0 {
+		return nil
+	}
+
+	url := statuses[m.selectedToolIndex(len(statuses))].DashboardURL
+	if url == "" {
+		return nil
+	}
+
+	return func() tea.Msg {
+		if err := openBrowser(url); err != nil {
+			log.Warnf("failed to open %s: %v", url, err)
+		}
+		return nil
+	}
+}
+
+// formatToolStatus renders one tools panel row, marked with > when selected
+func formatToolStatus(s *tracker.ToolStatus, selected bool) string {
+	marker := " "
+	if selected {
+		marker = lipgloss.NewStyle().Foreground(primaryColor).Render(">")
+	}
+
 	// Status icon and color
 	var icon string
 	var statusStyle lipgloss.Style
@@ -591,6 +2037,9 @@ func formatToolStatus(s *tracker.ToolStatus) string {
 	case "configured":
 		icon = lipgloss.NewStyle().Foreground(infoColor).Render("o")
 		statusStyle = lipgloss.NewStyle().Foreground(infoColor)
+	case "idle":
+		icon = lipgloss.NewStyle().Foreground(mutedColor).Render("*")
+		statusStyle = lipgloss.NewStyle().Foreground(mutedColor)
 	case "waiting":
 		icon = lipgloss.NewStyle().Foreground(mutedColor).Render("~")
 		statusStyle = lipgloss.NewStyle().Foreground(mutedColor)
@@ -611,11 +2060,19 @@ func formatToolStatus(s *tracker.ToolStatus) string {
 		eventInfo = fmt.Sprintf("%d events", s.EventCount)
 		// Show cost if available
 		if s.TotalCost > 0 {
-			eventInfo += fmt.Sprintf(" ($%.4f)", s.TotalCost)
+			eventInfo += " (" + config.FormatMoney(s.TotalCost) + ")"
 		}
 		if !s.LastEventTime.IsZero() {
 			eventInfo += "  " + formatRelativeTime(s.LastEventTime)
 		}
+		if s.CostMismatches > 0 {
+			eventInfo += lipgloss.NewStyle().Foreground(warningColor).
+				Render(fmt.Sprintf("  %d cost mismatches", s.CostMismatches))
+		}
+		if s.RejectedEvents > 0 {
+			eventInfo += lipgloss.NewStyle().Foreground(warningColor).
+				Render(fmt.Sprintf("  %d rejected", s.RejectedEvents))
+		}
 	} else if s.Tier == tracker.TierDetectionOnly && s.DashboardURL != "" {
 		// Show shortened dashboard URL for detection-only tools
 		shortURL := strings.TrimPrefix(s.DashboardURL, "https://")
@@ -624,7 +2081,30 @@ func formatToolStatus(s *tracker.ToolStatus) string {
 		eventInfo = lipgloss.NewStyle().Foreground(mutedColor).Render(s.Message)
 	}
 
-	return fmt.Sprintf(" %s %s %s  %s", icon, name, statusText, eventInfo)
+	return fmt.Sprintf("%s%s %s %s  %s", marker, icon, name, statusText, eventInfo)
+}
+
+// formatCost formats a cost, or "??" for a model left unpriced because no
+// fallback model is configured
+func formatCost(model string, cost float64) string {
+	if unpriced(model, cost) {
+		return "??"
+	}
+	return config.FormatMoney(cost)
+}
+
+// formatEventCost is formatCost for a single request, at the finer
+// per-event precision
+func formatEventCost(model string, cost float64) string {
+	if unpriced(model, cost) {
+		return "??"
+	}
+	return config.FormatMoneyEvent(cost)
+}
+
+// unpriced reports whether a zero cost means the model had no price
+func unpriced(model string, cost float64) bool {
+	return cost == 0 && pricing.FallbackModel == "" && pricing.NeedsFallback(model)
 }
 
 func formatTokens(tokens int) string {
@@ -0,0 +1,431 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bangarangler/burnrate/internal/config"
+	"github.com/bangarangler/burnrate/internal/daemon"
+	"github.com/bangarangler/burnrate/internal/storage"
+	"github.com/bangarangler/burnrate/internal/tracker"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+func TestTickKeepsTableSelection(t *testing.T) {
+	trk := tracker.NewTracker(time.Now)
+	for i := 0; i < 20; i++ {
+		trk.AddUsageWithTool("Aider", fmt.Sprintf("model-%02d", i), 1000, 100, 0.01)
+	}
+
+	// 1. First tick fills the table, then move the cursor down past the fold
+	var m tea.Model = InitialModelWith(config.Load(), trk)
+	m, _ = m.Update(tickMsg{})
+	for i := 0; i < 12; i++ {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	}
+	before := m.(model)
+	if before.table.Cursor() != 12 {
+		t.Fatalf("Expected cursor 12 before the tick, got %d", before.table.Cursor())
+	}
+
+	// 2. A second tick with the same rows leaves the cursor and window alone
+	m, _ = m.Update(tickMsg{})
+	after := m.(model)
+	if after.table.Cursor() != 12 {
+		t.Errorf("Expected cursor to stay at 12, got %d", after.table.Cursor())
+	}
+	if after.tableScroll.top != before.tableScroll.top {
+		t.Errorf("Expected top row %d, got %d", before.tableScroll.top, after.tableScroll.top)
+	}
+
+	// 3. When rows disappear, the cursor is clamped to the last one
+	trk.Reset()
+	for i := 0; i < 5; i++ {
+		trk.AddUsageWithTool("Aider", fmt.Sprintf("model-%02d", i), 1000, 100, 0.01)
+	}
+	m, _ = m.Update(tickMsg{})
+	if cursor := m.(model).table.Cursor(); cursor != 4 {
+		t.Errorf("Expected cursor clamped to 4, got %d", cursor)
+	}
+}
+
+func TestFirstRunHelpWhenNothingTracked(t *testing.T) {
+	trk := tracker.NewTracker(time.Now)
+	trk.SetToolStatus(tracker.ToolStatus{
+		Name: "Aider", Tier: tracker.TierFullTracking, Status: "waiting",
+		Path: "/home/me/.aider/usage.jsonl", Hint: "Run aider with --analytics-log",
+	})
+
+	// 1. The first tick shows where each tool was looked for
+	var m tea.Model = InitialModelWith(config.Load(), trk)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m, _ = m.Update(tickMsg{})
+	view := m.View()
+	if !strings.Contains(view, "/home/me/.aider/usage.jsonl") || !strings.Contains(view, "Run aider with --analytics-log") {
+		t.Fatalf("Expected the first-run help with Aider's path and hint, got:\n%s", view)
+	}
+
+	// 2. Any key dismisses it, without acting on the key
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")})
+	if m.(model).showFirstRun || m.(model).activeView != "session" {
+		t.Errorf("Expected the help closed and the view unchanged, got %v and %s", m.(model).showFirstRun, m.(model).activeView)
+	}
+
+	// 3. It doesn't come back on later ticks
+	m, _ = m.Update(tickMsg{})
+	if m.(model).showFirstRun {
+		t.Error("Expected the help to stay closed")
+	}
+}
+
+func TestFirstRunHelpHiddenWhenAToolIsActive(t *testing.T) {
+	trk := tracker.NewTracker(time.Now)
+	trk.SetToolStatus(tracker.ToolStatus{Name: "Aider", Tier: tracker.TierFullTracking, Status: "active"})
+
+	var m tea.Model = InitialModelWith(config.Load(), trk)
+	m, _ = m.Update(tickMsg{})
+	if m.(model).showFirstRun {
+		t.Error("Expected no first-run help with an active tool")
+	}
+}
+
+func TestSessionCapFiresOncePerCrossing(t *testing.T) {
+	trk := tracker.NewTracker(time.Now)
+	cfg := config.Load()
+	cfg.SessionCap = 1.00
+	cfg.OnCap = "true"
+	m := InitialModelWith(cfg, trk)
+
+	// 1. Under the cap nothing fires
+	trk.AddUsageWithTool("Aider", "gpt-4o", 1000, 100, 0.60)
+	if cmd := m.checkSessionCap(); cmd != nil || m.capTriggered {
+		t.Fatal("Expected no cap action under the cap")
+	}
+
+	// 2. Crossing it fires the command and shows the banner
+	trk.AddUsageWithTool("Aider", "gpt-4o", 1000, 100, 0.60)
+	if cmd := m.checkSessionCap(); cmd == nil {
+		t.Fatal("Expected the cap command when the cap is crossed")
+	}
+	if !strings.Contains(m.renderCapBanner(), "SESSION CAP REACHED") {
+		t.Errorf("Expected the cap banner, got %q", m.renderCapBanner())
+	}
+
+	// 3. Staying over it doesn't fire again
+	trk.AddUsageWithTool("Aider", "gpt-4o", 1000, 100, 0.60)
+	if cmd := m.checkSessionCap(); cmd != nil {
+		t.Error("Expected the cap to fire only once")
+	}
+
+	// 4. A reset re-arms it
+	trk.Reset()
+	m.checkSessionCap()
+	trk.AddUsageWithTool("Aider", "gpt-4o", 1000, 100, 1.50)
+	if cmd := m.checkSessionCap(); cmd == nil {
+		t.Error("Expected the cap to fire again after a reset")
+	}
+}
+
+func TestTopKeyWorksWhileABannerShows(t *testing.T) {
+	trk := tracker.NewTracker(time.Now)
+	trk.AddUsageWithTool("Aider", "gpt-4o", 1000, 100, 1.50)
+	cfg := config.Load()
+	cfg.SessionCap = 1.00
+	m := InitialModelWith(cfg, trk)
+	m.checkSessionCap()
+	press := func(k string) {
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+		m = next.(model)
+	}
+	if m.renderCapBanner() == "" {
+		t.Fatal("Expected the cap banner")
+	}
+
+	// 1. x opens the top requests and leaves the banner up
+	press("x")
+	if m.tableMode != topMode || m.renderCapBanner() == "" {
+		t.Errorf("Expected the top view under the banner, got mode %d, banner %q", m.tableMode, m.renderCapBanner())
+	}
+
+	// 2. x goes back
+	press("x")
+	if m.tableMode != aggregateMode {
+		t.Errorf("Expected x to leave the top view, got mode %d", m.tableMode)
+	}
+
+	// 3. d dismisses the banner without touching the table
+	press("d")
+	if m.renderCapBanner() != "" || m.tableMode != aggregateMode {
+		t.Errorf("Expected d to dismiss the banner only, got mode %d, banner %q", m.tableMode, m.renderCapBanner())
+	}
+}
+
+func TestSnapshotSavesPlainText(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	trk := tracker.NewTracker(time.Now)
+	trk.AddUsageWithTool("Aider", "gpt-4o", 1000, 100, 0.01)
+
+	var m tea.Model = InitialModelWith(config.Load(), trk)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m, _ = m.Update(tickMsg{})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+
+	// 1. The footer confirms where the snapshot went
+	saved := m.(model)
+	if saved.snapshotErr != nil {
+		t.Fatalf("Expected the snapshot to save, got %v", saved.snapshotErr)
+	}
+	if !strings.Contains(m.View(), saved.snapshotPath) {
+		t.Errorf("Expected the footer to show %s", saved.snapshotPath)
+	}
+
+	// 2. The file holds the dashboard without escape codes
+	data, err := os.ReadFile(saved.snapshotPath)
+	if err != nil {
+		t.Fatalf("Expected the snapshot file, got %v", err)
+	}
+	if !strings.Contains(string(data), "gpt-4o") || strings.Contains(string(data), "\x1b") {
+		t.Errorf("Expected plain text with the session's model, got:\n%s", data)
+	}
+}
+
+func TestRenderOncePrintsTheDashboard(t *testing.T) {
+	tracker.Global.Reset()
+	defer tracker.Global.Reset()
+
+	// 1. An empty session renders as the dashboard, not the first-run help
+	view := PlainText(RenderOnce(config.Load(), false, 120, 40))
+	if !strings.Contains(view, "Session") || strings.Contains(view, "Welcome to burnrate") {
+		t.Errorf("Expected the empty dashboard, got:\n%s", view)
+	}
+
+	// 2. Data is refreshed before rendering
+	tracker.Global.AddUsageWithTool("Aider", "gpt-4o", 1000, 100, 0.01)
+	view = PlainText(RenderOnce(config.Load(), false, 120, 40))
+	if !strings.Contains(view, "gpt-4o") || strings.Contains(view, "\x1b") {
+		t.Errorf("Expected plain text with the session's model, got:\n%s", view)
+	}
+}
+
+func TestBudgetBasisIsIndependentOfTheView(t *testing.T) {
+	cfg := config.Load()
+	cfg.DailyBudget = 10
+	cfg.MonthlyBudget = 200
+	var m tea.Model = InitialModelWith(cfg, tracker.NewTracker(time.Now))
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+
+	// 1. By default Today is measured against the daily budget
+	if got := m.(model).windowBudget(); got != 10 {
+		t.Errorf("Expected the daily budget, got %f", got)
+	}
+
+	// 2. b cycles the basis: day, week, then month
+	for i := 0; i < 3; i++ {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	}
+	if got := m.(model).windowBudget(); got != 200 {
+		t.Errorf("Expected the monthly budget, got %f", got)
+	}
+	if view := m.View(); !strings.Contains(view, "Today vs monthly budget") {
+		t.Errorf("Expected the bar labeled with its basis, got:\n%s", view)
+	}
+
+	// 3. Once more follows the view again
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("b")})
+	if got := m.(model).windowBudget(); got != 10 || m.(model).budgetBasis != "" {
+		t.Errorf("Expected the view's own budget again, got %f", got)
+	}
+}
+
+func TestFreeModelsKeyHidesZeroCostRows(t *testing.T) {
+	trk := tracker.NewTracker(time.Now)
+	trk.AddUsageWithTool("OpenCode", "gpt-4o", 1000, 100, 0.05)
+	trk.AddUsageWithTool("OpenCode", "deepseek/deepseek-r1:free", 2000, 200, 0)
+	trk.AddUsageWithTool("OpenCode", "qwen/qwen3-coder:free", 3000, 300, 0)
+
+	var m tea.Model = InitialModelWith(config.Load(), trk)
+	m, _ = m.Update(tickMsg{})
+	rows := func() []string {
+		var names []string
+		for _, row := range m.(model).table.Rows() {
+			names = append(names, row[0])
+		}
+		return names
+	}
+	press := func() { m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("F")}) }
+
+	// 1. Free models are shown by default
+	if got := rows(); len(got) != 3 {
+		t.Fatalf("Expected 3 rows, got %v", got)
+	}
+
+	// 2. F groups them into one row
+	press()
+	if got := rows(); len(got) != 2 || got[1] != tracker.FreeModelsRow {
+		t.Errorf("Expected gpt-4o and %s, got %v", tracker.FreeModelsRow, got)
+	}
+
+	// 3. Then hides them, and says so above the table
+	press()
+	if got := rows(); len(got) != 1 || got[0] != "gpt-4o" {
+		t.Errorf("Expected only gpt-4o, got %v", got)
+	}
+	if status := PlainText(m.(model).renderFilterStatus()); !strings.Contains(status, "free models hidden") {
+		t.Errorf("Expected the filter status to mention hidden free models, got %q", status)
+	}
+
+	// 4. And a tick keeps them hidden
+	m, _ = m.Update(tickMsg{})
+	if got := rows(); len(got) != 1 {
+		t.Errorf("Expected the tick to keep them hidden, got %v", got)
+	}
+}
+
+func TestFocusedToolShowsStatusHistory(t *testing.T) {
+	trk := tracker.NewTracker(time.Now)
+	trk.SetToolStatus(tracker.ToolStatus{Name: "Crush", Tier: tracker.TierFullTracking, Status: "active"})
+	trk.SetToolStatus(tracker.ToolStatus{Name: "Crush", Tier: tracker.TierFullTracking, Status: "not_found",
+		Message: ".crush/crush.db not found"})
+
+	var m tea.Model = InitialModelWith(config.Load(), trk)
+	m, _ = m.Update(tickMsg{})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc}) // Dismiss the first-run help
+
+	// 1. Hidden until a tool is selected
+	if panel := PlainText(m.(model).renderToolsPanel()); strings.Contains(panel, "status history") {
+		t.Errorf("Expected no history before selecting a tool, got:\n%s", panel)
+	}
+
+	// 2. Tab selects the tool and lists its changes, newest first
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	panel := PlainText(m.(model).renderToolsPanel())
+	changed := strings.Index(panel, "active -> not_found")
+	first := strings.LastIndex(panel, "  active ")
+	if !strings.Contains(panel, "Crush status history") || changed < 0 || first < changed {
+		t.Errorf("Expected Crush's changes, newest first, got:\n%s", panel)
+	}
+}
+
+func TestToolCursorMarksRowWithColors(t *testing.T) {
+	// Styled rows start with an escape sequence the marker must not split
+	defer lipgloss.SetColorProfile(lipgloss.ColorProfile())
+	lipgloss.SetColorProfile(termenv.ANSI256)
+
+	trk := tracker.NewTracker(time.Now)
+	trk.SetToolStatus(tracker.ToolStatus{Name: "Aider", Tier: tracker.TierFullTracking, Status: "active"})
+	trk.SetToolStatus(tracker.ToolStatus{Name: "Crush", Tier: tracker.TierFullTracking, Status: "idle"})
+
+	var m tea.Model = InitialModelWith(config.Load(), trk)
+	m, _ = m.Update(tickMsg{})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc}) // Dismiss the first-run help
+
+	// 1. Focus the tools panel and move the cursor to the second tool
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyTab})
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	panel := m.(model).renderToolsPanel()
+	if !strings.Contains(panel, "\x1b[") {
+		t.Fatalf("Expected a colored panel, got %q", panel)
+	}
+
+	// 2. Only the selected row is marked, and no escape sequence is broken
+	var marked []string
+	for _, line := range strings.Split(PlainText(panel), "\n") {
+		if strings.Contains(line, "[") {
+			t.Errorf("Expected no stray escape codes, got %q", line)
+		}
+		if strings.Contains(line, ">") {
+			marked = append(marked, line)
+		}
+	}
+	if len(marked) != 1 || !strings.Contains(marked[0], "Crush") {
+		t.Errorf("Expected only the Crush row marked, got %q", marked)
+	}
+}
+
+func TestResetKeyAsksAttachedDaemon(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.MkdirAll(filepath.Join(home, ".burnrate"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	storage.SetReadOnly(true)
+	defer storage.SetReadOnly(false)
+
+	trk := tracker.NewTracker(time.Now)
+	trk.AddUsageWithTool("Aider", "gpt-4o", 1000, 100, 0.01)
+	var m tea.Model = InitialModelWith(config.Load(), trk)
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc}) // Dismiss the first-run help
+
+	// 1. r leaves the daemon's session alone here
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("r")})
+	if got := len(trk.GetUsages()); got != 1 {
+		t.Errorf("Expected the attached session kept, got %d usages", got)
+	}
+
+	// 2. And asks the daemon to reset instead
+	if !daemon.TakeResetRequest() {
+		t.Error("Expected a reset request for the daemon")
+	}
+}
+
+func TestTailEvents(t *testing.T) {
+	events := make([]storage.UsageEvent, 5)
+	for i := range events {
+		events[i].Timestamp = int64(5 - i) // Newest first
+	}
+
+	if got := tailEvents(events, 3); len(got) != 3 || got[0].Timestamp != 5 || got[2].Timestamp != 3 {
+		t.Errorf("Expected the 3 newest events, got %+v", got)
+	}
+	if got := tailEvents(events, 10); len(got) != 5 {
+		t.Errorf("Expected all 5 events under a larger tail, got %d", len(got))
+	}
+	if got := tailEvents(events, 0); len(got) != 5 {
+		t.Errorf("Expected no tail to keep all 5 events, got %d", len(got))
+	}
+}
+
+func TestTailKeysResizeEventsView(t *testing.T) {
+	trk := tracker.NewTracker(time.Now)
+	for i := 0; i < 75; i++ {
+		trk.AddUsageWithTool("OpenCode", "gpt-4o", 1000, 100, 0.01)
+	}
+
+	cfg := config.Load()
+	cfg.EventTail = 20
+	m := InitialModelWith(cfg, trk)
+	m.openEvents("gpt-4o")
+	press := func(k string) {
+		next, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)})
+		m = next.(model)
+	}
+
+	// 1. The events view starts at the configured tail, and says it's cut
+	if got := len(m.eventsTable.Rows()); got != 20 {
+		t.Errorf("Expected 20 rows, got %d", got)
+	}
+	if title := PlainText(m.renderEvents()); !strings.Contains(title, "20 of 75 latest events") {
+		t.Errorf("Expected the title to count the cut events, got %q", title)
+	}
+
+	// 2. + shows a step more
+	press("+")
+	if got := len(m.eventsTable.Rows()); got != 20+tailStep {
+		t.Errorf("Expected %d rows after +, got %d", 20+tailStep, got)
+	}
+
+	// 3. - never goes below one step
+	for i := 0; i < 5; i++ {
+		press("-")
+	}
+	if got := len(m.eventsTable.Rows()); got != tailStep {
+		t.Errorf("Expected %d rows at the smallest tail, got %d", tailStep, got)
+	}
+}
@@ -0,0 +1,147 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bangarangler/burnrate/internal/log"
+	"github.com/bangarangler/burnrate/internal/storage"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// tableMode is what the usage table area shows
+type tableMode int
+
+const (
+	aggregateMode tableMode = iota // One row per model
+	eventsMode                     // One row per request for eventsModel
+	topMode                        // The most expensive requests, costliest first
+	sessionsMode                   // Runs of activity split by the session gap, newest first
+)
+
+// Event lists (the events and top views) show at most the tail size's rows,
+// so thousands of events don't all have to be rendered. It starts at the
+// configured EventTail and steps by tailStep with + and -.
+const (
+	defaultTail = 50
+	tailStep    = 10
+)
+
+// tailEvents keeps the first n of a newest- or costliest-first event list
+func tailEvents(events []storage.UsageEvent, n int) []storage.UsageEvent {
+	if n > 0 && len(events) > n {
+		return events[:n]
+	}
+	return events
+}
+
+// resizeTail grows or shrinks the tail size by tailStep, never below one
+// step, and reloads the event list on show
+func (m *model) resizeTail(delta int) {
+	m.tail = max(m.tail+delta, tailStep)
+	switch m.tableMode {
+	case eventsMode:
+		m.loadEvents()
+	case topMode:
+		m.loadTop()
+	}
+}
+
+func newEventsTable() table.Model {
+	t := table.New(
+		table.WithColumns([]table.Column{
+			{Title: "Time", Width: 16},
+			{Title: "Tool", Width: 17},
+			{Title: "Input", Width: 10},
+			{Title: "Output", Width: 10},
+			{Title: "Cost", Width: 12},
+		}),
+		table.WithFocused(true),
+		table.WithHeight(8),
+	)
+	t.SetStyles(tableStyles())
+	return t
+}
+
+// openEvents switches the table area to the given model's events
+func (m *model) openEvents(modelName string) {
+	m.tableMode = eventsMode
+	m.eventsModel = modelName
+	m.loadEvents()
+	m.eventsTable.GotoTop()
+	m.eventsScroll.follow(m.eventsTable)
+}
+
+// loadEvents fills the events table for eventsModel in the active window.
+// The session view reads the tracker; the others query history. The tool
+// filter applies here too, so the events add up to the row that was opened.
+func (m *model) loadEvents() {
+	var events []storage.UsageEvent
+
+	if m.activeView == "session" {
+		usages := m.tracker.GetUsages()
+		// Newest first, like the history query
+		for i := len(usages) - 1; i >= 0; i-- {
+			u := usages[i]
+			if u.Model != m.eventsModel {
+				continue
+			}
+			events = append(events, storage.UsageEvent{
+				Timestamp:        u.Timestamp.Unix(),
+				Tool:             u.Tool,
+				Model:            u.Model,
+				PromptTokens:     u.PromptTokens,
+				CompletionTokens: u.CompletionTokens,
+				Cost:             u.Cost,
+			})
+		}
+	} else {
+		since, err := storage.WindowStart(m.activeView, time.Now())
+		if err == nil {
+			events, err = storage.GetEventsForModel(m.eventsModel, since.Unix())
+		}
+		if err != nil {
+			log.Warnf("tui: failed to load events for %s: %v", m.eventsModel, err)
+		}
+	}
+
+	var shown []storage.UsageEvent
+	for _, e := range events {
+		if m.toolFilter == "" || e.Tool == m.toolFilter {
+			shown = append(shown, e)
+		}
+	}
+	m.eventsTotal = len(shown)
+
+	rows := []table.Row{}
+	for _, e := range tailEvents(shown, m.tail) {
+		rows = append(rows, table.Row{
+			time.Unix(e.Timestamp, 0).In(storage.Location()).Format("Jan 02 15:04:05"),
+			truncateCell(e.Tool, m.eventsTable.Columns()[1].Width),
+			formatTokens(e.PromptTokens),
+			formatTokens(e.CompletionTokens),
+			formatEventCost(e.Model, e.Cost),
+		})
+	}
+	m.eventsTable.SetRows(rows)
+	m.eventsScroll.follow(m.eventsTable)
+}
+
+// tailLabel counts the events shown, e.g. "50 of 812 latest events" when
+// the tail cuts the list short
+func tailLabel(shown, total int) string {
+	if shown < total {
+		return fmt.Sprintf("%d of %d latest events", shown, total)
+	}
+	return fmt.Sprintf("%d events", shown)
+}
+
+// renderEvents draws the drill-down: a title line and the events table
+func (m model) renderEvents() string {
+	title := " " + statValueStyle.Render(m.eventsModel) +
+		statLabelStyle.Render(" · "+m.activeView+" · ") +
+		statLabelStyle.Render(fmt.Sprintf("%s  (+/- to show more or fewer, esc to go back)",
+			tailLabel(len(m.eventsTable.Rows()), m.eventsTotal)))
+	return lipgloss.JoinVertical(lipgloss.Left, title, renderTableBox(m.eventsTable, m.eventsScroll))
+}
@@ -0,0 +1,70 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/bangarangler/burnrate/internal/tracker"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// codexOTELNote explains how to get token counts from Codex, whose session
+// logs don't include them
+const codexOTELNote = `Codex token counts need OTEL: add an [otel] section with
+exporter = "otlp-http" to ~/.codex/config.toml`
+
+// nothingTracked reports whether the session has nothing to show: no usage
+// yet and no full-tracking tool active or idle. Detection-only tools don't count.
+func (m model) nothingTracked() bool {
+	if len(m.tracker.GetUsages()) > 0 {
+		return false
+	}
+	for _, s := range m.tracker.GetToolStatuses() {
+		if s.Tier == tracker.TierFullTracking && (s.Status == "active" || s.Status == "idle") {
+			return false
+		}
+	}
+	return true
+}
+
+// renderFirstRun lists where each full-tracking tool was looked for and how
+// to get it tracked, shown over the dashboard when nothing is tracked
+func (m model) renderFirstRun() string {
+	lines := []string{
+		titleStyle.Render("Welcome to burnrate"),
+		subtitleStyle.Render("No tools are being tracked yet. Here's where burnrate looked:"),
+		"",
+	}
+
+	otelNote := false
+	var found int
+	for _, s := range m.tracker.GetToolStatuses() {
+		if s.Tier != tracker.TierFullTracking {
+			continue
+		}
+		found++
+		if s.Name == "Codex" && s.Status != "partial" {
+			// A partial Codex status already carries the OTEL hint
+			otelNote = true
+		}
+
+		lines = append(lines, formatToolStatus(s, false))
+		if s.Path != "" {
+			lines = append(lines, statLabelStyle.Render("    looked in "+s.Path))
+		}
+		if s.Hint != "" {
+			lines = append(lines, "    "+s.Hint)
+		}
+	}
+	if found == 0 {
+		lines = append(lines, statLabelStyle.Render("No tools are enabled; check --tools or $BURNRATE_TOOLS"))
+	}
+	if otelNote {
+		lines = append(lines, "", statLabelStyle.Render(codexOTELNote))
+	}
+
+	lines = append(lines, "",
+		footerStyle.Render("Run 'burnrate doctor' for a full check. Press any key to close"))
+
+	content := lipgloss.NewStyle().Align(lipgloss.Left).Render(strings.Join(lines, "\n"))
+	return modalStyle.Render(content)
+}
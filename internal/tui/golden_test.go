@@ -0,0 +1,104 @@
+package tui
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bangarangler/burnrate/internal/config"
+	"github.com/bangarangler/burnrate/internal/tracker"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Run "go test ./internal/tui -update" after an intended layout change
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// goldenSession is a fixed session: four requests from two tools, five
+// minutes apart, read a minute after the last
+func goldenSession() *tracker.Tracker {
+	now := time.Date(2025, 6, 2, 9, 10, 0, 0, time.UTC)
+	trk := tracker.NewTracker(func() time.Time { return now })
+	// No events are counted against the tools, so keep them from going idle
+	trk.SetToolActiveThreshold(0)
+
+	trk.SetToolStatus(tracker.ToolStatus{Name: "Aider", Tier: tracker.TierFullTracking, Status: "active", Message: "Watching usage.jsonl"})
+	trk.SetToolStatus(tracker.ToolStatus{Name: "OpenCode", Tier: tracker.TierFullTracking, Status: "active", Message: "Watching 3 sessions"})
+
+	usages := []struct {
+		tool, model        string
+		prompt, completion int
+		cost               float64
+	}{
+		{"Aider", "claude-sonnet-4", 12_000, 800, 0.048},
+		{"OpenCode", "gpt-4o", 30_000, 2_000, 0.095},
+		{"Aider", "claude-sonnet-4", 45_000, 1_500, 0.1575},
+		{"OpenCode", "deepseek-chat", 8_000, 1_000, 0.0033},
+	}
+	for _, u := range usages {
+		now = now.Add(5 * time.Minute)
+		trk.AddUsageWithTool(u.tool, u.model, u.prompt, u.completion, u.cost)
+	}
+	now = now.Add(time.Minute)
+	return trk
+}
+
+// goldenView is one golden file's dashboard state
+type goldenView struct {
+	name      string
+	key       string  // Switches to the view ("" stays on the session view)
+	compact   bool    // Starts in compact mode
+	hardLimit float64 // Daily hard limit, for the banner (0 = off)
+}
+
+// renderGolden renders the dashboard for v at a fixed size. Without a history
+// database the historical views show their empty states, so their layout
+// doesn't depend on today's date.
+func renderGolden(t *testing.T, v goldenView) string {
+	t.Helper()
+	cfg := &config.Config{DailyBudget: 5, DailyHardLimit: v.hardLimit, CurrencySymbol: "$", CurrencyRate: 1}
+
+	var m tea.Model = InitialModelWith(cfg, goldenSession()).WithCompact(v.compact)
+	m, _ = m.Update(tea.WindowSizeMsg{Width: 100, Height: 50})
+	if v.key != "" {
+		m, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(v.key)})
+	}
+	m, _ = m.Update(tickMsg{})
+	return m.View()
+}
+
+func TestDashboardGolden(t *testing.T) {
+	// Colors depend on the terminal; compare the layout alone
+	lipgloss.SetColorProfile(termenv.Ascii)
+
+	views := []goldenView{
+		{name: "session"},
+		{name: "today", key: "t"},
+		{name: "week", key: "w"},
+		{name: "compact", compact: true},
+		// The session's $0.30 is today's spend without a history database
+		{name: "banner", hardLimit: 0.25},
+	}
+	for _, v := range views {
+		t.Run(v.name, func(t *testing.T) {
+			got := renderGolden(t, v)
+			path := filepath.Join("testdata", v.name+".golden")
+
+			if *update {
+				if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+					t.Fatalf("Failed to write %s: %v", path, err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Failed to read %s (run with -update to create it): %v", path, err)
+			}
+			if got != string(want) {
+				t.Errorf("%s view differs from %s (run with -update if intended)\ngot:\n%s\nwant:\n%s", v.name, path, got, want)
+			}
+		})
+	}
+}
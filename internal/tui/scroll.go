@@ -0,0 +1,56 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// tableScroll tracks which rows of a table are on screen. The bubbles table
+// doesn't expose its viewport offset, so this mirrors it: the window only
+// moves when the cursor leaves it.
+type tableScroll struct {
+	top int // Index of the first visible row
+}
+
+// follow moves the window to keep t's cursor visible. Call it after anything
+// that moves the cursor or replaces the rows.
+func (s *tableScroll) follow(t table.Model) {
+	height := t.Height()
+	cursor := t.Cursor()
+	if cursor < s.top {
+		s.top = cursor
+	}
+	if cursor >= s.top+height {
+		s.top = cursor - height + 1
+	}
+	s.top = max(0, min(s.top, len(t.Rows())-height))
+}
+
+// restore puts back a cursor and window captured before t's rows were
+// replaced, clamped to the new row count
+func (s *tableScroll) restore(t *table.Model, cursor, top int) {
+	t.SetCursor(cursor)
+	s.top = top
+	s.follow(*t)
+}
+
+// indicator describes the visible rows, or "" when every row fits
+func (s tableScroll) indicator(t table.Model) string {
+	total := len(t.Rows())
+	if total <= t.Height() {
+		return ""
+	}
+	last := min(s.top+t.Height(), total)
+	return " " + statLabelStyle.Render(fmt.Sprintf("showing %d–%d of %d — ↑/↓ to scroll", s.top+1, last, total))
+}
+
+// renderTableBox draws a table in the table box, with the scroll indicator
+// under it when the rows overflow
+func renderTableBox(t table.Model, s tableScroll) string {
+	if indicator := s.indicator(t); indicator != "" {
+		return tableBoxStyle.Render(lipgloss.JoinVertical(lipgloss.Left, t.View(), indicator))
+	}
+	return tableBoxStyle.Render(t.View())
+}
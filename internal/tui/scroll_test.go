@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func numberedTable(n int) table.Model {
+	t := table.New(
+		table.WithColumns([]table.Column{{Title: "N", Width: 5}}),
+		table.WithFocused(true),
+		table.WithHeight(8),
+	)
+	rows := []table.Row{}
+	for i := 0; i < n; i++ {
+		rows = append(rows, table.Row{fmt.Sprint(i)})
+	}
+	t.SetRows(rows)
+	return t
+}
+
+func TestScrollIndicatorFollowsTheTable(t *testing.T) {
+	tbl := numberedTable(23)
+	var scroll tableScroll
+	scroll.follow(tbl)
+
+	// 1. At the top, the first rows are shown
+	if got := scroll.indicator(tbl); !strings.Contains(got, "showing 1–7 of 23") {
+		t.Errorf("Expected rows 1–7 of 23, got %q", got)
+	}
+
+	// 2. Moving past the bottom scrolls the window with the table's own view
+	down := tea.KeyMsg{Type: tea.KeyDown}
+	for i := 0; i < 10; i++ {
+		tbl, _ = tbl.Update(down)
+		scroll.follow(tbl)
+	}
+	if got := scroll.indicator(tbl); !strings.Contains(got, "showing 5–11 of 23") {
+		t.Errorf("Expected rows 5–11 of 23, got %q", got)
+	}
+	if first := strings.Fields(tbl.View())[1]; first != "4" {
+		t.Errorf("Expected the table to show row 4 first, got %s", first)
+	}
+
+	// 3. Moving up within the window doesn't scroll
+	up := tea.KeyMsg{Type: tea.KeyUp}
+	tbl, _ = tbl.Update(up)
+	scroll.follow(tbl)
+	if scroll.top != 4 {
+		t.Errorf("Expected top to stay at 4, got %d", scroll.top)
+	}
+
+	// 4. Replacing the rows keeps the position
+	tbl.SetRows(tbl.Rows())
+	scroll.follow(tbl)
+	if scroll.top != 4 || tbl.Cursor() != 9 {
+		t.Errorf("Expected top 4 and cursor 9 after SetRows, got %d and %d", scroll.top, tbl.Cursor())
+	}
+}
+
+func TestScrollIndicatorHiddenWhenRowsFit(t *testing.T) {
+	tbl := numberedTable(5)
+	var scroll tableScroll
+	scroll.follow(tbl)
+
+	if got := scroll.indicator(tbl); got != "" {
+		t.Errorf("Expected no indicator, got %q", got)
+	}
+}
@@ -0,0 +1,103 @@
+package tui
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+
+	"github.com/bangarangler/burnrate/internal/config"
+	"github.com/bangarangler/burnrate/internal/log"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// checkSessionCap compares the live session cost with SessionCap on each
+// tick. The cap's actions fire once when the cost crosses it; dropping back
+// under it (after a reset) re-arms the cap.
+func (m *model) checkSessionCap() tea.Cmd {
+	if m.config.SessionCap <= 0 {
+		return nil
+	}
+
+	m.capCost = m.tracker.GetSessionCost()
+	if m.capCost < m.config.SessionCap {
+		m.capTriggered = false
+		m.capDismissed = false
+		return nil
+	}
+	if m.capTriggered {
+		return nil
+	}
+	m.capTriggered = true
+
+	notify := hasCapAction(m.config.CapActions, "notify")
+	command := m.config.OnCap
+	if !notify && command == "" {
+		return nil
+	}
+
+	message := fmt.Sprintf("Session spend %s passed the %s cap",
+		config.FormatMoneyCents(m.capCost), config.FormatMoneyCents(m.config.SessionCap))
+	return func() tea.Msg {
+		if notify {
+			if err := desktopNotify("burnrate", message); err != nil {
+				log.Warnf("session cap: notification failed: %v", err)
+			}
+		}
+		if command != "" {
+			if err := runShell(command); err != nil {
+				log.Warnf("session cap: %q failed: %v", command, err)
+			}
+		}
+		return nil
+	}
+}
+
+// hasCapAction reports whether action is one of the configured cap actions
+func hasCapAction(actions []string, action string) bool {
+	for _, a := range actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// renderCapBanner renders a full-width warning once the session cap is
+// crossed, until it's dismissed. It's empty when the banner action is off.
+func (m model) renderCapBanner() string {
+	if !m.capTriggered || m.capDismissed || !hasCapAction(m.config.CapActions, "banner") {
+		return ""
+	}
+
+	text := fmt.Sprintf("SESSION CAP REACHED: %s this session (cap %s)  [d] dismiss",
+		config.FormatMoneyCents(m.capCost), config.FormatMoneyCents(m.config.SessionCap))
+
+	width := m.width
+	if width <= 0 {
+		width = lipgloss.Width(text) + 4
+	}
+	return limitBannerStyle.Width(width).Render(text)
+}
+
+// desktopNotify shows a desktop notification with the platform's own tool
+func desktopNotify(title, message string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("osascript", "-e", fmt.Sprintf("display notification %q with title %q", message, title))
+	case "windows":
+		return fmt.Errorf("desktop notifications aren't supported on windows")
+	default:
+		cmd = exec.Command("notify-send", title, message)
+	}
+	return cmd.Run()
+}
+
+// runShell runs a user's command through the platform shell and waits for it
+func runShell(command string) error {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/c", command).Run()
+	}
+	return exec.Command("sh", "-c", command).Run()
+}
@@ -0,0 +1,103 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bangarangler/burnrate/internal/config"
+	"github.com/bangarangler/burnrate/internal/log"
+	"github.com/bangarangler/burnrate/internal/storage"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func newSessionsTable() table.Model {
+	t := table.New(
+		table.WithColumns([]table.Column{
+			{Title: "Started", Width: 12},
+			{Title: "Length", Width: 8},
+			{Title: "Requests", Width: 8},
+			{Title: "Top model", Width: 26},
+			{Title: "Cost", Width: 12},
+		}),
+		table.WithFocused(true),
+		table.WithHeight(8),
+	)
+	t.SetStyles(tableStyles())
+	return t
+}
+
+// toggleSessions switches the table area between the window's sessions and
+// the per-model rollup
+func (m *model) toggleSessions() {
+	if m.tableMode == sessionsMode {
+		m.tableMode = aggregateMode
+		return
+	}
+	m.tableMode = sessionsMode
+	m.loadSessions()
+	m.sessionsTable.GotoTop()
+	m.sessionsScroll.follow(m.sessionsTable)
+}
+
+// sessionsWindow is the history window the sessions list covers: the
+// active one, or today's for the live session view
+func (m model) sessionsWindow() string {
+	if m.activeView == "session" {
+		return "today"
+	}
+	return m.activeView
+}
+
+// loadSessions fills the sessions table with the window's runs of activity,
+// split by the configured gap, newest first
+func (m *model) loadSessions() {
+	var sessions []storage.Session
+	since, err := storage.WindowStart(m.sessionsWindow(), time.Now())
+	if err == nil {
+		sessions, err = storage.GetSessionsSince(since.Unix(), m.config.SessionGap)
+	}
+	if err != nil {
+		log.Warnf("tui: failed to load sessions: %v", err)
+	}
+
+	rows := []table.Row{}
+	for i := len(sessions) - 1; i >= 0; i-- {
+		s := sessions[i]
+		rows = append(rows, table.Row{
+			s.Start.In(storage.Location()).Format("Jan 02 15:04"),
+			formatDuration(s.Duration()),
+			fmt.Sprintf("%d", s.Requests),
+			truncateCell(s.TopModel(), m.sessionsTable.Columns()[3].Width),
+			config.FormatMoney(s.Cost),
+		})
+	}
+	m.sessionsTable.SetRows(rows)
+	m.sessionsScroll.follow(m.sessionsTable)
+}
+
+// refreshSessionsToday counts today's sessions for the Today view. It's
+// called with the window totals, so shares their refresh interval.
+func (m *model) refreshSessionsToday() {
+	sessions, err := storage.GetSessions(m.config.SessionGap)
+	if err != nil {
+		m.sessionsToday = 0
+		return
+	}
+	m.sessionsToday = len(sessions)
+}
+
+// renderSessionsToday shows how many sessions there have been today, e.g.
+// "Sessions today 3"
+func (m model) renderSessionsToday() string {
+	return statLabelStyle.Render("Sessions today ") + statValueStyle.Render(fmt.Sprintf("%d", m.sessionsToday))
+}
+
+// renderSessions draws the sessions list: a title line and the table
+func (m model) renderSessions() string {
+	title := " " + statValueStyle.Render("Sessions") +
+		statLabelStyle.Render(" · "+m.sessionsWindow()+" · ") +
+		statLabelStyle.Render(fmt.Sprintf("%d, split by %s idle  (S or esc to go back)",
+			len(m.sessionsTable.Rows()), formatDuration(m.config.SessionGap)))
+	return lipgloss.JoinVertical(lipgloss.Left, title, renderTableBox(m.sessionsTable, m.sessionsScroll))
+}
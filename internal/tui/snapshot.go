@@ -0,0 +1,79 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bangarangler/burnrate/internal/config"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// snapshotNoteDuration is how long the footer confirms a saved snapshot
+const snapshotNoteDuration = 5 * time.Second
+
+// SnapshotDir returns where p saves snapshots: ~/.burnrate/snapshots
+func SnapshotDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".burnrate", "snapshots")
+}
+
+// saveSnapshot writes a rendered view as plain text, colors stripped, to a
+// file in dir named for now, and returns its path
+func saveSnapshot(dir, view string, now time.Time) (string, error) {
+	if dir == "" {
+		return "", fmt.Errorf("no home directory for snapshots")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, now.Format("2006-01-02-150405")+".txt")
+	if err := os.WriteFile(path, []byte(PlainText(view)+"\n"), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// PlainText strips a rendered view's colors and the trailing padding
+// lipgloss leaves, which makes pasted text ragged
+func PlainText(view string) string {
+	lines := strings.Split(ansi.Strip(view), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// RenderOnce builds the dashboard over tracker.Global, refreshes its data
+// once, and renders it at width x height, for printing without the event
+// loop. Nothing the refresh would run in the background, such as session
+// cap alerts, is started.
+func RenderOnce(cfg *config.Config, compact bool, width, height int) string {
+	m := InitialModel(cfg).WithCompact(compact)
+	m.firstRunChecked = true // An empty session prints as one, not as help
+
+	var view tea.Model = m
+	view, _ = view.Update(tea.WindowSizeMsg{Width: width, Height: height})
+	view, _ = view.Update(tickMsg(time.Now()))
+	return view.View()
+}
+
+// renderSnapshotNote confirms the latest snapshot, or why it failed, for a
+// few seconds after p
+func (m model) renderSnapshotNote() string {
+	if m.snapshotAt.IsZero() || time.Since(m.snapshotAt) > snapshotNoteDuration {
+		return ""
+	}
+	if m.snapshotErr != nil {
+		return lipgloss.NewStyle().Foreground(errorColor).Render("Snapshot failed: " + m.snapshotErr.Error())
+	}
+	return statLabelStyle.Render("Snapshot saved to " + m.snapshotPath)
+}
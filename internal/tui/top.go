@@ -0,0 +1,97 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/bangarangler/burnrate/internal/log"
+	"github.com/bangarangler/burnrate/internal/storage"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func newTopTable() table.Model {
+	t := table.New(
+		table.WithColumns([]table.Column{
+			{Title: "When", Width: 9},
+			{Title: "Model", Width: 30},
+			{Title: "Input", Width: 10},
+			{Title: "Output", Width: 10},
+			{Title: "Cost", Width: 12},
+		}),
+		table.WithFocused(true),
+		table.WithHeight(8),
+	)
+	t.SetStyles(tableStyles())
+	return t
+}
+
+// toggleTop switches the table area between the top requests and the
+// per-model rollup
+func (m *model) toggleTop() {
+	if m.tableMode == topMode {
+		m.tableMode = aggregateMode
+		return
+	}
+	m.tableMode = topMode
+	m.loadTop()
+	m.topTable.GotoTop()
+	m.topScroll.follow(m.topTable)
+}
+
+// loadTop fills the top table with the active window's most expensive
+// individual requests, as many as the tail size. The session view sorts the tracker's usages; the
+// others query history.
+func (m *model) loadTop() {
+	var events []storage.UsageEvent
+
+	if m.activeView == "session" {
+		usages := m.tracker.GetUsages()
+		sort.SliceStable(usages, func(i, j int) bool { return usages[i].Cost > usages[j].Cost })
+		for _, u := range usages {
+			// Older requests an attached daemon merged aren't one request
+			if u.Requests > 0 {
+				continue
+			}
+			events = append(events, storage.UsageEvent{
+				Timestamp:        u.Timestamp.Unix(),
+				Tool:             u.Tool,
+				Model:            u.Model,
+				PromptTokens:     u.PromptTokens,
+				CompletionTokens: u.CompletionTokens,
+				Cost:             u.Cost,
+			})
+		}
+	} else {
+		since, err := storage.WindowStart(m.activeView, time.Now())
+		if err == nil {
+			events, err = storage.GetTopEvents(since.Unix(), m.tail)
+		}
+		if err != nil {
+			log.Warnf("tui: failed to load top requests: %v", err)
+		}
+	}
+
+	rows := []table.Row{}
+	for _, e := range tailEvents(events, m.tail) {
+		rows = append(rows, table.Row{
+			formatRelativeTime(time.Unix(e.Timestamp, 0)),
+			truncateCell(e.Model, m.topTable.Columns()[1].Width),
+			formatTokens(e.PromptTokens),
+			formatTokens(e.CompletionTokens),
+			formatEventCost(e.Model, e.Cost),
+		})
+	}
+	m.topTable.SetRows(rows)
+	m.topScroll.follow(m.topTable)
+}
+
+// renderTop draws the top requests: a title line and the table
+func (m model) renderTop() string {
+	title := " " + statValueStyle.Render("Top requests") +
+		statLabelStyle.Render(" · "+m.activeView+" · ") +
+		statLabelStyle.Render(fmt.Sprintf("%d most expensive  (+/- to show more or fewer, x or esc to go back)",
+			len(m.topTable.Rows())))
+	return lipgloss.JoinVertical(lipgloss.Left, title, renderTableBox(m.topTable, m.topScroll))
+}
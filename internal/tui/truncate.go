@@ -0,0 +1,36 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// ellipsis replaces the part of a cell that doesn't fit
+const ellipsis = "…"
+
+// minNameWidth is the least of a model's name truncateCell keeps before a
+// provider suffix; any less and the suffix is cut instead
+const minNameWidth = 8
+
+// truncateCell fits s into width display cells, replacing what's cut with an
+// ellipsis, so a long value can't push the table's columns out of line. A
+// trailing " (provider)" is kept whole, cutting the end of the name before
+// it instead, since it tells apart the same model served by different
+// providers.
+func truncateCell(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	if ansi.StringWidth(s) <= width {
+		return s
+	}
+
+	if i := strings.LastIndex(s, " ("); i > 0 && strings.HasSuffix(s, ")") {
+		name, suffix := s[:i], s[i:]
+		if room := width - ansi.StringWidth(suffix); room >= minNameWidth {
+			return ansi.Truncate(name, room, ellipsis) + suffix
+		}
+	}
+	return ansi.Truncate(s, width, ellipsis)
+}
@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestTruncateCell(t *testing.T) {
+	tests := []struct {
+		in    string
+		width int
+		want  string
+	}{
+		// 1. Values that fit are left alone
+		{"gpt-4o", 35, "gpt-4o"},
+		{"claude-3-5-sonnet-20241022", 26, "claude-3-5-sonnet-20241022"},
+
+		// 2. A provider suffix is kept, cutting the name before it
+		{"claude-3-5-sonnet-20241022 (anthropic)", 30, "claude-3-5-sonnet… (anthropic)"},
+
+		// 3. Too narrow to keep the suffix and enough of the name: cut the end
+		{"claude-3-5-sonnet-20241022 (anthropic)", 16, "claude-3-5-sonn…"},
+
+		// 4. Parentheses that aren't a trailing suffix are ordinary text
+		{"gpt-4o (openai) preview build", 20, "gpt-4o (openai) pre…"},
+
+		// 5. Width counts display cells, not bytes
+		{"模型-模型-模型-模型", 9, "模型-模…"},
+
+		{"gpt-4o", 0, ""},
+	}
+	for _, tt := range tests {
+		got := truncateCell(tt.in, tt.width)
+		if got != tt.want {
+			t.Errorf("Expected %q at width %d to be %q, got %q", tt.in, tt.width, tt.want, got)
+		}
+		if lipgloss.Width(got) > tt.width {
+			t.Errorf("Expected %q to fit in %d cells, got %d", got, tt.width, lipgloss.Width(got))
+		}
+	}
+}
+
+func TestModelColumnWidth(t *testing.T) {
+	// 1. Short names keep the default width
+	if w := modelColumnWidth(12, 120); w != defaultModelWidth {
+		t.Errorf("Expected %d, got %d", defaultModelWidth, w)
+	}
+
+	// 2. Long names widen it, up to the maximum
+	if w := modelColumnWidth(42, 120); w != 42 {
+		t.Errorf("Expected 42, got %d", w)
+	}
+	if w := modelColumnWidth(90, 200); w != maxModelWidth {
+		t.Errorf("Expected %d, got %d", maxModelWidth, w)
+	}
+
+	// 3. A narrow terminal shrinks it, but never below the minimum
+	if w := modelColumnWidth(42, 70); w != 70-usageTableChrome {
+		t.Errorf("Expected %d, got %d", 70-usageTableChrome, w)
+	}
+	if w := modelColumnWidth(42, 40); w != minModelWidth {
+		t.Errorf("Expected %d, got %d", minModelWidth, w)
+	}
+}
@@ -0,0 +1,38 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bangarangler/burnrate/internal/storage"
+)
+
+// typicalBurnInterval is how often the usual burn rate is re-queried; two
+// weeks of history barely moves in that time
+const typicalBurnInterval = 5 * time.Minute
+
+// refreshTypicalBurn re-queries the usual burn rate once it's older than
+// typicalBurnInterval
+func (m *model) refreshTypicalBurn(now time.Time) {
+	if !m.typicalBurnAt.IsZero() && now.Sub(m.typicalBurnAt) < typicalBurnInterval || !historyAvailable() {
+		return
+	}
+	rate, ok, err := storage.GetTypicalBurnRate()
+	m.typicalBurn, m.typicalBurnOK = rate, ok && err == nil
+	m.typicalBurnAt = now
+}
+
+// renderVelocity compares the burn rate with the usual, e.g. " — 2.3× your
+// usual", highlighted once it reaches the configured velocity factor.
+// Without enough history there's no usual, and nothing is shown.
+func (m model) renderVelocity() string {
+	if !m.typicalBurnOK || m.typicalBurn <= 0 || m.burnRate <= 0 {
+		return ""
+	}
+	ratio := m.burnRate / m.typicalBurn
+	text := fmt.Sprintf(" — %.1f× your usual", ratio)
+	if factor := m.config.VelocityFactor; factor > 0 && ratio >= factor {
+		return velocityAlertStyle.Render(text)
+	}
+	return statLabelStyle.Render(text)
+}
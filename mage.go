@@ -16,7 +16,20 @@ var Default = Build
 
 func Build() error {
 	fmt.Println("Building burnrate...")
-	return sh.RunV("go", "build", "-trimpath", "-ldflags=-s -w", "-o", "burnrate", ".")
+	return sh.RunV("go", "build", "-trimpath", "-ldflags="+ldflags(), "-o", "burnrate", ".")
+}
+
+// ldflags strips the binary and stamps the version and commit from git,
+// leaving the defaults ("dev", no commit) outside a checkout
+func ldflags() string {
+	flags := "-s -w"
+	if version, err := sh.Output("git", "describe", "--tags", "--always", "--dirty"); err == nil && version != "" {
+		flags += " -X github.com/bangarangler/burnrate/cmd.Version=" + version
+	}
+	if commit, err := sh.Output("git", "rev-parse", "--short", "HEAD"); err == nil && commit != "" {
+		flags += " -X github.com/bangarangler/burnrate/cmd.Commit=" + commit
+	}
+	return flags
 }
 
 func Test() error {